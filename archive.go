@@ -0,0 +1,585 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// corpusArchiver writes and extracts the archives of the local corpus stored in
+// corpusDir, in the configured archive format and handling symbolic links as
+// configured. It is shared by the storage backends.
+type corpusArchiver struct {
+	logger        *slog.Logger
+	corpusDir     string
+	archiveFormat string
+	symlinks      string
+}
+
+// newCorpusArchiver returns the corpus archiver of the given config.
+func newCorpusArchiver(logger *slog.Logger, cfg *Config) corpusArchiver {
+	return corpusArchiver{
+		logger:        logger,
+		corpusDir:     cfg.Project.CorpusDir,
+		archiveFormat: cfg.Project.ArchiveFormat,
+		symlinks:      cfg.Project.CorpusSymlinks,
+	}
+}
+
+// archiveFormatOf returns the format of the corpus archive stored under the
+// given key, as detected from its extension.
+func archiveFormatOf(key string) string {
+	if strings.HasSuffix(key, "."+ArchiveFormatTarZst) {
+		return ArchiveFormatTarZst
+	}
+	return ArchiveFormatZip
+}
+
+// alternateArchiveKey returns the key under which the corpus archive stored
+// under the given key is found in the other archive format, i.e. from before
+// the archive format was changed.
+func alternateArchiveKey(key string) string {
+	if base, ok := strings.CutSuffix(key, "."+ArchiveFormatTarZst); ok {
+		return base + "." + ArchiveFormatZip
+	}
+	base := strings.TrimSuffix(key, "."+ArchiveFormatZip)
+	return base + "." + ArchiveFormatTarZst
+}
+
+// archiveContentType returns the Content-Type of corpus archives in the given
+// format.
+func archiveContentType(format string) string {
+	if format == ArchiveFormatTarZst {
+		return "application/zstd"
+	}
+	return "application/zip"
+}
+
+// extractArchive extracts the corpus archive in the given format stored at
+// archivePath into the destination directory corpusDir.
+//
+// It preserves file permissions and directory structure.
+func (a *corpusArchiver) extractArchive(archivePath, format string) error {
+	if format == ArchiveFormatTarZst {
+		return a.untarZstArchive(archivePath)
+	}
+	return a.unzipArchive(archivePath)
+}
+
+// unzipArchive extracts the contents of the given zip archive into the parent
+// directory of corpusDir, as archive entries are rooted at corpusDir's name.
+func (a *corpusArchiver) unzipArchive(zipPath string) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("opening zip: %w", err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			a.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	for _, f := range r.File {
+		if err := func(f *zip.File) error {
+			fullPath, err := a.entryPath(filepath.FromSlash(f.Name))
+			if err != nil {
+				return err
+			}
+
+			if f.Mode()&os.ModeSymlink != 0 {
+				return a.extractZipSymlink(f, fullPath)
+			}
+
+			if f.FileInfo().IsDir() {
+				err := os.MkdirAll(fullPath, f.Mode())
+				if err != nil {
+					return fmt.Errorf("creating dir %q: %w",
+						fullPath, err)
+				}
+				return nil
+			}
+
+			err = EnsureDirExists(filepath.Dir(fullPath))
+			if err != nil {
+				return fmt.Errorf("creating parent dir for "+
+					"%q: %w", fullPath, err)
+			}
+
+			srcFile, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("opening zip file %q: %w",
+					f.Name, err)
+			}
+			defer func() {
+				if err := srcFile.Close(); err != nil {
+					a.logger.Error("Failed to close "+
+						"file", "error", err)
+				}
+			}()
+
+			return a.writeArchiveFile(fullPath, f.Mode().Perm(),
+				srcFile)
+		}(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// untarZstArchive extracts the contents of the given zstd-compressed tarball
+// into the parent directory of corpusDir, as archive entries are rooted at
+// corpusDir's name.
+func (a *corpusArchiver) untarZstArchive(archivePath string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("opening tarball: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			a.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	zr, err := zstd.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("opening zstd stream: %w", err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tarball: %w", err)
+		}
+
+		fullPath, err := a.entryPath(header.Name)
+		if err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err := os.MkdirAll(fullPath, mode.Perm())
+			if err != nil {
+				return fmt.Errorf("creating dir %q: %w",
+					fullPath, err)
+			}
+
+		case tar.TypeReg:
+			err := EnsureDirExists(filepath.Dir(fullPath))
+			if err != nil {
+				return fmt.Errorf("creating parent dir for "+
+					"%q: %w", fullPath, err)
+			}
+
+			err = a.writeArchiveFile(fullPath, mode.Perm(), tr)
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			err := a.createSymlink(fullPath, header.Linkname)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// entryPath returns the path at which the archive entry with the given name,
+// rooted at corpusDir's name, is extracted. Entries outside corpusDir, and
+// entries below an existing symbolic link, which the extraction would follow,
+// are refused, so a crafted archive cannot make it write outside the corpus.
+func (a *corpusArchiver) entryPath(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+
+	rootDir := filepath.Clean(a.corpusDir)
+	fullPath := filepath.Join(filepath.Dir(rootDir), name)
+	rel, err := filepath.Rel(rootDir, fullPath)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("refusing to extract archive entry %q "+
+			"outside the corpus", name)
+	}
+
+	// Check the existing parents of the entry below corpusDir, down to
+	// the first one still to be created.
+	dir := rootDir
+	elems := strings.Split(rel, string(filepath.Separator))
+	for _, elem := range elems[:len(elems)-1] {
+		dir = filepath.Join(dir, elem)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("checking %q: %w", dir, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("refusing to extract archive "+
+				"entry %q below symlink %q", name, dir)
+		}
+	}
+
+	return fullPath, nil
+}
+
+// extractZipSymlink creates the symbolic link stored as the given zip entry,
+// whose content is the link's target, at fullPath.
+func (a *corpusArchiver) extractZipSymlink(f *zip.File, fullPath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("opening zip file %q: %w", f.Name, err)
+	}
+	defer func() {
+		if err := rc.Close(); err != nil {
+			a.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	target, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("reading zip file %q: %w", f.Name, err)
+	}
+
+	return a.createSymlink(fullPath, string(target))
+}
+
+// createSymlink creates a symbolic link to target at linkPath, replacing any
+// existing file. Links pointing outside corpusDir are refused, so a crafted
+// archive cannot make later writes escape the corpus.
+func (a *corpusArchiver) createSymlink(linkPath, target string) error {
+	if !symlinkInside(a.corpusDir, linkPath, target) {
+		return fmt.Errorf("refusing to create symlink %q pointing "+
+			"outside the corpus to %q", linkPath, target)
+	}
+
+	if err := EnsureDirExists(filepath.Dir(linkPath)); err != nil {
+		return fmt.Errorf("creating parent dir for %q: %w", linkPath,
+			err)
+	}
+	if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %q: %w", linkPath, err)
+	}
+	if err := os.Symlink(target, linkPath); err != nil {
+		return fmt.Errorf("creating symlink %q: %w", linkPath, err)
+	}
+
+	return nil
+}
+
+// symlinkInside reports whether a symbolic link at linkPath with the given
+// target resolves to a path inside rootDir. Absolute targets are never
+// considered inside, as the corpus may be extracted anywhere. Neither are
+// targets going up with ".." after going down, since going down through a
+// symbolic link, existing or created later, would resolve them elsewhere than
+// their text says.
+func symlinkInside(rootDir, linkPath, target string) bool {
+	if filepath.IsAbs(target) {
+		return false
+	}
+
+	descended := false
+	for _, elem := range strings.Split(filepath.ToSlash(target), "/") {
+		switch elem {
+		case "", ".":
+
+		case "..":
+			if descended {
+				return false
+			}
+
+		default:
+			descended = true
+		}
+	}
+
+	resolved := filepath.Join(filepath.Dir(linkPath), target)
+	rel, err := filepath.Rel(filepath.Clean(rootDir), resolved)
+	if err != nil {
+		return false
+	}
+
+	return filepath.IsLocal(rel)
+}
+
+// archivedSymlink decides how the symbolic link at path, with the given Lstat
+// info, is archived according to the configured symlink handling. It returns
+// the info of the entry to archive along with, when the link is preserved,
+// its target, or nil info if the link is left out of the archive.
+func (a *corpusArchiver) archivedSymlink(path string,
+	info os.FileInfo) (os.FileInfo, string, error) {
+
+	switch a.symlinks {
+	case CorpusSymlinksSkip:
+		a.logger.Warn("Skipping symlink in corpus", "path", path)
+		return nil, "", nil
+
+	case CorpusSymlinksPreserve:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("reading symlink %q: %w",
+				path, err)
+		}
+		if !symlinkInside(a.corpusDir, path, target) {
+			a.logger.Warn("Skipping symlink pointing outside "+
+				"the corpus", "path", path, "target", target)
+			return nil, "", nil
+		}
+		return info, target, nil
+
+	default:
+		linked, err := os.Stat(path)
+		if err != nil {
+			a.logger.Warn("Skipping broken symlink in corpus",
+				"path", path, "error", err)
+			return nil, "", nil
+		}
+		if !linked.Mode().IsRegular() {
+			a.logger.Warn("Skipping symlink to a non-regular "+
+				"file in corpus", "path", path)
+			return nil, "", nil
+		}
+		return linked, "", nil
+	}
+}
+
+// writeArchiveFile writes the content of an archive entry read from r to the
+// file at fullPath, created with the given permissions. A symbolic link at
+// fullPath is replaced rather than written through.
+func (a *corpusArchiver) writeArchiveFile(fullPath string, perm os.FileMode,
+	r io.Reader) error {
+
+	info, err := os.Lstat(fullPath)
+	if err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(fullPath); err != nil {
+			return fmt.Errorf("removing %q: %w", fullPath, err)
+		}
+	}
+
+	destFile, err := os.OpenFile(fullPath,
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("creating file %q: %w", fullPath, err)
+	}
+	defer func() {
+		if err := destFile.Close(); err != nil {
+			a.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	if _, err := io.Copy(destFile, r); err != nil {
+		return fmt.Errorf("copying to file %q: %w", fullPath, err)
+	}
+
+	return nil
+}
+
+// writeArchive compresses the contents of srcDir, which must be located inside
+// corpusDir, into a corpus archive in the configured format and writes the
+// archive to the provided io.PipeWriter.
+//
+// It is typically run in a separate goroutine and paired with an io.PipeReader
+// for streaming uploads to the storage backend.
+func (a *corpusArchiver) writeArchive(w *io.PipeWriter, srcDir string) error {
+	if a.archiveFormat == ArchiveFormatTarZst {
+		return a.tarZstTree(w, srcDir)
+	}
+	return a.zipTree(w, srcDir)
+}
+
+// zipTree compresses the contents of srcDir, which must be located inside
+// corpusDir, into a ZIP archive written to the provided io.PipeWriter. Entries
+// are named relative to the parent directory of corpusDir, so that archives of
+// any part of the corpus can be extracted with unzipArchive.
+func (a *corpusArchiver) zipTree(zipWriter *io.PipeWriter,
+	srcDir string) error {
+
+	zw := zip.NewWriter(zipWriter)
+	defer func() {
+		if err := zw.Close(); err != nil {
+			a.logger.Error("Failed to close zip writer", "error",
+				err)
+		}
+	}()
+
+	baseDir := filepath.Clean(a.corpusDir)
+
+	err := filepath.Walk(filepath.Clean(srcDir), func(path string,
+		info os.FileInfo, walkErr error) error {
+
+		if walkErr != nil {
+			return walkErr
+		}
+
+		relPath, err := filepath.Rel(filepath.Dir(baseDir), path)
+		if err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		var target string
+		if info.Mode()&os.ModeSymlink != 0 {
+			info, target, err = a.archivedSymlink(path, info)
+			if err != nil || info == nil {
+				return err
+			}
+		}
+
+		if info.IsDir() {
+			header := &zip.FileHeader{
+				Name:   relPath + "/",
+				Method: zip.Deflate,
+			}
+			header.SetMode(info.Mode())
+			_, err := zw.CreateHeader(header)
+			return err
+		}
+
+		// Preserved symlinks are stored with the symlink mode and
+		// their target as content, as done by the zip tool.
+		if target != "" {
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = relPath
+			header.SetMode(info.Mode())
+
+			writer, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(writer, target)
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening file %q: %w", path, err)
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				a.logger.Error("Failed to close file",
+					"error", err)
+			}
+		}()
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = relPath
+		header.Method = zip.Deflate
+		header.SetMode(info.Mode())
+
+		writer, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(writer, file)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// tarZstTree compresses the contents of srcDir, which must be located inside
+// corpusDir, into a zstd-compressed tarball written to the provided
+// io.PipeWriter. Entries are named like those written by zipTree, so that
+// tarballs of any part of the corpus can be extracted with untarZstArchive.
+func (a *corpusArchiver) tarZstTree(w *io.PipeWriter, srcDir string) error {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return fmt.Errorf("creating zstd writer: %w", err)
+	}
+	tw := tar.NewWriter(zw)
+
+	baseDir := filepath.Clean(a.corpusDir)
+
+	err = filepath.Walk(filepath.Clean(srcDir), func(path string,
+		info os.FileInfo, walkErr error) error {
+
+		if walkErr != nil {
+			return walkErr
+		}
+
+		relPath, err := filepath.Rel(filepath.Dir(baseDir), path)
+		if err != nil {
+			return err
+		}
+
+		var target string
+		if info.Mode()&os.ModeSymlink != 0 {
+			info, target, err = a.archivedSymlink(path, info)
+			if err != nil || info == nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, target)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening file %q: %w", path, err)
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				a.logger.Error("Failed to close file",
+					"error", err)
+			}
+		}()
+
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Unlike the ZIP writer, the tarball is only valid once both writers
+	// are flushed, so their errors must fail the upload.
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tar writer: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("closing zstd writer: %w", err)
+	}
+
+	return nil
+}
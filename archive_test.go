@@ -0,0 +1,543 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestArchiveAndExtractDir validates that a directory can be compressed to a
+// corpus archive of each format using writeArchive and subsequently
+// decompressed using extractArchive to reproduce the original directory
+// structure and file contents.
+func TestArchiveAndExtractDir(t *testing.T) {
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			testArchiveAndExtractDir(t, format)
+		})
+	}
+}
+
+func testArchiveAndExtractDir(t *testing.T, format string) {
+	// Create source directory with sample files.
+	sourceDir := filepath.Join(t.TempDir(), "test_corpus")
+	assert.NoError(t, os.Mkdir(sourceDir, 0o755))
+
+	fileContents := map[string][]byte{
+		"file1.txt": []byte("testing extractArchive"),
+		"file2.txt": []byte("testing writeArchive"),
+	}
+	for name, data := range fileContents {
+		path := filepath.Join(sourceDir, name)
+		assert.NoError(t, os.WriteFile(path, data, 0o644))
+	}
+
+	// Initialize the archiver for archiving.
+	archiver := &corpusArchiver{
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		corpusDir:     sourceDir,
+		archiveFormat: format,
+	}
+
+	// Stream the archive into a pipe.
+	pr, pw := io.Pipe()
+	go func() {
+		err := archiver.writeArchive(pw, sourceDir)
+		pw.CloseWithError(err)
+	}()
+
+	// Write the archive to a separate temporary workspace.
+	archiveDir := t.TempDir()
+	archiveName := "out." + format
+	archivePath := filepath.Join(archiveDir, archiveName)
+
+	archiveFile, err := os.Create(archivePath)
+	assert.NoError(t, err)
+
+	_, err = io.Copy(archiveFile, pr)
+	assert.NoError(t, err)
+	assert.NoError(t, archiveFile.Close())
+
+	// Initialize the archiver for extracting.
+	extractor := &corpusArchiver{
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		corpusDir: filepath.Join(archiveDir, "test_corpus"),
+	}
+
+	// Perform the extraction, detecting the format from the file name.
+	assert.NoError(t, extractor.extractArchive(archivePath,
+		archiveFormatOf(archiveName)))
+
+	// Validate directory entries.
+	parent := filepath.Dir(extractor.corpusDir)
+	entries, err := os.ReadDir(parent)
+	assert.NoError(t, err)
+
+	// Expect exactly the archive and the extracted directory
+	assert.Len(t, entries, 2)
+	for _, e := range entries {
+		switch e.Name() {
+		case archiveName:
+			assert.False(t, e.IsDir(), "%s should not be a "+
+				"directory", archiveName)
+		case "test_corpus":
+			assert.True(t, e.IsDir(), "test_corpus should be a "+
+				"directory")
+		default:
+			assert.Fail(t, "unexpected entry %q in %s", e.Name(),
+				parent)
+		}
+	}
+
+	// Validate contents of the extracted directory.
+	files, err := os.ReadDir(extractor.corpusDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, len(fileContents))
+
+	var fileNames []string
+	for _, f := range files {
+		fileNames = append(fileNames, f.Name())
+		assert.False(t, f.IsDir(), "%s should be a file", f.Name())
+	}
+	sort.Strings(fileNames)
+	assert.Equal(t, []string{"file1.txt", "file2.txt"}, fileNames)
+
+	// Verify file content.
+	for name, expected := range fileContents {
+		path := filepath.Join(extractor.corpusDir, name)
+		actual, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+// TestArchiveSymlinks verifies that symbolic links in the corpus are archived
+// according to the configured symlink handling, in each archive format.
+func TestArchiveSymlinks(t *testing.T) {
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			testArchiveSymlinks(t, format)
+		})
+	}
+}
+
+func testArchiveSymlinks(t *testing.T, format string) {
+	outside := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(outside, []byte("secret"), 0o644))
+
+	sourceDir := filepath.Join(t.TempDir(), "test_corpus")
+	writeFiles(t, sourceDir, map[string]string{
+		"pkg/testdata/fuzz/FuzzA/input": "input",
+	})
+	targetDir := filepath.Join(sourceDir, "pkg", "testdata", "fuzz",
+		"FuzzA")
+	assert.NoError(t, os.Symlink("input", filepath.Join(targetDir,
+		"inside")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(targetDir,
+		"outside")))
+
+	tests := []struct {
+		symlinks string
+
+		// inside and outside describe the extracted links: "file"
+		// for a regular file, "link" for a symlink, or "" if absent.
+		inside  string
+		outside string
+	}{
+		{CorpusSymlinksFollow, "file", "file"},
+		{CorpusSymlinksSkip, "", ""},
+		{CorpusSymlinksPreserve, "link", ""},
+	}
+
+	for _, tc := range tests {
+		archiver := &corpusArchiver{
+			logger: slog.New(slog.NewTextHandler(io.Discard,
+				nil)),
+			corpusDir:     sourceDir,
+			archiveFormat: format,
+			symlinks:      tc.symlinks,
+		}
+		archivePath := filepath.Join(t.TempDir(), "out."+format)
+		writeArchiveToFile(t, archiver, archivePath)
+
+		extractor := &corpusArchiver{
+			logger: slog.New(slog.NewTextHandler(io.Discard,
+				nil)),
+			corpusDir: filepath.Join(t.TempDir(), "test_corpus"),
+		}
+		assert.NoError(t, extractor.extractArchive(archivePath,
+			format), tc.symlinks)
+
+		extractedDir := filepath.Join(extractor.corpusDir, "pkg",
+			"testdata", "fuzz", "FuzzA")
+		for name, kind := range map[string]string{
+			"inside":  tc.inside,
+			"outside": tc.outside,
+		} {
+			path := filepath.Join(extractedDir, name)
+			info, err := os.Lstat(path)
+			if kind == "" {
+				assert.True(t, os.IsNotExist(err), tc.symlinks,
+					name)
+				continue
+			}
+			if !assert.NoError(t, err, tc.symlinks, name) {
+				continue
+			}
+
+			isLink := info.Mode()&os.ModeSymlink != 0
+			assert.Equal(t, kind == "link", isLink, tc.symlinks,
+				name)
+
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			expected := "input"
+			if name == "outside" {
+				expected = "secret"
+			}
+			assert.Equal(t, expected, string(data), tc.symlinks)
+		}
+	}
+}
+
+// TestExtractEscapingSymlink verifies that extracting an archive holding a
+// symbolic link pointing outside the corpus fails without creating the link.
+func TestExtractEscapingSymlink(t *testing.T) {
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			archivePath := filepath.Join(t.TempDir(),
+				"out."+format)
+			writeSymlinkArchive(t, archivePath, format,
+				"test_corpus/escape", "../../etc")
+
+			extractor := &corpusArchiver{
+				logger: slog.New(slog.NewTextHandler(
+					io.Discard, nil)),
+				corpusDir: filepath.Join(t.TempDir(),
+					"test_corpus"),
+			}
+			err := extractor.extractArchive(archivePath, format)
+			assert.ErrorContains(t, err, "pointing outside the "+
+				"corpus")
+
+			_, err = os.Lstat(filepath.Join(extractor.corpusDir,
+				"escape"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// writeArchiveToFile writes the archive of the archiver's corpus directory to
+// the file at archivePath.
+func writeArchiveToFile(t *testing.T, archiver *corpusArchiver,
+	archivePath string) {
+
+	t.Helper()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(archiver.writeArchive(pw,
+			archiver.corpusDir))
+	}()
+
+	archiveFile, err := os.Create(archivePath)
+	assert.NoError(t, err)
+	_, err = io.Copy(archiveFile, pr)
+	assert.NoError(t, err)
+	assert.NoError(t, archiveFile.Close())
+}
+
+// writeSymlinkArchive writes an archive in the given format to archivePath,
+// holding a single symbolic link entry with the given name and target, as a
+// crafted archive would.
+func writeSymlinkArchive(t *testing.T, archivePath, format, name,
+	target string) {
+
+	t.Helper()
+
+	writeCraftedArchive(t, archivePath, format, []craftedEntry{
+		{name: name, target: target},
+	})
+}
+
+// craftedEntry is an entry of a crafted archive: a symbolic link to target if
+// set, and a regular file holding content otherwise.
+type craftedEntry struct {
+	name    string
+	target  string
+	content string
+}
+
+// writeCraftedArchive writes an archive in the given format to archivePath,
+// holding the given entries in order.
+func writeCraftedArchive(t *testing.T, archivePath, format string,
+	entries []craftedEntry) {
+
+	t.Helper()
+
+	archiveFile, err := os.Create(archivePath)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, archiveFile.Close())
+	}()
+
+	if format == ArchiveFormatZip {
+		zw := zip.NewWriter(archiveFile)
+		for _, e := range entries {
+			header := &zip.FileHeader{Name: e.name}
+			header.SetMode(0o644)
+			data := e.content
+			if e.target != "" {
+				header.SetMode(os.ModeSymlink | 0o777)
+				data = e.target
+			}
+			w, err := zw.CreateHeader(header)
+			assert.NoError(t, err)
+			_, err = io.WriteString(w, data)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, zw.Close())
+		return
+	}
+
+	zw, err := zstd.NewWriter(archiveFile)
+	assert.NoError(t, err)
+	tw := tar.NewWriter(zw)
+	for _, e := range entries {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.name,
+			Size:     int64(len(e.content)),
+			Mode:     0o644,
+		}
+		if e.target != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = e.target
+			header.Size = 0
+			header.Mode = 0o777
+		}
+		assert.NoError(t, tw.WriteHeader(header))
+		if e.target == "" {
+			_, err := io.WriteString(tw, e.content)
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, zw.Close())
+}
+
+// TestExtractChainedSymlinks verifies that symbolic links that only point
+// outside the corpus once resolved through other links of the archive are
+// refused.
+func TestExtractChainedSymlinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []craftedEntry
+	}{
+		{
+			name: "link below link",
+			entries: []craftedEntry{
+				{name: "test_corpus/a", target: "."},
+				{name: "test_corpus/a/b", target: "../.."},
+			},
+		},
+		{
+			name: "up through link",
+			entries: []craftedEntry{
+				{name: "test_corpus/s", target: "."},
+				{name: "test_corpus/b", target: "s/.."},
+			},
+		},
+		{
+			name: "up through later link",
+			entries: []craftedEntry{
+				{name: "test_corpus/b", target: "s/.."},
+				{name: "test_corpus/s", target: "."},
+			},
+		},
+	}
+
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarZst} {
+		for _, tc := range tests {
+			t.Run(format+"/"+tc.name, func(t *testing.T) {
+				archivePath := filepath.Join(t.TempDir(),
+					"out."+format)
+				writeCraftedArchive(t, archivePath, format,
+					tc.entries)
+
+				extractor := &corpusArchiver{
+					logger: slog.New(
+						slog.DiscardHandler),
+					corpusDir: filepath.Join(t.TempDir(),
+						"test_corpus"),
+				}
+				err := extractor.extractArchive(archivePath,
+					format)
+				assert.Error(t, err)
+
+				_, err = os.Lstat(filepath.Join(
+					extractor.corpusDir, "b"))
+				assert.True(t, os.IsNotExist(err))
+			})
+		}
+	}
+}
+
+// TestExtractThroughSymlinkedDir verifies that archive entries are never
+// written through a symbolic link to a directory, nor outside the corpus
+// directory next to it.
+func TestExtractThroughSymlinkedDir(t *testing.T) {
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			parentDir := t.TempDir()
+			corpusDir := filepath.Join(parentDir, "test_corpus")
+			siblingDir := filepath.Join(parentDir, "sibling")
+			assert.NoError(t, os.MkdirAll(corpusDir, 0o755))
+			assert.NoError(t, os.MkdirAll(siblingDir, 0o755))
+
+			// A link to the sibling directory left in the local
+			// corpus.
+			assert.NoError(t, os.Symlink("../sibling",
+				filepath.Join(corpusDir, "d")))
+
+			extractor := &corpusArchiver{
+				logger:    slog.New(slog.DiscardHandler),
+				corpusDir: corpusDir,
+			}
+
+			for _, name := range []string{"test_corpus/d/x",
+				"sibling/x"} {
+
+				archivePath := filepath.Join(t.TempDir(),
+					"out."+format)
+				writeCraftedArchive(t, archivePath, format,
+					[]craftedEntry{
+						{name: name, content: "x"},
+					})
+
+				err := extractor.extractArchive(archivePath,
+					format)
+				assert.ErrorContains(t, err, "refusing", name)
+			}
+
+			_, err := os.Lstat(filepath.Join(siblingDir, "x"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// TestSymlinkInside verifies that only relative symlink targets resolving
+// inside the root directory are considered inside it.
+func TestSymlinkInside(t *testing.T) {
+	root := filepath.Join("/corpus", "test_corpus")
+	link := filepath.Join(root, "pkg", "link")
+
+	tests := []struct {
+		target string
+		inside bool
+	}{
+		{"input", true},
+		{"../other/input", true},
+		{"../../test_corpus/pkg/input", true},
+		{"sub/../input", false},
+		{"../pkg/../..", false},
+		{"../..", false},
+		{"../../../etc/passwd", false},
+		{"/etc/passwd", false},
+		{filepath.Join(root, "pkg", "input"), false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.inside, symlinkInside(root, link, tc.target),
+			tc.target)
+	}
+}
+
+// TestArchiveKeys verifies that the format of corpus archives is detected from
+// their key, and that the key of an archive in the other format is derived
+// from it for migrating between formats.
+func TestArchiveKeys(t *testing.T) {
+	tests := []struct {
+		key       string
+		format    string
+		alternate string
+	}{
+		{"repo_corpus.zip", ArchiveFormatZip, "repo_corpus.tar.zst"},
+		{"repo_corpus.tar.zst", ArchiveFormatTarZst, "repo_corpus.zip"},
+		{"repo_corpus/pkg/sub.zip", ArchiveFormatZip,
+			"repo_corpus/pkg/sub.tar.zst"},
+		{"repo_corpus/pkg/sub.tar.zst", ArchiveFormatTarZst,
+			"repo_corpus/pkg/sub.zip"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.format, archiveFormatOf(tc.key), tc.key)
+		assert.Equal(t, tc.alternate, alternateArchiveKey(tc.key),
+			tc.key)
+	}
+}
+
+// TestZipTreeShards validates that the corpus of each package can be archived
+// as a separate shard with zipTree, and that extracting all shards with
+// unzipArchive reproduces the original corpus.
+func TestZipTreeShards(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Create a corpus with two packages.
+	sourceDir := filepath.Join(t.TempDir(), "test_corpus")
+	fileContents := map[string][]byte{
+		"pkg1/testdata/fuzz/FuzzFoo/seed1":   []byte("pkg1 input"),
+		"pkg2/sub/testdata/fuzz/FuzzBar/a1b": []byte("pkg2 input"),
+	}
+	for name, data := range fileContents {
+		path := filepath.Join(sourceDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, data, 0o644))
+	}
+
+	zipArchiver := &corpusArchiver{logger: logger, corpusDir: sourceDir}
+
+	// Archive each package as its own shard.
+	archiveDir := t.TempDir()
+	var shards []string
+	for _, pkg := range []string{"pkg1", "pkg2/sub"} {
+		pr, pw := io.Pipe()
+		go func() {
+			srcDir := filepath.Join(sourceDir, pkg, "testdata")
+			pw.CloseWithError(zipArchiver.zipTree(pw, srcDir))
+		}()
+
+		zipFile, err := os.CreateTemp(archiveDir, "shard-*.zip")
+		assert.NoError(t, err)
+
+		_, err = io.Copy(zipFile, pr)
+		assert.NoError(t, err)
+		assert.NoError(t, zipFile.Close())
+
+		shards = append(shards, zipFile.Name())
+	}
+
+	// Extract all shards into a fresh corpus directory.
+	unzipArchiver := &corpusArchiver{
+		logger:    logger,
+		corpusDir: filepath.Join(archiveDir, "test_corpus"),
+	}
+	for _, shard := range shards {
+		assert.NoError(t, unzipArchiver.unzipArchive(shard))
+	}
+
+	for name, expected := range fileContents {
+		path := filepath.Join(unzipArchiver.corpusDir, name)
+		actual, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"golang.org/x/time/rate"
+)
+
+// AzureBlobStore manages the corpus and reports stored in an Azure Blob
+// Storage container, like S3Store does for S3. It stores the corpus as a
+// single archive, without the sharding, versioning and quarantine supported by
+// S3Store.
+type AzureBlobStore struct {
+	corpusArchiver
+
+	ctx           context.Context
+	client        *azblob.Client
+	container     string
+	corpusKey     string
+	reportDir     string
+	mergeStrategy string
+	srcDir        string
+
+	// bandwidth throttles the uploads, and the downloads if
+	// limitDownload is set, or is nil if the bandwidth is unlimited.
+	bandwidth     *rate.Limiter
+	limitDownload bool
+}
+
+// NewAzureBlobStore constructs an AzureBlobStore for the given context, logger,
+// and config, authenticating with the DefaultAzureCredential chain.
+func NewAzureBlobStore(ctx context.Context, logger *slog.Logger,
+	cfg *Config) (*AzureBlobStore, error) {
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w",
+			err)
+	}
+
+	client, err := azblob.NewClient(cfg.Project.AzureAccountURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Blob Storage "+
+			"client: %w", err)
+	}
+
+	return &AzureBlobStore{
+		corpusArchiver: newCorpusArchiver(logger, cfg),
+		ctx:            ctx,
+		client:         client,
+		container:      cfg.Project.AzureContainer,
+		corpusKey:      cfg.Project.CorpusKey,
+		reportDir:      cfg.Project.ReportDir,
+		mergeStrategy:  cfg.Project.CorpusMergeStrategy,
+		srcDir:         cfg.Project.SrcDir,
+		bandwidth:      newBandwidthLimiter(cfg.Project.BandwidthLimit),
+		limitDownload:  cfg.Project.LimitDownloadBandwidth,
+	}, nil
+}
+
+// azureMetadataName returns the name under which the metadata with the given
+// key is stored in Azure, whose metadata names must be valid C# identifiers.
+func azureMetadataName(key string) string {
+	return strings.ReplaceAll(key, "-", "_")
+}
+
+// downloadObject downloads the blob stored under key in the container to the
+// local file at outPath. If the blob does not exist, it returns true with a nil
+// error, indicating that the process should continue with an empty data.
+func (abs *AzureBlobStore) downloadObject(outPath, key string) (bool, error) {
+	resp, err := abs.client.DownloadStream(abs.ctx, abs.container, key,
+		nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("downloading blob %q from container "+
+			"%q: %w", key, abs.container, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			abs.logger.Error("Failed to close blob reader", "error",
+				err)
+		}
+	}()
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return false, fmt.Errorf("creating local file: %w", err)
+	}
+	defer func() {
+		if err := outFile.Close(); err != nil {
+			abs.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	var r io.Reader = resp.Body
+	if abs.bandwidth != nil && abs.limitDownload {
+		r = &rateLimitedReader{
+			ctx:     abs.ctx,
+			r:       resp.Body,
+			limiter: abs.bandwidth,
+		}
+	}
+
+	n, err := io.Copy(outFile, r)
+	if err != nil {
+		return false, fmt.Errorf("downloading blob %q from container "+
+			"%q: %w", key, abs.container, err)
+	}
+
+	abs.logger.Info("Downloaded object", "bytes", n, "azureContainer",
+		abs.container, "key", key, "destPath", outPath)
+
+	return false, nil
+}
+
+// uploadObject uploads the content read from fileReader to the container at
+// the specified key, setting its content type to contentType, and adds the
+// provided metadata (if any). The content is streamed in blocks, so it is never
+// held in memory as a whole. The upload is throttled if the bandwidth is
+// limited.
+func (abs *AzureBlobStore) uploadObject(fileReader io.Reader, key,
+	contentType string, metadata map[string]string) error {
+
+	if abs.bandwidth != nil {
+		fileReader = &rateLimitedReader{
+			ctx:     abs.ctx,
+			r:       fileReader,
+			limiter: abs.bandwidth,
+		}
+	}
+
+	var blobMetadata map[string]*string
+	if len(metadata) > 0 {
+		blobMetadata = make(map[string]*string, len(metadata))
+		for k, v := range metadata {
+			blobMetadata[azureMetadataName(k)] = &v
+		}
+	}
+
+	_, err := abs.client.UploadStream(abs.ctx, abs.container, key,
+		fileReader, &azblob.UploadStreamOptions{
+			HTTPHeaders: &blob.HTTPHeaders{
+				BlobContentType: &contentType,
+			},
+			Metadata: blobMetadata,
+		})
+	if err != nil {
+		return fmt.Errorf("uploading blob %q to container %q: %w", key,
+			abs.container, err)
+	}
+
+	abs.logger.Info("Uploaded object to Azure", "azureContainer",
+		abs.container, "key", key)
+
+	return nil
+}
+
+// getLastMinimizedTime returns the "last-minimized" timestamp from the corpus
+// blob's metadata. If the blob does not exist or the "last-minimized" metadata
+// is missing or empty, it returns the current time.
+func (abs *AzureBlobStore) getLastMinimizedTime() (time.Time, error) {
+	props, err := abs.client.ServiceClient().
+		NewContainerClient(abs.container).
+		NewBlobClient(abs.corpusKey).GetProperties(abs.ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return time.Now(), nil
+		}
+		return time.Time{}, fmt.Errorf("fetching metadata for key %q: "+
+			"%w", abs.corpusKey, err)
+	}
+
+	// The metadata names are returned in canonical header casing.
+	var lastMinStr string
+	lastMinName := azureMetadataName("last-minimized")
+	for name, value := range props.Metadata {
+		if strings.EqualFold(name, lastMinName) && value != nil {
+			lastMinStr = *value
+		}
+	}
+	if lastMinStr == "" {
+		return time.Now(), nil
+	}
+
+	lastMinTime, err := time.Parse(time.RFC3339, lastMinStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last-minimized "+
+			"metadata for key %q: %w", abs.corpusKey, err)
+	}
+
+	return lastMinTime, nil
+}
+
+// snapshotCorpusBaseline always fails, as corpus quarantine is not supported
+// with Azure, which the config validation already ensures.
+func (abs *AzureBlobStore) snapshotCorpusBaseline() error {
+	return errors.New("corpus quarantine is not supported with the " +
+		"azure storage backend")
+}
+
+// downloadCorpus downloads the corpus archive from Azure and extracts it into
+// the local corpusDir. Returns true if the corpus is empty.
+func (abs *AzureBlobStore) downloadCorpus() (bool, error) {
+	empty, err := abs.fetchArchive(abs.corpusKey, abs.downloadObject)
+	if err != nil {
+		return false, err
+	}
+
+	if empty {
+		abs.logger.Info("Corpus object not found. Starting with empty "+
+			"corpus.", "azureContainer", abs.container, "key",
+			abs.corpusKey)
+	}
+
+	return empty, nil
+}
+
+// downloadCorpusAndReports downloads the corpus from Azure, merges any corpus
+// already present locally into it according to the merge strategy, and then
+// downloads any associated reports (unless the downloaded corpus is empty).
+func (abs *AzureBlobStore) downloadCorpusAndReports() error {
+	empty, err := abs.downloadMergedCorpus(abs.ctx, abs.srcDir,
+		abs.mergeStrategy, abs.downloadCorpus)
+	if err != nil {
+		return err
+	}
+
+	if empty {
+		return nil
+	}
+
+	if err := abs.downloadReports(); err != nil {
+		return fmt.Errorf("reports download failed: %w", err)
+	}
+
+	abs.logger.Info("Successfully downloaded reports", "azureContainer",
+		abs.container)
+
+	return nil
+}
+
+// downloadReports downloads all JSON report files from the container, saving
+// each under the reports directory.
+func (abs *AzureBlobStore) downloadReports() error {
+	pager := abs.client.NewListBlobsFlatPager(abs.container, nil)
+	for pager.More() {
+		page, err := pager.NextPage(abs.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			key := *item.Name
+
+			// Skip any file that does not have a .json extension
+			if filepath.Ext(key) != ".json" {
+				continue
+			}
+
+			localPath := filepath.Join(abs.reportDir, key)
+			err := EnsureDirExists(filepath.Dir(localPath))
+			if err != nil {
+				return fmt.Errorf("creating report directory: "+
+					"%w", err)
+			}
+
+			_, err = abs.downloadObject(localPath, key)
+			if err != nil {
+				return fmt.Errorf("download report %q: %w",
+					key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// uploadCorpusAndReports streams corpusDir as a corpus archive, uploads it to
+// Azure unless uploadCorpus is false, and then uploads any generated coverage
+// reports. Like with S3Store, the corpus and the reports are uploaded
+// independently.
+func (abs *AzureBlobStore) uploadCorpusAndReports(lastMinTime time.Time,
+	uploadCorpus bool) error {
+
+	var corpusErr error
+	if uploadCorpus {
+		corpusErr = abs.streamArchive(abs.corpusDir, abs.corpusKey,
+			lastMinTime, abs.uploadObject)
+	}
+	if corpusErr != nil {
+		corpusErr = fmt.Errorf("corpus upload failed: %w", corpusErr)
+	}
+
+	reportsErr := abs.uploadReports()
+	if reportsErr != nil {
+		reportsErr = fmt.Errorf("reports upload failed: %w",
+			reportsErr)
+	}
+
+	return combineUploadErrors(abs.logger.With("azureContainer",
+		abs.container), corpusErr, reportsErr)
+}
+
+// uploadReports uploads the files of the local reportDir to Azure, using each
+// file's path relative to reportDir as the blob name, with the appropriate
+// content type. Files whose content is unchanged from the blob already stored
+// under their name are skipped.
+func (abs *AzureBlobStore) uploadReports() error {
+	digests, err := abs.listBlobMD5s()
+	if err != nil {
+		return err
+	}
+
+	keys, skipped, err := changedReports(abs.reportDir, digests)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := abs.uploadReport(key); err != nil {
+			return err
+		}
+	}
+
+	abs.logger.Info("Uploaded reports", "azureContainer", abs.container,
+		"uploaded", len(keys), "unchanged", skipped)
+
+	return nil
+}
+
+// uploadReport uploads the report file stored under the given key, relative to
+// reportDir, with the appropriate content type.
+func (abs *AzureBlobStore) uploadReport(key string) error {
+	path := filepath.Join(abs.reportDir, filepath.FromSlash(key))
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open report %q: %w", path, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			abs.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	contentType := detectContentType(path)
+	if err := abs.uploadObject(file, key, contentType, nil); err != nil {
+		return fmt.Errorf("upload report %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// listBlobMD5s returns the hex-encoded MD5 digests of all blobs in the
+// container, keyed by their name, in the form of the ETags compared by
+// changedReports. Blobs without an MD5 digest, e.g. those uploaded in several
+// blocks, are left out, so the matching reports are always uploaded.
+func (abs *AzureBlobStore) listBlobMD5s() (map[string]string, error) {
+	digests := make(map[string]string)
+	pager := abs.client.NewListBlobsFlatPager(abs.container, nil)
+	for pager.More() {
+		page, err := pager.NextPage(abs.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Properties == nil ||
+				len(item.Properties.ContentMD5) == 0 {
+
+				continue
+			}
+			digests[*item.Name] = hex.EncodeToString(
+				item.Properties.ContentMD5)
+		}
+	}
+
+	return digests, nil
+}
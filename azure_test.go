@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzureBlob is a blob stored by fakeAzure.
+type fakeAzureBlob struct {
+	content     []byte
+	contentType string
+	metadata    http.Header
+}
+
+// fakeAzure is an in-memory fake of the subset of the Azure Blob Storage API
+// used by AzureBlobStore, holding the blobs of a single container.
+type fakeAzure struct {
+	mu       sync.Mutex
+	blobs    map[string]fakeAzureBlob
+	uploaded []string
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const containerPath = "/account/container"
+
+	if r.URL.Path == containerPath && r.URL.Query().Get("comp") == "list" {
+		f.list(w)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, containerPath+"/")
+	if r.Method == http.MethodPut {
+		content, _ := io.ReadAll(r.Body)
+		metadata := make(http.Header)
+		for k, v := range r.Header {
+			if strings.HasPrefix(k, "X-Ms-Meta-") {
+				metadata[k] = v
+			}
+		}
+		f.blobs[name] = fakeAzureBlob{
+			content:     content,
+			contentType: r.Header.Get("X-Ms-Blob-Content-Type"),
+			metadata:    metadata,
+		}
+		f.uploaded = append(f.uploaded, name)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	blob, ok := f.blobs[name]
+	if !ok {
+		w.Header().Set("X-Ms-Error-Code", "BlobNotFound")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	for k, v := range blob.metadata {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Content-Type", blob.contentType)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(blob.content)
+	}
+}
+
+// list writes the listing of all blobs of the container.
+func (f *fakeAzure) list(w http.ResponseWriter) {
+	type blobItem struct {
+		Name       string `xml:"Name"`
+		ContentMD5 string `xml:"Properties>Content-MD5"`
+	}
+	var listing struct {
+		XMLName xml.Name   `xml:"EnumerationResults"`
+		Blobs   []blobItem `xml:"Blobs>Blob"`
+	}
+	for name, blob := range f.blobs {
+		sum := md5.Sum(blob.content)
+		listing.Blobs = append(listing.Blobs, blobItem{
+			Name:       name,
+			ContentMD5: base64.StdEncoding.EncodeToString(sum[:]),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(listing)
+}
+
+// TestAzureBlobStoreRoundTrip verifies that the corpus and reports uploaded to
+// Azure, along with the last minimization time, are downloaded back, and that
+// the reports unchanged since the last upload are not uploaded again.
+func TestAzureBlobStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeAzure{blobs: make(map[string]fakeAzureBlob)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := azblob.NewClientWithNoCredential(server.URL+"/account/",
+		nil)
+	assert.NoError(t, err)
+
+	newStore := func() *AzureBlobStore {
+		corpusDir := filepath.Join(t.TempDir(), "corpus")
+
+		return &AzureBlobStore{
+			corpusArchiver: corpusArchiver{
+				logger:        slog.New(slog.DiscardHandler),
+				corpusDir:     corpusDir,
+				archiveFormat: ArchiveFormatZip,
+			},
+			ctx:           ctx,
+			client:        client,
+			container:     "container",
+			corpusKey:     "repo_corpus.zip",
+			reportDir:     t.TempDir(),
+			mergeStrategy: CorpusMergeUnion,
+		}
+	}
+
+	// Without a corpus blob, the corpus is empty and has never been
+	// minimized.
+	abs := newStore()
+	lastMinTime, err := abs.getLastMinimizedTime()
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), lastMinTime, time.Minute)
+
+	empty, err := abs.downloadCorpus()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+
+	writeFiles(t, abs.corpusDir, map[string]string{
+		"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+	})
+	writeFiles(t, abs.reportDir, map[string]string{
+		"state.json": `{"state":1}`,
+		"index.html": "<html></html>",
+	})
+	minimized := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, abs.uploadCorpusAndReports(minimized, true))
+	assert.ElementsMatch(t, []string{"repo_corpus.zip", "state.json",
+		"index.html"}, fake.uploaded)
+	assert.Equal(t, "text/html; charset=utf-8",
+		fake.blobs["index.html"].contentType)
+
+	lastMinTime, err = abs.getLastMinimizedTime()
+	assert.NoError(t, err)
+	assert.True(t, minimized.Equal(lastMinTime))
+
+	// Only the corpus and the changed report are uploaded again.
+	fake.uploaded = nil
+	writeFiles(t, abs.reportDir, map[string]string{
+		"state.json": `{"state":2}`,
+	})
+	assert.NoError(t, abs.uploadCorpusAndReports(minimized, true))
+	assert.ElementsMatch(t, []string{"repo_corpus.zip", "state.json"},
+		fake.uploaded)
+
+	// Without the corpus, only the changed report is uploaded.
+	fake.uploaded = nil
+	writeFiles(t, abs.reportDir, map[string]string{
+		"state.json": `{"state":3}`,
+	})
+	assert.NoError(t, abs.uploadCorpusAndReports(minimized, false))
+	assert.Equal(t, []string{"state.json"}, fake.uploaded)
+
+	// A fresh store gets back the corpus and the JSON reports.
+	abs = newStore()
+	assert.NoError(t, abs.downloadCorpusAndReports())
+
+	seed, err := os.ReadFile(filepath.Join(abs.corpusDir, "pkg",
+		"testdata", "fuzz", "FuzzFoo", "seed"))
+	assert.NoError(t, err)
+	assert.Equal(t, "input", string(seed))
+
+	state, err := os.ReadFile(filepath.Join(abs.reportDir, "state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"state":3}`, string(state))
+	assert.NoFileExists(t, filepath.Join(abs.reportDir, "index.html"))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// newBandwidthLimiter returns a limiter allowing bytesPerSec bytes to be
+// transferred per second, shared by all the transfers it throttles, or nil if
+// bytesPerSec is 0.
+func newBandwidthLimiter(bytesPerSec int64) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	// Allow bursts of up to a second worth of transfer, so the limiter
+	// can hand out reasonably large chunks at a time.
+	return rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+}
+
+// rateLimitedReader is an io.Reader throttling the reads from the wrapped
+// reader to the bandwidth allowed by its limiter.
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+// Read reads at most a burst of the limiter from the wrapped reader and waits
+// until the limiter allows the bytes read to be transferred.
+func (lr *rateLimitedReader) Read(p []byte) (int, error) {
+	if burst := lr.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		if err := lr.limiter.WaitN(lr.ctx, n); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+// rateLimitedWriterAt is an io.WriterAt throttling the writes to the wrapped
+// writer to the bandwidth allowed by its limiter.
+type rateLimitedWriterAt struct {
+	ctx     context.Context
+	w       io.WriterAt
+	limiter *rate.Limiter
+}
+
+// WriteAt writes p to the wrapped writer at offset off, in chunks of at most a
+// burst of the limiter, each waiting until the limiter allows it to be
+// transferred.
+func (lw *rateLimitedWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written:min(len(p), written+lw.limiter.Burst())]
+		if err := lw.limiter.WaitN(lw.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := lw.w.WriteAt(chunk, off+int64(written))
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
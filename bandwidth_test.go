@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimitedReader verifies that reading through a rate-limited reader
+// yields the wrapped reader's content, throttled to the limiter's bandwidth.
+func TestRateLimitedReader(t *testing.T) {
+	data := bytes.Repeat([]byte("corpus"), 1024)
+	limiter := newBandwidthLimiter(4096)
+
+	start := time.Now()
+	r := &rateLimitedReader{
+		ctx:     context.Background(),
+		r:       bytes.NewReader(data),
+		limiter: limiter,
+	}
+	read, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, data, read)
+
+	// The first burst is allowed immediately, the remaining 2048 bytes
+	// take half a second.
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
+
+// TestRateLimitedWriterAt verifies that writing through a rate-limited writer
+// writes the whole content at the right offset, throttled to the limiter's
+// bandwidth, and stops once the context is canceled.
+func TestRateLimitedWriterAt(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "object"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	data := bytes.Repeat([]byte("corpus"), 1024)
+	limiter := newBandwidthLimiter(4096)
+
+	start := time.Now()
+	w := &rateLimitedWriterAt{
+		ctx:     context.Background(),
+		w:       file,
+		limiter: limiter,
+	}
+	n, err := w.WriteAt(data, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	written, err := os.ReadFile(file.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, data, written[10:])
+
+	// A canceled transfer is not throttled any further.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.ctx = ctx
+	n, err = w.WriteAt(data, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, n)
+}
+
+// TestNewBandwidthLimiter verifies that a zero bandwidth is unlimited.
+func TestNewBandwidthLimiter(t *testing.T) {
+	assert.Nil(t, newBandwidthLimiter(0))
+	assert.NotNil(t, newBandwidthLimiter(1024))
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	// BisectResultOK marks a run of the selected corpus files that neither
+	// crashed nor timed out.
+	BisectResultOK = "ok"
+
+	// BisectResultCrash marks a run of the selected corpus files in which
+	// the fuzz target crashed.
+	BisectResultCrash = "crash"
+
+	// BisectResultTimeout marks a run of the selected corpus files that
+	// did not exit on its own within its duration, e.g. because an input
+	// is very slow.
+	BisectResultTimeout = "timeout"
+
+	// BisectResultError marks a run of the selected corpus files in which
+	// the fuzz container exited with an error, e.g. because it ran out of
+	// memory.
+	BisectResultError = "error"
+)
+
+// bisectReport describes the outcome of running a fuzz target with a subset of
+// its corpus: the selected corpus files, the result of the run, and its
+// resource usage.
+type bisectReport struct {
+	Package         string   `json:"package"`
+	Target          string   `json:"target"`
+	Inputs          []string `json:"inputs"`
+	Result          string   `json:"result"`
+	Error           string   `json:"error,omitempty"`
+	Crash           string   `json:"crash,omitempty"`
+	FailingInput    string   `json:"failing_input,omitempty"`
+	ElapsedSeconds  float64  `json:"elapsed_seconds"`
+	CPUSeconds      float64  `json:"cpu_seconds"`
+	PeakMemoryBytes uint64   `json:"peak_memory_bytes"`
+}
+
+// write renders the report to w, either as human-readable text or, if asJSON
+// is set, as a single line of JSON.
+func (r *bisectReport) write(w io.Writer, asJSON bool) error {
+	if asJSON {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to serialize bisect "+
+				"report: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "=== go-continuous-fuzz bisect-corpus ===\n"+
+		"Target: %s/%s\nInputs (%d): %s\nResult: %s\n", r.Package,
+		r.Target, len(r.Inputs), strings.Join(r.Inputs, ", "),
+		r.Result)
+	if err != nil {
+		return err
+	}
+
+	if r.Error != "" {
+		_, err := fmt.Fprintf(w, "Error: %s\n", r.Error)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "Elapsed: %.1fs\nCPU time: %.1fs\n"+
+		"Peak memory: %.1f MiB\n", r.ElapsedSeconds, r.CPUSeconds,
+		float64(r.PeakMemoryBytes)/(1<<20))
+	if err != nil {
+		return err
+	}
+
+	if r.Crash != "" {
+		_, err := fmt.Fprintf(w, "Crash:\n%s\nFailing input:\n%s\n",
+			r.Crash, r.FailingInput)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runBisectCorpus runs the fuzz target given to the bisect-corpus command with
+// only the selected subset of its corpus, and writes a report of the run to w.
+// It consists of:
+//  1. Cloning the Git repository specified in cfg.Project.SrcRepo.
+//  2. Downloading the corpus from the S3 bucket, unless disabled by
+//     cfg.Project.CorpusSyncMode.
+//  3. Gathering the selected corpus files of the target.
+//  4. Building the fuzz binary of the target, without its seed corpus from
+//     testdata, so only the selected corpus files are run.
+//  5. Fuzzing the target with the selected corpus files for the configured
+//     duration, while sampling its resource usage.
+func runBisectCorpus(ctx context.Context, logger *slog.Logger, cfg *Config,
+	w io.Writer) error {
+
+	opts := cfg.BisectCorpus
+	logger = logger.With("target", opts.Target).With("package",
+		opts.Package)
+
+	// Cleanup the directories created during previous runs.
+	cleanupTmpDirs(logger, cfg, false)
+
+	// 1. Clone the repository based on the provided configuration.
+	logger.Info("Cloning project repository", "url",
+		SanitizeURL(cfg.Project.SrcRepo), "path", cfg.Project.SrcDir)
+
+	_, err := cloneWithRetries(ctx, logger, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to clone project repository: %w", err)
+	}
+
+	// 2. Download the corpus from the storage bucket.
+	if cfg.Project.downloadsCorpus() {
+		store, err := NewCorpusStore(ctx, logger, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create corpus store: %w",
+				err)
+		}
+
+		if err := store.downloadCorpusAndReports(); err != nil {
+			return fmt.Errorf("failed to download corpus: %w", err)
+		}
+	}
+
+	// 3. Gather the selected corpus files into their own corpus directory,
+	// which is mounted into the fuzz container.
+	targetCorpusDir := filepath.Join(cfg.Project.CorpusDir, opts.Package,
+		"testdata", "fuzz", opts.Target)
+	corpus, err := listCorpusFiles(targetCorpusDir)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := selectCorpusSubset(corpus, opts.Inputs, opts.Half)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Selected corpus files", "count", len(inputs), "total",
+		len(corpus))
+
+	if err := os.RemoveAll(cfg.Project.BisectCorpusDir); err != nil {
+		return fmt.Errorf("failed to clean up bisect corpus: %w", err)
+	}
+	subsetDir := filepath.Join(cfg.Project.BisectCorpusDir, opts.Target)
+	if err := EnsureDirExists(subsetDir); err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		err := copyData(filepath.Join(targetCorpusDir, input),
+			filepath.Join(subsetDir, input))
+		if err != nil {
+			return fmt.Errorf("failed to copy corpus file %q: %w",
+				input, err)
+		}
+	}
+
+	// 4. Build the fuzz binary and copy the package's testdata next to it,
+	// as is done for the fuzzing cycles.
+	engine := newFuzzEngine(cfg.Fuzz.Engine)
+	err = createFuzzBinary(ctx, logger, cfg, engine, opts.Package,
+		opts.Target)
+	if err != nil {
+		return err
+	}
+
+	fuzzBinaryPath := filepath.Join(cfg.Project.BinaryDir, opts.Package,
+		opts.Target)
+	err = copyData(filepath.Join(cfg.Project.SrcDir, opts.Package,
+		"testdata"), filepath.Join(fuzzBinaryPath, "testdata"))
+	if err != nil {
+		return fmt.Errorf("failed to copy testdata directory: %w", err)
+	}
+
+	// The target's seed corpus in testdata would be run along with the
+	// selected corpus files, so drop it. The directory itself is kept,
+	// since failing inputs are saved in it.
+	seedCorpusDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz",
+		opts.Target)
+	if err := os.RemoveAll(seedCorpusDir); err != nil {
+		return fmt.Errorf("failed to remove seed corpus: %w", err)
+	}
+	if err := EnsureDirExists(seedCorpusDir); err != nil {
+		return err
+	}
+
+	// 5. Run the fuzz target in a Docker container.
+	cli, err := client.NewClientWithOpts(client.FromEnv,
+		client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed to start docker client: %w", err)
+	}
+	defer func() {
+		if err := cli.Close(); err != nil {
+			logger.Error("Failed to stop docker client", "error",
+				err)
+		}
+	}()
+
+	err = pullContainerImage(ctx, logger, cli, cfg.Fuzz.ContainerImage,
+		cfg.Fuzz.ImagePullAuth)
+	if err != nil {
+		return err
+	}
+
+	c := newContainer(ctx, logger, cli, &cfg.Fuzz, engine, opts.Package,
+		opts.Target)
+	c.fuzzBinaryPath = fuzzBinaryPath
+	c.hostCorpusPath = cfg.Project.BisectCorpusDir
+	c.cmd = engine.fuzzCmd(opts.Target, opts.Duration)
+	report, err := runCorpusSubset(ctx, c, opts, inputs)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Ran fuzz target with selected corpus files", "result",
+		report.Result)
+
+	return report.write(w, cfg.JSONSummary)
+}
+
+// runCorpusSubset runs the fuzz target of the bisect-corpus command in the
+// given container, which must be set up to fuzz it with the selected corpus
+// files, and reports the result of the run along with its resource usage. The
+// fuzzer is told to stop after the configured duration, with a grace period
+// before the run is considered timed out.
+func runCorpusSubset(ctx context.Context, c *Container,
+	opts BisectCorpusCommand, inputs []string) (*bisectReport, error) {
+
+	fuzzCtx, cancel := context.WithTimeout(ctx, opts.Duration+
+		ContainerGracePeriod)
+	defer cancel()
+	c.ctx = fuzzCtx
+
+	start := time.Now()
+	containerID, err := c.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error while starting container: %w",
+			err)
+	}
+	defer func() {
+		if err := c.Stop(containerID); err != nil {
+			c.logger.Error("Failed to stop container", "error",
+				err, "containerID", containerID)
+		}
+	}()
+
+	report := &bisectReport{
+		Package: opts.Package,
+		Target:  opts.Target,
+		Inputs:  inputs,
+		Result:  BisectResultOK,
+	}
+
+	// Sample the resource usage of the container while it runs.
+	usageChan := make(chan resourceUsage, 1)
+	go c.MonitorUsage(containerID, usageChan)
+
+	// Channels to receive either a fuzz failure or a container error.
+	fuzzCrashChan := make(chan fuzzCrash, 1)
+	errorChan := make(chan error, 1)
+	go c.WaitAndGetLogs(containerID, opts.Package, opts.Target,
+		fuzzCrashChan, errorChan)
+
+	select {
+	case <-fuzzCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		report.Result = BisectResultTimeout
+
+	case err := <-errorChan:
+		if err != nil {
+			report.Result = BisectResultError
+			report.Error = err.Error()
+		}
+
+	case crash := <-fuzzCrashChan:
+		report.Result = BisectResultCrash
+		report.Crash = crash.errorLogs
+		report.FailingInput = crash.failingInput
+	}
+	report.ElapsedSeconds = time.Since(start).Seconds()
+
+	// Stop the container and its stats stream before collecting the
+	// sampled resource usage.
+	if err := c.Stop(containerID); err != nil {
+		return nil, fmt.Errorf("failed to stop container %s after "+
+			"fuzzing: %w", containerID, err)
+	}
+	cancel()
+
+	usage := <-usageChan
+	report.CPUSeconds = usage.cpuTime.Seconds()
+	report.PeakMemoryBytes = usage.peakMemory
+
+	return report, nil
+}
+
+// listCorpusFiles returns the names of the corpus files in the given corpus
+// directory of a fuzz target, sorted by name.
+func listCorpusFiles(corpusDir string) ([]string, error) {
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus directory: %w",
+			err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// selectCorpusSubset returns the corpus files to run the fuzz target with,
+// sorted by name: the given inputs, or the whole corpus if no inputs are
+// given, narrowed down to the first or second half if requested. For an odd
+// number of files, the first half holds the extra file. Returns an error if an
+// input is not part of the corpus or no files are selected.
+func selectCorpusSubset(corpus, inputs []string, half string) ([]string,
+	error) {
+
+	selected := corpus
+	if len(inputs) > 0 {
+		inCorpus := make(map[string]bool, len(corpus))
+		for _, name := range corpus {
+			inCorpus[name] = true
+		}
+
+		seen := make(map[string]bool, len(inputs))
+		selected = nil
+		for _, input := range inputs {
+			if !inCorpus[input] {
+				return nil, fmt.Errorf("corpus file %q not "+
+					"found", input)
+			}
+			if !seen[input] {
+				seen[input] = true
+				selected = append(selected, input)
+			}
+		}
+	}
+
+	selected = append([]string(nil), selected...)
+	sort.Strings(selected)
+
+	mid := (len(selected) + 1) / 2
+	switch half {
+	case BisectHalfFirst:
+		selected = selected[:mid]
+	case BisectHalfSecond:
+		selected = selected[mid:]
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no corpus files selected")
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSelectCorpusSubset verifies that selectCorpusSubset selects the given
+// inputs, or the whole corpus, narrows them down to the requested half, and
+// rejects unknown inputs and empty selections.
+func TestSelectCorpusSubset(t *testing.T) {
+	corpus := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name     string
+		inputs   []string
+		half     string
+		expected []string
+		errMsg   string
+	}{
+		{
+			name:     "whole corpus",
+			expected: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "first half of corpus",
+			half:     BisectHalfFirst,
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "second half of corpus",
+			half:     BisectHalfSecond,
+			expected: []string{"d", "e"},
+		},
+		{
+			name:     "inputs sorted and deduplicated",
+			inputs:   []string{"e", "b", "e"},
+			expected: []string{"b", "e"},
+		},
+		{
+			name:     "second half of inputs",
+			inputs:   []string{"a", "c", "d", "e"},
+			half:     BisectHalfSecond,
+			expected: []string{"d", "e"},
+		},
+		{
+			name:   "unknown input",
+			inputs: []string{"a", "z"},
+			errMsg: `corpus file "z" not found`,
+		},
+		{
+			name:   "empty second half",
+			inputs: []string{"a"},
+			half:   BisectHalfSecond,
+			errMsg: "no corpus files selected",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := selectCorpusSubset(corpus, tc.inputs,
+				tc.half)
+			if tc.errMsg != "" {
+				assert.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, selected)
+		})
+	}
+}
+
+// TestBisectReportWrite verifies that the bisect report is rendered as text
+// and as JSON.
+func TestBisectReportWrite(t *testing.T) {
+	report := &bisectReport{
+		Package:         "parser",
+		Target:          "FuzzEvalExpr",
+		Inputs:          []string{"0a1b2c3d", "4e5f6a7b"},
+		Result:          BisectResultError,
+		Error:           "fuzz container exited with status 137",
+		ElapsedSeconds:  12.34,
+		CPUSeconds:      11.5,
+		PeakMemoryBytes: 2 << 30,
+	}
+
+	var text bytes.Buffer
+	assert.NoError(t, report.write(&text, false))
+	assert.Equal(t, "=== go-continuous-fuzz bisect-corpus ===\n"+
+		"Target: parser/FuzzEvalExpr\n"+
+		"Inputs (2): 0a1b2c3d, 4e5f6a7b\n"+
+		"Result: error\n"+
+		"Error: fuzz container exited with status 137\n"+
+		"Elapsed: 12.3s\n"+
+		"CPU time: 11.5s\n"+
+		"Peak memory: 2048.0 MiB\n", text.String())
+
+	var jsonOut bytes.Buffer
+	assert.NoError(t, report.write(&jsonOut, true))
+	assert.JSONEq(t, `{"package": "parser", "target": "FuzzEvalExpr", `+
+		`"inputs": ["0a1b2c3d", "4e5f6a7b"], "result": "error", `+
+		`"error": "fuzz container exited with status 137", `+
+		`"elapsed_seconds": 12.34, "cpu_seconds": 11.5, `+
+		`"peak_memory_bytes": 2147483648}`, jsonOut.String())
+}
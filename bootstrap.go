@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+)
+
+// errCorpusFound stops walking the corpus directory at its first input.
+var errCorpusFound = errors.New("corpus input found")
+
+// bootstrapPolicy tracks the bootstrap cycles of a new project: the first
+// cycles run without any historical corpus or report state, so they only
+// establish the coverage baselines, without opening coverage-derived issues
+// (e.g. for flaky coverage) that would be false alarms.
+type bootstrapPolicy struct {
+	// cycles is the number of initial cycles of a new project treated as
+	// bootstrap cycles.
+	cycles int
+
+	// left is the number of bootstrap cycles left, or -1 until the
+	// project has been checked for prior state on the first cycle.
+	left int
+}
+
+// newBootstrapPolicy returns the bootstrap policy of the given config, whose
+// project has not been checked for prior state yet.
+func newBootstrapPolicy(cfg *Config) *bootstrapPolicy {
+	return &bootstrapPolicy{
+		cycles: cfg.Fuzz.BootstrapCycles,
+		left:   -1,
+	}
+}
+
+// next reports whether the cycle starting, whose corpus and reports have been
+// downloaded into cfg.Project.CorpusDir and cfg.Project.ReportDir, is a
+// bootstrap cycle. Only a project with an empty corpus and no prior report
+// state on the first cycle is bootstrapped.
+func (b *bootstrapPolicy) next(logger *slog.Logger, cfg *Config,
+	cycle int) (bool, error) {
+
+	if b.left < 0 {
+		b.left = 0
+		if b.cycles > 0 {
+			fresh, err := isNewProject(cfg.Project.CorpusDir,
+				cfg.Project.ReportDir)
+			if err != nil {
+				return false, err
+			}
+			if fresh {
+				b.left = b.cycles
+			}
+		}
+	}
+
+	if b.left == 0 {
+		return false, nil
+	}
+	b.left--
+
+	logger.Info("Bootstrap mode active: establishing coverage baselines "+
+		"without opening coverage issues", "cycle", cycle,
+		"bootstrapCyclesLeft", b.left)
+
+	return true, nil
+}
+
+// isNewProject reports whether the project has never been fuzzed before, i.e.
+// whether its corpus directory holds no input and its report directory holds
+// no report state (state.json).
+func isNewProject(corpusDir, reportDir string) (bool, error) {
+	hasState, err := FileExistsInDir(reportDir, "state.json")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("checking report state: %w", err)
+	}
+	if hasState {
+		return false, nil
+	}
+
+	err = filepath.WalkDir(corpusDir, func(_ string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			return errCorpusFound
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errCorpusFound):
+		return false, nil
+
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return false, fmt.Errorf("checking corpus: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsNewProject verifies that a project is new only if its corpus holds no
+// input and its reports hold no state.
+func TestIsNewProject(t *testing.T) {
+	corpusDir := filepath.Join(t.TempDir(), "corpus")
+	reportDir := filepath.Join(t.TempDir(), "reports")
+
+	// Neither directory exists before the first download.
+	fresh, err := isNewProject(corpusDir, reportDir)
+	assert.NoError(t, err)
+	assert.True(t, fresh)
+
+	// Empty corpus directories hold no input.
+	writeFiles(t, reportDir, map[string]string{"index.html": ""})
+	assert.NoError(t, EnsureDirExists(filepath.Join(corpusDir, "pkg",
+		"testdata", "fuzz", "FuzzFoo")))
+	fresh, err = isNewProject(corpusDir, reportDir)
+	assert.NoError(t, err)
+	assert.True(t, fresh)
+
+	// A project with a corpus input is not new.
+	writeFiles(t, corpusDir, map[string]string{
+		"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+	})
+	fresh, err = isNewProject(corpusDir, reportDir)
+	assert.NoError(t, err)
+	assert.False(t, fresh)
+
+	// Neither is a project with report state.
+	stateDir := t.TempDir()
+	writeFiles(t, stateDir, map[string]string{"state.json": "[]"})
+	fresh, err = isNewProject(t.TempDir(), stateDir)
+	assert.NoError(t, err)
+	assert.False(t, fresh)
+}
+
+// TestBootstrapPolicy verifies that only the configured number of initial
+// cycles of a new project are bootstrap cycles, and that projects with prior
+// state are never bootstrapped.
+func TestBootstrapPolicy(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	cfg := &Config{
+		Project: Project{
+			CorpusDir: t.TempDir(),
+			ReportDir: t.TempDir(),
+		},
+		Fuzz: Fuzz{BootstrapCycles: 2},
+	}
+
+	bootstrap := newBootstrapPolicy(cfg)
+	var cycles []bool
+	for cycle := 1; cycle <= 3; cycle++ {
+		bootstrapping, err := bootstrap.next(logger, cfg, cycle)
+		assert.NoError(t, err)
+		cycles = append(cycles, bootstrapping)
+
+		// Fuzzing adds inputs to the corpus, which must not end the
+		// bootstrap cycles early.
+		writeFiles(t, cfg.Project.CorpusDir, map[string]string{
+			"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+		})
+	}
+	assert.Equal(t, []bool{true, true, false}, cycles)
+
+	// A project with a corpus is not bootstrapped.
+	bootstrap = newBootstrapPolicy(cfg)
+	bootstrapping, err := bootstrap.next(logger, cfg, 1)
+	assert.NoError(t, err)
+	assert.False(t, bootstrapping)
+
+	// Neither is a new project if bootstrapping is disabled.
+	cfg.Fuzz.BootstrapCycles = 0
+	cfg.Project.CorpusDir = t.TempDir()
+	bootstrap = newBootstrapPolicy(cfg)
+	bootstrapping, err = bootstrap.next(logger, cfg, 1)
+	assert.NoError(t, err)
+	assert.False(t, bootstrapping)
+}
@@ -3,14 +3,25 @@ package main
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/user"
+	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/distribution/reference"
+	"github.com/go-git/go-git/v5/plumbing"
 	flags "github.com/jessevdk/go-flags"
 )
 
@@ -23,16 +34,24 @@ const (
 	// are located
 	TmpReportDir = "reports"
 
+	// TmpBisectCorpusDir is the temporary directory where the corpus files
+	// selected by the bisect-corpus command are gathered.
+	TmpBisectCorpusDir = "bisect_corpus"
+
 	// TmpBinaryDir is the temporary directory where the fuzz target
 	// binaries are located.
 	TmpBinaryDir = "binaries"
 
+	// TmpCoverageDir is the temporary directory where each worker stages
+	// the coverage runs of its fuzz targets.
+	TmpCoverageDir = "coverage"
+
 	// ConfigFilename is the filename for the go-continuous-fuzz
 	// configuration file.
 	ConfigFilename = "go-continuous-fuzz.conf"
 
-	// ContainerImage specifies the Docker image to use for running the
-	// container.
+	// ContainerImage specifies the default Docker image to use for running
+	// the container.
 	ContainerImage = "golang:1.24.6"
 
 	// ContainerWorkDir specifies the working directory for the fuzz
@@ -43,11 +62,317 @@ const (
 	// for the fuzz corpus.
 	ContainerCorpusPath = "/go-continuous-fuzz-corpus"
 
+	// ContainerMemoryLimit specifies the default memory limit of the fuzz
+	// container, in bytes.
+	ContainerMemoryLimit = 2 * 1024 * 1024 * 1024
+
+	// MinContainerNanoCPUs is the smallest CPU limit of the fuzz containers
+	// accepted by Docker, in billionths of a CPU.
+	MinContainerNanoCPUs = 10_000_000
+
+	// OOMKillStatus is the exit status of a container killed with SIGKILL,
+	// as done by the kernel's OOM killer.
+	OOMKillStatus = 137
+
+	// RuntimeCheckTimeout is the maximum time the startup check waits for
+	// the Docker daemon to respond.
+	RuntimeCheckTimeout = 30 * time.Second
+
+	// DefaultCloneRetryWait is the initial wait before retrying a failed
+	// clone of the project repository.
+	DefaultCloneRetryWait = 5 * time.Second
+
+	// MaxCloneRetryWait caps the wait before retrying a failed clone of the
+	// project repository.
+	MaxCloneRetryWait = 1 * time.Minute
+
+	// TrackerCheckTimeout is the maximum time the startup check waits for
+	// GitHub to confirm the access to the crash repository.
+	TrackerCheckTimeout = 30 * time.Second
+
 	// ContainerGracePeriod specifies the grace period to account for
 	// container startup overhead and ensures that all targets have
 	// sufficient time to complete.
 	ContainerGracePeriod = 20 * time.Second
 
+	// StackDumpDelay is the time the other processes of a fuzz container
+	// are given to dump their goroutine stacks before its main process is
+	// signaled too.
+	StackDumpDelay = 1 * time.Second
+
+	// StackDumpTimeout is the maximum time waited for a fuzz container to
+	// dump its goroutine stacks and exit, when dumping them on timeout.
+	StackDumpTimeout = 15 * time.Second
+
+	// MaxStackDumpSize is the maximum size in bytes of the goroutine
+	// stack dump of a fuzz container that is captured.
+	MaxStackDumpSize = 1 << 20
+
+	// CorpusSyncBoth syncs the corpus and reports in both directions: they
+	// are downloaded before and uploaded after every fuzzing cycle.
+	CorpusSyncBoth = "both"
+
+	// CorpusSyncDownload only downloads the corpus and reports, so the S3
+	// bucket is never modified.
+	CorpusSyncDownload = "download"
+
+	// CorpusSyncUpload only uploads the corpus and reports, e.g. to migrate
+	// a local corpus into the S3 bucket.
+	CorpusSyncUpload = "upload"
+
+	// CorpusSyncNone disables syncing the corpus and reports entirely.
+	CorpusSyncNone = "none"
+
+	// CorpusMergeUnion keeps the inputs of both the local and downloaded
+	// corpus of a target, deduplicated by content.
+	CorpusMergeUnion = "union"
+
+	// CorpusMergeS3Wins keeps the downloaded corpus of a target present in
+	// both corpora.
+	CorpusMergeS3Wins = "s3-wins"
+
+	// CorpusMergeLocalWins keeps the local corpus of a target present in
+	// both corpora.
+	CorpusMergeLocalWins = "local-wins"
+
+	// CorpusMergeCoverageMax keeps whichever corpus of a target present in
+	// both corpora reaches the most coverage.
+	CorpusMergeCoverageMax = "coverage-max"
+
+	// SampleStrategyStaleness samples the fuzz targets of a cycle randomly,
+	// weighted by the time since they were last fuzzed.
+	SampleStrategyStaleness = "staleness"
+
+	// SampleStrategyUniform samples the fuzz targets of a cycle uniformly
+	// at random.
+	SampleStrategyUniform = "uniform"
+
+	// ScheduleStrategyEven splits the time of a cycle evenly among its fuzz
+	// targets.
+	ScheduleStrategyEven = "even"
+
+	// ScheduleStrategyWeighted splits the time of a cycle among its fuzz
+	// targets in proportion to the coverage they recently gained.
+	ScheduleStrategyWeighted = "weighted"
+
+	// OrphanCorpusKeep leaves the corpus of a fuzz target that is no longer
+	// discovered, e.g. after being renamed, in place.
+	OrphanCorpusKeep = "keep"
+
+	// OrphanCorpusArchive moves the corpus of a fuzz target that is no
+	// longer discovered under OrphanCorpusDir of its package.
+	OrphanCorpusArchive = "archive"
+
+	// OrphanCorpusDelete deletes the corpus of a fuzz target that is no
+	// longer discovered.
+	OrphanCorpusDelete = "delete"
+
+	// OrphanCorpusDir is the directory, relative to the testdata directory
+	// of a package's corpus, under which the corpora of its fuzz targets
+	// that are no longer discovered are archived. It is stored along with
+	// the corpus, but never fuzzed.
+	OrphanCorpusDir = "fuzz-orphaned"
+
+	// ArchiveFormatZip stores the corpus as Deflate-compressed ZIP
+	// archives.
+	ArchiveFormatZip = "zip"
+
+	// ArchiveFormatTarZst stores the corpus as zstd-compressed tarballs,
+	// which are smaller and faster to create than ZIP archives.
+	ArchiveFormatTarZst = "tar.zst"
+
+	// StorageBackendS3 stores the corpus and reports in an AWS S3 bucket.
+	StorageBackendS3 = "s3"
+
+	// StorageBackendGCS stores the corpus and reports in a Google Cloud
+	// Storage bucket.
+	StorageBackendGCS = "gcs"
+
+	// StorageBackendAzure stores the corpus and reports in an Azure Blob
+	// Storage container.
+	StorageBackendAzure = "azure"
+
+	// CrashTrackerGitHub reports crashes as issues of a GitHub repository.
+	CrashTrackerGitHub = "github"
+
+	// CrashTrackerGitLab reports crashes as issues of a GitLab project, on
+	// gitlab.com or a self-hosted instance.
+	CrashTrackerGitLab = "gitlab"
+
+	// IssueStateOpen and IssueStateClosed are the states of the issues
+	// listed from a crash tracker.
+	IssueStateOpen   = "open"
+	IssueStateClosed = "closed"
+
+	// CorpusSymlinksFollow archives the content of the file a symbolic
+	// link in the corpus points to, as a regular file.
+	CorpusSymlinksFollow = "follow"
+
+	// CorpusSymlinksSkip leaves symbolic links in the corpus out of its
+	// archives.
+	CorpusSymlinksSkip = "skip"
+
+	// CorpusSymlinksPreserve archives symbolic links in the corpus as
+	// links, as long as they point inside the corpus.
+	CorpusSymlinksPreserve = "preserve"
+
+	// MinFuzzDuration is the minimum duration a fuzz target is fuzzed for
+	// in each cycle.
+	MinFuzzDuration = 1 * time.Second
+
+	// TaskPriorityNormal is the priority of the fuzz targets queued in a
+	// cycle by default.
+	TaskPriorityNormal = 0
+
+	// TaskPriorityCrashing is the priority of the fuzz targets with open
+	// crash issues, when prioritizing them.
+	TaskPriorityCrashing = 1
+
+	// MaxScheduleWeight is the largest weight of a fuzz target in the
+	// weighted schedule, relative to a target that gained no coverage.
+	MaxScheduleWeight = 4.0
+
+	// ToolLabel is the label applied to every container started by
+	// go-continuous-fuzz, identifying it as managed by this tool.
+	ToolLabel = "io.go-continuous-fuzz.managed"
+
+	// RestoreCorpusCmd is the name of the subcommand restoring an archived
+	// corpus version.
+	RestoreCorpusCmd = "restore-corpus"
+
+	// PromoteCorpusCmd is the name of the subcommand promoting quarantined
+	// corpus inputs to the canonical corpus.
+	PromoteCorpusCmd = "promote-corpus"
+
+	// BisectCorpusCmd is the name of the subcommand running a fuzz target
+	// with a subset of its corpus.
+	BisectCorpusCmd = "bisect-corpus"
+
+	// ReproduceCmd is the name of the subcommand running a fuzz target
+	// against a single input to reproduce its crash.
+	ReproduceCmd = "reproduce"
+
+	// MigrateCorpusCmd is the name of the subcommand rewriting a corpus
+	// archive into the expected layout.
+	MigrateCorpusCmd = "migrate-corpus"
+
+	// BisectHalfFirst selects the first half of the corpus files selected
+	// by the bisect-corpus command, in name order.
+	BisectHalfFirst = "first"
+
+	// BisectHalfSecond selects the second half of the corpus files
+	// selected by the bisect-corpus command, in name order.
+	BisectHalfSecond = "second"
+
+	// LatestCorpusVersion selects the most recent archived corpus version.
+	LatestCorpusVersion = "latest"
+
+	// CorpusVersionLayout is the layout of the dates naming the archived
+	// corpus versions.
+	CorpusVersionLayout = time.DateOnly
+
+	// ReproduceInputPrefix prefixes the names of the failing inputs of open
+	// issues written to the testdata directory of a fuzz target to
+	// reproduce their crash, so they cannot clash with its seed corpus.
+	ReproduceInputPrefix = "gcf-reproduce-"
+
+	// MinIssueBodyLimit is the smallest allowed limit on the number of
+	// characters in the body of a crash issue.
+	MinIssueBodyLimit = 4096
+
+	// OutputTailLines is the number of last lines of a fuzz container's
+	// output included in the issue reporting its unrecognized failure.
+	OutputTailLines = 100
+
+	// UnknownFailureSignature is the signature under which unrecognized
+	// failures of fuzz containers are reported.
+	UnknownFailureSignature = "unknown-failure"
+
+	// OOMSignature is the signature under which fuzz containers killed
+	// for running out of memory are reported.
+	OOMSignature = "out-of-memory"
+
+	// OOMFailureLocation is the synthetic failure location of the fuzz
+	// crashes recognized from out-of-memory lines in the fuzzer output,
+	// under which they are deduplicated and reported.
+	OOMFailureLocation = "oom"
+
+	// FlakyCoverageSignature is the signature under which fuzz targets
+	// whose coverage varies across repeated measurements of the same
+	// corpus are reported.
+	FlakyCoverageSignature = "flaky-coverage"
+
+	// DisabledTargetSignature is the signature under which fuzz targets
+	// disabled for crashing early in consecutive cycles are tracked.
+	DisabledTargetSignature = "disabled"
+
+	// DigestSignature is the signature under which the daily digest
+	// issues listing the fuzz crashes are reported.
+	DigestSignature = "crash-digest"
+
+	// DigestEntryLimit is the maximum number of characters of the report
+	// of each crash listed in a digest issue.
+	DigestEntryLimit = 8192
+
+	// DigestPostTimeout is the maximum time taken to post the crashes of a
+	// cycle to the digest issue.
+	DigestPostTimeout = 1 * time.Minute
+
+	// ReportModePerCrash reports every fuzz crash signature in an issue of
+	// its own.
+	ReportModePerCrash = "per-crash"
+
+	// ReportModeDigest lists the fuzz crashes found each day in a single
+	// digest issue.
+	ReportModeDigest = "digest"
+
+	// LogSinkBufferLines is the number of log lines buffered for the log
+	// sink, beyond which new lines are dropped rather than blocking.
+	LogSinkBufferLines = 10000
+
+	// LogSinkBatchLines is the maximum number of log lines shipped to the
+	// log sink at once.
+	LogSinkBatchLines = 500
+
+	// LogSinkFlushInterval is how often the buffered log lines are shipped
+	// to the log sink, unless a full batch is shipped earlier.
+	LogSinkFlushInterval = time.Second
+
+	// LogSinkTimeout is the maximum time shipping a batch of log lines to
+	// the log sink may take.
+	LogSinkTimeout = 10 * time.Second
+
+	// LogSinkLokiPath is the path of the push API of Loki, to which log
+	// lines are shipped in Loki's format.
+	LogSinkLokiPath = "/loki/api/v1/push"
+
+	// NotifyTimeout is the maximum time posting a notification to the
+	// Slack webhook or the notification webhook may take.
+	NotifyTimeout = 10 * time.Second
+
+	// CrashLogPrefix is the S3 object key prefix under which the full
+	// error logs and failing inputs of crashes too large for their issue
+	// are stored.
+	CrashLogPrefix = "crash-logs/"
+
+	// DefaultSecondaryRateLimitWait is the initial wait before retrying a
+	// GitHub write operation that hit the secondary rate limit without a
+	// Retry-After header, as recommended by GitHub.
+	DefaultSecondaryRateLimitWait = 1 * time.Minute
+
+	// MaxSecondaryRateLimitWait caps the wait before retrying a GitHub
+	// write operation that hit the secondary rate limit without a
+	// Retry-After header.
+	MaxSecondaryRateLimitWait = 15 * time.Minute
+
+	// DefaultS3RetryWait is the initial wait before retrying a failed S3
+	// download or upload.
+	DefaultS3RetryWait = 1 * time.Second
+
+	// MaxS3RetryWait caps the wait before retrying a failed S3 download
+	// or upload.
+	MaxS3RetryWait = 30 * time.Second
+
 	// LogFilename is the filename where go-continuous-fuzz writes its log
 	// output, in addition to writing it to stdout.
 	LogFilename = "gcf.log"
@@ -71,11 +396,15 @@ var (
 	// DefaultLogDir is the full path to the go-continuous-fuzz default log
 	// file directory.
 	DefaultLogDir = filepath.Join(GoContinuousFuzzDir, "logs")
+
+	// errVersionShown is returned by loadConfig once the version has been
+	// shown, so the program exits successfully.
+	errVersionShown = errors.New("version shown")
 )
 
 // Project holds configuration details for the target project under test.
-// It includes the Git repository URL, workspace path, S3 bucket name, and the
-// local paths for the project, corpus, and coverage reports.
+// It includes the Git repository URL, workspace path, S3 bucket name, corpus
+// sync mode, and the local paths for the project, corpus, and coverage reports.
 //
 //nolint:lll
 type Project struct {
@@ -83,12 +412,63 @@ type Project struct {
 
 	SrcRepo string `long:"src-repo" description:"Git repo URL of the project to fuzz" required:"true"`
 
-	S3BucketName string `long:"s3-bucket-name" description:"Name of the S3 bucket where the seed corpus will be stored" required:"true"`
+	CloneDepth int `long:"clone-depth" description:"Number of most recent commits of the project history to clone, e.g. 1 for a shallow clone that only fetches the fuzzed commit; 0 clones the full history. A shallow clone limits the recent commits of fuzz.issue-include-blame to the cloned history" default:"0"`
+
+	Ref string `long:"ref" description:"Branch or tag of the project repository to fuzz, e.g. release-1.2 or v1.2.0, or a full reference name such as refs/tags/v1.2.0; branches take precedence over tags of the same name (default: the default branch)"`
+
+	IncrementalClone bool `long:"incremental-clone" description:"Keep the clone of the project repository between cycles, and update it by fetching project.ref (default: HEAD) and resetting the checkout to it, instead of cloning the project every cycle; the project is only cloned again if the clone is missing or cannot be updated"`
+
+	CloneRetries int `long:"clone-retries" description:"Number of times a failed clone of the project repository, e.g. on a transient DNS or network error, is retried with an exponentially increasing wait; rejected credentials and missing repositories are not retried" default:"3"`
+
+	StorageBackend string `long:"storage-backend" description:"Storage backend holding the corpus and reports" choice:"s3" choice:"gcs" choice:"azure" default:"s3"`
+
+	S3BucketName string `long:"s3-bucket-name" description:"Name of the S3 bucket where the seed corpus will be stored (required with the s3 storage backend)"`
+
+	GCSBucketName string `long:"gcs-bucket-name" description:"Name of the Google Cloud Storage bucket where the seed corpus will be stored (required with the gcs storage backend)"`
+
+	AzureAccountURL string `long:"azure-account-url" description:"URL of the Azure storage account holding the container, e.g. https://ACCOUNT.blob.core.windows.net/ (required with the azure storage backend)"`
+
+	AzureContainer string `long:"azure-container" description:"Name of the Azure Blob Storage container where the seed corpus will be stored (required with the azure storage backend)"`
+
+	S3BaseURL string `long:"s3-base-url" description:"Base URL under which the objects of the S3 bucket can be viewed, e.g. its static website endpoint, used to link to full crash logs from issues (default: s3:// URIs)"`
+
+	CorpusSharding bool `long:"corpus-sharding" description:"Store the corpus as one archive per package instead of a single archive, transferred in parallel"`
+
+	CorpusSymlinks string `long:"corpus-symlinks" description:"How symbolic links in the corpus are archived: follow stores the content of the linked file, skip leaves them out, and preserve stores them as links if they point inside the corpus; links pointing outside the corpus are never extracted" choice:"follow" choice:"skip" choice:"preserve" default:"follow"`
+
+	ArchiveFormat string `long:"archive-format" description:"Format of the corpus archives stored in S3; existing archives in the other format are still downloaded" choice:"zip" choice:"tar.zst" default:"zip"`
+
+	CorpusVersions bool `long:"corpus-versions" description:"Keep a dated copy of the corpus in S3 after every upload, which can be restored with the restore-corpus command"`
+
+	CorpusQuarantine bool `long:"corpus-quarantine" description:"Upload the corpus inputs found by each cycle to a quarantine prefix in S3 instead of updating the canonical corpus, so they can be reviewed and merged with the promote-corpus command"`
+
+	CorpusMergeStrategy string `long:"corpus-merge-strategy" description:"How the local corpus is combined with the downloaded corpus for targets present in both" choice:"union" choice:"s3-wins" choice:"local-wins" choice:"coverage-max" default:"union"`
+
+	SkipUnchangedCorpus bool `long:"skip-unchanged-corpus" description:"Skip the corpus upload of a cycle that neither added nor removed corpus inputs since the download, still uploading the reports"`
+
+	CorpusSyncMode string `long:"corpus-sync-mode" description:"Direction in which the corpus and reports are synced with the S3 bucket" choice:"both" choice:"download" choice:"upload" choice:"none" default:"both"`
+
+	UploadBandwidthLimit string `long:"upload-bandwidth-limit" description:"Maximum bandwidth used to upload the corpus and reports to the S3 bucket, in bytes per second with an optional K, M or G suffix, e.g. 10M (default: unlimited)"`
+
+	LimitDownloadBandwidth bool `long:"limit-download-bandwidth" description:"Also apply the upload bandwidth limit to downloads from the S3 bucket"`
+
+	S3MaxRetries int `long:"s3-max-retries" description:"Number of times a failed download from or upload to the S3 bucket, e.g. on a transient 503 error, is retried with an exponentially increasing wait before giving up" default:"3"`
+
+	// BandwidthLimit is the maximum number of bytes per second transferred
+	// to (and optionally from) the S3 bucket, parsed from
+	// UploadBandwidthLimit, or 0 to not limit it.
+	BandwidthLimit int64
 
 	// SrcDir contains the absolute path to the directory where the project
 	// to fuzz is located.
 	SrcDir string
 
+	// SrcBranch contains the name of the checked out branch of the project:
+	// its default branch, detected from the remote HEAD after cloning, or the
+	// branch selected by Ref. Changes to the project can be compared against
+	// it. It is empty if it could not be detected, e.g. if Ref is a tag.
+	SrcBranch string
+
 	// CorpusDir contains the absolute path to the directory where the seed
 	// corpus is located
 	CorpusDir string
@@ -96,6 +476,18 @@ type Project struct {
 	// CorpusKey is the S3 object key under which the corpus is stored.
 	CorpusKey string
 
+	// CorpusShardPrefix is the S3 object key prefix under which the
+	// per-package corpus shards are stored, if sharding is enabled.
+	CorpusShardPrefix string
+
+	// CorpusVersionPrefix is the S3 object key prefix under which the
+	// dated corpus versions are stored, if versioning is enabled.
+	CorpusVersionPrefix string
+
+	// CorpusQuarantinePrefix is the S3 object key prefix under which the
+	// corpus inputs awaiting review are stored, if quarantine is enabled.
+	CorpusQuarantinePrefix string
+
 	// ReportDir contains the absolute path to the directory where the
 	// coverage reports are located.
 	ReportDir string
@@ -103,6 +495,28 @@ type Project struct {
 	// BinaryDir contains the absolute path to the directory where the
 	// fuzz target binaries are located.
 	BinaryDir string
+
+	// BisectCorpusDir contains the absolute path to the directory where
+	// the corpus files selected by the bisect-corpus command are gathered.
+	BisectCorpusDir string
+
+	// CoverageDir contains the absolute path to the directory where each
+	// worker stages the coverage runs of its fuzz targets.
+	CoverageDir string
+}
+
+// downloadsCorpus reports whether the corpus and reports should be downloaded
+// from the S3 bucket at the start of each fuzzing cycle.
+func (p *Project) downloadsCorpus() bool {
+	return p.CorpusSyncMode == CorpusSyncBoth ||
+		p.CorpusSyncMode == CorpusSyncDownload
+}
+
+// uploadsCorpus reports whether the corpus and reports should be uploaded to
+// the S3 bucket at the end of each successful fuzzing cycle.
+func (p *Project) uploadsCorpus() bool {
+	return p.CorpusSyncMode == CorpusSyncBoth ||
+		p.CorpusSyncMode == CorpusSyncUpload
 }
 
 // Fuzz defines all fuzzing-related flags and defaults, including the Git
@@ -113,32 +527,351 @@ type Project struct {
 type Fuzz struct {
 	CrashRepo string `long:"crash-repo" description:"Git repository URL where issues are created for fuzz crashes" required:"true"`
 
-	PkgsPath []string `long:"pkgs-path" description:"List of package paths to fuzz" required:"true"`
+	CrashTracker string `long:"crash-tracker" description:"Issue tracker of the crash repository, e.g. gitlab for a self-hosted GitLab instance (default: gitlab for gitlab.com, github otherwise)" choice:"github" choice:"gitlab"`
+
+	PkgsPath []string `long:"pkgs-path" description:"List of package paths to fuzz, relative to the project root; a path ending in /... (e.g. ./... or parser/...) fuzzes all packages in and below its directory" required:"true"`
 
 	SyncFrequency time.Duration `long:"sync-frequency" description:"Duration between consecutive fuzzing cycles" default:"24h"`
 
+	CycleGracePeriod string `long:"cycle-grace-period" description:"Time granted on top of sync-frequency to the fuzz targets still running before the cycle is canceled, e.g. 10m, or 0 to cancel it as soon as sync-frequency elapses; a cycle whose targets are all done ends right away regardless (default: a third of sync-frequency, at most 1h)"`
+
 	NumWorkers int `long:"num-workers" description:"Number of concurrent fuzzing workers" default:"1"`
 
 	CorpusMinimizeInterval time.Duration `long:"corpus-minimize-interval" description:"Interval between consecutive corpus minimizations" default:"7d"`
 
+	MaxCorpusFiles int `long:"max-corpus-files" description:"Maximum number of corpus files per fuzz target, enforced after every fuzz run by evicting the inputs contributing the least coverage (0 disables)" default:"0"`
+
+	DiscoveryTimeout time.Duration `long:"discovery-timeout" description:"Maximum time to discover the fuzz targets of a package (0 disables the limit)" default:"15m"`
+
+	BuildTimeout time.Duration `long:"build-timeout" description:"Maximum time to build the binary of a fuzz target (0 disables the limit)" default:"15m"`
+
+	FuzzCacheDir string `long:"fuzz-cache-dir" description:"Directory, ideally on fast local disk, where the fuzzer works on a copy of each target's corpus, with only the new inputs copied back to the corpus once fuzzing ends (default: the fuzzer works on the corpus directly)"`
+
+	DeterministicOrder bool `long:"deterministic-order" description:"Fuzz the targets of every cycle in a deterministic order, sorted by package and target, rather than in discovery order, e.g. for reproducible benchmarking"`
+
+	FocusActiveTargets bool `long:"focus-active-targets" description:"Only fuzz the targets whose coverage has plateaued (see plateau-window) once every plateau-rerun-interval, focusing the cycles on the targets still gaining coverage"`
+
+	PlateauWindow int `long:"plateau-window" description:"Number of most recent daily coverage measurements of a target that must all be equal for its coverage to have plateaued (at least 2)" default:"5"`
+
+	PlateauRerunInterval time.Duration `long:"plateau-rerun-interval" description:"Minimum time between two runs of a target whose coverage has plateaued, when focusing on active targets" default:"24h"`
+
+	AutoDisableCrashing int `long:"auto-disable-crashing" description:"Number of consecutive cycles in which fuzzing a target must end in an early crash (see early-crash-execs) for the target to be disabled, with a tracking issue, until the verification of its open issues finds its crashes fixed; 0 never disables targets" default:"0"`
+
+	EarlyCrashExecs int64 `long:"early-crash-execs" description:"Maximum number of executions reported by the fuzzer before a crash for the crash to be early, when auto-disabling crashing targets" default:"1000"`
+
+	MinExecs int64 `long:"min-execs" description:"Minimum number of inputs a target must execute within its time slot, as reported by the fuzzer, not to be starved, e.g. by building and starting it taking most of the slot; starved targets are logged with a warning (0 disables the check)" default:"0"`
+
+	StarvedExtraTime time.Duration `long:"starved-extra-time" description:"Extra time granted in the next cycle to a target that was starved (see min-execs), set aside from the cycle before it is split among the targets (0 grants none)" default:"0"`
+
+	PrioritizeCrashing bool `long:"prioritize-crashing" description:"Fuzz the targets with open crash issues first in every cycle, so fixes of their crashes are confirmed sooner; their open issues are counted once per target when queuing them"`
+
+	ScheduleStrategy string `long:"schedule-strategy" description:"How the time of a cycle is split among its fuzz targets: evenly, or weighted by the coverage each target gained across its most recent plateau-window measurements, so targets still gaining coverage get more time; the cycle takes no longer either way" choice:"even" choice:"weighted" default:"even"`
+
+	SampleSize int `long:"sample-size" description:"Number of fuzz targets randomly sampled to be fuzzed in every cycle, so that each gets a larger share of the cycle in projects with many targets; the others are skipped until sampled in a later cycle (0 fuzzes all targets)" default:"0"`
+
+	SampleStrategy string `long:"sample-strategy" description:"How the fuzz targets of a cycle are sampled: weighted by the time since they were last fuzzed, always including the targets never fuzzed, or uniformly" choice:"staleness" choice:"uniform" default:"staleness"`
+
+	FixCorpusPermissions bool `long:"fix-corpus-permissions" description:"Make the corpus and fuzz cache directories writable (chmod, and chown when running as root) when they are not, instead of aborting with a diagnostic of their ownership"`
+
+	ReuseCheckout bool `long:"reuse-checkout" description:"Reuse the project checkout and discovered fuzz targets of the previous cycle unless the remote commit of project.ref (default: HEAD) changed, as checked by listing the remote's references, instead of cloning the project every cycle"`
+
+	PreCycleHook string `long:"pre-cycle-hook" description:"Shell command run before each fuzzing cycle, e.g. to refresh credentials; a non-zero exit status aborts the run"`
+
+	PostCycleHook string `long:"post-cycle-hook" description:"Shell command run after each completed fuzzing cycle, e.g. to publish artifacts; failures are logged as warnings"`
+
+	HookTimeout time.Duration `long:"hook-timeout" description:"Maximum time a cycle hook or corpus loader or saver may run (0 disables the limit)" default:"10m"`
+
+	CorpusLoader string `long:"corpus-loader" description:"Shell command run before fuzzing each target to materialize its corpus, e.g. from a custom format, into the target's corpus directory; a non-zero exit status aborts the cycle"`
+
+	CorpusSaver string `long:"corpus-saver" description:"Shell command run after fuzzing each target to persist its corpus from the target's corpus directory, e.g. to a custom format; a non-zero exit status aborts the cycle"`
+
 	Iterations int `long:"iterations" description:"Number of fuzzing cycles to run (0 means to run forever)" default:"0"`
+
+	MaxRuntime time.Duration `long:"max-runtime" description:"Maximum wall-clock time of the fuzzing cycles, e.g. 30m for time-boxed CI runs; the last cycle is shortened to end in time and still uploads its corpus and reports before the program exits (0 means no limit)" default:"0"`
+
+	FuzzTimeBudget bool `long:"fuzztime-budget" description:"Pass the per-target fuzzing time to the fuzzer (e.g. -fuzztime) so it exits cleanly on its own, using the timeout only as a backstop"`
+
+	DumpOnTimeout bool `long:"dump-on-timeout" description:"Before stopping a fuzz container still running at its timeout, send SIGQUIT to its processes so the Go runtime dumps their goroutine stacks, and log the dump; most useful with fuzztime-budget, where hitting the timeout means the fuzzer hung"`
+
+	Engine string `long:"engine" description:"Fuzzing engine used to build and run the fuzz targets" choice:"go" choice:"libfuzzer" default:"go"`
+
+	CloseCommentTemplate string `long:"close-comment-template" description:"Go text/template for the comment posted when closing resolved issues, with access to .Package, .Target, .Signature and .Commit"`
+
+	BatchVerify bool `long:"batch-verify" description:"Verify the open issues of a fuzz target by running all their failing inputs in a single container, instead of one container per issue (only supported by the go engine)"`
+
+	VerifyWorkers int `long:"verify-workers" description:"Number of workers verifying the open issues of the fuzz targets in the background, ahead of the fuzzing workers, so other targets are fuzzed meanwhile; each target is still only fuzzed once its issues are verified (0 verifies the issues of each target right before fuzzing it)" default:"0"`
+
+	IssueIncludeProgress bool `long:"issue-include-progress" description:"Include the last progress line of the fuzzer before the crash (elapsed time, executions, new interesting inputs) in crash issues, to tell seed corpus crashes from those found by deep fuzzing"`
+
+	IssueIncludeBlame int `long:"issue-include-blame" description:"Number of recent commits touching the crashing file to include in crash issues (0 disables), limited to the cloned history with project.clone-depth" default:"0"`
+
+	FailureLogRetention string `long:"failure-log-retention" description:"Retention of the full crash logs stored in the S3 bucket for crash issues too large to hold them: a number of most recent crashes to keep per target (e.g. 5), or a maximum age (e.g. 720h); older logs are pruned after every upload (default: keep all)"`
+
+	// FailureLogKeep is the number of most recent crash logs kept per
+	// target, parsed from FailureLogRetention, or 0 to not limit it.
+	FailureLogKeep int
+
+	// FailureLogMaxAge is the maximum age of the kept crash logs, parsed
+	// from FailureLogRetention, or 0 to not limit it.
+	FailureLogMaxAge time.Duration
+
+	IssueBodyLimit int `long:"issue-body-limit" description:"Maximum number of characters in the body of a crash issue; longer error logs and failing inputs are truncated, with the full versions uploaded to the S3 bucket and linked from the issue" default:"65536"`
+
+	IssueBodyFetchLimit int `long:"issue-body-fetch-limit" description:"Maximum number of characters of an issue body parsed for its failing testcase when verifying open issues; issues with longer bodies are left open for manual verification" default:"1048576"`
+
+	ReportMode string `long:"report-mode" description:"How fuzz crashes are reported: per-crash opens an issue per crash signature, and digest lists the crashes found each day, one per signature, in a single digest issue updated at the end of every cycle" choice:"per-crash" choice:"digest" default:"per-crash"`
+
+	ClusterFuzzSignature bool `long:"clusterfuzz-signature" description:"Compute crash signatures from a ClusterFuzz-compatible fingerprint (crash type and top normalized stack frames) instead of the failure location, and include the fingerprint in crash issues and the run summary"`
+
+	ReportUnknownFailures bool `long:"report-unknown-failures" description:"Open an issue with the tail of the output when a fuzz container exits with a non-zero status without a recognized crash, and continue fuzzing, instead of aborting the cycle"`
+
+	SuppressOOMIssues bool `long:"suppress-oom-issues" description:"Do not open an issue when a fuzz container is killed for running out of memory; the target is still marked as oom in the reports"`
+
+	CompactJSON bool `long:"compact-json" description:"Write the report state (state.json) and the per-target coverage history files as compact JSON instead of indented JSON, making them smaller for projects with many targets"`
+
+	FlakinessRuns int `long:"flakiness-runs" description:"Number of times the coverage of each target's corpus is measured after fuzzing to assess its stability; if the measurements differ, an issue is opened advising to make the target deterministic (0 disables, otherwise at least 2)" default:"0"`
+
+	BootstrapCycles int `long:"bootstrap-cycles" description:"Number of initial cycles of a new project (empty corpus and no prior report state) treated as bootstrap cycles, which only establish the coverage baselines without opening coverage issues such as flaky coverage (0 disables)" default:"1"`
+
+	ReportAllFailingInputs bool `long:"report-all-failing-inputs" description:"When a fuzz run saves several failing inputs, reproduce each one besides that of the reported crash and report every distinct crash, instead of only the first one"`
+
+	CrashExportDir string `long:"crash-export-dir" description:"Directory to which the failing input of every detected crash is written, along with a manifest.json mapping each crash signature to its input file and issue URL, for external tooling"`
+
+	LogSink string `long:"log-sink" description:"URL of a remote sink to which the log lines, including the fuzzer output, are shipped in batches: tcp://host:port for newline-delimited lines, or an http(s) URL to which they are posted, in Loki's format if its path is the Loki push API (/loki/api/v1/push); lines are dropped rather than blocking fuzzing if the sink is slow"`
+
+	WebhookURL string `long:"webhook-url" description:"URL to which the report of every detected crash is posted as JSON"`
+
+	WebhookSecret string `long:"webhook-secret" description:"Secret with which the webhook payloads are signed using HMAC-SHA256, in an X-Signature header of the form sha256=<hex digest>, so the receiver can verify their authenticity"`
+
+	WebhookHeaders []string `long:"webhook-header" description:"List of custom \"Name: value\" headers sent with the webhook requests, e.g. for authentication"`
+
+	WebhookTimeout time.Duration `long:"webhook-timeout" description:"Maximum time a webhook request may take" default:"10s"`
+
+	TrackRecurrences bool `long:"track-recurrences" description:"Keep a single comment on the open issue of a crash that is found again up to date with the number of times the crash was seen and when it was last seen, editing it in place instead of posting a comment per occurrence"`
+
+	OrphanCorpusPolicy string `long:"orphan-corpus-policy" description:"What to do with the corpus of a fuzz target that is no longer discovered, e.g. after being renamed: keep it in place, archive it under testdata/fuzz-orphaned of its package, or delete it" choice:"keep" choice:"archive" choice:"delete" default:"keep"`
+
+	CloseOrphanIssues bool `long:"close-orphan-issues" description:"Close the open crash issues of a fuzz target that is no longer discovered but still has a corpus, with a comment explaining that the target no longer exists"`
+
+	IssueLabels string `long:"issue-labels" description:"Comma-separated labels applied to the created issues; only the issues carrying all of them are searched for deduplication and verification, so issues created by others are never touched"`
+
+	IssueAssignees string `long:"issue-assignees" description:"Comma-separated usernames to which the created issues are assigned; assignment is best-effort, so assignees that cannot be assigned only cause a warning"`
+
+	AssigneeRotation string `long:"assignee-rotation" description:"Comma-separated usernames among which the created issues are assigned round-robin, on top of issue-assignees; the rotation is persisted in the reports, so it carries over across cycles"`
+
+	ReopenIssues bool `long:"reopen-issues" description:"Reopen the closed issue of a crash that reproduces again instead of creating a new issue"`
+
+	ReopenCooldown time.Duration `long:"reopen-cooldown" description:"Minimum time since an issue was closed before it is reopened, to avoid flapping issues for nondeterministic crashes" default:"24h"`
+
+	GitHubWriteRetries int `long:"github-write-retries" description:"Number of times a GitHub write operation (creating issues and comments, closing and reopening issues) is retried when hitting GitHub's secondary rate limit" default:"3"`
+
+	Labels []string `long:"labels" description:"List of key=value labels applied to the fuzz containers"`
+
+	GoCacheMaxSize string `long:"gocache-max-size" description:"Maximum size of the Go build cache (GOCACHE) used on the host to build the fuzz binaries and measure coverage, in bytes with an optional K, M or G suffix, e.g. 10G; its least recently used entries are evicted between cycles to stay within it (default: unlimited)"`
+
+	ContainerImage string `long:"container-image" description:"Docker image in which the fuzz targets are run, e.g. to pin a Go toolchain or provide the system libraries needed by CGO targets (default: golang:1.24.6)"`
+
+	RegistryAuth string `long:"registry-auth" description:"username:password credentials with which the container image is pulled from its private registry (default: the credentials stored for the registry by docker login in ~/.docker/config.json, or $DOCKER_CONFIG/config.json, if any)"`
+
+	CapAdd []string `long:"cap-add" description:"List of Linux capabilities (e.g. NET_ADMIN) added to the fuzz containers; grants the fuzz targets extra privileges"`
+
+	MemLimit string `long:"mem-limit" description:"Memory limit of the fuzz containers, in bytes with an optional K, M or G suffix" default:"2G"`
+
+	CPULimit string `long:"cpu-limit" description:"CPU limit of the fuzz containers, as a number of CPUs that may be fractional (e.g. 0.5)" default:"1"`
+
+	TargetMemory []string `long:"target-memory" description:"List of pkg/Target=size memory limits of the fuzz containers of specific targets, overriding mem-limit; sizes are in bytes with an optional K, M or G suffix (e.g. parser/FuzzParse=8G)"`
+
+	SkipRuntimeCheck bool `long:"skip-runtime-check" description:"Do not check that the Docker daemon is reachable at startup, before cloning the project and downloading the corpus"`
+
+	SkipTrackerCheck bool `long:"skip-tracker-check" description:"Do not check at startup that the token of the GitHub crash repository can write its issues"`
+
+	// ContainerLabels contains the labels applied to the fuzz containers,
+	// parsed from Labels and including ToolLabel.
+	ContainerLabels map[string]string
+
+	// CrashIssueLabels contains the labels applied to the created issues,
+	// parsed from IssueLabels.
+	CrashIssueLabels []string
+
+	// CrashIssueAssignees contains the usernames to which the created
+	// issues are assigned, parsed from IssueAssignees.
+	CrashIssueAssignees []string
+
+	// IssueAssigneeRotation contains the usernames among which the created
+	// issues are assigned round-robin, parsed from AssigneeRotation.
+	IssueAssigneeRotation []string
+
+	// WebhookHTTPHeaders contains the custom headers sent with the webhook
+	// requests, keyed by their canonical name, parsed from WebhookHeaders.
+	WebhookHTTPHeaders map[string]string
+
+	// GoCacheMaxBytes is the maximum size in bytes of the Go build cache,
+	// parsed from GoCacheMaxSize, or 0 to not limit it.
+	GoCacheMaxBytes int64
+
+	// GracePeriod is the time granted on top of SyncFrequency to the fuzz
+	// targets still running before the cycle is canceled, parsed from
+	// CycleGracePeriod.
+	GracePeriod time.Duration
+
+	// ContainerMemory is the memory limit in bytes of the fuzz containers,
+	// parsed from MemLimit.
+	ContainerMemory int64
+
+	// ContainerNanoCPUs is the CPU limit of the fuzz containers in
+	// billionths of a CPU, parsed from CPULimit.
+	ContainerNanoCPUs int64
+
+	// TargetMemoryLimits contains the memory limits in bytes of the fuzz
+	// containers of specific targets, keyed by "pkg/Target", parsed from
+	// TargetMemory.
+	TargetMemoryLimits map[string]int64
+
+	// ImagePullAuth contains the encoded credentials with which the
+	// container image is pulled, resolved from RegistryAuth or the Docker
+	// CLI configuration file, or is empty to pull it anonymously.
+	ImagePullAuth string
+}
+
+// memoryLimit returns the memory limit in bytes of the fuzz containers of the
+// given target: its configured limit, or ContainerMemory by default.
+func (f *Fuzz) memoryLimit(pkg, target string) int64 {
+	if limit, ok := f.TargetMemoryLimits[pkg+"/"+target]; ok {
+		return limit
+	}
+	return f.ContainerMemory
+}
+
+// Metrics defines the flags of the Prometheus metrics endpoint, which is only
+// served if a listen address is configured, and of the StatsD exporter, which
+// only sends the metrics if a StatsD address is configured.
+//
+//nolint:lll
+type Metrics struct {
+	ListenAddr string `long:"listen-addr" description:"Address (e.g. :9090) on which to serve Prometheus metrics of the fuzzing progress on /metrics (default: metrics are not served)"`
+
+	StatsDAddr string `long:"statsd-addr" description:"Address (host:port) of a StatsD or DogStatsD agent to which the metrics of the fuzzing progress are sent over UDP, with their labels as DogStatsD tags (default: metrics are not sent)"`
+}
+
+// Health defines the flags of the health endpoints, e.g. for the liveness and
+// readiness probes of Kubernetes, which are only served if a listen address is
+// configured.
+//
+//nolint:lll
+type Health struct {
+	ListenAddr string `long:"listen-addr" description:"Address (e.g. :8080) on which to serve /healthz, answering 200 while the fuzzing cycles run, and /readyz, answering 200 once the project was first cloned and its corpus downloaded (default: health endpoints are not served)"`
+}
+
+// Notify defines the flags of the notifications sent when crash issues are
+// opened or closed.
+//
+//nolint:lll
+type Notify struct {
+	SlackWebhook string `long:"slack-webhook" description:"Slack incoming webhook URL to which a message is posted whenever a new crash issue is opened or a resolved one is closed (default: no Slack notifications)"`
+
+	WebhookURL string `long:"webhook-url" description:"URL to which a JSON crash_opened or crash_closed event is posted whenever a new crash issue is opened or a resolved one is closed (default: no webhook notifications)"`
+
+	WebhookSecret string `long:"webhook-secret" description:"Secret with which the notification webhook payloads are signed using HMAC-SHA256, in an X-Signature header of the form sha256=<hex digest>"`
 }
 
 // Config encapsulates all top-level configuration parameters required to run
 // the fuzzing system. It is populated from, in order of priority:
 //  1. Command-line flags.
-//  2. CONF file (ConfigFile).
+//  2. CONF files (ConfigFiles, or ConfigFile by default), later files taking
+//     priority over earlier ones.
 //  3. Default
+//
+//nolint:lll
 type Config struct {
+	ConfigFiles []string `long:"config" description:"Path to a config file; may be specified multiple times, with later files overriding earlier ones (default: ~/.go-continuous-fuzz/go-continuous-fuzz.conf)" no-ini:"true"`
+
+	ShowVersion bool `long:"version" description:"Display the version, Git commit and build date, and exit" no-ini:"true"`
+
 	LogDir string `long:"logdir" description:"Directory to log output."`
 
+	JSONSummary bool `long:"json-summary" description:"Print the end-of-run summary of bounded runs as a single line of JSON"`
+
 	Project Project `group:"Project" namespace:"project"`
 
 	Fuzz Fuzz `group:"Fuzz Options" namespace:"fuzz"`
+
+	Metrics Metrics `group:"Metrics" namespace:"metrics"`
+
+	Health Health `group:"Health" namespace:"health"`
+
+	Notify Notify `group:"Notifications" namespace:"notify"`
+
+	RestoreCorpus RestoreCorpusCommand `command:"restore-corpus" description:"Promote an archived corpus version to the canonical corpus in S3 and exit"`
+
+	PromoteCorpus PromoteCorpusCommand `command:"promote-corpus" description:"Merge approved quarantined corpus inputs into the canonical corpus in S3 and exit"`
+
+	BisectCorpus BisectCorpusCommand `command:"bisect-corpus" description:"Run a fuzz target with a subset of its corpus, report its resource usage and crash status, and exit"`
+
+	Reproduce ReproduceCommand `command:"reproduce" description:"Run a fuzz target against a single input, print its output, and exit with a non-zero status if it crashes"`
+
+	MigrateCorpus MigrateCorpusCommand `command:"migrate-corpus" description:"Rewrite a corpus archive in S3 into the <pkg>/testdata/fuzz/<target> layout and upload it as the canonical corpus, and exit"`
+
+	// Command is the name of the subcommand to run, or empty to run the
+	// fuzzing cycles.
+	Command string
+}
+
+// RestoreCorpusCommand defines the flags of the restore-corpus subcommand.
+//
+//nolint:lll
+type RestoreCorpusCommand struct {
+	Version string `long:"version" description:"Corpus version to restore, as a YYYY-MM-DD date or 'latest'" required:"true"`
+}
+
+// PromoteCorpusCommand defines the flags of the promote-corpus subcommand.
+//
+//nolint:lll
+type PromoteCorpusCommand struct {
+	Inputs []string `long:"input" description:"Path of an approved quarantined input, or of a directory of them, relative to the quarantine prefix (e.g. pkg/testdata/fuzz/FuzzFoo); may be specified multiple times (default: all quarantined inputs)"`
+}
+
+// BisectCorpusCommand defines the flags of the bisect-corpus subcommand.
+//
+//nolint:lll
+type BisectCorpusCommand struct {
+	Package string `long:"package" description:"Package of the fuzz target, relative to the project root" required:"true"`
+
+	Target string `long:"target" description:"Fuzz target to run" required:"true"`
+
+	Inputs []string `long:"input" description:"Name of a corpus file of the fuzz target to run it with; may be specified multiple times (default: all corpus files)"`
+
+	Half string `long:"half" description:"Only run the first or second half of the selected corpus files, in name order" choice:"first" choice:"second"`
+
+	Duration time.Duration `long:"duration" description:"How long to run the fuzz target with the selected corpus files" default:"1m"`
+}
+
+// ReproduceCommand defines the flags of the reproduce subcommand.
+//
+//nolint:lll
+type ReproduceCommand struct {
+	Package string `long:"package" description:"Package of the fuzz target, relative to the project root" required:"true"`
+
+	Target string `long:"target" description:"Fuzz target to run" required:"true"`
+
+	Input string `long:"input" description:"Path of the input file to run the fuzz target against, in the corpus format of the fuzzing engine (e.g. a failing input saved by Go's fuzzing engine)" required:"true"`
+}
+
+// MigrateCorpusCommand defines the flags of the migrate-corpus subcommand.
+//
+//nolint:lll
+type MigrateCorpusCommand struct {
+	Mappings []string `long:"map" description:"Mapping of a directory of the corpus archive to the corpus directory of a fuzz target, as from=to (e.g. FuzzParse=parser/testdata/fuzz/FuzzParse); {name} segments of from match any directory name and can be used in to (e.g. {pkg}/{target}={pkg}/testdata/fuzz/{target}); the first matching mapping applies; may be specified multiple times"`
+
+	SourceKey string `long:"source-key" description:"S3 object key of the corpus archive to migrate (default: the canonical corpus archive)"`
+
+	DryRun bool `long:"dry-run" description:"Print the number of inputs of each fuzz target after the migration without uploading the migrated corpus"`
+
+	// CorpusMappings holds the parsed Mappings, in order of precedence.
+	CorpusMappings []corpusMapping
 }
 
 // loadConfig reads configuration values from
-// (1) a default CONF file and
+// (1) the CONF files given with --config, or the default CONF file, and
 // (2) any overriding command-line flags.
 // It performs validation on required fields and applies defaults where needed.
 // Returns a pointer to a Config struct or an error if validation fails.
@@ -147,34 +880,52 @@ func loadConfig() (*Config, error) {
 		LogDir: DefaultLogDir,
 	}
 
-	// Determine the config file path
-	configFilePath := CleanAndExpandPath(ConfigFile)
-
-	// Parse the CONF file (if it exists). Any values in this file
-	// populate fields in cfg. If the file is missing, that's okay.
-	parser := flags.NewParser(&cfg, flags.Default)
-	err := flags.NewIniParser(parser).ParseFile(configFilePath)
+	// Pre-parse the command line for the config files to load, ignoring
+	// all other flags, which are parsed once the files have been loaded.
+	preCfg := struct {
+		ConfigFiles []string `long:"config"`
+		ShowVersion bool     `long:"version"`
+	}{}
+	_, err := flags.NewParser(&preCfg, flags.IgnoreUnknown).Parse()
 	if err != nil {
-		var iniErr *flags.IniError
-		var flagsErr *flags.Error
-		// If it's a parsing related error, then we'll return
-		// immediately, otherwise we can proceed as possibly the config
-		// file doesn't exist which is OK.
-		if errors.As(err, &iniErr) || errors.As(err, &flagsErr) {
-			return nil, err
-		}
+		return nil, err
+	}
+
+	// Show the version and exit if the version flag was specified, before
+	// any config file is loaded or validated.
+	if preCfg.ShowVersion {
+		fmt.Println(currentVersion())
+		return nil, errVersionShown
+	}
+
+	// Parse the CONF files. Any values in these files populate fields in
+	// cfg.
+	parser := flags.NewParser(&cfg, flags.Default)
+	parser.SubcommandsOptional = true
+	err = parseConfigFiles(parser, preCfg.ConfigFiles)
+	if err != nil {
+		return nil, err
 	}
 
 	// Re-parse command-line flags so they override any values from the
-	// file.
+	// files. Required fields are validated here, so they may be set by
+	// any of the files or flags.
 	if _, err := parser.Parse(); err != nil {
 		return nil, err
 	}
+	if parser.Active != nil {
+		cfg.Command = parser.Active.Name
+	}
 
 	// As soon as we're done parsing configuration options, ensure paths to
 	// directories and files are cleaned and expanded before attempting
 	// to use them later on.
 	cfg.LogDir = CleanAndExpandPath(cfg.LogDir)
+	if cfg.Command == ReproduceCmd {
+		// The package may be given as a relative path, e.g. ./foo.
+		cfg.Reproduce.Package = filepath.Clean(cfg.Reproduce.Package)
+		cfg.Reproduce.Input = CleanAndExpandPath(cfg.Reproduce.Input)
+	}
 
 	// Create the logs directory if they don't already exist.
 	if err := EnsureDirExists(cfg.LogDir); err != nil {
@@ -195,6 +946,411 @@ func loadConfig() (*Config, error) {
 		return nil, fmt.Errorf("invalid number of iterations: %d, "+
 			"must be non-negative", cfg.Fuzz.Iterations)
 	}
+	if cfg.Fuzz.MaxRuntime < 0 {
+		return nil, fmt.Errorf("invalid maximum runtime: %s, must be "+
+			"non-negative", cfg.Fuzz.MaxRuntime)
+	}
+
+	// Ensure the number of recent commits included in crash issues is
+	// non-negative.
+	if cfg.Fuzz.IssueIncludeBlame < 0 {
+		return nil, fmt.Errorf("invalid number of blame commits: %d, "+
+			"must be non-negative", cfg.Fuzz.IssueIncludeBlame)
+	}
+
+	// Ensure the issue body limit leaves room for the truncated error logs
+	// and failing input.
+	if cfg.Fuzz.IssueBodyLimit < MinIssueBodyLimit {
+		return nil, fmt.Errorf("invalid issue body limit: %d, must be "+
+			"at least %d", cfg.Fuzz.IssueBodyLimit,
+			MinIssueBodyLimit)
+	}
+
+	// Ensure the issues created within the issue body limit can be
+	// verified.
+	if cfg.Fuzz.IssueBodyFetchLimit < cfg.Fuzz.IssueBodyLimit {
+		return nil, fmt.Errorf("invalid issue body fetch limit: %d, "+
+			"must be at least the issue body limit %d",
+			cfg.Fuzz.IssueBodyFetchLimit, cfg.Fuzz.IssueBodyLimit)
+	}
+
+	// Ensure the S3 base URL, if set, is an absolute HTTP(S) URL.
+	if cfg.Project.S3BaseURL != "" {
+		u, err := url.Parse(cfg.Project.S3BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" {
+
+			return nil, fmt.Errorf("invalid S3 base URL %q, must "+
+				"be an absolute http(s) URL",
+				cfg.Project.S3BaseURL)
+		}
+	}
+
+	// Ensure the bucket of the storage backend is set, and that the
+	// features only the S3 backend supports are not enabled with another
+	// backend.
+	switch cfg.Project.StorageBackend {
+	case StorageBackendS3:
+		if cfg.Project.S3BucketName == "" {
+			return nil, errors.New("project.s3-bucket-name is " +
+				"required with the s3 storage backend")
+		}
+
+	case StorageBackendGCS:
+		if cfg.Project.GCSBucketName == "" {
+			return nil, errors.New("project.gcs-bucket-name is " +
+				"required with the gcs storage backend")
+		}
+
+	case StorageBackendAzure:
+		if cfg.Project.AzureAccountURL == "" ||
+			cfg.Project.AzureContainer == "" {
+
+			return nil, errors.New("project.azure-account-url and " +
+				"project.azure-container are required with " +
+				"the azure storage backend")
+		}
+	}
+
+	if cfg.Project.StorageBackend != StorageBackendS3 &&
+		(cfg.Project.CorpusSharding || cfg.Project.CorpusVersions ||
+			cfg.Project.CorpusQuarantine ||
+			cfg.Fuzz.FailureLogRetention != "") {
+
+		return nil, errors.New("corpus sharding, versions and " +
+			"quarantine and failure log retention are only " +
+			"supported with the s3 storage backend")
+	}
+
+	// Measuring coverage relies on `go test`, which requires the corpus to
+	// be in Go's corpus file format.
+	if cfg.Project.CorpusMergeStrategy == CorpusMergeCoverageMax &&
+		cfg.Fuzz.Engine != FuzzEngineGo {
+
+		return nil, fmt.Errorf("corpus merge strategy %q requires the "+
+			"%q fuzzing engine", CorpusMergeCoverageMax,
+			FuzzEngineGo)
+	}
+
+	// Ensure a plateau spans at least two coverage measurements, and that
+	// plateaued targets are rerun after a non-negative interval.
+	if cfg.Fuzz.PlateauWindow < 2 {
+		return nil, fmt.Errorf("invalid plateau window: %d, must be "+
+			"at least 2", cfg.Fuzz.PlateauWindow)
+	}
+	if cfg.Fuzz.PlateauRerunInterval < 0 {
+		return nil, fmt.Errorf("invalid plateau rerun interval: %s, "+
+			"must be non-negative", cfg.Fuzz.PlateauRerunInterval)
+	}
+
+	// Ensure crashing targets are disabled after a non-negative number of
+	// cycles, and early crashes are counted in non-negative executions.
+	if cfg.Fuzz.AutoDisableCrashing < 0 {
+		return nil, fmt.Errorf("invalid auto-disable crashing cycles: "+
+			"%d, must be non-negative", cfg.Fuzz.AutoDisableCrashing)
+	}
+	if cfg.Fuzz.EarlyCrashExecs < 0 {
+		return nil, fmt.Errorf("invalid early crash executions: %d, "+
+			"must be non-negative", cfg.Fuzz.EarlyCrashExecs)
+	}
+
+	// Ensure the minimum executions of a target and the extra time granted
+	// to starved targets are non-negative.
+	if cfg.Fuzz.MinExecs < 0 {
+		return nil, fmt.Errorf("invalid minimum executions: %d, must "+
+			"be non-negative", cfg.Fuzz.MinExecs)
+	}
+	if cfg.Fuzz.StarvedExtraTime < 0 {
+		return nil, fmt.Errorf("invalid starved extra time: %s, must "+
+			"be non-negative", cfg.Fuzz.StarvedExtraTime)
+	}
+
+	// Ensure the number of sampled targets is non-negative.
+	if cfg.Fuzz.SampleSize < 0 {
+		return nil, fmt.Errorf("invalid sample size: %d, must be "+
+			"non-negative", cfg.Fuzz.SampleSize)
+	}
+
+	// Ensure the coverage stability assessment compares at least two
+	// measurements.
+	if cfg.Fuzz.FlakinessRuns < 0 || cfg.Fuzz.FlakinessRuns == 1 {
+		return nil, fmt.Errorf("invalid flakiness runs: %d, must be 0 "+
+			"or at least 2", cfg.Fuzz.FlakinessRuns)
+	}
+
+	if cfg.Fuzz.MaxCorpusFiles < 0 {
+		return nil, fmt.Errorf("invalid maximum number of corpus files: "+
+			"%d, must be non-negative", cfg.Fuzz.MaxCorpusFiles)
+	}
+
+	if cfg.Fuzz.VerifyWorkers < 0 {
+		return nil, fmt.Errorf("invalid number of verify workers: %d, "+
+			"must be non-negative", cfg.Fuzz.VerifyWorkers)
+	}
+
+	if cfg.Fuzz.BootstrapCycles < 0 {
+		return nil, fmt.Errorf("invalid bootstrap cycles: %d, must be "+
+			"non-negative", cfg.Fuzz.BootstrapCycles)
+	}
+
+	// Ensure the discovery and build timeouts are non-negative.
+	if cfg.Fuzz.DiscoveryTimeout < 0 || cfg.Fuzz.BuildTimeout < 0 {
+		return nil, fmt.Errorf("invalid discovery or build timeout: "+
+			"%s, %s, must be non-negative",
+			cfg.Fuzz.DiscoveryTimeout, cfg.Fuzz.BuildTimeout)
+	}
+
+	// Detect the issue tracker of the crash repository from its host,
+	// unless set explicitly.
+	if cfg.Fuzz.CrashTracker == "" {
+		cfg.Fuzz.CrashTracker = crashTrackerFor(cfg.Fuzz.CrashRepo)
+	}
+
+	// Ensure the crash repository URL names a repository and carries an
+	// access token, so a misconfiguration is reported right away rather
+	// than once the first fuzz target is verified.
+	parseRepo := parseCrashRepo
+	if cfg.Fuzz.CrashTracker == CrashTrackerGitLab {
+		parseRepo = parseGitLabRepo
+	}
+	if _, _, _, err := parseRepo(cfg.Fuzz.CrashRepo); err != nil {
+		return nil, fmt.Errorf("invalid crash repository: %w", err)
+	}
+
+	// Expand the crash export directory, like the other paths.
+	if cfg.Fuzz.CrashExportDir != "" {
+		cfg.Fuzz.CrashExportDir = CleanAndExpandPath(
+			cfg.Fuzz.CrashExportDir)
+	}
+
+	// Resolve the fuzz cache directory to an absolute path, since it is
+	// mounted into the fuzz containers.
+	if cfg.Fuzz.FuzzCacheDir != "" {
+		cfg.Fuzz.FuzzCacheDir, err = filepath.Abs(
+			CleanAndExpandPath(cfg.Fuzz.FuzzCacheDir))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fuzz cache directory: "+
+				"%w", err)
+		}
+	}
+
+	// Parse the retention of the stored crash logs.
+	cfg.Fuzz.FailureLogKeep, cfg.Fuzz.FailureLogMaxAge, err =
+		parseLogRetention(cfg.Fuzz.FailureLogRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the grace period of the fuzzing cycles.
+	cfg.Fuzz.GracePeriod, err = parseCycleGracePeriod(
+		cfg.Fuzz.CycleGracePeriod, cfg.Fuzz.SyncFrequency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the size limit of the Go build cache.
+	cfg.Fuzz.GoCacheMaxBytes, err = parseGoCacheMaxSize(
+		cfg.Fuzz.GoCacheMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the bandwidth limit of the transfers to the S3 bucket.
+	cfg.Project.BandwidthLimit, err = parseBandwidth(
+		cfg.Project.UploadBandwidthLimit)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Project.LimitDownloadBandwidth &&
+		cfg.Project.BandwidthLimit == 0 {
+
+		return nil, errors.New("limit-download-bandwidth requires " +
+			"upload-bandwidth-limit")
+	}
+
+	// Ensure the cycle hook timeout is non-negative.
+	if cfg.Fuzz.HookTimeout < 0 {
+		return nil, fmt.Errorf("invalid hook timeout: %s, must be "+
+			"non-negative", cfg.Fuzz.HookTimeout)
+	}
+
+	// Ensure the number of clone retries is non-negative.
+	if cfg.Project.CloneRetries < 0 {
+		return nil, fmt.Errorf("invalid number of clone retries: %d, "+
+			"must be non-negative", cfg.Project.CloneRetries)
+	}
+
+	// Ensure the clone depth is non-negative, 0 cloning the full history.
+	if cfg.Project.CloneDepth < 0 {
+		return nil, fmt.Errorf("invalid clone depth: %d, must be "+
+			"non-negative", cfg.Project.CloneDepth)
+	}
+
+	// Ensure the reference to fuzz is a valid branch, tag or reference
+	// name.
+	if err := validateRef(cfg.Project.Ref); err != nil {
+		return nil, err
+	}
+
+	// Ensure the number of S3 retries is non-negative.
+	if cfg.Project.S3MaxRetries < 0 {
+		return nil, fmt.Errorf("invalid number of S3 retries: %d, "+
+			"must be non-negative", cfg.Project.S3MaxRetries)
+	}
+
+	// Ensure the number of GitHub write retries is non-negative.
+	if cfg.Fuzz.GitHubWriteRetries < 0 {
+		return nil, fmt.Errorf("invalid number of GitHub write "+
+			"retries: %d, must be non-negative",
+			cfg.Fuzz.GitHubWriteRetries)
+	}
+
+	// Ensure the reopen cooldown is non-negative.
+	if cfg.Fuzz.ReopenCooldown < 0 {
+		return nil, fmt.Errorf("invalid reopen cooldown: %s, must be "+
+			"non-negative", cfg.Fuzz.ReopenCooldown)
+	}
+
+	// Ensure the issue close comment template is valid, so a typo does not
+	// only surface once an issue gets resolved.
+	_, err = template.New("close-comment").Parse(
+		cfg.Fuzz.CloseCommentTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid close comment template: %w",
+			err)
+	}
+
+	// Ensure the corpus version to restore is either a date or "latest".
+	if cfg.Command == RestoreCorpusCmd {
+		err := validateCorpusVersion(cfg.RestoreCorpus.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse the mappings of the corpus archive to migrate.
+	if cfg.Command == MigrateCorpusCmd {
+		cfg.MigrateCorpus.CorpusMappings, err = parseCorpusMappings(
+			cfg.MigrateCorpus.Mappings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid corpus mapping: %w", err)
+		}
+	}
+
+	// Ensure the fuzz target is run with the selected corpus files for a
+	// positive duration.
+	if cfg.Command == BisectCorpusCmd && cfg.BisectCorpus.Duration <= 0 {
+		return nil, fmt.Errorf("invalid bisect duration: %s, must be "+
+			"positive", cfg.BisectCorpus.Duration)
+	}
+
+	// Parse and validate the labels applied to the fuzz containers.
+	cfg.Fuzz.ContainerLabels, err = parseLabels(cfg.Fuzz.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+
+	// Validate the sink to which the log lines are shipped.
+	if err := validateLogSink(cfg.Fuzz.LogSink); err != nil {
+		return nil, err
+	}
+
+	// Validate the webhook to which crash reports are posted, and parse
+	// the custom headers sent with its requests.
+	if err := validateWebhook(&cfg.Fuzz); err != nil {
+		return nil, err
+	}
+	cfg.Fuzz.WebhookHTTPHeaders, err = parseWebhookHeaders(
+		cfg.Fuzz.WebhookHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook headers: %w", err)
+	}
+
+	// Validate the webhooks to which notifications are posted.
+	if err := validateSlackWebhook(cfg.Notify.SlackWebhook); err != nil {
+		return nil, err
+	}
+	if err := validateNotifyWebhook(&cfg.Notify); err != nil {
+		return nil, err
+	}
+
+	// Validate the address of the StatsD agent to send the metrics to.
+	if err := validateStatsDAddr(cfg.Metrics.StatsDAddr); err != nil {
+		return nil, err
+	}
+
+	// Parse and validate the labels applied to the created issues.
+	cfg.Fuzz.CrashIssueLabels, err = parseIssueLabels(cfg.Fuzz.IssueLabels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue labels: %w", err)
+	}
+
+	// Parse and validate the assignees of the created issues.
+	cfg.Fuzz.CrashIssueAssignees, err = parseIssueAssignees(
+		cfg.Fuzz.IssueAssignees)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue assignees: %w", err)
+	}
+	cfg.Fuzz.IssueAssigneeRotation, err = parseIssueAssignees(
+		cfg.Fuzz.AssigneeRotation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid assignee rotation: %w", err)
+	}
+
+	// Validate the image of the fuzz containers, using the default one
+	// if unset.
+	if cfg.Fuzz.ContainerImage == "" {
+		cfg.Fuzz.ContainerImage = ContainerImage
+	}
+	if err := validateContainerImage(cfg.Fuzz.ContainerImage); err != nil {
+		return nil, err
+	}
+
+	// Resolve the credentials with which the container image is pulled
+	// from its registry, if any.
+	cfg.Fuzz.ImagePullAuth, err = resolveRegistryAuth(
+		cfg.Fuzz.ContainerImage, cfg.Fuzz.RegistryAuth,
+		dockerConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize and validate the capabilities added to the fuzz
+	// containers.
+	cfg.Fuzz.CapAdd, err = parseCapabilities(cfg.Fuzz.CapAdd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capabilities: %w", err)
+	}
+
+	// Parse and validate the resource limits of the fuzz containers.
+	cfg.Fuzz.ContainerMemory, err = parseMemLimit(cfg.Fuzz.MemLimit)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Fuzz.ContainerNanoCPUs, err = parseCPULimit(cfg.Fuzz.CPULimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the recursive package patterns, whose packages are only
+	// known once the project is cloned. Corpus shards are keyed by
+	// package, so they must be known without a clone.
+	if err := validatePkgsPath(cfg.Fuzz.PkgsPath); err != nil {
+		return nil, err
+	}
+	if cfg.Project.CorpusSharding &&
+		slices.ContainsFunc(cfg.Fuzz.PkgsPath, isPkgPattern) {
+
+		return nil, errors.New("recursive package patterns in " +
+			"fuzz.pkgs-path are not supported with corpus sharding")
+	}
+
+	// Parse and validate the memory limits of specific fuzz targets.
+	cfg.Fuzz.TargetMemoryLimits, err = parseTargetMemory(
+		cfg.Fuzz.TargetMemory, cfg.Fuzz.PkgsPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target memory: %w", err)
+	}
 
 	// Extract the repository name from the source URL and use it to set the
 	// corpus key and corpus directory.
@@ -202,7 +1358,13 @@ func loadConfig() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	cfg.Project.CorpusKey = fmt.Sprintf("%s_corpus.zip", repo)
+	cfg.Project.CorpusKey = fmt.Sprintf("%s_corpus.%s", repo,
+		cfg.Project.ArchiveFormat)
+	cfg.Project.CorpusShardPrefix = fmt.Sprintf("%s_corpus/", repo)
+	cfg.Project.CorpusVersionPrefix = fmt.Sprintf("%s_corpus_versions/",
+		repo)
+	cfg.Project.CorpusQuarantinePrefix = fmt.Sprintf(
+		"%s_corpus_quarantine/", repo)
 
 	// Set the absolute path to the workspace directory.
 	//
@@ -226,10 +1388,522 @@ func loadConfig() (*Config, error) {
 		fmt.Sprintf("%s_corpus", repo))
 	cfg.Project.ReportDir = filepath.Join(tmpDirPath, TmpReportDir)
 	cfg.Project.BinaryDir = filepath.Join(tmpDirPath, TmpBinaryDir)
+	cfg.Project.BisectCorpusDir = filepath.Join(tmpDirPath,
+		TmpBisectCorpusDir)
+	cfg.Project.CoverageDir = filepath.Join(tmpDirPath, TmpCoverageDir)
 
 	return &cfg, nil
 }
 
+// parseConfigFiles parses the given CONF files in order into the parser's
+// config, so values from later files override those from earlier ones. If no
+// files are given, the default CONF file is parsed instead, which may be
+// missing; explicitly given files must exist.
+func parseConfigFiles(parser *flags.Parser, configFiles []string) error {
+	explicit := len(configFiles) > 0
+	if !explicit {
+		configFiles = []string{ConfigFile}
+	}
+
+	for _, configFile := range configFiles {
+		configFilePath := CleanAndExpandPath(configFile)
+		err := flags.NewIniParser(parser).ParseFile(configFilePath)
+		if err == nil {
+			continue
+		}
+
+		var iniErr *flags.IniError
+		var flagsErr *flags.Error
+		// If it's a parsing related error, then we'll return
+		// immediately, otherwise we can proceed as possibly the default
+		// config file doesn't exist which is OK.
+		if errors.As(err, &iniErr) || errors.As(err, &flagsErr) {
+			return err
+		}
+		if explicit {
+			return fmt.Errorf("reading config file %q: %w",
+				configFilePath, err)
+		}
+	}
+
+	return nil
+}
+
+// parseLogRetention parses a log retention given either as the number of most
+// recent logs to keep, or as the maximum age of the logs to keep. An empty
+// retention or a count of 0 keeps all logs.
+func parseLogRetention(retention string) (int, time.Duration, error) {
+	if retention == "" {
+		return 0, 0, nil
+	}
+
+	if keep, err := strconv.Atoi(retention); err == nil {
+		if keep < 0 {
+			return 0, 0, fmt.Errorf("invalid failure log "+
+				"retention %q: count must be non-negative",
+				retention)
+		}
+		return keep, 0, nil
+	}
+
+	maxAge, err := time.ParseDuration(retention)
+	if err != nil || maxAge <= 0 {
+		return 0, 0, fmt.Errorf("invalid failure log retention %q: "+
+			"must be a count or a positive duration", retention)
+	}
+
+	return 0, maxAge, nil
+}
+
+// parseBandwidth parses a bandwidth given as a number of bytes per second,
+// optionally suffixed with K, M or G for multiples of 1024. An empty bandwidth
+// or a bandwidth of 0 is unlimited.
+func parseBandwidth(bandwidth string) (int64, error) {
+	if bandwidth == "" {
+		return 0, nil
+	}
+
+	n, ok := parseByteSize(bandwidth)
+	if !ok || n > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid bandwidth limit %q: must be a "+
+			"non-negative number of bytes per second, optionally "+
+			"suffixed with K, M or G", bandwidth)
+	}
+
+	return n, nil
+}
+
+// parseCycleGracePeriod parses the grace period of the fuzzing cycles, which
+// defaults to the cycleGracePeriod of the given sync frequency if empty.
+func parseCycleGracePeriod(gracePeriod string,
+	syncFrequency time.Duration) (time.Duration, error) {
+
+	if gracePeriod == "" {
+		return cycleGracePeriod(syncFrequency), nil
+	}
+
+	d, err := time.ParseDuration(gracePeriod)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid cycle grace period %q: must be "+
+			"a non-negative duration", gracePeriod)
+	}
+
+	return d, nil
+}
+
+// parseGoCacheMaxSize parses the maximum size of the Go build cache given as a
+// number of bytes, optionally suffixed with K, M or G for multiples of 1024. An
+// empty size or a size of 0 is unlimited.
+func parseGoCacheMaxSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+
+	n, ok := parseByteSize(size)
+	if !ok {
+		return 0, fmt.Errorf("invalid Go build cache size %q: must be "+
+			"a non-negative number of bytes, optionally suffixed "+
+			"with K, M or G", size)
+	}
+
+	return n, nil
+}
+
+// byteSizeUnits are the case-insensitive unit suffixes accepted by
+// parseByteSize, longest first, with their multipliers.
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KI", 1 << 10}, {"MI", 1 << 20}, {"GI", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+}
+
+// parseByteSize parses a non-negative number of bytes, optionally suffixed
+// with K, M or G (or Ki, Mi or Gi, as used by Kubernetes) for multiples of
+// 1024. Returns false if the size is malformed or overflows.
+func parseByteSize(size string) (int64, bool) {
+	number, multiplier := strings.ToUpper(size), int64(1)
+	for _, unit := range byteSizeUnits {
+		n, ok := strings.CutSuffix(number, unit.suffix)
+		if ok {
+			number, multiplier = n, unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseUint(number, 10, 63)
+	if err != nil || int64(n) > math.MaxInt64/multiplier {
+		return 0, false
+	}
+
+	return int64(n) * multiplier, true
+}
+
+// parseMemLimit parses the memory limit of the fuzz containers given as a
+// positive number of bytes, optionally suffixed with K, M or G for multiples
+// of 1024.
+func parseMemLimit(limit string) (int64, error) {
+	n, ok := parseByteSize(limit)
+	if !ok || n == 0 {
+		return 0, fmt.Errorf("invalid memory limit %q: must be a "+
+			"positive number of bytes, optionally suffixed with K, "+
+			"M or G", limit)
+	}
+
+	return n, nil
+}
+
+// parseCPULimit parses the CPU limit of the fuzz containers given as a possibly
+// fractional number of CPUs into billionths of a CPU, as expected by Docker.
+// Returns an error if the limit is below MinContainerNanoCPUs.
+func parseCPULimit(limit string) (int64, error) {
+	cpus, err := strconv.ParseFloat(limit, 64)
+	if err != nil || math.IsNaN(cpus) || math.IsInf(cpus, 0) ||
+		cpus*1e9 < MinContainerNanoCPUs || cpus*1e9 > math.MaxInt64 {
+
+		return 0, fmt.Errorf("invalid CPU limit %q: must be a number "+
+			"of CPUs of at least %.2f", limit,
+			float64(MinContainerNanoCPUs)/1e9)
+	}
+
+	return int64(math.Round(cpus * 1e9)), nil
+}
+
+// recursivePkgDir returns the directory of the packages matched by the given
+// package path of fuzz.pkgs-path if it is a recursive pattern, i.e. ends in
+// "/..." like "./..." or "parser/...", and whether it is such a pattern.
+func recursivePkgDir(pkgPath string) (string, bool) {
+	if pkgPath == "..." {
+		return ".", true
+	}
+
+	dir, ok := strings.CutSuffix(pkgPath, "/...")
+	if !ok {
+		return "", false
+	}
+
+	return path.Clean(dir), true
+}
+
+// isPkgPattern reports whether the given package path of fuzz.pkgs-path is a
+// recursive pattern.
+func isPkgPattern(pkgPath string) bool {
+	_, ok := recursivePkgDir(pkgPath)
+	return ok
+}
+
+// matchesPkgPath reports whether the package pkg is the given package path of
+// fuzz.pkgs-path, or is matched by it if it is a recursive pattern.
+func matchesPkgPath(pkgPath, pkg string) bool {
+	dir, ok := recursivePkgDir(pkgPath)
+	if !ok {
+		return pkgPath == pkg
+	}
+
+	pkg = path.Clean(pkg)
+	return dir == "." || pkg == dir || strings.HasPrefix(pkg, dir+"/")
+}
+
+// validatePkgsPath returns an error if a package path of fuzz.pkgs-path uses
+// "..." other than as the "/..." suffix of a recursive pattern.
+func validatePkgsPath(pkgPaths []string) error {
+	for _, pkgPath := range pkgPaths {
+		dir, ok := recursivePkgDir(pkgPath)
+		if !ok {
+			dir = pkgPath
+		}
+
+		if strings.Contains(dir, "...") {
+			return fmt.Errorf("invalid package path %q: \"...\" "+
+				"is only supported as a \"/...\" suffix", pkgPath)
+		}
+	}
+
+	return nil
+}
+
+// parseTargetMemory parses a list of "pkg/Target=size" memory limits of fuzz
+// targets into a map keyed by "pkg/Target". Returns an error if an entry is
+// malformed or duplicated, its size is not positive, or its package is not
+// one of the fuzzed packages, so a typo cannot silently leave a target at the
+// default limit.
+func parseTargetMemory(entries, pkgs []string) (map[string]int64, error) {
+	limits := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		key, size, ok := strings.Cut(entry, "=")
+		pkg, target := path.Split(key)
+		pkg = strings.TrimSuffix(pkg, "/")
+		if !ok || pkg == "" || target == "" {
+			return nil, fmt.Errorf("%q must be of the form "+
+				"pkg/Target=size", entry)
+		}
+
+		matches := func(pkgPath string) bool {
+			return matchesPkgPath(pkgPath, pkg)
+		}
+		if !slices.ContainsFunc(pkgs, matches) {
+			return nil, fmt.Errorf("%q: package %q is not "+
+				"fuzzed", entry, pkg)
+		}
+
+		if _, ok := limits[key]; ok {
+			return nil, fmt.Errorf("duplicate memory limit for "+
+				"%q", key)
+		}
+
+		limit, ok := parseByteSize(size)
+		if !ok || limit == 0 {
+			return nil, fmt.Errorf("%q: size must be a positive "+
+				"number of bytes, optionally suffixed with K, "+
+				"M or G", entry)
+		}
+		limits[key] = limit
+	}
+
+	return limits, nil
+}
+
+// labelKeyRegex matches valid container label keys: alphanumeric characters
+// separated by dots, dashes, underscores, or slashes.
+var labelKeyRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*` +
+	`[A-Za-z0-9])?$`)
+
+// parseLabels parses a list of "key=value" labels into a map, always including
+// ToolLabel to identify the containers started by go-continuous-fuzz. Returns
+// an error if a label is malformed, duplicated, or overrides ToolLabel.
+func parseLabels(labels []string) (map[string]string, error) {
+	parsed := map[string]string{ToolLabel: "true"}
+	for _, label := range labels {
+		key, value, found := strings.Cut(label, "=")
+		if !found {
+			return nil, fmt.Errorf("label %q is not of the form "+
+				"key=value", label)
+		}
+
+		if !labelKeyRegex.MatchString(key) {
+			return nil, fmt.Errorf("invalid label key %q", key)
+		}
+
+		if strings.ContainsAny(value, "\n\r") {
+			return nil, fmt.Errorf("invalid value for label %q",
+				key)
+		}
+
+		if _, ok := parsed[key]; ok {
+			return nil, fmt.Errorf("duplicate or reserved label "+
+				"key %q", key)
+		}
+		parsed[key] = value
+	}
+
+	return parsed, nil
+}
+
+// validateLogSink ensures the log sink URL, if any, is either a tcp://host:port
+// URL or an absolute HTTP(S) URL.
+func validateLogSink(sink string) error {
+	if sink == "" {
+		return nil
+	}
+
+	u, err := url.Parse(sink)
+	if err == nil {
+		switch u.Scheme {
+		case "tcp":
+			if u.Port() != "" && (u.Path == "" || u.Path == "/") {
+				return nil
+			}
+
+		case "http", "https":
+			if u.Host != "" {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("invalid log sink %q: must be a tcp://host:port "+
+		"URL or an absolute http or https URL", SanitizeURL(sink))
+}
+
+// validateStatsDAddr ensures the StatsD address, if any, is of the form
+// host:port.
+func validateStatsDAddr(addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil || port == "" {
+		return fmt.Errorf("invalid StatsD address %q: must be of the "+
+			"form host:port", addr)
+	}
+
+	return nil
+}
+
+// validateSlackWebhook ensures the Slack webhook URL, if any, is an absolute
+// HTTPS URL.
+func validateSlackWebhook(webhook string) error {
+	if webhook == "" {
+		return nil
+	}
+
+	u, err := url.Parse(webhook)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid Slack webhook URL %q: must be an "+
+			"absolute https URL", SanitizeURL(webhook))
+	}
+
+	return nil
+}
+
+// validateNotifyWebhook ensures the notification webhook URL, if any, is an
+// absolute HTTP(S) URL, and that a webhook secret is only set along with it.
+func validateNotifyWebhook(n *Notify) error {
+	if n.WebhookURL == "" {
+		if n.WebhookSecret != "" {
+			return errors.New("notify.webhook-secret requires " +
+				"notify.webhook-url")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(n.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" {
+
+		return fmt.Errorf("invalid notification webhook URL %q: must "+
+			"be an absolute http or https URL",
+			SanitizeURL(n.WebhookURL))
+	}
+
+	return nil
+}
+
+// validateWebhook ensures the webhook URL, if any, is an absolute HTTP(S) URL,
+// that its timeout is positive, and that a webhook secret or custom headers
+// are only set along with a webhook URL.
+func validateWebhook(f *Fuzz) error {
+	if f.WebhookURL == "" {
+		if f.WebhookSecret != "" || len(f.WebhookHeaders) > 0 {
+			return errors.New("webhook-secret and webhook-header " +
+				"require webhook-url")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(f.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" {
+
+		return fmt.Errorf("invalid webhook URL %q: must be an "+
+			"absolute http or https URL", SanitizeURL(f.WebhookURL))
+	}
+
+	if f.WebhookTimeout <= 0 {
+		return fmt.Errorf("invalid webhook timeout: %s, must be "+
+			"positive", f.WebhookTimeout)
+	}
+
+	return nil
+}
+
+// headerNameRegex matches valid HTTP header names, made of token characters.
+var headerNameRegex = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// parseWebhookHeaders parses a list of "Name: value" headers into a map keyed
+// by their canonical name. Returns an error if a header is malformed or
+// duplicated, or overrides the Content-Type or WebhookSignatureHeader headers
+// set by the webhook itself.
+func parseWebhookHeaders(headers []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found || !headerNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("header %q is not of the form "+
+				"\"Name: value\"", header)
+		}
+
+		value = strings.TrimSpace(value)
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid value for header %q",
+				name)
+		}
+
+		name = http.CanonicalHeaderKey(name)
+		if name == "Content-Type" || name == WebhookSignatureHeader {
+			return nil, fmt.Errorf("reserved header %q", name)
+		}
+		if _, ok := parsed[name]; ok {
+			return nil, fmt.Errorf("duplicate header %q", name)
+		}
+		parsed[name] = value
+	}
+
+	return parsed, nil
+}
+
+// parseIssueLabels parses a comma-separated list of issue labels, trimming the
+// spaces around each label. Returns nil if no labels are given, and an error if
+// a label is empty or contains a double quote, which would break the issue
+// search queries.
+func parseIssueLabels(labels string) ([]string, error) {
+	if labels == "" {
+		return nil, nil
+	}
+
+	var parsed []string
+	for _, label := range strings.Split(labels, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			return nil, fmt.Errorf("empty label in %q", labels)
+		}
+
+		if strings.Contains(label, `"`) {
+			return nil, fmt.Errorf("invalid label %q", label)
+		}
+		parsed = append(parsed, label)
+	}
+
+	return parsed, nil
+}
+
+// assigneeRegex matches valid usernames of GitHub and GitLab users: alphanumeric
+// characters separated by dots, dashes, or underscores.
+var assigneeRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*` +
+	`[A-Za-z0-9])?$`)
+
+// parseIssueAssignees parses a comma-separated list of usernames, trimming the
+// spaces and any leading @ around each one. Returns nil if no assignees are
+// given, and an error if a username is empty, malformed, or duplicated.
+func parseIssueAssignees(assignees string) ([]string, error) {
+	if assignees == "" {
+		return nil, nil
+	}
+
+	var parsed []string
+	for _, assignee := range strings.Split(assignees, ",") {
+		assignee = strings.TrimPrefix(strings.TrimSpace(assignee), "@")
+		if assignee == "" {
+			return nil, fmt.Errorf("empty assignee in %q",
+				assignees)
+		}
+
+		if !assigneeRegex.MatchString(assignee) {
+			return nil, fmt.Errorf("invalid assignee %q", assignee)
+		}
+
+		if slices.Contains(parsed, assignee) {
+			return nil, fmt.Errorf("duplicate assignee %q",
+				assignee)
+		}
+		parsed = append(parsed, assignee)
+	}
+
+	return parsed, nil
+}
+
 // CleanAndExpandPath expands environment variables and leading ~ in the
 // passed path, cleans the result, and returns it.
 // This function is taken from https://github.com/btcsuite/btcd
@@ -255,3 +1929,78 @@ func CleanAndExpandPath(path string) string {
 	// but the variables can still be expanded via POSIX-style $VARIABLE.
 	return filepath.Clean(os.ExpandEnv(path))
 }
+
+// capabilityRegex matches Linux capability names without the "CAP_" prefix.
+var capabilityRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// parseCapabilities normalizes a list of Linux capabilities to the upper-case
+// form without the "CAP_" prefix expected by Docker, dropping duplicates.
+// Returns an error if a capability name is malformed or is "ALL", as granting
+// every capability would effectively run the fuzz targets privileged.
+func parseCapabilities(caps []string) ([]string, error) {
+	var parsed []string
+	seen := make(map[string]bool)
+	for _, c := range caps {
+		name := strings.TrimPrefix(strings.ToUpper(c), "CAP_")
+		if !capabilityRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid capability %q", c)
+		}
+
+		if name == "ALL" {
+			return nil, errors.New("adding all capabilities is " +
+				"not allowed, list the required ones instead")
+		}
+
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		parsed = append(parsed, name)
+	}
+
+	return parsed, nil
+}
+
+// validateContainerImage returns an error if the image is not a valid Docker
+// image reference, e.g. golang:1.24.6 or registry.example.com/fuzz@sha256:...
+func validateContainerImage(image string) error {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return fmt.Errorf("invalid container image %q: %w", image, err)
+	}
+
+	return nil
+}
+
+// validateRef returns an error if the reference of the project repository to
+// fuzz, if set, is neither a valid branch or tag name nor a valid full
+// reference name.
+func validateRef(ref string) error {
+	if ref == "" {
+		return nil
+	}
+
+	name := plumbing.NewBranchReferenceName(ref)
+	if strings.HasPrefix(ref, "refs/") {
+		name = plumbing.ReferenceName(ref)
+	}
+	if err := name.Validate(); err != nil {
+		return fmt.Errorf("invalid ref %q: %w", ref, err)
+	}
+
+	return nil
+}
+
+// validateCorpusVersion returns an error if the corpus version is neither
+// LatestCorpusVersion nor a date formatted according to CorpusVersionLayout.
+func validateCorpusVersion(version string) error {
+	if version == LatestCorpusVersion {
+		return nil
+	}
+
+	if _, err := time.Parse(CorpusVersionLayout, version); err != nil {
+		return fmt.Errorf("invalid corpus version %q: must be a "+
+			"YYYY-MM-DD date or %q", version, LatestCorpusVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,640 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseLabels verifies that container labels are parsed from key=value
+// pairs, always include the tool label, and reject malformed input.
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name           string
+		labels         []string
+		expectedLabels map[string]string
+		expectErrMsg   string
+	}{
+		{
+			name:           "no labels",
+			expectedLabels: map[string]string{ToolLabel: "true"},
+		},
+		{
+			name: "valid labels",
+			labels: []string{"team=security",
+				"com.example/cost-center=", "env=a=b"},
+			expectedLabels: map[string]string{
+				ToolLabel:                 "true",
+				"team":                    "security",
+				"com.example/cost-center": "",
+				"env":                     "a=b",
+			},
+		},
+		{
+			name:         "missing separator",
+			labels:       []string{"team"},
+			expectErrMsg: "not of the form key=value",
+		},
+		{
+			name:         "invalid key",
+			labels:       []string{"team name=security"},
+			expectErrMsg: "invalid label key",
+		},
+		{
+			name:         "invalid value",
+			labels:       []string{"team=a\nb"},
+			expectErrMsg: "invalid value",
+		},
+		{
+			name:         "duplicate key",
+			labels:       []string{"team=a", "team=b"},
+			expectErrMsg: "duplicate or reserved",
+		},
+		{
+			name:         "reserved tool label",
+			labels:       []string{ToolLabel + "=false"},
+			expectErrMsg: "duplicate or reserved",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLabels(tt.labels)
+			if tt.expectErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLabels, got)
+		})
+	}
+}
+
+// TestParseWebhookHeaders verifies that webhook headers are parsed from
+// "Name: value" pairs into their canonical names, and that malformed,
+// duplicated and reserved headers are rejected.
+func TestParseWebhookHeaders(t *testing.T) {
+	headers, err := parseWebhookHeaders([]string{
+		"authorization: Bearer token", "X-Team:security",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Authorization": "Bearer token",
+		"X-Team":        "security",
+	}, headers)
+
+	_, err = parseWebhookHeaders([]string{"Authorization"})
+	assert.ErrorContains(t, err, "not of the form")
+
+	_, err = parseWebhookHeaders([]string{"X Team: security"})
+	assert.ErrorContains(t, err, "not of the form")
+
+	_, err = parseWebhookHeaders([]string{"X-Team: a", "x-team: b"})
+	assert.ErrorContains(t, err, "duplicate header")
+
+	_, err = parseWebhookHeaders([]string{"x-signature: forged"})
+	assert.ErrorContains(t, err, "reserved header")
+}
+
+// TestValidateLogSink verifies that the log sink must be a tcp://host:port URL
+// or an absolute HTTP(S) URL.
+func TestValidateLogSink(t *testing.T) {
+	assert.NoError(t, validateLogSink(""))
+	assert.NoError(t, validateLogSink("tcp://logs.example.com:5170"))
+	assert.NoError(t, validateLogSink("https://logs.example.com/ingest"))
+	assert.NoError(t, validateLogSink(
+		"http://loki:3100/loki/api/v1/push"))
+
+	for _, sink := range []string{
+		"tcp://logs.example.com",
+		"tcp://logs.example.com:5170/path",
+		"logs.example.com:5170",
+		"https:///ingest",
+		"udp://logs.example.com:514",
+	} {
+		assert.ErrorContains(t, validateLogSink(sink),
+			"invalid log sink", sink)
+	}
+}
+
+// TestValidateStatsDAddr verifies that the StatsD address must be of the form
+// host:port.
+func TestValidateStatsDAddr(t *testing.T) {
+	assert.NoError(t, validateStatsDAddr(""))
+	assert.NoError(t, validateStatsDAddr("localhost:8125"))
+	assert.NoError(t, validateStatsDAddr("[::1]:8125"))
+
+	for _, addr := range []string{
+		"localhost",
+		"localhost:",
+		"udp://localhost:8125",
+	} {
+		assert.ErrorContains(t, validateStatsDAddr(addr),
+			"invalid StatsD address", addr)
+	}
+}
+
+// TestValidateSlackWebhook verifies that the Slack webhook must be an absolute
+// HTTPS URL.
+func TestValidateSlackWebhook(t *testing.T) {
+	assert.NoError(t, validateSlackWebhook(""))
+	assert.NoError(t, validateSlackWebhook(
+		"https://hooks.slack.com/services/T000/B000/XXXX"))
+
+	for _, webhook := range []string{
+		"http://hooks.slack.com/services/T000/B000/XXXX",
+		"hooks.slack.com/services/T000/B000/XXXX",
+		"https:///services",
+	} {
+		assert.ErrorContains(t, validateSlackWebhook(webhook),
+			"invalid Slack webhook URL", webhook)
+	}
+}
+
+// TestValidateNotifyWebhook verifies that the notification webhook must be an
+// absolute HTTP(S) URL, and that its secret requires it.
+func TestValidateNotifyWebhook(t *testing.T) {
+	assert.NoError(t, validateNotifyWebhook(&Notify{}))
+	assert.NoError(t, validateNotifyWebhook(&Notify{
+		WebhookURL:    "https://incidents.example.com/hooks/fuzz",
+		WebhookSecret: "secret",
+	}))
+
+	err := validateNotifyWebhook(&Notify{WebhookSecret: "secret"})
+	assert.ErrorContains(t, err, "requires notify.webhook-url")
+
+	err = validateNotifyWebhook(&Notify{
+		WebhookURL: "incidents.example.com/hooks/fuzz",
+	})
+	assert.ErrorContains(t, err, "invalid notification webhook URL")
+}
+
+// TestValidateWebhook verifies that the webhook URL must be an absolute HTTP(S)
+// URL with a positive timeout, and that the webhook secret and headers require
+// it.
+func TestValidateWebhook(t *testing.T) {
+	assert.NoError(t, validateWebhook(&Fuzz{}))
+	assert.NoError(t, validateWebhook(&Fuzz{
+		WebhookURL:     "https://hooks.example.com/fuzz",
+		WebhookSecret:  "secret",
+		WebhookTimeout: time.Second,
+	}))
+
+	err := validateWebhook(&Fuzz{WebhookSecret: "secret"})
+	assert.ErrorContains(t, err, "require webhook-url")
+
+	err = validateWebhook(&Fuzz{
+		WebhookURL:     "hooks.example.com/fuzz",
+		WebhookTimeout: time.Second,
+	})
+	assert.ErrorContains(t, err, "invalid webhook URL")
+
+	err = validateWebhook(&Fuzz{WebhookURL: "https://hooks.example.com"})
+	assert.ErrorContains(t, err, "invalid webhook timeout")
+}
+
+// TestParseIssueLabels verifies that issue labels are parsed from a
+// comma-separated list, and that empty and quoted labels are rejected.
+func TestParseIssueLabels(t *testing.T) {
+	labels, err := parseIssueLabels("")
+	assert.NoError(t, err)
+	assert.Nil(t, labels)
+
+	labels, err = parseIssueLabels("fuzz, needs triage ,security")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fuzz", "needs triage", "security"}, labels)
+
+	_, err = parseIssueLabels("fuzz,,security")
+	assert.ErrorContains(t, err, "empty label")
+
+	_, err = parseIssueLabels("fuzz, ")
+	assert.ErrorContains(t, err, "empty label")
+
+	_, err = parseIssueLabels(`fuzz,"security"`)
+	assert.ErrorContains(t, err, "invalid label")
+}
+
+// TestParseIssueAssignees verifies that issue assignees are parsed from a
+// comma-separated list of usernames, and that empty, malformed, and duplicate
+// usernames are rejected.
+func TestParseIssueAssignees(t *testing.T) {
+	assignees, err := parseIssueAssignees("")
+	assert.NoError(t, err)
+	assert.Nil(t, assignees)
+
+	assignees, err = parseIssueAssignees("alice, @bob ,carol.d_e")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol.d_e"}, assignees)
+
+	_, err = parseIssueAssignees("alice,,bob")
+	assert.ErrorContains(t, err, "empty assignee")
+
+	_, err = parseIssueAssignees("alice,org/team")
+	assert.ErrorContains(t, err, "invalid assignee")
+
+	_, err = parseIssueAssignees("alice,@alice")
+	assert.ErrorContains(t, err, "duplicate assignee")
+}
+
+// TestValidateCorpusVersion verifies that only dates and "latest" are accepted
+// as corpus versions to restore.
+func TestValidateCorpusVersion(t *testing.T) {
+	assert.NoError(t, validateCorpusVersion("latest"))
+	assert.NoError(t, validateCorpusVersion("2025-07-12"))
+
+	for _, version := range []string{"", "Latest", "2025-7-12",
+		"2025-07-12/x", "2025-13-01"} {
+
+		assert.ErrorContains(t, validateCorpusVersion(version),
+			"invalid corpus version", version)
+	}
+}
+
+// TestValidateRef verifies that branch and tag names and full reference names
+// are accepted, and malformed names rejected.
+func TestValidateRef(t *testing.T) {
+	for _, ref := range []string{"", "main", "release/1.2", "v1.2.0",
+		"refs/tags/v1.2.0"} {
+
+		assert.NoError(t, validateRef(ref), ref)
+	}
+
+	for _, ref := range []string{"release..1", "feature branch", "v1.2.",
+		"/main", "refs/heads//main", "main~1"} {
+
+		assert.ErrorContains(t, validateRef(ref), "invalid ref", ref)
+	}
+}
+
+// TestParseCapabilities verifies that capabilities are normalized to Docker's
+// form, deduplicated, and that malformed names and "ALL" are rejected.
+func TestParseCapabilities(t *testing.T) {
+	caps, err := parseCapabilities(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, caps)
+
+	caps, err = parseCapabilities([]string{"NET_ADMIN", "cap_sys_ptrace",
+		"CAP_NET_ADMIN"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"NET_ADMIN", "SYS_PTRACE"}, caps)
+
+	_, err = parseCapabilities([]string{"NET ADMIN"})
+	assert.ErrorContains(t, err, "invalid capability")
+
+	_, err = parseCapabilities([]string{"all"})
+	assert.ErrorContains(t, err, "not allowed")
+}
+
+// TestValidateContainerImage verifies that Docker image references, with or
+// without registry, tag or digest, are accepted and malformed ones rejected.
+func TestValidateContainerImage(t *testing.T) {
+	for _, image := range []string{
+		ContainerImage,
+		"golang",
+		"registry.example.com:5000/team/fuzz:go1.24-cgo",
+		"ghcr.io/org/fuzz@sha256:" + strings.Repeat("a", 64),
+	} {
+		assert.NoError(t, validateContainerImage(image), image)
+	}
+
+	for _, image := range []string{"", "Golang:1.24", "golang:1.24 cgo",
+		"golang:", "golang@sha256:abc"} {
+
+		assert.ErrorContains(t, validateContainerImage(image),
+			"invalid container image", image)
+	}
+}
+
+// TestParseContainerLimits verifies that the memory and CPU limits of the fuzz
+// containers are parsed into the values expected by Docker, and that limits
+// that are not positive or malformed are rejected.
+func TestParseContainerLimits(t *testing.T) {
+	memory, err := parseMemLimit("2G")
+	assert.NoError(t, err)
+	assert.EqualValues(t, ContainerMemoryLimit, memory)
+
+	memory, err = parseMemLimit("512Mi")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 512<<20, memory)
+
+	for _, limit := range []string{"", "0", "-1G", "1.5G", "8GB"} {
+		_, err := parseMemLimit(limit)
+		assert.ErrorContains(t, err, "invalid memory limit", limit)
+	}
+
+	cpus := map[string]int64{
+		"1":    1_000_000_000,
+		"0.5":  500_000_000,
+		"2.25": 2_250_000_000,
+		"0.01": MinContainerNanoCPUs,
+	}
+	for limit, expected := range cpus {
+		nanoCPUs, err := parseCPULimit(limit)
+		assert.NoError(t, err, limit)
+		assert.Equal(t, expected, nanoCPUs, limit)
+	}
+
+	for _, limit := range []string{"", "0", "-1", "0.001", "NaN", "Inf",
+		"1e300", "two"} {
+
+		_, err := parseCPULimit(limit)
+		assert.ErrorContains(t, err, "invalid CPU limit", limit)
+	}
+}
+
+// TestParseGoCacheMaxSize verifies that the maximum size of the Go build cache
+// is parsed as a number of bytes with an optional binary unit suffix, and that
+// malformed sizes are rejected.
+func TestParseGoCacheMaxSize(t *testing.T) {
+	size, err := parseGoCacheMaxSize("")
+	assert.NoError(t, err)
+	assert.Zero(t, size)
+
+	size, err = parseGoCacheMaxSize("10G")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10<<30), size)
+
+	_, err = parseGoCacheMaxSize("-1G")
+	assert.ErrorContains(t, err, "invalid Go build cache size")
+
+	_, err = parseGoCacheMaxSize("10 GB")
+	assert.ErrorContains(t, err, "invalid Go build cache size")
+}
+
+// TestParseCycleGracePeriod verifies that the cycle grace period defaults to a
+// fraction of the sync frequency, that 0 disables it, and that negative or
+// malformed durations are rejected.
+func TestParseCycleGracePeriod(t *testing.T) {
+	gracePeriod, err := parseCycleGracePeriod("", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, cycleGracePeriod(24*time.Hour), gracePeriod)
+
+	gracePeriod, err = parseCycleGracePeriod("0", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, gracePeriod)
+
+	gracePeriod, err = parseCycleGracePeriod("10m", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, gracePeriod)
+
+	for _, gracePeriod := range []string{"-1m", "10", "soon"} {
+		_, err = parseCycleGracePeriod(gracePeriod, 24*time.Hour)
+		assert.ErrorContains(t, err, "invalid cycle grace period",
+			gracePeriod)
+	}
+}
+
+// TestParseBandwidth verifies that a bandwidth limit is parsed as a number of
+// bytes per second with an optional binary unit suffix, and that negative or
+// malformed limits are rejected.
+func TestParseBandwidth(t *testing.T) {
+	tests := map[string]int64{
+		"":     0,
+		"0":    0,
+		"1500": 1500,
+		"512K": 512 << 10,
+		"10M":  10 << 20,
+		"10m":  10 << 20,
+		"10Mi": 10 << 20,
+		"1G":   1 << 30,
+	}
+	for bandwidth, expected := range tests {
+		limit, err := parseBandwidth(bandwidth)
+		assert.NoError(t, err, bandwidth)
+		assert.Equal(t, expected, limit, bandwidth)
+	}
+
+	for _, bandwidth := range []string{"-1", "M", "10MB", "1.5M", "4G",
+		"10iM"} {
+		_, err := parseBandwidth(bandwidth)
+		assert.ErrorContains(t, err, "invalid bandwidth limit",
+			bandwidth)
+	}
+}
+
+// TestParseTargetMemory verifies that per-target memory limits are parsed into
+// byte counts keyed by target, that they override the default container memory
+// limit, and that malformed entries are rejected.
+func TestParseTargetMemory(t *testing.T) {
+	pkgs := []string{"parser", "encoding/json"}
+	limits, err := parseTargetMemory([]string{
+		"parser/FuzzParse=8Gi",
+		"encoding/json/FuzzDecode=512M",
+		"parser/FuzzLex=1073741824",
+	}, pkgs)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"parser/FuzzParse":         8 << 30,
+		"encoding/json/FuzzDecode": 512 << 20,
+		"parser/FuzzLex":           1 << 30,
+	}, limits)
+
+	fuzz := Fuzz{
+		ContainerMemory:    ContainerMemoryLimit,
+		TargetMemoryLimits: limits,
+	}
+	assert.EqualValues(t, 8<<30, fuzz.memoryLimit("parser", "FuzzParse"))
+	assert.EqualValues(t, ContainerMemoryLimit,
+		fuzz.memoryLimit("parser", "FuzzEval"))
+
+	tests := map[string]string{
+		"parser/FuzzParse":                       "must be of the form",
+		"FuzzParse=8G":                           "must be of the form",
+		"parser/=8G":                             "must be of the form",
+		"lexer/FuzzLex=8G":                       "is not fuzzed",
+		"parser/FuzzParse=0":                     "must be a positive",
+		"parser/FuzzParse=8GB":                   "must be a positive",
+		"parser/FuzzParse=-1G":                   "must be a positive",
+		"parser/FuzzParse=99999999999999999999G": "must be a positive",
+	}
+	for entry, expected := range tests {
+		_, err := parseTargetMemory([]string{entry}, pkgs)
+		assert.ErrorContains(t, err, expected, entry)
+	}
+
+	_, err = parseTargetMemory([]string{
+		"parser/FuzzParse=8G", "parser/FuzzParse=4G",
+	}, pkgs)
+	assert.ErrorContains(t, err, "duplicate memory limit")
+
+	// Packages matched by recursive patterns are fuzzed too.
+	_, err = parseTargetMemory([]string{"encoding/xml/FuzzDecode=1G"},
+		[]string{"encoding/..."})
+	assert.NoError(t, err)
+	_, err = parseTargetMemory([]string{"encodings/FuzzDecode=1G"},
+		[]string{"encoding/..."})
+	assert.ErrorContains(t, err, "is not fuzzed")
+}
+
+// TestMatchesPkgPath verifies that recursive package patterns match the
+// packages in and below their directory, and that "..." is only accepted as
+// their suffix.
+func TestMatchesPkgPath(t *testing.T) {
+	for pkgPath, dir := range map[string]string{
+		"./...":         ".",
+		"...":           ".",
+		"parser/...":    "parser",
+		"./parser/...":  "parser",
+		"encoding/...":  "encoding",
+		"parser/./...":  "parser",
+		"parser//...":   "parser",
+		"cmd/tool/...":  "cmd/tool",
+		"./cmd/tool/..": "",
+	} {
+		got, ok := recursivePkgDir(pkgPath)
+		assert.Equal(t, dir != "", ok, pkgPath)
+		assert.Equal(t, dir, got, pkgPath)
+	}
+
+	assert.True(t, matchesPkgPath("parser", "parser"))
+	assert.False(t, matchesPkgPath("parser", "parser/lexer"))
+	assert.True(t, matchesPkgPath("./...", "parser/lexer"))
+	assert.True(t, matchesPkgPath("parser/...", "parser"))
+	assert.True(t, matchesPkgPath("parser/...", "parser/lexer"))
+	assert.False(t, matchesPkgPath("parser/...", "parsers"))
+
+	assert.NoError(t, validatePkgsPath([]string{"parser", "./...",
+		"cmd/..."}))
+	for _, pkgPath := range []string{"parser/.../lexer", "./..../",
+		"parser..."} {
+
+		assert.ErrorContains(t, validatePkgsPath([]string{pkgPath}),
+			"only supported as", pkgPath)
+	}
+}
+
+// TestParseLogRetention verifies that a log retention is parsed as either a
+// count or a maximum age, and that negative or malformed retentions are
+// rejected.
+func TestParseLogRetention(t *testing.T) {
+	keep, maxAge, err := parseLogRetention("")
+	assert.NoError(t, err)
+	assert.Zero(t, keep)
+	assert.Zero(t, maxAge)
+
+	keep, maxAge, err = parseLogRetention("5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, keep)
+	assert.Zero(t, maxAge)
+
+	keep, maxAge, err = parseLogRetention("720h")
+	assert.NoError(t, err)
+	assert.Zero(t, keep)
+	assert.Equal(t, 720*time.Hour, maxAge)
+
+	for _, retention := range []string{"-1", "0s", "-1h", "week"} {
+		_, _, err = parseLogRetention(retention)
+		assert.ErrorContains(t, err, "invalid failure log retention",
+			retention)
+	}
+}
+
+// TestParseConfigFiles verifies that config files are layered in order, with
+// later files overriding earlier ones, and that missing files are rejected.
+func TestParseConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.conf")
+	assert.NoError(t, os.WriteFile(base, []byte(`
+[Project]
+project.src-repo = https://github.com/OWNER/REPO.git
+project.s3-bucket-name = base-bucket
+
+[Fuzz Options]
+fuzz.num-workers = 2
+fuzz.pkgs-path = parser
+fuzz.pkgs-path = tree
+`), 0o644))
+
+	override := filepath.Join(dir, "prod.conf")
+	assert.NoError(t, os.WriteFile(override, []byte(`
+[Project]
+project.s3-bucket-name = prod-bucket
+
+[Fuzz Options]
+fuzz.pkgs-path = stringutils
+`), 0o644))
+
+	var cfg Config
+	parser := flags.NewParser(&cfg, flags.Default)
+	assert.NoError(t, parseConfigFiles(parser, []string{base, override}))
+
+	assert.Equal(t, "https://github.com/OWNER/REPO.git",
+		cfg.Project.SrcRepo)
+	assert.Equal(t, "prod-bucket", cfg.Project.S3BucketName)
+	assert.Equal(t, 2, cfg.Fuzz.NumWorkers)
+	assert.Equal(t, []string{"stringutils"}, cfg.Fuzz.PkgsPath)
+
+	// Explicitly given config files must exist.
+	parser = flags.NewParser(&Config{}, flags.Default)
+	err := parseConfigFiles(parser, []string{base,
+		filepath.Join(dir, "missing.conf")})
+	assert.ErrorContains(t, err, "missing.conf")
+}
+
+// TestCorpusSyncMode verifies whether the corpus and reports are downloaded
+// and uploaded in each corpus sync mode, and whether the local reports are
+// kept across cycles when they are not downloaded.
+func TestCorpusSyncMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		download bool
+		upload   bool
+	}{
+		{CorpusSyncBoth, true, true},
+		{CorpusSyncDownload, true, false},
+		{CorpusSyncUpload, false, true},
+		{CorpusSyncNone, false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.mode, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &Config{Project: Project{
+				CorpusSyncMode: tc.mode,
+				SrcDir:         filepath.Join(dir, "project"),
+				ReportDir:      filepath.Join(dir, "reports"),
+				BinaryDir:      filepath.Join(dir, "binaries"),
+				CoverageDir:    filepath.Join(dir, "coverage"),
+			}}
+			project := &cfg.Project
+			assert.Equal(t, tc.download, project.downloadsCorpus())
+			assert.Equal(t, tc.upload, project.uploadsCorpus())
+
+			writeFiles(t, project.ReportDir, map[string]string{
+				"state.json": "{}",
+			})
+			cleanupTmpDirs(slog.New(slog.DiscardHandler), cfg,
+				false)
+			_, err := os.Stat(filepath.Join(project.ReportDir,
+				"state.json"))
+			assert.Equal(t, tc.download, os.IsNotExist(err))
+		})
+	}
+
+	// Unknown modes are rejected when parsing the options.
+	parse := func(mode string) error {
+		parser := flags.NewParser(&Config{}, flags.None)
+		parser.SubcommandsOptional = true
+		_, err := parser.ParseArgs([]string{
+			"--project.src-repo=https://github.com/OWNER/REPO.git",
+			"--fuzz.crash-repo=https://github.com/OWNER/CRASH.git",
+			"--fuzz.pkgs-path=parser",
+			// The option's "7d" default is not a Go duration.
+			"--fuzz.corpus-minimize-interval=168h",
+			"--project.corpus-sync-mode=" + mode,
+		})
+		return err
+	}
+	assert.NoError(t, parse(CorpusSyncDownload))
+	assert.ErrorContains(t, parse("sideways"), "Invalid value "+
+		"`sideways' for option `--project.corpus-sync-mode'")
+}
@@ -2,26 +2,70 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 )
 
-// Container encapsulates the configuration and state needed to manage a Docker
-// container for running fuzzing tasks, including context, logger, Docker client
-// configuration, directories path, and command.
+// Container manages a Docker container running a fuzzing task.
 type Container struct {
-	ctx            context.Context
-	logger         *slog.Logger
-	cli            *client.Client
+	ctx    context.Context
+	logger *slog.Logger
+	cli    *client.Client
+
+	// image is the image the container runs.
+	image string
+
 	fuzzBinaryPath string
 	hostCorpusPath string
 	cmd            []string
+
+	// labels are the labels of the container.
+	labels map[string]string
+
+	// capAdd lists the Linux capabilities added to the container.
+	capAdd []string
+
+	// memory is the memory limit of the container in bytes, and
+	// nanoCPUs its CPU limit in billionths of a CPU.
+	memory   int64
+	nanoCPUs int64
+
+	// engine is the fuzzing engine whose output is processed.
+	engine fuzzEngine
+
+	// execs is the number of executions last reported by the fuzzer.
+	execs atomic.Int64
+}
+
+// newContainer returns a container running the given fuzz target with the
+// image, labels, capabilities and resource limits configured in cfg, so that
+// none of them is forgotten. The caller sets the mounted paths and command.
+func newContainer(ctx context.Context, logger *slog.Logger,
+	cli *client.Client, cfg *Fuzz, engine fuzzEngine, pkg,
+	target string) *Container {
+
+	return &Container{
+		ctx:      ctx,
+		logger:   logger,
+		cli:      cli,
+		image:    cfg.ContainerImage,
+		labels:   cfg.ContainerLabels,
+		capAdd:   cfg.CapAdd,
+		memory:   cfg.memoryLimit(pkg, target),
+		nanoCPUs: cfg.ContainerNanoCPUs,
+		engine:   engine,
+	}
 }
 
 // Start creates and starts a Docker container with the specified configuration.
@@ -31,19 +75,21 @@ func (c *Container) Start() (string, error) {
 	// Prepare Docker container configuration and limit resources for the
 	// container.
 	containerConfig := &container.Config{
-		Image:        ContainerImage,
+		Image:        c.image,
 		Cmd:          c.cmd,
 		WorkingDir:   ContainerWorkDir,
 		User:         fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
 		AttachStdout: true,
 		AttachStderr: true,
 		Tty:          true,
+		Labels:       c.labels,
 		Env: []string{
 			"GOCACHE=/tmp",
 		},
 	}
 	hostConfig := &container.HostConfig{
 		AutoRemove: true,
+		CapAdd:     c.capAdd,
 		Binds: []string{
 			fmt.Sprintf("%s:%s", c.fuzzBinaryPath,
 				ContainerWorkDir),
@@ -51,8 +97,8 @@ func (c *Container) Start() (string, error) {
 				ContainerCorpusPath),
 		},
 		Resources: container.Resources{
-			Memory:   2 * 1024 * 1024 * 1024,
-			NanoCPUs: 1_000_000_000,
+			Memory:   c.memory,
+			NanoCPUs: c.nanoCPUs,
 		},
 	}
 
@@ -79,6 +125,9 @@ func (c *Container) Start() (string, error) {
 // 1. If a fuzz failure is detected, crash data is sent on fuzzCrashChan.
 // 2. Otherwise, retrieves the container's exit error and sends it on errChan.
 //
+// An out-of-memory crash detected in the output is only sent on fuzzCrashChan
+// if the container exited with an error.
+//
 // No values are sent if the context is canceled or times out.
 //
 //	This MUST be run as a goroutine.
@@ -86,13 +135,7 @@ func (c *Container) WaitAndGetLogs(ID, pkg, target string,
 	fuzzCrashChan chan fuzzCrash, errChan chan error) {
 
 	// Acquire the log stream (stdout + stderr) for the running container.
-	logsReader, err := c.cli.ContainerLogs(c.ctx, ID,
-		container.LogsOptions{
-			ShowStdout: true,
-			ShowStderr: true,
-			Follow:     true,
-			Timestamps: false,
-		})
+	logsReader, err := c.followLogs(ID)
 	if err != nil {
 		if c.ctx.Err() == nil {
 			errChan <- fmt.Errorf("unable to attach to logs for "+
@@ -115,7 +158,8 @@ func (c *Container) WaitAndGetLogs(ID, pkg, target string,
 	// Process the standard output, which may include both stdout and stderr
 	// content.
 	processor := NewFuzzOutputProcessor(c.logger.With("target", target).
-		With("package", pkg), maybeFailingCorpusPath)
+		With("package", pkg), maybeFailingCorpusPath, c.engine)
+	processor.execs = &c.execs
 	crashData, err := processor.processFuzzStream(logsReader)
 	if err != nil {
 		errChan <- fmt.Errorf("failed to process fuzz stream for "+
@@ -124,19 +168,47 @@ func (c *Container) WaitAndGetLogs(ID, pkg, target string,
 	}
 
 	// Fuzz target crashed, so report and exit this goroutine.
-	if crashData != nil {
+	if crashData != nil &&
+		crashData.failureFileAndLine != OOMFailureLocation {
+
 		fuzzCrashChan <- *crashData
 		return
 	}
 
 	// Retrieve the container's exit status and send error (if any) on
-	// errChan.
-	errChan <- c.Wait(ID)
+	// errChan, along with the last lines of the output for a non-zero exit
+	// status.
+	err = c.Wait(ID)
+
+	// An out-of-memory line in the output is only a crash if the container
+	// failed because of it.
+	if crashData != nil && err != nil {
+		fuzzCrashChan <- *crashData
+		return
+	}
+
+	var exitErr *containerExitError
+	if errors.As(err, &exitErr) {
+		exitErr.outputTail = processor.outputTail()
+	}
+	errChan <- err
+}
+
+// followLogs returns the log stream (stdout + stderr) of the running container,
+// which ends when the container exits.
+func (c *Container) followLogs(ID string) (io.ReadCloser, error) {
+	return c.cli.ContainerLogs(c.ctx, ID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: false,
+	})
 }
 
 // Wait waits for the specified Docker container to finish execution. It returns
-// an error if the container exits with a non-zero status or if there is an
-// error waiting for the container to finish.
+// an error if the container exits with a non-zero status, noting whether it was
+// killed for running out of memory, or if there is an error waiting for the
+// container to finish.
 func (c *Container) Wait(ID string) error {
 	// Wait for the container to finish.
 	statusCh, errCh := c.cli.ContainerWait(c.ctx, ID,
@@ -150,14 +222,179 @@ func (c *Container) Wait(ID string) error {
 		}
 	case status := <-statusCh:
 		if status.StatusCode != 0 {
-			return fmt.Errorf("fuzz container exited with "+
-				"status %d", status.StatusCode)
+			return &containerExitError{
+				statusCode: status.StatusCode,
+				oomKilled: c.oomKilled(ID,
+					status.StatusCode),
+				memoryLimit: c.memory,
+			}
 		}
 	}
 
 	return nil
 }
 
+// oomKilled reports whether the exited container with the given exit status
+// was killed for running out of memory, as recorded in its state. Since the
+// container is removed once it exits, its state may already be gone, in which
+// case being killed with SIGKILL is attributed to the OOM killer: the container
+// is only ever stopped after it exits.
+func (c *Container) oomKilled(ID string, statusCode int64) bool {
+	info, err := c.cli.ContainerInspect(c.ctx, ID)
+	if err != nil || info.State == nil {
+		c.logger.Debug("unable to inspect exited container",
+			"container", ID, "error", err)
+		return statusCode == OOMKillStatus
+	}
+
+	return info.State.OOMKilled
+}
+
+// containerExitError reports a fuzz container that exited with a non-zero
+// status without the fuzzing engine reporting a crash, along with whether it
+// was killed for running out of memory, its memory limit, and the last lines of
+// its output, if known.
+type containerExitError struct {
+	statusCode  int64
+	oomKilled   bool
+	memoryLimit int64
+	outputTail  string
+}
+
+// Error implements the error interface.
+func (e *containerExitError) Error() string {
+	if e.oomKilled {
+		return fmt.Sprintf("fuzz container was killed for running "+
+			"out of memory (status %d)", e.statusCode)
+	}
+
+	return fmt.Sprintf("fuzz container exited with status %d",
+		e.statusCode)
+}
+
+// resourceUsage holds the resource usage of a container, as sampled from its
+// stats stream: the peak memory usage in bytes and the total CPU time.
+type resourceUsage struct {
+	peakMemory uint64
+	cpuTime    time.Duration
+}
+
+// MonitorUsage samples the resource usage of the container from its stats
+// stream until the container exits or the context is canceled, and then sends
+// it on usageChan. Since Docker samples the stats about once per second, the
+// usage of very short runs may be underreported.
+//
+//	This MUST be run as a goroutine.
+func (c *Container) MonitorUsage(ID string, usageChan chan resourceUsage) {
+	var usage resourceUsage
+	defer func() {
+		usageChan <- usage
+	}()
+
+	stats, err := c.cli.ContainerStats(c.ctx, ID, true)
+	if err != nil {
+		if c.ctx.Err() == nil {
+			c.logger.Error("unable to get stats for container",
+				"container", ID, "error", err)
+		}
+		return
+	}
+	defer func() {
+		if err := stats.Body.Close(); err != nil {
+			c.logger.Error("error closing stats reader",
+				"container", ID, "error", err)
+		}
+	}()
+
+	decoder := json.NewDecoder(stats.Body)
+	for {
+		var sample container.StatsResponse
+		if err := decoder.Decode(&sample); err != nil {
+			if !errors.Is(err, io.EOF) && c.ctx.Err() == nil {
+				c.logger.Error("error reading stats for "+
+					"container", "container", ID, "error",
+					err)
+			}
+			return
+		}
+
+		// The final samples of an exited container are zeroed, so
+		// only ever raise the recorded usage.
+		usage.peakMemory = max(usage.peakMemory,
+			sample.MemoryStats.Usage, sample.MemoryStats.MaxUsage)
+		usage.cpuTime = max(usage.cpuTime,
+			time.Duration(sample.CPUStats.CPUUsage.TotalUsage))
+	}
+}
+
+// DumpStacks makes the processes of the running container dump their goroutine
+// stacks and exit, and returns the output they printed meanwhile, of at most
+// MaxStackDumpSize bytes. SIGQUIT, on which the Go runtime dumps the stacks of
+// all goroutines, is first sent to the processes other than the main one
+// (e.g. the workers of Go's fuzzer), from a shell run in the container, and
+// after StackDumpDelay to the main one. The output is read until the container
+// exits, or StackDumpTimeout elapses.
+func (c *Container) DumpStacks(ID string) (string, error) {
+	// The context of the container is done once it timed out.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.ctx),
+		StackDumpTimeout)
+	defer cancel()
+
+	// Only follow the output printed from now on, before the container
+	// exits and is removed along with its logs.
+	now := time.Now()
+	logs, err := c.cli.ContainerLogs(ctx, ID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Since: fmt.Sprintf("%d.%09d", now.Unix(),
+			now.Nanosecond()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to attach to logs for container "+
+			"%s: %w", ID, err)
+	}
+	defer func() {
+		if err := logs.Close(); err != nil {
+			c.logger.Error("error closing logs reader", "container",
+				ID, "error", err)
+		}
+	}()
+
+	// Images without a shell only get their main process signaled.
+	exec, err := c.cli.ContainerExecCreate(ctx, ID, container.ExecOptions{
+		User: fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		Cmd:  []string{"sh", "-c", "kill -QUIT -1"},
+	})
+	if err == nil {
+		err = c.cli.ContainerExecStart(ctx, exec.ID,
+			container.ExecStartOptions{Detach: true})
+	}
+	if err != nil {
+		c.logger.Debug("unable to signal the other processes of "+
+			"container", "container", ID, "error", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(StackDumpDelay):
+	}
+	if err := c.cli.ContainerKill(ctx, ID, "SIGQUIT"); err != nil {
+		return "", fmt.Errorf("failed to signal container %s: %w", ID,
+			err)
+	}
+
+	// A dump cut short by the timeout is still returned.
+	var dump strings.Builder
+	_, err = io.Copy(&dump, io.LimitReader(logs, MaxStackDumpSize))
+	if err != nil && ctx.Err() == nil {
+		return "", fmt.Errorf("failed to read stack dump of container "+
+			"%s: %w", ID, err)
+	}
+
+	return dump.String(), nil
+}
+
 // Stop attempts to gracefully stop the specified Docker container by its ID.
 // After a default timeout of 10 seconds, the container is forcefully killed.
 func (c *Container) Stop(ID string) error {
@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -54,9 +59,13 @@ func TestContainerRace(t *testing.T) {
 				ctx:            taskCtx,
 				logger:         logger,
 				cli:            cli,
+				image:          ContainerImage,
 				fuzzBinaryPath: tmpDir,
 				hostCorpusPath: tmpDir,
 				cmd:            []string{"sleep", "infinity"},
+				memory:         ContainerMemoryLimit,
+				nanoCPUs:       1_000_000_000,
+				engine:         &goFuzzEngine{},
 			}
 
 			id, err := c.Start()
@@ -80,3 +89,111 @@ func TestContainerRace(t *testing.T) {
 		})
 	}
 }
+
+// TestCheckContainerRuntimeUnreachable verifies that the startup check fails
+// with an actionable error if the Docker daemon cannot be reached.
+func TestCheckContainerRuntimeUnreachable(t *testing.T) {
+	host := "unix://" + filepath.Join(t.TempDir(), "docker.sock")
+	t.Setenv("DOCKER_HOST", host)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	err := checkContainerRuntime(context.Background(), logger)
+	assert.ErrorContains(t, err, host)
+	assert.ErrorContains(t, err, "fuzz.skip-runtime-check")
+}
+
+// TestDumpStacks verifies that the processes of a container other than the
+// main one are sent SIGQUIT from a shell before the main one, and that the
+// output they print meanwhile is returned, even once the context of the
+// container is done.
+func TestDumpStacks(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	killed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path[strings.Index(
+			r.URL.Path[1:], "/")+1:])
+		mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/logs"):
+			assert.NotEmpty(t, r.URL.Query().Get("since"))
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-killed
+			_, _ = io.WriteString(w, "SIGQUIT: quit\n"+
+				"goroutine 1 [select]:\n")
+
+		case strings.HasSuffix(r.URL.Path, "/exec"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"Id": "exec1"}`)
+
+		case strings.HasSuffix(r.URL.Path, "/kill"):
+			assert.Equal(t, "SIGQUIT", r.URL.Query().Get("signal"))
+			close(killed)
+
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	cli, err := client.NewClientWithOpts(client.WithHost(server.URL),
+		client.WithVersion("1.47"))
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cli.Close())
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &Container{
+		ctx:    ctx,
+		logger: slog.New(slog.DiscardHandler),
+		cli:    cli,
+	}
+	dump, err := c.DumpStacks("fuzz1")
+	assert.NoError(t, err)
+	assert.Equal(t, "SIGQUIT: quit\ngoroutine 1 [select]:\n", dump)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"GET /containers/fuzz1/logs",
+		"POST /containers/fuzz1/exec",
+		"POST /exec/exec1/start",
+		"POST /containers/fuzz1/kill",
+	}, calls)
+}
+
+// TestNewContainer verifies that containers get the image, labels,
+// capabilities and resource limits configured for their fuzz target.
+func TestNewContainer(t *testing.T) {
+	cfg := &Fuzz{
+		ContainerImage:    "golang:1.24",
+		ContainerLabels:   map[string]string{"team": "fuzz"},
+		CapAdd:            []string{"SYS_PTRACE"},
+		ContainerMemory:   2 << 30,
+		ContainerNanoCPUs: 1_000_000_000,
+		TargetMemoryLimits: map[string]int64{
+			"parser/FuzzParse": 4 << 30,
+		},
+	}
+	engine := &goFuzzEngine{}
+
+	c := newContainer(context.Background(), slog.New(slog.DiscardHandler),
+		nil, cfg, engine, "parser", "FuzzParse")
+	assert.Equal(t, "golang:1.24", c.image)
+	assert.Equal(t, map[string]string{"team": "fuzz"}, c.labels)
+	assert.Equal(t, []string{"SYS_PTRACE"}, c.capAdd)
+	assert.Equal(t, int64(4<<30), c.memory)
+	assert.Equal(t, int64(1_000_000_000), c.nanoCPUs)
+	assert.Equal(t, engine, c.engine)
+
+	c = newContainer(context.Background(), slog.New(slog.DiscardHandler),
+		nil, cfg, engine, "parser", "FuzzLex")
+	assert.Equal(t, int64(2<<30), c.memory)
+}
@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"syscall"
 )
 
 // runFuzzTest builds and executes a fuzzing command for the given target.
@@ -114,7 +120,9 @@ func MeasureCoverage(ctx context.Context, pkgDir, corpusDir, target string,
 func MinimizeCorpus(ctx context.Context, logger *slog.Logger, pkgDir, corpusDir,
 	target string) error {
 
-	// Remove the seed fuzz testdata directory to start fresh.
+	// Remove the seed fuzz testdata directory to start fresh. Only the
+	// worker fuzzing the target reads it during the cycle, since the other
+	// workers only copy the seed corpora of their own targets.
 	fuzzTestDataDir := filepath.Join(pkgDir, "testdata", "fuzz", target)
 	if err := os.RemoveAll(fuzzTestDataDir); err != nil {
 		return fmt.Errorf("removing testdata: %w", err)
@@ -141,43 +149,11 @@ func MinimizeCorpus(ctx context.Context, logger *slog.Logger, pkgDir, corpusDir,
 	// smallest to largest input, greedily adding those that improve
 	// coverage.
 	corpusTargetDir := filepath.Join(corpusDir, target)
-	entries, err := os.ReadDir(corpusTargetDir)
+	files, err := corpusFilesBySize(corpusTargetDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("reading corpus dir: %w", err)
-	}
-
-	// fileInfo represents the name and size of a file, used for sorting
-	// files by their size.
-	type fileInfo struct {
-		Name string
-		Size int64
-	}
-
-	// Collect file information for sorting by size.
-	var files []fileInfo
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		info, err := entry.Info()
-		if err != nil {
-			return fmt.Errorf("getting file info for %s: %w",
-				entry.Name(), err)
-		}
-		files = append(files, fileInfo{
-			Name: entry.Name(),
-			Size: info.Size(),
-		})
+		return err
 	}
 
-	// Sort files from smallest to largest by size.
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Size < files[j].Size
-	})
-
 	// Calculate how many inputs were provided via f.Add() calls. This is
 	// necessary because the "initial coverage bits:" line is only printed
 	// after all baseline coverage inputs have been executed. Therefore, we
@@ -195,8 +171,8 @@ func MinimizeCorpus(ctx context.Context, logger *slog.Logger, pkgDir, corpusDir,
 	// Iterate through each corpus file, measure its impact on coverage,
 	// and remove it if it does not improve or reduces the coverage.
 	for _, file := range files {
-		srcPath := filepath.Join(corpusTargetDir, file.Name)
-		dstPath := filepath.Join(cacheCorpusDir, file.Name)
+		srcPath := filepath.Join(corpusTargetDir, file.name)
+		dstPath := filepath.Join(cacheCorpusDir, file.name)
 
 		// Copy file to temporary corpus directory.
 		if err := copyData(srcPath, dstPath); err != nil {
@@ -218,7 +194,7 @@ func MinimizeCorpus(ctx context.Context, logger *slog.Logger, pkgDir, corpusDir,
 
 		if newCoverage < bestCoverage {
 			logger.Warn("nondeterministic fuzz target: coverage "+
-				"decreased", "file", file.Name, "oldCoverage",
+				"decreased", "file", file.name, "oldCoverage",
 				bestCoverage, "newCoverage", newCoverage)
 		}
 
@@ -239,6 +215,214 @@ func MinimizeCorpus(ctx context.Context, logger *slog.Logger, pkgDir, corpusDir,
 	return nil
 }
 
+// corpusFile is the name and size of a corpus file, used for sorting files by
+// their size.
+type corpusFile struct {
+	name string
+	size int64
+}
+
+// corpusFilesBySize returns the corpus files in the given directory, sorted
+// from smallest to largest. A missing directory holds no files.
+func corpusFilesBySize(corpusTargetDir string) ([]corpusFile, error) {
+	entries, err := os.ReadDir(corpusTargetDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading corpus dir: %w", err)
+	}
+
+	var files []corpusFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("getting file info for %s: %w",
+				entry.Name(), err)
+		}
+		files = append(files, corpusFile{
+			name: entry.Name(),
+			size: info.Size(),
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].size < files[j].size
+	})
+
+	return files, nil
+}
+
+// CapCorpusFiles evicts the corpus files of the target beyond maxFiles, those
+// contributing the least coverage first. Like MinimizeCorpus, it greedily
+// measures the coverage of the inputs from smallest to largest, keeping those
+// that improve it, until maxFiles of them are kept. Returns the number of
+// evicted files.
+func CapCorpusFiles(ctx context.Context, logger *slog.Logger, pkgDir,
+	corpusDir, target string, maxFiles int) (int, error) {
+
+	corpusTargetDir := filepath.Join(corpusDir, target)
+	files, err := corpusFilesBySize(corpusTargetDir)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) <= maxFiles {
+		return 0, nil
+	}
+
+	// Temporary directory for the corpus cache where the inputs are added
+	// one by one to check if they increase coverage.
+	cacheDir, err := os.MkdirTemp("", "go-continuous-fuzz-cache-")
+	if err != nil {
+		return 0, fmt.Errorf("creating temp cache dir: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(cacheDir); err != nil {
+			logger.Error("Failed to remove cache", "error", err)
+		}
+	}()
+
+	cacheCorpusDir := filepath.Join(cacheDir, target)
+	if err := EnsureDirExists(cacheCorpusDir); err != nil {
+		return 0, fmt.Errorf("creating cache corpus dir: %w", err)
+	}
+
+	fuzzAddInputs, err := calculateFuzzAddInputs(ctx, logger, pkgDir,
+		corpusDir, target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate f.Add inputs: %w",
+			err)
+	}
+
+	// An input is kept in the cache only if it improves the coverage of
+	// the inputs kept before it.
+	bestCoverage := 0
+	improves := func(file corpusFile) (bool, error) {
+		dstPath := filepath.Join(cacheCorpusDir, file.name)
+		err := copyData(filepath.Join(corpusTargetDir, file.name),
+			dstPath)
+		if err != nil {
+			return false, fmt.Errorf("copy %q to cache: %w",
+				file.name, err)
+		}
+
+		coverage, err := MeasureCoverage(ctx, pkgDir, cacheDir, target,
+			fuzzAddInputs)
+		if err != nil {
+			return false, fmt.Errorf("measuring coverage: %w", err)
+		}
+		if coverage > bestCoverage {
+			bestCoverage = coverage
+			return true, nil
+		}
+
+		if err := os.Remove(dstPath); err != nil {
+			return false, fmt.Errorf("remove %q: %w", dstPath, err)
+		}
+		return false, nil
+	}
+
+	evicted, err := corpusFilesToEvict(files, maxFiles, improves)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range evicted {
+		path := filepath.Join(corpusTargetDir, file.name)
+		if err := os.Remove(path); err != nil {
+			return 0, fmt.Errorf("remove %q: %w", path, err)
+		}
+	}
+
+	logger.Info("Capped corpus files", "maxFiles", maxFiles,
+		"evictedCount", len(evicted), "coverage", bestCoverage)
+	return len(evicted), nil
+}
+
+// corpusFilesToEvict returns the files, sorted from smallest to largest, to
+// evict so at most maxFiles remain. The files improving coverage, as reported
+// by improves for each file in turn until maxFiles of them are found, are kept
+// first. The remaining slots are filled with the smallest of the other
+// measured files, which are the cheapest to run, and the files left unmeasured
+// once maxFiles files improving coverage are found are evicted.
+func corpusFilesToEvict(files []corpusFile, maxFiles int,
+	improves func(corpusFile) (bool, error)) ([]corpusFile, error) {
+
+	if len(files) <= maxFiles {
+		return nil, nil
+	}
+
+	var (
+		kept      int
+		redundant []corpusFile
+		measured  int
+	)
+	for _, file := range files {
+		if kept == maxFiles {
+			break
+		}
+
+		ok, err := improves(file)
+		if err != nil {
+			return nil, err
+		}
+		measured++
+
+		if ok {
+			kept++
+			continue
+		}
+		redundant = append(redundant, file)
+	}
+
+	free := min(maxFiles-kept, len(redundant))
+	evicted := append(redundant[free:], files[measured:]...)
+
+	return evicted, nil
+}
+
+// measureCoverageStability measures the coverage bits reached by the target's
+// corpus in corpusDir the given number of times and returns the measurements.
+// A deterministic target reaches the same coverage on every run of the same
+// corpus.
+func measureCoverageStability(ctx context.Context, logger *slog.Logger, pkgDir,
+	corpusDir, target string, runs int) ([]int, error) {
+
+	fuzzAddInputs, err := calculateFuzzAddInputs(ctx, logger, pkgDir,
+		corpusDir, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate f.Add inputs: %w",
+			err)
+	}
+
+	measurements := make([]int, 0, runs)
+	for range runs {
+		coverage, err := MeasureCoverage(ctx, pkgDir, corpusDir, target,
+			fuzzAddInputs)
+		if err != nil {
+			return nil, fmt.Errorf("measuring coverage: %w", err)
+		}
+		measurements = append(measurements, coverage)
+	}
+
+	return measurements, nil
+}
+
+// coverageUnstable reports whether the coverage measurements of the same
+// corpus differ, revealing a nondeterministic target.
+func coverageUnstable(measurements []int) bool {
+	for _, m := range measurements[1:] {
+		if m != measurements[0] {
+			return true
+		}
+	}
+
+	return false
+}
+
 // calculateFuzzAddInputs runs `go test` with fuzzing enabled to determine
 // how many inputs were added via f.Add() calls in the fuzz target.
 //
@@ -290,3 +474,473 @@ func calculateFuzzAddInputs(ctx context.Context, logger *slog.Logger, pkgDir,
 
 	return addedInputs, nil
 }
+
+// mergeCorpus merges the local corpus in localDir into the downloaded corpus in
+// corpusDir according to the given merge strategy. The corpora are merged
+// target by target: targets present in only one of them are always kept, and
+// the strategy decides how targets present in both are combined. The package
+// sources in srcDir are used to measure coverage for the coverage-max strategy.
+func mergeCorpus(ctx context.Context, logger *slog.Logger, srcDir, localDir,
+	corpusDir, strategy string) error {
+
+	targetDirs, err := corpusTargetDirs(localDir)
+	if err != nil {
+		return fmt.Errorf("listing local corpus: %w", err)
+	}
+
+	for _, rel := range targetDirs {
+		localTargetDir := filepath.Join(localDir, rel)
+		targetDir := filepath.Join(corpusDir, rel)
+
+		hasFiles, err := dirHasFiles(targetDir)
+		if err != nil {
+			return err
+		}
+
+		// No conflict: the target only exists locally.
+		if !hasFiles {
+			err := replaceDir(localTargetDir, targetDir)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch strategy {
+		case CorpusMergeS3Wins:
+			continue
+
+		case CorpusMergeLocalWins:
+			err = replaceDir(localTargetDir, targetDir)
+
+		case CorpusMergeCoverageMax:
+			var localWins bool
+			localWins, err = localCoverageHigher(ctx, logger,
+				srcDir, localDir, corpusDir, rel)
+			switch {
+			case err != nil:
+				logger.Warn("Failed to compare corpus "+
+					"coverage; merging both corpora",
+					"target", rel, "error", err)
+				err = unionCorpusDir(localTargetDir, targetDir)
+
+			case localWins:
+				err = replaceDir(localTargetDir, targetDir)
+			}
+
+		default:
+			err = unionCorpusDir(localTargetDir, targetDir)
+		}
+		if err != nil {
+			return fmt.Errorf("merging corpus of %q: %w", rel, err)
+		}
+	}
+
+	logger.Info("Merged local corpus into downloaded corpus", "strategy",
+		strategy, "targets", len(targetDirs))
+
+	return nil
+}
+
+// corpusTargetDirs returns the paths, relative to corpusDir, of all directories
+// in corpusDir that directly contain corpus files.
+func corpusTargetDirs(corpusDir string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(corpusDir, func(path string, d os.DirEntry,
+		err error) error {
+
+		if err != nil || !d.IsDir() {
+			return err
+		}
+
+		hasFiles, err := dirHasFiles(path)
+		if err != nil || !hasFiles {
+			return err
+		}
+
+		rel, err := filepath.Rel(corpusDir, path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, rel)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dirs, nil
+}
+
+// dirHasFiles reports whether dir exists and directly contains at least one
+// regular file.
+func dirHasFiles(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading dir %q: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// replaceDir moves srcDir to dstDir, replacing any existing dstDir.
+func replaceDir(srcDir, dstDir string) error {
+	if err := os.RemoveAll(dstDir); err != nil {
+		return fmt.Errorf("removing %q: %w", dstDir, err)
+	}
+
+	if err := EnsureDirExists(filepath.Dir(dstDir)); err != nil {
+		return err
+	}
+
+	if err := os.Rename(srcDir, dstDir); err != nil {
+		return fmt.Errorf("moving %q to %q: %w", srcDir, dstDir, err)
+	}
+
+	return nil
+}
+
+// stageFuzzCache prepares the fuzz cache of the target under cacheDir, seeded
+// with the target's persisted corpus under corpusDir, so the fuzzer works on
+// the cache while still starting from all known inputs.
+func stageFuzzCache(cacheDir, corpusDir, target string) error {
+	stageDir := filepath.Join(cacheDir, target)
+	if err := os.RemoveAll(stageDir); err != nil {
+		return fmt.Errorf("removing stale fuzz cache: %w", err)
+	}
+
+	err := copyData(filepath.Join(corpusDir, target), stageDir)
+	if err != nil {
+		return fmt.Errorf("seeding fuzz cache: %w", err)
+	}
+
+	return EnsureDirExists(stageDir)
+}
+
+// corpusFileName returns the name Go's fuzzing engine gives to a corpus file
+// with the given content: the first 16 hex characters of its SHA-256 hash.
+// Naming the corpus files written by go-continuous-fuzz the same way keeps a
+// single file per input, whether the fuzzer or go-continuous-fuzz wrote it.
+func corpusFileName(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+// corpusContentNames returns the corpusFileName of the content of every corpus
+// file in the given directory.
+func corpusContentNames(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading dir %q: %w", dir, err)
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		names[corpusFileName(data)] = true
+	}
+
+	return names, nil
+}
+
+// promoteFuzzCache copies the inputs the fuzzer added to the fuzz cache of the
+// target under cacheDir into the target's persisted corpus under corpusDir,
+// named after their content, then removes the cache. The inputs the cache was
+// seeded with, and any input whose content is already in the corpus, are
+// skipped. Returns the number of promoted inputs.
+func promoteFuzzCache(cacheDir, corpusDir, target string) (int, error) {
+	stageDir := filepath.Join(cacheDir, target)
+	targetDir := filepath.Join(corpusDir, target)
+	if err := EnsureDirExists(targetDir); err != nil {
+		return 0, err
+	}
+
+	seen, err := corpusContentNames(targetDir)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(stageDir)
+	if err != nil {
+		return 0, fmt.Errorf("reading dir %q: %w", stageDir, err)
+	}
+
+	promoted := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		// Inputs the cache was seeded with keep their name, so they
+		// are skipped without reading them.
+		_, err := os.Stat(filepath.Join(targetDir, entry.Name()))
+		if err == nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(stageDir, entry.Name()))
+		if err != nil {
+			return promoted, err
+		}
+
+		name := corpusFileName(data)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		dstPath := filepath.Join(targetDir, name)
+		if err := os.WriteFile(dstPath, data, 0644); err != nil {
+			return promoted, fmt.Errorf("writing %q: %w", dstPath,
+				err)
+		}
+		promoted++
+	}
+
+	if err := os.RemoveAll(stageDir); err != nil {
+		return promoted, fmt.Errorf("removing fuzz cache: %w", err)
+	}
+
+	return promoted, nil
+}
+
+// unionCorpusDir moves the corpus files of srcDir into dstDir, named after
+// their content, skipping any file whose content is already present in dstDir.
+func unionCorpusDir(srcDir, dstDir string) error {
+	seen, err := corpusContentNames(dstDir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return fmt.Errorf("reading dir %q: %w", srcDir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		srcPath := filepath.Join(srcDir, entry.Name())
+		data, err := os.ReadFile(srcPath)
+		if err != nil {
+			return err
+		}
+
+		name := corpusFileName(data)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		dstPath := filepath.Join(dstDir, name)
+		if err := os.Rename(srcPath, dstPath); err != nil {
+			return fmt.Errorf("moving %q to %q: %w", srcPath,
+				dstPath, err)
+		}
+	}
+
+	return nil
+}
+
+// localCoverageHigher reports whether the local corpus of the target located
+// at rel ("<pkg>/testdata/fuzz/<target>") reaches more coverage than the
+// downloaded one.
+func localCoverageHigher(ctx context.Context, logger *slog.Logger, srcDir,
+	localDir, corpusDir, rel string) (bool, error) {
+
+	fuzzDir := filepath.Dir(rel)
+	pkg, found := strings.CutSuffix(filepath.ToSlash(fuzzDir),
+		"/testdata/fuzz")
+	if !found {
+		return false, fmt.Errorf("unexpected corpus path %q", rel)
+	}
+	target := filepath.Base(rel)
+	pkgDir := filepath.Join(srcDir, pkg)
+
+	fuzzAddInputs, err := calculateFuzzAddInputs(ctx, logger, pkgDir,
+		filepath.Join(corpusDir, fuzzDir), target)
+	if err != nil {
+		return false, err
+	}
+
+	localCoverage, err := MeasureCoverage(ctx, pkgDir,
+		filepath.Join(localDir, fuzzDir), target, fuzzAddInputs)
+	if err != nil {
+		return false, err
+	}
+
+	coverage, err := MeasureCoverage(ctx, pkgDir,
+		filepath.Join(corpusDir, fuzzDir), target, fuzzAddInputs)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Info("Compared corpus coverage", "target", rel,
+		"localCoverage", localCoverage, "downloadedCoverage", coverage)
+
+	return localCoverage > coverage, nil
+}
+
+// checkCorpusAccess verifies, before fuzzing, that the corpus directory and the
+// fuzz cache directory, if any, are writable by this process, whose user also
+// runs the fuzz containers. Unless they are, the fuzz runs would only fail
+// deep inside the container on writing new inputs, e.g. when a shared volume
+// was written by a job running as another user. If fix is set, directories that
+// are not writable are first made writable when the process has the privileges
+// to. Returns an error describing the expected ownership otherwise.
+func checkCorpusAccess(logger *slog.Logger, cfg *Config) error {
+	dirs := []string{cfg.Project.CorpusDir}
+	if cfg.Fuzz.FuzzCacheDir != "" {
+		dirs = append(dirs, cfg.Fuzz.FuzzCacheDir)
+	}
+
+	for _, dir := range dirs {
+		err := checkDirTreeAccess(logger, dir,
+			cfg.Fuzz.FixCorpusPermissions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDirTreeAccess creates the directory at root if needed, and verifies
+// that it and all directories under it are writable, fixing their permissions
+// first if fix is set.
+func checkDirTreeAccess(logger *slog.Logger, root string, fix bool) error {
+	if err := EnsureDirExists(root); err != nil {
+		if !errors.Is(err, fs.ErrPermission) {
+			return err
+		}
+
+		// Diagnose the closest existing parent directory, in which
+		// the directory cannot be created.
+		parent := filepath.Dir(root)
+		for parent != filepath.Dir(parent) {
+			if _, err := os.Stat(parent); err == nil {
+				break
+			}
+			parent = filepath.Dir(parent)
+		}
+		return dirAccessError(parent)
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				return dirAccessError(path)
+			}
+			return err
+		}
+
+		if !d.IsDir() || dirWritable(path) {
+			return nil
+		}
+
+		if fix && fixDirAccess(logger, path) && dirWritable(path) {
+			return nil
+		}
+
+		return dirAccessError(path)
+	})
+}
+
+// dirWritable reports whether a file can be created in the directory.
+func dirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".gcf-access-check-*")
+	if err != nil {
+		return false
+	}
+
+	// Cleanup errors are ignored, since access was already verified.
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	return true
+}
+
+// fixDirAccess attempts to make the directory writable by this process and by
+// its group, by taking ownership of it if running as root, and adding read,
+// write and search permissions for its owner and group. Returns whether its
+// permissions were changed.
+func fixDirAccess(logger *slog.Logger, dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+
+	uid, gid := os.Geteuid(), os.Getegid()
+	if uid == 0 && int(stat.Uid) != uid {
+		if err := os.Chown(dir, uid, gid); err != nil {
+			logger.Warn("Failed to take ownership of corpus "+
+				"directory", "dir", dir, "error", err)
+			return false
+		}
+	} else if int(stat.Uid) != uid {
+		// Only the owner of the directory may change its mode.
+		return false
+	}
+
+	if err := os.Chmod(dir, info.Mode().Perm()|0o770); err != nil {
+		logger.Warn("Failed to make corpus directory writable", "dir",
+			dir, "error", err)
+		return false
+	}
+
+	logger.Info("Fixed permissions of corpus directory", "dir", dir)
+	return true
+}
+
+// dirAccessError returns an error reporting that the directory is not writable
+// by this process, along with its ownership and how to fix it.
+func dirAccessError(dir string) error {
+	uid, gid := os.Geteuid(), os.Getegid()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("corpus directory %q is not accessible by "+
+			"uid %d (gid %d), which runs the fuzz containers: %w",
+			dir, uid, gid, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("corpus directory %q (mode %s) is not "+
+			"writable by uid %d (gid %d), which runs the fuzz "+
+			"containers", dir, info.Mode().Perm(), uid, gid)
+	}
+
+	return fmt.Errorf("corpus directory %q (owner %d:%d, mode %s) is "+
+		"not writable by uid %d (gid %d), which runs the fuzz "+
+		"containers: make it owned by %d:%d, or writable by group %d "+
+		"and run with that group (e.g. with securityContext.fsGroup "+
+		"set to %d on Kubernetes), or enable "+
+		"fuzz.fix-corpus-permissions when running with sufficient "+
+		"privileges", dir, stat.Uid, stat.Gid, info.Mode().Perm(), uid,
+		gid, uid, gid, stat.Gid, stat.Gid)
+}
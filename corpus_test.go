@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMergeCorpus verifies how a local corpus is merged into the downloaded
+// corpus for each merge strategy not requiring coverage measurements.
+func TestMergeCorpus(t *testing.T) {
+	const (
+		shared    = "pkg/testdata/fuzz/FuzzShared"
+		localOnly = "pkg/testdata/fuzz/FuzzLocal"
+	)
+
+	variantName := corpusFileName([]byte("local variant"))
+	localName := corpusFileName([]byte("local input"))
+
+	tests := []struct {
+		strategy       string
+		expectedShared map[string]string
+	}{
+		{
+			strategy: CorpusMergeUnion,
+			expectedShared: map[string]string{
+				"s3":   "s3 input",
+				"same": "same input",
+				// Merged inputs are named after their content.
+				variantName: "local variant",
+				localName:   "local input",
+			},
+		},
+		{
+			strategy: CorpusMergeS3Wins,
+			expectedShared: map[string]string{
+				"s3":   "s3 input",
+				"same": "same input",
+			},
+		},
+		{
+			strategy: CorpusMergeLocalWins,
+			expectedShared: map[string]string{
+				"same":  "local variant",
+				"local": "local input",
+				"dup":   "same input",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.strategy, func(t *testing.T) {
+			dir := t.TempDir()
+			localDir := filepath.Join(dir, "local")
+			corpusDir := filepath.Join(dir, "corpus")
+
+			writeCorpus(t, corpusDir, shared, map[string]string{
+				"s3":   "s3 input",
+				"same": "same input",
+			})
+			writeCorpus(t, localDir, shared, map[string]string{
+				"same":  "local variant",
+				"local": "local input",
+				"dup":   "same input",
+			})
+			writeCorpus(t, localDir, localOnly, map[string]string{
+				"seed": "seed input",
+			})
+
+			err := mergeCorpus(context.Background(),
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				"", localDir, corpusDir, tc.strategy)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectedShared,
+				readCorpus(t, corpusDir, shared))
+
+			// Targets only present locally are always kept.
+			assert.Equal(t, map[string]string{
+				"seed": "seed input",
+			}, readCorpus(t, corpusDir, localOnly))
+		})
+	}
+}
+
+// writeCorpus writes the given inputs to the target directory rel inside
+// corpusDir.
+func writeCorpus(t *testing.T, corpusDir, rel string,
+	inputs map[string]string) {
+
+	dir := filepath.Join(corpusDir, rel)
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	for name, data := range inputs {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name),
+			[]byte(data), 0o644))
+	}
+}
+
+// readCorpus returns the inputs stored in the target directory rel inside
+// corpusDir.
+func readCorpus(t *testing.T, corpusDir, rel string) map[string]string {
+	dir := filepath.Join(corpusDir, rel)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	inputs := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		inputs[entry.Name()] = string(data)
+	}
+	return inputs
+}
+
+// TestFuzzCacheStaging verifies that the fuzz cache is seeded with the target's
+// corpus, and that only the inputs added to the cache are promoted back to the
+// corpus before the cache is removed.
+func TestFuzzCacheStaging(t *testing.T) {
+	corpusDir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	targetDir := filepath.Join(corpusDir, "FuzzFoo")
+	assert.NoError(t, os.MkdirAll(targetDir, 0755))
+	err := os.WriteFile(filepath.Join(targetDir, "known"),
+		[]byte("known input"), 0644)
+	assert.NoError(t, err)
+
+	assert.NoError(t, stageFuzzCache(cacheDir, corpusDir, "FuzzFoo"))
+	stageDir := filepath.Join(cacheDir, "FuzzFoo")
+	assert.FileExists(t, filepath.Join(stageDir, "known"))
+
+	// Simulate the fuzzer adding an input to the cache, along with a copy
+	// of a known input under another name.
+	err = os.WriteFile(filepath.Join(stageDir, "new"), []byte("new input"),
+		0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(stageDir, "copy"),
+		[]byte("known input"), 0644)
+	assert.NoError(t, err)
+
+	promoted, err := promoteFuzzCache(cacheDir, corpusDir, "FuzzFoo")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, promoted)
+	assert.NoDirExists(t, stageDir)
+
+	assert.Equal(t, map[string]string{
+		"known":                             "known input",
+		corpusFileName([]byte("new input")): "new input",
+	}, readCorpus(t, corpusDir, "FuzzFoo"))
+
+	// A target without corpus yet starts from an empty cache.
+	assert.NoError(t, stageFuzzCache(cacheDir, corpusDir, "FuzzBar"))
+	assert.DirExists(t, filepath.Join(cacheDir, "FuzzBar"))
+	promoted, err = promoteFuzzCache(cacheDir, corpusDir, "FuzzBar")
+	assert.NoError(t, err)
+	assert.Zero(t, promoted)
+	assert.DirExists(t, filepath.Join(corpusDir, "FuzzBar"))
+}
+
+// TestCorpusFileName verifies that corpus files are named like Go's fuzzing
+// engine names them, using a file written by `go test -fuzz` for a crash.
+func TestCorpusFileName(t *testing.T) {
+	data := []byte("go test fuzz v1\n[]byte(\"x\")\n")
+	assert.Equal(t, "8705ad4a950664ad", corpusFileName(data))
+}
+
+// TestCheckCorpusAccess verifies that missing corpus and fuzz cache directories
+// are created, that directories which are not writable are reported along with
+// their ownership, and that their permissions are fixed if enabled.
+func TestCheckCorpusAccess(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	workspace := t.TempDir()
+	cfg := &Config{
+		Project: Project{
+			CorpusDir: filepath.Join(workspace, "corpus"),
+		},
+		Fuzz: Fuzz{
+			FuzzCacheDir: filepath.Join(workspace, "cache"),
+		},
+	}
+
+	assert.NoError(t, checkCorpusAccess(logger, cfg))
+	assert.DirExists(t, cfg.Project.CorpusDir)
+	assert.DirExists(t, cfg.Fuzz.FuzzCacheDir)
+
+	info, err := os.Stat(cfg.Project.CorpusDir)
+	assert.NoError(t, err)
+	err = dirAccessError(cfg.Project.CorpusDir)
+	assert.ErrorContains(t, err, fmt.Sprintf("corpus directory %q "+
+		"(owner %d:%d, mode %s) is not writable by uid %d",
+		cfg.Project.CorpusDir, os.Geteuid(), os.Getegid(),
+		info.Mode().Perm(), os.Geteuid()))
+
+	// The superuser can write to any directory.
+	if os.Geteuid() == 0 {
+		t.Skip("running as root")
+	}
+
+	targetDir := filepath.Join(cfg.Project.CorpusDir, "pkg", "FuzzFoo")
+	assert.NoError(t, os.MkdirAll(targetDir, 0755))
+	assert.NoError(t, os.Chmod(targetDir, 0555))
+
+	err = checkCorpusAccess(logger, cfg)
+	assert.ErrorContains(t, err, fmt.Sprintf("corpus directory %q",
+		targetDir))
+	assert.ErrorContains(t, err, "mode -r-xr-xr-x")
+
+	cfg.Fuzz.FixCorpusPermissions = true
+	assert.NoError(t, checkCorpusAccess(logger, cfg))
+	info, err = os.Stat(targetDir)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0775), info.Mode().Perm())
+}
+
+// TestCoverageUnstable verifies that coverage is unstable only if some of its
+// measurements of the same corpus differ.
+func TestCoverageUnstable(t *testing.T) {
+	assert.False(t, coverageUnstable([]int{42, 42}))
+	assert.False(t, coverageUnstable([]int{42, 42, 42}))
+	assert.True(t, coverageUnstable([]int{42, 41}))
+	assert.True(t, coverageUnstable([]int{42, 42, 43}))
+}
+
+// TestCorpusFilesToEvict verifies that the files improving coverage are kept
+// first, that the remaining slots are filled with the smallest other files, and
+// that the files left unmeasured once enough files are kept are evicted.
+func TestCorpusFilesToEvict(t *testing.T) {
+	files := []corpusFile{
+		{name: "a", size: 1},
+		{name: "b", size: 2},
+		{name: "c", size: 3},
+		{name: "d", size: 4},
+		{name: "e", size: 5},
+	}
+	names := func(files []corpusFile) []string {
+		var names []string
+		for _, file := range files {
+			names = append(names, file.name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		maxFiles  int
+		improving string
+		measured  []string
+		evicted   []string
+	}{
+		{
+			name:      "under the cap",
+			maxFiles:  5,
+			improving: "a",
+		},
+		{
+			name:      "filled with smallest redundant files",
+			maxFiles:  3,
+			improving: "bd",
+			measured:  []string{"a", "b", "c", "d", "e"},
+			evicted:   []string{"c", "e"},
+		},
+		{
+			name:      "unmeasured files evicted",
+			maxFiles:  2,
+			improving: "abe",
+			measured:  []string{"a", "b"},
+			evicted:   []string{"c", "d", "e"},
+		},
+		{
+			name:      "redundant files evicted",
+			maxFiles:  2,
+			improving: "bd",
+			measured:  []string{"a", "b", "c", "d"},
+			evicted:   []string{"a", "c", "e"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var measured []string
+			improves := func(file corpusFile) (bool, error) {
+				measured = append(measured, file.name)
+				return strings.Contains(tc.improving,
+					file.name), nil
+			}
+
+			evicted, err := corpusFilesToEvict(files, tc.maxFiles,
+				improves)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.measured, measured)
+			assert.Equal(t, tc.evicted, names(evicted))
+		})
+	}
+
+	// A failure to measure coverage aborts the eviction.
+	_, err := corpusFilesToEvict(files, 1, func(corpusFile) (bool, error) {
+		return false, errors.New("go test failed")
+	})
+	assert.ErrorContains(t, err, "go test failed")
+}
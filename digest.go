@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/client"
+)
+
+// digestMarkerPattern matches the marker preceding every crash listed in a
+// digest issue, capturing its signature.
+var digestMarkerPattern = regexp.MustCompile(`<!-- crash-digest: (\S+) -->`)
+
+// digestEntry is a fuzz crash listed in a digest issue.
+type digestEntry struct {
+	pkg       string
+	target    string
+	signature string
+
+	// report is the markdown crash report listed in the digest, holding
+	// the error logs and the failing input of the crash.
+	report string
+}
+
+// crashDigest collects the fuzz crashes of a cycle in digest mode, one per
+// signature, so they are posted to the digest issue of the day at the end of
+// the cycle instead of in an issue each. It is safe for concurrent use by the
+// workers.
+type crashDigest struct {
+	mu sync.Mutex
+
+	entries map[string]digestEntry
+}
+
+// newCrashDigest returns an empty crashDigest.
+func newCrashDigest() *crashDigest {
+	return &crashDigest{entries: make(map[string]digestEntry)}
+}
+
+// add adds the crash to the digest, unless a crash with the same signature is
+// already in it. Returns whether the crash was added.
+func (d *crashDigest) add(entry digestEntry) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.entries[entry.signature]; ok {
+		return false
+	}
+	d.entries[entry.signature] = entry
+
+	return true
+}
+
+// crashes returns the crashes of the digest, sorted by package, target and
+// signature.
+func (d *crashDigest) crashes() []digestEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries := make([]digestEntry, 0, len(d.entries))
+	for _, entry := range d.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.pkg != b.pkg {
+			return a.pkg < b.pkg
+		}
+		if a.target != b.target {
+			return a.target < b.target
+		}
+		return a.signature < b.signature
+	})
+
+	return entries
+}
+
+// digestTitle returns the title of the digest issue listing the crashes found
+// on the day of the given time, in UTC.
+func digestTitle(now time.Time) string {
+	return fmt.Sprintf("[%s] Fuzzing crashes on %s", DigestSignature,
+		now.UTC().Format(time.DateOnly))
+}
+
+// formatDigestEntry returns the section of a digest issue listing the crash,
+// with its report collapsed in a <details> section, or replaced by a note if
+// withReport is false.
+func formatDigestEntry(entry digestEntry, withReport bool) string {
+	report := entry.report
+	if !withReport {
+		report = "_The crash report was omitted, since the digest " +
+			"reached the issue body limit._"
+	}
+
+	return fmt.Sprintf("\n<!-- crash-digest: %s -->\n<details>\n"+
+		"<summary><code>%s</code> in <code>%s/%s</code></summary>\n\n"+
+		"%s\n\n</details>\n", entry.signature, entry.signature,
+		entry.pkg, entry.target, report)
+}
+
+// appendDigestEntries appends the crashes whose signature is not listed yet to
+// the body of the digest issue of the given day, starting a new body if empty.
+// Crashes whose report would make the body exceed limit characters are listed
+// without it, and left out if even that exceeds the limit. Returns the new body
+// and the crashes added to it.
+func appendDigestEntries(body string, day time.Time, entries []digestEntry,
+	limit int) (string, []digestEntry) {
+
+	if body == "" {
+		body = fmt.Sprintf("Fuzzing crashes found on %s, one per "+
+			"crash signature. Expand a crash for its error logs "+
+			"and failing input.\n", day.UTC().Format(time.DateOnly))
+	}
+
+	listed := make(map[string]bool)
+	for _, match := range digestMarkerPattern.FindAllStringSubmatch(body,
+		-1) {
+
+		listed[match[1]] = true
+	}
+
+	var added []digestEntry
+	for _, entry := range entries {
+		if listed[entry.signature] {
+			continue
+		}
+
+		section := formatDigestEntry(entry, true)
+		length := utf8.RuneCountInString(body)
+		if length+utf8.RuneCountInString(section) > limit {
+			section = formatDigestEntry(entry, false)
+		}
+		if length+utf8.RuneCountInString(section) > limit {
+			continue
+		}
+
+		body += section
+		listed[entry.signature] = true
+		added = append(added, entry)
+	}
+
+	return body, added
+}
+
+// digestCrash adds the fuzz crash of the target to the digest instead of
+// opening an issue for it, and returns the report of the crash. Since the
+// crash is only posted at the end of the cycle, the report has no issue URL.
+func (cr *crashReporter) digestCrash(pkg, target string, fc fuzzCrash,
+	digest *crashDigest) *crashReport {
+
+	crashHash, fingerprint := cr.crashSignature(fc)
+	crashesFound.Inc(pkg, target)
+
+	report := &crashReport{
+		Package:   pkg,
+		Target:    target,
+		Signature: crashHash,
+	}
+	if fingerprint != nil {
+		report.CrashType = fingerprint.crashType
+		report.CrashState = fingerprint.state()
+	}
+
+	// The digest lists many crashes, so each one only gets a share of the
+	// issue body.
+	limit := min(DigestEntryLimit, cr.cfg.Fuzz.IssueBodyLimit)
+	added := digest.add(digestEntry{
+		pkg:       pkg,
+		target:    target,
+		signature: crashHash,
+		report: cr.crashReportBody(pkg, target, crashHash,
+			fingerprint, fc, limit),
+	})
+	if !added {
+		cr.logger.Info("Fuzz crash already in digest", "signature",
+			crashHash)
+		return report
+	}
+
+	cr.logger.Info("Added fuzz crash to digest", "signature", crashHash)
+
+	return report
+}
+
+// postDigest lists the given crashes in the digest issue of the day of the
+// given time, opening it if needed, and skipping the crashes whose signature
+// it already lists. Returns the URL of the digest issue, if any, and the
+// number of crashes added to it.
+func (cr *crashReporter) postDigest(entries []digestEntry,
+	now time.Time) (string, int, error) {
+
+	title := digestTitle(now)
+	issue, err := cr.findExistingIssue(title)
+	if err != nil {
+		return "", 0, fmt.Errorf("checking existing issues: %w", err)
+	}
+
+	var body string
+	if issue != nil {
+		body = issue.body
+	}
+	body, added := appendDigestEntries(body, now, entries,
+		cr.cfg.Fuzz.IssueBodyLimit)
+	if len(added) < len(entries) {
+		cr.logger.Info("Crashes already listed in digest or beyond "+
+			"the issue body limit", "title", title, "skipped",
+			len(entries)-len(added))
+	}
+
+	switch {
+	case len(added) == 0 && issue != nil:
+		return issue.url, 0, nil
+
+	case len(added) == 0:
+		return "", 0, nil
+
+	case issue != nil:
+		err := cr.issues.updateIssueBody(issue.number, body)
+		if err != nil {
+			return "", 0, fmt.Errorf("updating digest issue: %w",
+				err)
+		}
+
+	default:
+		issue, err = cr.issues.createIssue(title, body)
+		if err != nil {
+			return "", 0, fmt.Errorf("creating digest issue: %w",
+				err)
+		}
+		issuesOpened.Inc()
+	}
+
+	for _, entry := range added {
+		cr.notifier.emitEvent(cr.ctx, NotifyEventCrashOpened, entry.pkg,
+			entry.target, entry.signature, issue.url)
+	}
+
+	return issue.url, len(added), nil
+}
+
+// postCrashDigest posts the crashes collected by the digest during a cycle to
+// the digest issue of the day, if any. Since cycles normally end by canceling
+// their context, the crashes are posted under a context of their own, bounded
+// by DigestPostTimeout.
+func postCrashDigest(ctx context.Context, logger *slog.Logger,
+	cli *client.Client, cfg *Config, digest *crashDigest) error {
+
+	entries := digest.crashes()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx),
+		DigestPostTimeout)
+	defer cancel()
+
+	tracker, err := NewCrashTracker(ctx, logger, cli, cfg)
+	if err != nil {
+		return fmt.Errorf("error initializing crash tracker client: %w",
+			err)
+	}
+
+	url, added, err := tracker.postDigest(entries, time.Now())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Posted crash digest", "url", url, "crashes",
+		len(entries), "added", added)
+
+	return nil
+}
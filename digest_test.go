@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIssueClient is an in-memory issueClient holding the issues created and
+// updated by the crash reports.
+type fakeIssueClient struct {
+	issues []*trackerIssue
+
+	// listedBodyLimit, if positive, cuts the bodies of the listed issues
+	// short to that many bytes, like search results may.
+	listedBodyLimit int
+}
+
+func (f *fakeIssueClient) listIssues(title,
+	state string) ([]*trackerIssue, error) {
+
+	var issues []*trackerIssue
+	for _, issue := range f.issues {
+		if state != IssueStateOpen || issue.title != title {
+			continue
+		}
+		limit := f.listedBodyLimit
+		if limit > 0 && len(issue.body) > limit {
+			listed := *issue
+			listed.body = issue.body[:limit]
+			issue = &listed
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (f *fakeIssueClient) createIssue(title,
+	body string) (*trackerIssue, error) {
+
+	issue := &trackerIssue{
+		number: len(f.issues) + 1,
+		title:  title,
+		body:   body,
+		url:    fmt.Sprintf("https://example.com/%d", len(f.issues)+1),
+	}
+	f.issues = append(f.issues, issue)
+	return issue, nil
+}
+
+func (f *fakeIssueClient) updateIssueBody(number int, body string) error {
+	f.issues[number-1].body = body
+	return nil
+}
+
+func (f *fakeIssueClient) getIssue(number int) (*trackerIssue, error) {
+	return f.issues[number-1], nil
+}
+
+func (f *fakeIssueClient) closeIssue(int, string) error  { return nil }
+func (f *fakeIssueClient) reopenIssue(int, string) error { return nil }
+func (f *fakeIssueClient) addComment(int, string) error  { return nil }
+func (f *fakeIssueClient) editComment(int, int64, string) error {
+	return nil
+}
+func (f *fakeIssueClient) listComments(int) ([]*trackerComment, error) {
+	return nil, nil
+}
+
+// TestCrashDigest verifies that the digest keeps a single crash per signature,
+// and returns its crashes in a stable order.
+func TestCrashDigest(t *testing.T) {
+	digest := newCrashDigest()
+	assert.True(t, digest.add(digestEntry{pkg: "tree", target: "FuzzBuild",
+		signature: "bbb", report: "first"}))
+	assert.True(t, digest.add(digestEntry{pkg: "parser",
+		target: "FuzzEval", signature: "ccc"}))
+	assert.True(t, digest.add(digestEntry{pkg: "parser",
+		target: "FuzzEval", signature: "aaa"}))
+	assert.False(t, digest.add(digestEntry{pkg: "parser",
+		target: "FuzzLex", signature: "bbb", report: "second"}))
+
+	var signatures []string
+	for _, entry := range digest.crashes() {
+		signatures = append(signatures, entry.signature)
+	}
+	assert.Equal(t, []string{"aaa", "ccc", "bbb"}, signatures)
+	assert.Equal(t, "first", digest.crashes()[2].report)
+}
+
+// TestAppendDigestEntries verifies that crashes are appended to the digest
+// body unless already listed, and listed without their report, or left out,
+// once the body reaches the limit.
+func TestAppendDigestEntries(t *testing.T) {
+	day := time.Date(2025, 7, 15, 23, 0, 0, 0, time.UTC)
+	first := digestEntry{pkg: "parser", target: "FuzzEval",
+		signature: "aaa", report: "panic: first"}
+	second := digestEntry{pkg: "tree", target: "FuzzBuild",
+		signature: "bbb", report: "panic: second"}
+
+	body, added := appendDigestEntries("", day, []digestEntry{first},
+		MinIssueBodyLimit)
+	assert.Equal(t, []digestEntry{first}, added)
+	assert.True(t, strings.HasPrefix(body, "Fuzzing crashes found on "+
+		"2025-07-15"))
+	assert.Contains(t, body, "<!-- crash-digest: aaa -->")
+	assert.Contains(t, body, "<summary><code>aaa</code> in "+
+		"<code>parser/FuzzEval</code></summary>\n\npanic: first\n")
+
+	// Crashes already listed are skipped.
+	body, added = appendDigestEntries(body, day, []digestEntry{first,
+		second}, MinIssueBodyLimit)
+	assert.Equal(t, []digestEntry{second}, added)
+	assert.Equal(t, 1, strings.Count(body, "panic: first"))
+	assert.Contains(t, body, "panic: second")
+
+	// Reports beyond the limit are omitted, and crashes left out once
+	// even their summary does not fit.
+	large := digestEntry{pkg: "tree", target: "FuzzBuild",
+		signature: "ccc",
+		report:    strings.Repeat("x", MinIssueBodyLimit)}
+	body, added = appendDigestEntries(body, day, []digestEntry{large},
+		MinIssueBodyLimit)
+	assert.Equal(t, []digestEntry{large}, added)
+	assert.Contains(t, body, "<!-- crash-digest: ccc -->")
+	assert.Contains(t, body, "The crash report was omitted")
+	assert.NotContains(t, body, large.report)
+
+	limit := len(body)
+	body, added = appendDigestEntries(body, day, []digestEntry{{
+		pkg: "tree", target: "FuzzBuild", signature: "ddd",
+	}}, limit)
+	assert.Empty(t, added)
+	assert.NotContains(t, body, "ddd")
+}
+
+// TestPostDigest verifies that crashes are collected into the digest instead
+// of being reported in issues of their own, and that the digest issue of the
+// day is opened once and then updated with the crashes it does not list yet.
+func TestPostDigest(t *testing.T) {
+	issues := &fakeIssueClient{}
+	cr := &crashReporter{
+		ctx:    context.Background(),
+		logger: slog.New(slog.DiscardHandler),
+		cfg: &Config{
+			Project: Project{ReportDir: t.TempDir()},
+			Fuzz:    Fuzz{IssueBodyLimit: 65536},
+		},
+		issues: issues,
+	}
+
+	digest := newCrashDigest()
+	fc := fuzzCrash{
+		failureFileAndLine: "parser.go:12",
+		errorLogs:          "panic: boom",
+		failingInput:       "go test fuzz v1\nstring(\"x\")",
+	}
+	report := cr.digestCrash("parser", "FuzzEval", fc, digest)
+	assert.Equal(t, ComputeSHA256Short("parser.go:12"), report.Signature)
+	assert.Empty(t, report.IssueURL)
+	assert.False(t, report.New)
+	cr.digestCrash("parser", "FuzzEval", fc, digest)
+	assert.Len(t, digest.crashes(), 1)
+	assert.Empty(t, issues.issues)
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	url, added, err := cr.postDigest(digest.crashes(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url)
+	assert.Equal(t, 1, added)
+	assert.Len(t, issues.issues, 1)
+	assert.Equal(t, "[crash-digest] Fuzzing crashes on 2025-07-15",
+		issues.issues[0].title)
+	assert.Contains(t, issues.issues[0].body, "panic: boom")
+	assert.Contains(t, issues.issues[0].body, "string(\"x\")")
+
+	// A later cycle of the same day updates the digest issue with its new
+	// crashes only.
+	fc.failureFileAndLine = "lexer.go:3"
+	cr.digestCrash("parser", "FuzzLex", fc, digest)
+	url, added, err = cr.postDigest(digest.crashes(), now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url)
+	assert.Equal(t, 1, added)
+	assert.Len(t, issues.issues, 1)
+	assert.Equal(t, 2, strings.Count(issues.issues[0].body,
+		"<!-- crash-digest: "))
+
+	// The next day gets a digest issue of its own.
+	_, added, err = cr.postDigest(digest.crashes(), now.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Len(t, issues.issues, 2)
+	assert.Equal(t, "[crash-digest] Fuzzing crashes on 2025-07-16",
+		issues.issues[1].title)
+}
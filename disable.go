@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// disablePolicy decides which fuzz targets are disabled for crashing early: a
+// target whose fuzzing ended in a crash within its first few executions in a
+// number of consecutive cycles only spends its time slot reporting the same
+// crash, so it is no longer fuzzed until the verification of its open issues
+// finds its crashes fixed.
+type disablePolicy struct {
+	// cycles is the number of consecutive cycles ending in an early crash
+	// after which a target is disabled.
+	cycles int
+
+	// maxExecs is the maximum number of executions reported by the fuzzer
+	// before a crash for the crash to be early.
+	maxExecs int64
+
+	// earlyCrashes holds the number of consecutive cycles, before this
+	// one, in which fuzzing each target ended in an early crash.
+	earlyCrashes map[TargetState]int
+}
+
+// newDisablePolicy returns the disable policy of a fuzzing cycle, based on the
+// target statuses in the report directory.
+func newDisablePolicy(cfg *Config) (*disablePolicy, error) {
+	statusPath := filepath.Join(cfg.Project.ReportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	earlyCrashes := make(map[TargetState]int, len(statuses))
+	for _, s := range statuses {
+		earlyCrashes[TargetState{s.PkgPath, s.Target}] = s.EarlyCrashes
+	}
+
+	return &disablePolicy{
+		cycles:       cfg.Fuzz.AutoDisableCrashing,
+		maxExecs:     cfg.Fuzz.EarlyCrashExecs,
+		earlyCrashes: earlyCrashes,
+	}, nil
+}
+
+// disabled reports whether the target crashed early in enough consecutive
+// cycles to be disabled.
+func (p *disablePolicy) disabled(pkg, target string) bool {
+	return p.earlyCrashes[TargetState{pkg, target}] >= p.cycles
+}
+
+// disables reports whether an early crash of the target in this cycle makes it
+// crash early in enough consecutive cycles to be disabled, returning the
+// number of these cycles.
+func (p *disablePolicy) disables(pkg, target string) (int, bool) {
+	cycles := p.earlyCrashes[TargetState{pkg, target}] + 1
+	return cycles, cycles >= p.cycles
+}
+
+// earlyCrash reports whether the fuzzer reported at most the maximum number of
+// executions of an early crash before the given crash. A crash before any
+// progress was reported is always early.
+func (p *disablePolicy) earlyCrash(engine fuzzEngine, fc fuzzCrash) bool {
+	return engine.progressExecs(fc.progress) <= p.maxExecs
+}
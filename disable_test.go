@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDisablePolicy verifies that targets are disabled once they crashed early
+// in the configured number of consecutive cycles, and that only crashes after
+// at most the configured number of executions are early.
+func TestDisablePolicy(t *testing.T) {
+	reportDir := t.TempDir()
+	statuses := []TargetStatus{
+		{PkgPath: "parser", Target: "FuzzEval", EarlyCrashes: 3},
+		{PkgPath: "parser", Target: "FuzzLex", EarlyCrashes: 2},
+		{PkgPath: "tree", Target: "FuzzBuild"},
+	}
+	assert.NoError(t, saveTargetStatuses(filepath.Join(reportDir,
+		"status.json"), statuses))
+
+	cfg := &Config{
+		Project: Project{ReportDir: reportDir},
+		Fuzz: Fuzz{
+			AutoDisableCrashing: 3,
+			EarlyCrashExecs:     1000,
+		},
+	}
+	disable, err := newDisablePolicy(cfg)
+	assert.NoError(t, err)
+
+	assert.True(t, disable.disabled("parser", "FuzzEval"))
+	assert.False(t, disable.disabled("parser", "FuzzLex"))
+	assert.False(t, disable.disabled("tree", "FuzzBuild"))
+	assert.False(t, disable.disabled("tree", "FuzzNew"))
+
+	// One more early crash disables a target after two, but not after
+	// none.
+	cycles, disables := disable.disables("parser", "FuzzLex")
+	assert.Equal(t, 3, cycles)
+	assert.True(t, disables)
+
+	cycles, disables = disable.disables("tree", "FuzzBuild")
+	assert.Equal(t, 1, cycles)
+	assert.False(t, disables)
+
+	engine := newFuzzEngine(FuzzEngineGo)
+	assert.True(t, disable.earlyCrash(engine, fuzzCrash{}))
+	assert.True(t, disable.earlyCrash(engine, fuzzCrash{
+		progress: "fuzz: elapsed: 0s, gathering baseline coverage: " +
+			"0/12 completed",
+	}))
+	assert.True(t, disable.earlyCrash(engine, fuzzCrash{
+		progress: "fuzz: elapsed: 3s, execs: 1000 (333/sec), new " +
+			"interesting: 0 (total: 12)",
+	}))
+	assert.False(t, disable.earlyCrash(engine, fuzzCrash{
+		progress: "fuzz: elapsed: 3s, execs: 1001 (333/sec), new " +
+			"interesting: 0 (total: 12)",
+	}))
+}
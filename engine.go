@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// FuzzEngineGo selects Go's native fuzzing engine (`go test -fuzz`).
+	FuzzEngineGo = "go"
+
+	// FuzzEngineLibFuzzer selects libFuzzer, with the fuzz targets built
+	// by go-118-fuzz-build and linked using clang.
+	FuzzEngineLibFuzzer = "libfuzzer"
+)
+
+var (
+	// goProgressRegex matches the progress lines periodically printed by
+	// Go's fuzzing engine, like:
+	//   "fuzz: elapsed: 3s, execs: 102345 (34112/sec), new interesting: 5
+	//   (total: 12)"
+	//   "fuzz: elapsed: 0s, gathering baseline coverage: 0/12 completed"
+	//
+	// Captured groups:
+	//   - "execs": the number of executions so far (e.g., "102345"), if
+	//     reported
+	goProgressRegex = regexp.MustCompile(
+		`^fuzz: elapsed: \S+, (execs: (?P<execs>\d+)|gathering ` +
+			`baseline coverage: )`,
+	)
+
+	// libFuzzerProgressRegex matches the status lines printed by libFuzzer
+	// when it makes progress, like:
+	//   "#1024	pulse  cov: 123 ft: 200 corp: 10/100b exec/s: 512"
+	//
+	// Captured groups:
+	//   - "execs": the number of executions so far (e.g., "1024")
+	libFuzzerProgressRegex = regexp.MustCompile(
+		`^#(?P<execs>\d+)\s+(INITED|NEW|REDUCE|RELOAD|pulse|DONE)\s`,
+	)
+
+	// libFuzzerFailureRegex matches lines indicating where libFuzzer saved
+	// the input that crashed the fuzz target, capturing the fuzz target
+	// name and the name of the saved input.
+	//
+	// It matches lines like:
+	//   "Test unit written to testdata/fuzz/FuzzFoo/crash-da39a3ee5e6b"
+	//
+	// Captured groups:
+	//   - "target": the fuzz target name (e.g., "FuzzFoo")
+	//   - "id": the saved input name (e.g., "crash-da39a3ee5e6b")
+	libFuzzerFailureRegex = regexp.MustCompile(
+		`Test unit written to testdata/fuzz/` +
+			`(?P<target>[^/]+)/(?P<id>[a-z]+-[0-9a-f]+)`,
+	)
+
+	// goSubtestResultRegex matches the lines reporting the result of a
+	// subtest, printed by `go test -v` for each seed corpus entry run,
+	// like:
+	//   "    --- PASS: FuzzFoo/gcf-reproduce-771e938e4458e983 (0.00s)"
+	//
+	// Captured groups:
+	//   - "result": "PASS" or "FAIL"
+	//   - "target": the fuzz target name (e.g., "FuzzFoo")
+	//   - "id": the name of the seed corpus entry
+	goSubtestResultRegex = regexp.MustCompile(
+		`^\s*--- (?P<result>PASS|FAIL): (?P<target>[^/\s]+)/` +
+			`(?P<id>\S+) \(`,
+	)
+)
+
+// fuzzEngine abstracts the steps of the fuzzing process that depend on the
+// fuzzing engine: building the fuzz binary, running it, reproducing a crash,
+// and parsing its output. The corpus, reporting, and scheduling infrastructure
+// is shared between all engines.
+type fuzzEngine interface {
+	// buildBinary builds the fuzz binary for the target in the package
+	// located at pkgPath and writes it to binaryPath.
+	buildBinary(ctx context.Context, pkgPath, binaryPath,
+		target string) error
+
+	// fuzzCmd returns the command that fuzzes the target inside the
+	// container. If budget is positive, the fuzzer is told to stop on its
+	// own after fuzzing for that long; otherwise it runs until killed.
+	fuzzCmd(target string, budget time.Duration) []string
+
+	// reproduceCmd returns the command that runs the target against the
+	// single input saved as testdata/fuzz/<target>/<inputID> inside the
+	// container.
+	reproduceCmd(target, inputID string) []string
+
+	// batchReproduceCmd returns the command that runs the target against
+	// all the inputs saved as testdata/fuzz/<target>/<inputID> inside the
+	// container, reporting the result of each, or nil if the engine cannot
+	// run several inputs in a single invocation.
+	batchReproduceCmd(target string, inputIDs []string) []string
+
+	// parseReproduceResults reads the output of the batch reproduce
+	// command, and returns whether each input whose result is reported
+	// passed, keyed by input ID.
+	parseReproduceResults(r io.Reader, target string) (map[string]bool,
+		error)
+
+	// isFailureLine reports whether the output line marks the start of a
+	// fuzz crash.
+	isFailureLine(line string) bool
+
+	// parseFailureLine extracts the fuzz target name and the ID of the
+	// saved failing input from an output line, if present.
+	parseFailureLine(line string) (string, string)
+
+	// isProgressLine reports whether the output line reports the progress
+	// of the fuzzer, e.g. its elapsed time and number of executions.
+	isProgressLine(line string) bool
+
+	// progressExecs returns the number of executions reported by the
+	// progress line, or 0 if it reports none, e.g. while gathering the
+	// baseline coverage.
+	progressExecs(line string) int64
+
+	// goCorpus reports whether the corpus is stored in Go's corpus file
+	// format, which is required to generate coverage reports and to
+	// minimize the corpus using `go test`.
+	goCorpus() bool
+}
+
+// newFuzzEngine returns the fuzzEngine for the given engine name. Unknown names
+// fall back to Go's native fuzzing engine.
+func newFuzzEngine(name string) fuzzEngine {
+	if name == FuzzEngineLibFuzzer {
+		return &libFuzzerEngine{}
+	}
+	return &goFuzzEngine{}
+}
+
+// goFuzzEngine runs fuzz targets using Go's native fuzzing engine.
+type goFuzzEngine struct{}
+
+// buildBinary compiles the fuzz test binary using `go test -c`.
+func (e *goFuzzEngine) buildBinary(ctx context.Context, pkgPath, binaryPath,
+	target string) error {
+
+	// Prepare the command and environment to build the fuzz binary.
+	// Command arguments (explanations):
+	//
+	//   -fuzz=^%s$
+	// Run only the fuzz test function matching this regular expression.
+	//
+	//   -o %s
+	// Write the compiled test binary to the given output path
+	// (instead of running tests immediately).
+	//
+	//   -c
+	// Compile the test binary but do not run it. This is required so
+	// we can later run the binary directly in Docker container.
+	cmd := []string{"test", fmt.Sprintf("-fuzz=^%s$", target),
+		"-o", binaryPath, "-c"}
+
+	// Run the go test command with GOOS and GOARCH set to build a
+	// linux/amd64 binary.
+	//
+	// GOOS is the target operating system (here "linux"), and GOARCH
+	// is the target architecture (here "amd64"). These values control
+	// the environment for the go toolchain when building and testing.
+	_, err := runGoCommand(ctx, pkgPath, cmd, "GOOS=linux", "GOARCH=amd64")
+	return err
+}
+
+// fuzzCmd returns the command running the compiled test binary in fuzzing
+// mode, using the mounted corpus as the fuzz cache directory, and bounding the
+// fuzzing time with -test.fuzztime if a budget is given.
+func (e *goFuzzEngine) fuzzCmd(target string,
+	budget time.Duration) []string {
+
+	cmd := []string{
+		fmt.Sprintf("./%s.test", target),
+		fmt.Sprintf("-test.fuzz=^%s$", target),
+		fmt.Sprintf("-test.fuzzcachedir=%s", ContainerCorpusPath),
+		"-test.parallel=1",
+	}
+	if budget > 0 {
+		cmd = append(cmd, fmt.Sprintf("-test.fuzztime=%s", budget))
+	}
+	return cmd
+}
+
+// reproduceCmd returns the command running the compiled test binary against
+// a single seed corpus entry.
+func (e *goFuzzEngine) reproduceCmd(target, inputID string) []string {
+	return []string{
+		fmt.Sprintf("./%s.test", target),
+		fmt.Sprintf("-test.run=%s/%s", target, inputID),
+	}
+}
+
+// batchReproduceCmd returns the command running the compiled test binary
+// verbosely against all the given seed corpus entries, so the result of each
+// is reported. Any entry panicking aborts the run, leaving the entries after it
+// without a result.
+func (e *goFuzzEngine) batchReproduceCmd(target string,
+	inputIDs []string) []string {
+
+	quoted := make([]string, len(inputIDs))
+	for i, id := range inputIDs {
+		quoted[i] = regexp.QuoteMeta(id)
+	}
+
+	return []string{
+		fmt.Sprintf("./%s.test", target),
+		fmt.Sprintf("-test.run=^%s$/^(%s)$", target,
+			strings.Join(quoted, "|")),
+		"-test.v",
+	}
+}
+
+// parseReproduceResults returns the results of the "--- PASS:" and "--- FAIL:"
+// lines reported for the subtests of the target.
+func (e *goFuzzEngine) parseReproduceResults(r io.Reader,
+	target string) (map[string]bool, error) {
+
+	results := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		matches := goSubtestResultRegex.FindStringSubmatch(
+			scanner.Text())
+		if matches == nil ||
+			matches[goSubtestResultRegex.SubexpIndex("target")] !=
+				target {
+
+			continue
+		}
+
+		id := matches[goSubtestResultRegex.SubexpIndex("id")]
+		results[id] = matches[goSubtestResultRegex.SubexpIndex(
+			"result")] == "PASS"
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading reproduce output: %w", err)
+	}
+
+	return results, nil
+}
+
+// isFailureLine reports whether the line starts a "--- FAIL:" section.
+func (e *goFuzzEngine) isFailureLine(line string) bool {
+	return strings.Contains(line, "--- FAIL:")
+}
+
+// parseFailureLine extracts the target and input ID from a "Failing input
+// written to ..." line.
+func (e *goFuzzEngine) parseFailureLine(line string) (string, string) {
+	return parseFailureLine(line)
+}
+
+// isProgressLine reports whether the line is a "fuzz: elapsed: ..." progress
+// line.
+func (e *goFuzzEngine) isProgressLine(line string) bool {
+	return goProgressRegex.MatchString(line)
+}
+
+// progressExecs returns the number of executions of a "fuzz: elapsed: ..."
+// progress line, or 0 while gathering the baseline coverage.
+func (e *goFuzzEngine) progressExecs(line string) int64 {
+	return progressExecs(goProgressRegex, line)
+}
+
+// goCorpus returns true, since Go's fuzzing engine stores its corpus in Go's
+// corpus file format.
+func (e *goFuzzEngine) goCorpus() bool {
+	return true
+}
+
+// libFuzzerLinkTarget is the target triple clang links libFuzzer binaries for,
+// matching the linux/amd64 platform of the fuzzing container whatever the
+// platform of the host.
+const libFuzzerLinkTarget = "x86_64-linux-gnu"
+
+// libFuzzerEngine runs fuzz targets using libFuzzer. The native Go fuzz targets
+// are built with go-118-fuzz-build and linked into a libFuzzer binary using
+// clang, so both tools must be available on the host, and clang must be able
+// to link for linux/amd64.
+type libFuzzerEngine struct{}
+
+// buildBinary builds a libFuzzer archive for the target with go-118-fuzz-build
+// and links it into a standalone fuzzing binary with clang.
+func (e *libFuzzerEngine) buildBinary(ctx context.Context, pkgPath,
+	binaryPath, target string) error {
+
+	archivePath := binaryPath + ".a"
+
+	// Build the fuzz target as a libFuzzer-compatible archive for
+	// linux/amd64, the platform of the fuzzing container.
+	_, err := runCommand(ctx, pkgPath, "go-118-fuzz-build",
+		e.buildArgs(archivePath, target), "GOOS=linux", "GOARCH=amd64")
+	if err != nil {
+		return err
+	}
+
+	// Link the archive against the libFuzzer runtime.
+	_, err = runCommand(ctx, pkgPath, "clang",
+		e.linkArgs(archivePath, binaryPath))
+	return err
+}
+
+// buildArgs returns the arguments of go-118-fuzz-build building the target as
+// a libFuzzer-compatible archive at archivePath.
+func (e *libFuzzerEngine) buildArgs(archivePath, target string) []string {
+	return []string{"-o", archivePath, "-func", target, "."}
+}
+
+// linkArgs returns the arguments of clang linking the archive at archivePath
+// against the libFuzzer runtime into the binary at binaryPath, for the same
+// platform the archive was built for rather than the host's.
+func (e *libFuzzerEngine) linkArgs(archivePath, binaryPath string) []string {
+	return []string{"--target=" + libFuzzerLinkTarget,
+		"-fsanitize=fuzzer", archivePath, "-o", binaryPath}
+}
+
+// fuzzCmd returns the command running the libFuzzer binary on the mounted
+// corpus. Crashing inputs are written to testdata/fuzz/<target>/, the same
+// location used by Go's fuzzing engine. A budget is passed as -max_total_time,
+// which libFuzzer only accepts in whole seconds.
+func (e *libFuzzerEngine) fuzzCmd(target string,
+	budget time.Duration) []string {
+
+	cmd := []string{
+		fmt.Sprintf("./%s.test", target),
+		fmt.Sprintf("-artifact_prefix=testdata/fuzz/%s/", target),
+	}
+	if seconds := int64(budget / time.Second); seconds > 0 {
+		cmd = append(cmd, fmt.Sprintf("-max_total_time=%d", seconds))
+	}
+
+	// libFuzzer treats the last positional argument as the corpus
+	// directory, so it must come after all flags.
+	return append(cmd, fmt.Sprintf("%s/%s", ContainerCorpusPath, target))
+}
+
+// reproduceCmd returns the command running the libFuzzer binary once against
+// a single input file.
+func (e *libFuzzerEngine) reproduceCmd(target, inputID string) []string {
+	return []string{
+		fmt.Sprintf("./%s.test", target),
+		fmt.Sprintf("testdata/fuzz/%s/%s", target, inputID),
+	}
+}
+
+// batchReproduceCmd returns nil, since libFuzzer stops at the first crashing
+// input when given several, without reporting the result of each.
+func (e *libFuzzerEngine) batchReproduceCmd(target string,
+	inputIDs []string) []string {
+
+	return nil
+}
+
+// parseReproduceResults returns no results, since libFuzzer has no batch
+// reproduce command.
+func (e *libFuzzerEngine) parseReproduceResults(r io.Reader,
+	target string) (map[string]bool, error) {
+
+	return nil, nil
+}
+
+// isFailureLine reports whether the line starts a Go panic, a fatal runtime
+// error, or a libFuzzer error report.
+func (e *libFuzzerEngine) isFailureLine(line string) bool {
+	return strings.HasPrefix(line, "panic: ") ||
+		strings.HasPrefix(line, "fatal error: ") ||
+		strings.Contains(line, "ERROR: libFuzzer:")
+}
+
+// parseFailureLine extracts the target and input name from a "Test unit
+// written to ..." line.
+func (e *libFuzzerEngine) parseFailureLine(line string) (string, string) {
+	matches := libFuzzerFailureRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return "", ""
+	}
+
+	return matches[libFuzzerFailureRegex.SubexpIndex("target")],
+		matches[libFuzzerFailureRegex.SubexpIndex("id")]
+}
+
+// isProgressLine reports whether the line is a libFuzzer status line, like
+// "#1024 pulse ...".
+func (e *libFuzzerEngine) isProgressLine(line string) bool {
+	return libFuzzerProgressRegex.MatchString(line)
+}
+
+// progressExecs returns the number of executions of a libFuzzer status line,
+// like 1024 for "#1024 pulse ...".
+func (e *libFuzzerEngine) progressExecs(line string) int64 {
+	return progressExecs(libFuzzerProgressRegex, line)
+}
+
+// goCorpus returns false, since libFuzzer stores its corpus as raw inputs.
+func (e *libFuzzerEngine) goCorpus() bool {
+	return false
+}
+
+// progressExecs returns the number of executions captured by the "execs" group
+// of the given progress regex in the line, or 0 if none is captured.
+func progressExecs(progressRegex *regexp.Regexp, line string) int64 {
+	matches := progressRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return 0
+	}
+
+	execs, err := strconv.ParseInt(
+		matches[progressRegex.SubexpIndex("execs")], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return execs
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLibFuzzerEngineParseOutput verifies that the libFuzzer engine detects
+// crashes and extracts the saved failing input from libFuzzer's output format.
+func TestLibFuzzerEngineParseOutput(t *testing.T) {
+	engine := newFuzzEngine(FuzzEngineLibFuzzer)
+
+	tests := []struct {
+		name           string
+		logLine        string
+		expectFailure  bool
+		expectedTarget string
+		expectedID     string
+	}{
+		{
+			name: "go panic",
+			logLine: "panic: runtime error: index out of " +
+				"range",
+			expectFailure: true,
+		},
+		{
+			name:          "libFuzzer deadly signal",
+			logLine:       "==12== ERROR: libFuzzer: deadly signal",
+			expectFailure: true,
+		},
+		{
+			name: "saved crashing input",
+			logLine: "Test unit written to testdata/fuzz/" +
+				"FuzzFoo/crash-da39a3ee5e6b4b0d3255bf" +
+				"ef95601890afd80709",
+			expectedTarget: "FuzzFoo",
+			expectedID: "crash-da39a3ee5e6b4b0d3255bfef956018" +
+				"90afd80709",
+		},
+		{
+			name:    "non-relevant log line",
+			logLine: "#1024 pulse  cov: 12 ft: 14 corp: 3/9b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectFailure,
+				engine.isFailureLine(tt.logLine))
+
+			target, id := engine.parseFailureLine(tt.logLine)
+			assert.Equal(t, tt.expectedTarget, target)
+			assert.Equal(t, tt.expectedID, id)
+		})
+	}
+}
+
+// TestFuzzEngineReproduceCmd verifies that each engine runs the saved failing
+// input of a target when reproducing a crash.
+func TestFuzzEngineReproduceCmd(t *testing.T) {
+	assert.Equal(t, []string{"./FuzzFoo.test",
+		"-test.run=FuzzFoo/771e938e4458e983"},
+		newFuzzEngine(FuzzEngineGo).reproduceCmd("FuzzFoo",
+			"771e938e4458e983"))
+
+	assert.Equal(t, []string{"./FuzzFoo.test",
+		"testdata/fuzz/FuzzFoo/771e938e4458e983"},
+		newFuzzEngine(FuzzEngineLibFuzzer).reproduceCmd("FuzzFoo",
+			"771e938e4458e983"))
+}
+
+// TestFuzzEngineBatchReproduce verifies that Go's engine runs all the given
+// inputs of a target at once and parses the result reported for each, while
+// libFuzzer has no batch reproduce command.
+func TestFuzzEngineBatchReproduce(t *testing.T) {
+	goEngine := newFuzzEngine(FuzzEngineGo)
+	assert.Equal(t, []string{"./FuzzFoo.test",
+		`-test.run=^FuzzFoo$/^(gcf-reproduce-a1|gcf-reproduce-b2)$`,
+		"-test.v"}, goEngine.batchReproduceCmd("FuzzFoo",
+		[]string{"gcf-reproduce-a1", "gcf-reproduce-b2"}))
+
+	// The input after the panicking one is never run, and the results of
+	// other targets are ignored.
+	output := "=== RUN   FuzzFoo\r\n" +
+		"=== RUN   FuzzFoo/gcf-reproduce-a1\r\n" +
+		"=== RUN   FuzzFoo/gcf-reproduce-b2\r\n" +
+		"--- FAIL: FuzzFoo (0.00s)\r\n" +
+		"    --- PASS: FuzzFoo/gcf-reproduce-a1 (0.00s)\r\n" +
+		"    --- FAIL: FuzzFoo/gcf-reproduce-b2 (0.01s)\r\n" +
+		"    --- PASS: FuzzBar/gcf-reproduce-c3 (0.00s)\r\n" +
+		"panic: boom [recovered]\r\n"
+	results, err := goEngine.parseReproduceResults(
+		strings.NewReader(output), "FuzzFoo")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"gcf-reproduce-a1": true,
+		"gcf-reproduce-b2": false,
+	}, results)
+
+	assert.Nil(t, newFuzzEngine(FuzzEngineLibFuzzer).batchReproduceCmd(
+		"FuzzFoo", []string{"gcf-reproduce-a1"}))
+}
+
+// TestFuzzEngineFuzzCmd verifies that the fuzzing budget is only passed to the
+// fuzzer when positive, in the unit expected by each engine.
+func TestFuzzEngineFuzzCmd(t *testing.T) {
+	goEngine := newFuzzEngine(FuzzEngineGo)
+	assert.Equal(t, []string{"./FuzzFoo.test", "-test.fuzz=^FuzzFoo$",
+		"-test.fuzzcachedir=" + ContainerCorpusPath,
+		"-test.parallel=1"}, goEngine.fuzzCmd("FuzzFoo", 0))
+	assert.Equal(t, []string{"./FuzzFoo.test", "-test.fuzz=^FuzzFoo$",
+		"-test.fuzzcachedir=" + ContainerCorpusPath,
+		"-test.parallel=1", "-test.fuzztime=1m30s"},
+		goEngine.fuzzCmd("FuzzFoo", 90*time.Second))
+
+	libFuzzer := newFuzzEngine(FuzzEngineLibFuzzer)
+	assert.Equal(t, []string{"./FuzzFoo.test",
+		"-artifact_prefix=testdata/fuzz/FuzzFoo/",
+		ContainerCorpusPath + "/FuzzFoo"},
+		libFuzzer.fuzzCmd("FuzzFoo", 500*time.Millisecond))
+	assert.Equal(t, []string{"./FuzzFoo.test",
+		"-artifact_prefix=testdata/fuzz/FuzzFoo/",
+		"-max_total_time=90", ContainerCorpusPath + "/FuzzFoo"},
+		libFuzzer.fuzzCmd("FuzzFoo", 90*time.Second))
+}
+
+// TestLibFuzzerBuildArgs verifies that libFuzzer binaries are built and linked
+// for linux/amd64, the platform of the fuzzing container.
+func TestLibFuzzerBuildArgs(t *testing.T) {
+	e := &libFuzzerEngine{}
+	assert.Equal(t, []string{"-o", "/bin/pkg/FuzzFoo.a", "-func",
+		"FuzzFoo", "."}, e.buildArgs("/bin/pkg/FuzzFoo.a", "FuzzFoo"))
+	assert.Equal(t, []string{"--target=x86_64-linux-gnu",
+		"-fsanitize=fuzzer", "/bin/pkg/FuzzFoo.a", "-o",
+		"/bin/pkg/FuzzFoo"}, e.linkArgs("/bin/pkg/FuzzFoo.a",
+		"/bin/pkg/FuzzFoo"))
+}
+
+// TestFuzzEngineIsProgressLine verifies that the progress lines of both
+// engines are recognized, and other output lines are not.
+func TestFuzzEngineIsProgressLine(t *testing.T) {
+	goEngine := newFuzzEngine(FuzzEngineGo)
+	assert.True(t, goEngine.isProgressLine("fuzz: elapsed: 3s, execs: "+
+		"102345 (34112/sec), new interesting: 5 (total: 12)"))
+	assert.True(t, goEngine.isProgressLine("fuzz: elapsed: 0s, "+
+		"gathering baseline coverage: 0/12 completed"))
+	assert.False(t, goEngine.isProgressLine("--- FAIL: FuzzFoo (0.00s)"))
+	assert.False(t, goEngine.isProgressLine("    fuzz: elapsed: 3s"))
+
+	libFuzzer := newFuzzEngine(FuzzEngineLibFuzzer)
+	assert.True(t, libFuzzer.isProgressLine("#1024\tpulse  cov: 123 "+
+		"ft: 200 corp: 10/100b lim: 4 exec/s: 512 rss: 30Mb"))
+	assert.True(t, libFuzzer.isProgressLine("#2\tINITED cov: 3 ft: 3 "+
+		"corp: 1/1b exec/s: 0 rss: 28Mb"))
+	assert.False(t, libFuzzer.isProgressLine("INFO: Seed: 1234"))
+	assert.False(t, libFuzzer.isProgressLine("==12== ERROR: libFuzzer: "+
+		"deadly signal"))
+}
+
+// TestFuzzEngineProgressExecs verifies that the number of executions is
+// extracted from the progress lines of both engines, and is 0 for the lines
+// reporting none.
+func TestFuzzEngineProgressExecs(t *testing.T) {
+	goEngine := newFuzzEngine(FuzzEngineGo)
+	assert.EqualValues(t, 102345, goEngine.progressExecs("fuzz: "+
+		"elapsed: 3s, execs: 102345 (34112/sec), new interesting: 5 "+
+		"(total: 12)"))
+	assert.Zero(t, goEngine.progressExecs("fuzz: elapsed: 0s, gathering "+
+		"baseline coverage: 0/12 completed"))
+	assert.Zero(t, goEngine.progressExecs(""))
+
+	libFuzzer := newFuzzEngine(FuzzEngineLibFuzzer)
+	assert.EqualValues(t, 1024, libFuzzer.progressExecs("#1024\tpulse  "+
+		"cov: 123 ft: 200 corp: 10/100b lim: 4 exec/s: 512 rss: 30Mb"))
+	assert.Zero(t, libFuzzer.progressExecs("INFO: Seed: 1234"))
+}
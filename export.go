@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// crashExportMu serializes the exports of crashes found concurrently by the
+// workers, which all update the same manifest.
+var crashExportMu sync.Mutex
+
+// crashExport is an entry of the manifest of the crash export directory,
+// mapping the signature of a crash to the file holding its failing input and
+// to the issue tracking it.
+type crashExport struct {
+	Package   string `json:"package"`
+	Target    string `json:"target"`
+	Signature string `json:"signature"`
+
+	// Input is the path of the failing input file, relative to the export
+	// directory, or empty for crashes of the seed corpus, which have no
+	// failing input.
+	Input string `json:"input,omitempty"`
+
+	IssueURL   string    `json:"issue_url"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// exportCrash writes the failing input of the reported crash to the export
+// directory, as <pkg>/<target>/<signature>, and records it in the directory's
+// manifest.json, replacing any previous export of the same crash. It is safe
+// for concurrent use.
+func exportCrash(exportDir string, report crashReport, failingInput string,
+	now time.Time) error {
+
+	crashExportMu.Lock()
+	defer crashExportMu.Unlock()
+
+	entry := crashExport{
+		Package:    report.Package,
+		Target:     report.Target,
+		Signature:  report.Signature,
+		IssueURL:   report.IssueURL,
+		ExportedAt: now.UTC(),
+	}
+
+	if err := EnsureDirExists(exportDir); err != nil {
+		return fmt.Errorf("create crash export directory: %w", err)
+	}
+
+	if failingInput != "" {
+		entry.Input = filepath.Join(report.Package, report.Target,
+			report.Signature)
+		inputPath := filepath.Join(exportDir, entry.Input)
+		if err := EnsureDirExists(filepath.Dir(inputPath)); err != nil {
+			return err
+		}
+
+		err := os.WriteFile(inputPath, []byte(failingInput), 0644)
+		if err != nil {
+			return fmt.Errorf("write failing input %q: %w",
+				inputPath, err)
+		}
+	}
+
+	manifestPath := filepath.Join(exportDir, "manifest.json")
+	manifest, err := loadCrashManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	// Replace the previous export of the crash, if any.
+	kept := manifest[:0]
+	for _, e := range manifest {
+		if e.Package != entry.Package || e.Target != entry.Target ||
+			e.Signature != entry.Signature {
+
+			kept = append(kept, e)
+		}
+	}
+	manifest = append(kept, entry)
+	sort.Slice(manifest, func(i, j int) bool {
+		a, b := manifest[i], manifest[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Signature < b.Signature
+	})
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize crash manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, manifestData, 0644); err != nil {
+		return fmt.Errorf("write crash manifest %q: %w", manifestPath,
+			err)
+	}
+
+	return nil
+}
+
+// loadCrashManifest loads the manifest of the crash export directory from the
+// JSON file at the given path. If the file does not exist, it returns an empty
+// slice.
+func loadCrashManifest(manifestPath string) ([]crashExport, error) {
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read crash manifest %q: %w",
+			manifestPath, err)
+	}
+
+	var manifest []crashExport
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid JSON in crash manifest %q: %w",
+			manifestPath, err)
+	}
+
+	return manifest, nil
+}
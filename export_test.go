@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExportCrash verifies that the failing inputs of crashes are written to
+// the export directory and recorded in its manifest, with a new export of the
+// same crash replacing the previous one.
+func TestExportCrash(t *testing.T) {
+	exportDir := filepath.Join(t.TempDir(), "crashes")
+	first := time.Date(2025, 7, 12, 10, 0, 0, 0, time.UTC)
+
+	evalCrash := crashReport{
+		Package:   "x/parser",
+		Target:    "FuzzEval",
+		Signature: "1a2b3c4d",
+		IssueURL:  "https://github.com/OWNER/REPO/issues/1",
+	}
+	assert.NoError(t, exportCrash(exportDir, evalCrash, "first input",
+		first))
+
+	// Crashes of the seed corpus have no failing input to export.
+	seedCrash := crashReport{
+		Package:   "lexer",
+		Target:    "FuzzLex",
+		Signature: "5e6f7a8b",
+		IssueURL:  "https://github.com/OWNER/REPO/issues/2",
+	}
+	assert.NoError(t, exportCrash(exportDir, seedCrash, "", first))
+
+	second := first.Add(time.Hour)
+	assert.NoError(t, exportCrash(exportDir, evalCrash, "second input",
+		second))
+
+	input, err := os.ReadFile(filepath.Join(exportDir, "x", "parser",
+		"FuzzEval", "1a2b3c4d"))
+	assert.NoError(t, err)
+	assert.Equal(t, "second input", string(input))
+
+	manifest, err := loadCrashManifest(filepath.Join(exportDir,
+		"manifest.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []crashExport{
+		{
+			Package:    "lexer",
+			Target:     "FuzzLex",
+			Signature:  "5e6f7a8b",
+			IssueURL:   "https://github.com/OWNER/REPO/issues/2",
+			ExportedAt: first,
+		},
+		{
+			Package:   "x/parser",
+			Target:    "FuzzEval",
+			Signature: "1a2b3c4d",
+			Input: filepath.Join("x", "parser", "FuzzEval",
+				"1a2b3c4d"),
+			IssueURL:   "https://github.com/OWNER/REPO/issues/1",
+			ExportedAt: second,
+		},
+	}, manifest)
+}
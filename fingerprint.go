@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxCrashStateFrames is the number of stack frames making up the crash state,
+// like ClusterFuzz's.
+const maxCrashStateFrames = 3
+
+var (
+	// goroutineHeaderRegex matches the header of a goroutine's stack trace,
+	// like "goroutine 20 [running]:".
+	goroutineHeaderRegex = regexp.MustCompile(`^goroutine \d+ \[.*\]:$`)
+
+	// stackFileLineRegex matches the line following a function in a Go
+	// stack trace, holding the function's file and line, like
+	// "/src/parser/complex.go:21 +0x1d8".
+	stackFileLineRegex = regexp.MustCompile(
+		`^\S+\.go:\d+( \+0x[0-9a-f]+)?$`,
+	)
+
+	// stackFunctionRegex matches a function in a Go stack trace, capturing
+	// its symbol without the arguments, like "parser.(*Parser).Parse" in
+	// "parser.(*Parser).Parse(0xc000012345, {0xc0000b6018, 0x2})".
+	stackFunctionRegex = regexp.MustCompile(`^(\S+)\(.*\)$`)
+
+	// ignoredFrameRegex matches the symbols of the frames skipped when
+	// computing the crash state: those of the runtime, the testing and
+	// fuzzing harnesses, and the panic call itself.
+	ignoredFrameRegex = regexp.MustCompile(`^(runtime[./]|testing\.|` +
+		`internal/fuzz\.|reflect\.|panic$|` +
+		`github\.com/AdamKorcz/go-118-fuzz-build/|` +
+		`main\.LLVMFuzzerTestOneInput)`)
+
+	// goCrashTypes maps the messages of Go runtime errors to the crash
+	// types ClusterFuzz assigns them.
+	goCrashTypes = []struct {
+		message   string
+		crashType string
+	}{
+		{"index out of range", "Index out of range"},
+		{"slice bounds out of range", "Slice bounds out of range"},
+		{"integer divide by zero", "Integer divide by zero"},
+		{"invalid memory address", "Invalid memory address"},
+		{"makeslice: len out of range", "Makeslice: len out of range"},
+	}
+)
+
+// crashFingerprint identifies a crash like ClusterFuzz does, by its type and
+// state, so crashes can be correlated across both systems.
+type crashFingerprint struct {
+	// crashType classifies the crash, e.g. "Index out of range".
+	crashType string
+
+	// crashState holds the top normalized frames of the crashing
+	// goroutine's stack, or the failure locations if there is no stack.
+	crashState []string
+}
+
+// clusterFuzzFingerprint computes the ClusterFuzz-compatible fingerprint of the
+// crash with the given error logs.
+func clusterFuzzFingerprint(errorLogs string) *crashFingerprint {
+	lines := strings.Split(errorLogs, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	state := stackCrashState(lines)
+	if len(state) == 0 {
+		state = locationCrashState(lines)
+	}
+
+	return &crashFingerprint{
+		crashType:  crashType(lines),
+		crashState: state,
+	}
+}
+
+// state returns the crash state in ClusterFuzz's format, with one frame per
+// line.
+func (f *crashFingerprint) state() string {
+	return strings.Join(f.crashState, "\n")
+}
+
+// signature returns the crash signature derived from the fingerprint, which
+// is the same for all crashes ClusterFuzz considers duplicates.
+func (f *crashFingerprint) signature() string {
+	return ComputeSHA256Short(f.crashType + "\n" + f.state())
+}
+
+// markdown returns the issue body section describing the fingerprint.
+func (f *crashFingerprint) markdown() string {
+	return fmt.Sprintf("## Crash fingerprint\nCrash type: %s\n"+
+		"Crash state:\n~~~sh\n%s\n~~~\n", f.crashType, f.state())
+}
+
+// crashType classifies the crash with the given trimmed error log lines using
+// ClusterFuzz's crash types, falling back to "Fuzz target failure" for
+// failures reported without panicking, e.g. using t.Errorf.
+func crashType(lines []string) string {
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "ERROR: libFuzzer: timeout"):
+			return "Timeout"
+
+		case strings.Contains(line, "ERROR: libFuzzer: out-of-memory"):
+			return "Out-of-memory"
+
+		case strings.Contains(line, "fatal error: stack overflow"),
+			strings.Contains(line, "goroutine stack exceeds"):
+
+			return "Stack overflow"
+
+		case strings.Contains(line, "fatal error: "):
+			return "Fatal error"
+		}
+
+		_, message, ok := strings.Cut(line, "panic: ")
+		if !ok {
+			continue
+		}
+
+		if runtimeErr, ok := strings.CutPrefix(message,
+			"runtime error: "); ok {
+
+			for _, ct := range goCrashTypes {
+				if strings.HasPrefix(runtimeErr, ct.message) {
+					return ct.crashType
+				}
+			}
+		}
+
+		return "Panic"
+	}
+
+	return "Fuzz target failure"
+}
+
+// stackCrashState returns the top normalized frames of the first goroutine
+// stack trace in the given trimmed error log lines, skipping the frames of the
+// runtime and the fuzzing harness.
+func stackCrashState(lines []string) []string {
+	var state []string
+	inStack := false
+	for i, line := range lines {
+		if goroutineHeaderRegex.MatchString(line) {
+			// Only the stack of the crashing goroutine, which is
+			// printed first, makes up the crash state.
+			if inStack {
+				break
+			}
+			inStack = true
+			continue
+		}
+
+		// A function is always followed by its file and line.
+		if !inStack || i+1 >= len(lines) ||
+			!stackFileLineRegex.MatchString(lines[i+1]) {
+
+			continue
+		}
+
+		symbol := normalizeSymbol(line)
+		if symbol == "" || ignoredFrameRegex.MatchString(symbol) {
+			continue
+		}
+
+		state = append(state, symbol)
+		if len(state) == maxCrashStateFrames {
+			break
+		}
+	}
+
+	return state
+}
+
+// locationCrashState returns the first failure locations ("<file>.go:<line>")
+// in the given trimmed error log lines, for failures reported without a stack
+// trace.
+func locationCrashState(lines []string) []string {
+	var state []string
+	for _, line := range lines {
+		location := parseFileAndLine(line)
+		if location == "" {
+			continue
+		}
+
+		state = append(state, strings.TrimSpace(location))
+		if len(state) == maxCrashStateFrames {
+			break
+		}
+	}
+
+	return state
+}
+
+// normalizeSymbol returns the symbol of the function in a Go stack trace line,
+// without its arguments, and with the escaping of the package path (e.g. "%2e"
+// for dots in its last element) undone. Returns an empty string if the line is
+// not a function.
+func normalizeSymbol(line string) string {
+	matches := stackFunctionRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return ""
+	}
+
+	symbol, err := url.PathUnescape(matches[1])
+	if err != nil {
+		return matches[1]
+	}
+
+	return symbol
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// indexPanicLogs are the error logs of a fuzz target panicking with an index
+// out of range error, as reported by `go test -fuzz`.
+const indexPanicLogs = "    --- FAIL: FuzzParseComplex (0.00s)\n" +
+	"testing.go:1591: panic: runtime error: index out of range [2] " +
+	"with length 2\n" +
+	"goroutine 20 [running]:\n" +
+	"runtime/debug.Stack()\n" +
+	"\t/usr/local/go/src/runtime/debug/stack.go:24 +0x9e\n" +
+	"testing.tRunner.func1()\n" +
+	"\t/usr/local/go/src/testing/testing.go:1591 +0x1c8\n" +
+	"panic({0x5f2b20?, 0xc0000a8018?})\n" +
+	"\t/usr/local/go/src/runtime/panic.go:914 +0x21f\n" +
+	"github.com/user/proj/parser.parseImaginary(...)\n" +
+	"\t/src/parser/complex.go:42\n" +
+	"github.com/user/proj/parser.ParseComplex({0xc0000b6018, 0x2})\n" +
+	"\t/src/parser/complex.go:21 +0x1d8\n" +
+	"github.com/user/proj/parser.FuzzParseComplex.func1(0x0?, " +
+	"{0xc0000b6018, 0x2})\n" +
+	"\t/src/parser/complex_test.go:17 +0x45\n" +
+	"reflect.Value.call({0x5d3f00?, 0x63d1e8?, 0x13?}, {0x613a0c, 0x4}, " +
+	"{0xc0000a0120, 0x2, 0x2?})\n" +
+	"\t/usr/local/go/src/reflect/value.go:596 +0xce5\n" +
+	"testing.(*F).Fuzz.func1.1(0x0?)\n" +
+	"\t/usr/local/go/src/testing/fuzz.go:335 +0x3f3\n" +
+	"created by testing.(*F).Fuzz.func1 in goroutine 7\n" +
+	"\t/usr/local/go/src/testing/fuzz.go:322 +0x597\n\n" +
+	"Failing input written to testdata/fuzz/FuzzParseComplex/" +
+	"771e938e4458e983\n"
+
+// TestClusterFuzzFingerprint verifies that the crash type and state are
+// computed from sample crash logs, skipping runtime and harness frames and
+// falling back to the failure locations for crashes without a stack trace.
+func TestClusterFuzzFingerprint(t *testing.T) {
+	tests := []struct {
+		name          string
+		errorLogs     string
+		expectedType  string
+		expectedState []string
+	}{
+		{
+			name:         "index out of range panic",
+			errorLogs:    indexPanicLogs,
+			expectedType: "Index out of range",
+			expectedState: []string{
+				"github.com/user/proj/parser.parseImaginary",
+				"github.com/user/proj/parser.ParseComplex",
+				"github.com/user/proj/parser." +
+					"FuzzParseComplex.func1",
+			},
+		},
+		{
+			name: "nil dereference in escaped package",
+			errorLogs: "panic: runtime error: invalid " +
+				"memory address or nil pointer " +
+				"dereference\n" +
+				"[signal SIGSEGV: segmentation violation]\n\n" +
+				"goroutine 1 [running]:\n" +
+				"example.com/go%2eyaml.(*Decoder).Decode" +
+				"(0xc000012345, {0x0, 0x0})\n" +
+				"\t/src/decode.go:88 +0x12\n" +
+				"main.LLVMFuzzerTestOneInput(" +
+				"0xc0000b6018, 0x2)\n" +
+				"\t/src/main.go:30 +0x8e\n",
+			expectedType: "Invalid memory address",
+			expectedState: []string{
+				"example.com/go.yaml.(*Decoder).Decode",
+			},
+		},
+		{
+			name: "custom panic only uses first goroutine",
+			errorLogs: "panic: unexpected token\n\n" +
+				"goroutine 5 [running]:\n" +
+				"example.com/lexer.Next[...](...)\n" +
+				"\t/src/lexer.go:12\n\n" +
+				"goroutine 1 [chan receive]:\n" +
+				"example.com/lexer.Run()\n" +
+				"\t/src/run.go:3 +0x1\n",
+			expectedType: "Panic",
+			expectedState: []string{
+				"example.com/lexer.Next[...]",
+			},
+		},
+		{
+			name: "fatal error",
+			errorLogs: "fatal error: concurrent map writes\n\n" +
+				"goroutine 9 [running]:\n" +
+				"example.com/cache.(*Cache).Put(...)\n" +
+				"\t/src/cache.go:51\n",
+			expectedType: "Fatal error",
+			expectedState: []string{
+				"example.com/cache.(*Cache).Put",
+			},
+		},
+		{
+			name: "libFuzzer timeout",
+			errorLogs: "==12== ERROR: libFuzzer: timeout after " +
+				"25 seconds\n",
+			expectedType: "Timeout",
+		},
+		{
+			name: "test failure without stack",
+			errorLogs: "    --- FAIL: FuzzReverse (0.00s)\n" +
+				"        reverse_test.go:20: Reverse " +
+				"produced invalid UTF-8 string " +
+				"\"\\x9c\\xdd\"\n",
+			expectedType:  "Fuzz target failure",
+			expectedState: []string{"reverse_test.go:20"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fingerprint := clusterFuzzFingerprint(tc.errorLogs)
+			assert.Equal(t, tc.expectedType, fingerprint.crashType)
+			assert.Equal(t, tc.expectedState,
+				fingerprint.crashState)
+		})
+	}
+}
+
+// TestCrashFingerprintSignature verifies that crashes differing only in their
+// arguments, addresses and line offsets share the same signature, while
+// crashes of different types do not.
+func TestCrashFingerprintSignature(t *testing.T) {
+	first := clusterFuzzFingerprint(indexPanicLogs)
+
+	second := clusterFuzzFingerprint("panic: runtime error: index out " +
+		"of range [7] with length 3\n\ngoroutine 3 [running]:\n" +
+		"github.com/user/proj/parser.parseImaginary(...)\n" +
+		"\t/src/parser/complex.go:44\n" +
+		"github.com/user/proj/parser.ParseComplex({0xc0001, 0x7})\n" +
+		"\t/src/parser/complex.go:21 +0x2a0\n" +
+		"github.com/user/proj/parser.FuzzParseComplex.func1(0x0?, " +
+		"{0xc0001, 0x7})\n" +
+		"\t/src/parser/complex_test.go:17 +0x45\n")
+	assert.Equal(t, first.signature(), second.signature())
+
+	third := &crashFingerprint{
+		crashType:  "Slice bounds out of range",
+		crashState: first.crashState,
+	}
+	assert.NotEqual(t, first.signature(), third.signature())
+
+	assert.Equal(t, "## Crash fingerprint\n"+
+		"Crash type: Index out of range\n"+
+		"Crash state:\n~~~sh\n"+
+		"github.com/user/proj/parser.parseImaginary\n"+
+		"github.com/user/proj/parser.ParseComplex\n"+
+		"github.com/user/proj/parser.FuzzParseComplex.func1\n"+
+		"~~~\n", first.markdown())
+}
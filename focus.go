@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// focusPolicy decides which fuzz targets are deferred from a fuzzing cycle when
+// focusing on active targets: targets whose coverage has plateaued are only
+// fuzzed once their rerun interval has elapsed since they last ran, so the
+// cycle's time goes to the targets still gaining coverage, while plateaued
+// targets still run occasionally to catch regressions.
+type focusPolicy struct {
+	// reportDir is the directory holding the coverage history of the
+	// targets.
+	reportDir string
+
+	// window is the number of most recent coverage measurements that must
+	// all be equal for a target to have plateaued.
+	window int
+
+	// rerunInterval is the minimum time between two runs of a plateaued
+	// target.
+	rerunInterval time.Duration
+
+	// lastRun holds the time each target was last fuzzed.
+	lastRun map[TargetState]time.Time
+
+	// now is the time the cycle started.
+	now time.Time
+}
+
+// newFocusPolicy returns the focus policy of a fuzzing cycle starting at now,
+// based on the coverage history and target statuses in the report directory.
+func newFocusPolicy(cfg *Config, now time.Time) (*focusPolicy, error) {
+	statusPath := filepath.Join(cfg.Project.ReportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	lastRun := make(map[TargetState]time.Time, len(statuses))
+	for _, s := range statuses {
+		lastRun[TargetState{s.PkgPath, s.Target}] = s.LastRun
+	}
+
+	return &focusPolicy{
+		reportDir:     cfg.Project.ReportDir,
+		window:        cfg.Fuzz.PlateauWindow,
+		rerunInterval: cfg.Fuzz.PlateauRerunInterval,
+		lastRun:       lastRun,
+		now:           now,
+	}, nil
+}
+
+// deferred reports whether the target's coverage has plateaued and it ran
+// less than the rerun interval ago, so it should not be fuzzed in this cycle.
+func (p *focusPolicy) deferred(pkg, target string) (bool, error) {
+	history, err := loadTargetHistory(p.reportDir, pkg, target)
+	if err != nil {
+		return false, err
+	}
+
+	if !coveragePlateaued(history, p.window) {
+		return false, nil
+	}
+
+	lastRun := p.lastRun[TargetState{pkg, target}]
+	return p.now.Sub(lastRun) < p.rerunInterval, nil
+}
+
+// coveragePlateaued reports whether the given coverage history, newest first,
+// holds at least window measurements and the most recent window ones are all
+// equal.
+func coveragePlateaued(history []TargetHistory, window int) bool {
+	if len(history) < window {
+		return false
+	}
+
+	for _, h := range history[1:window] {
+		if h.Coverage != history[0].Coverage {
+			return false
+		}
+	}
+
+	return true
+}
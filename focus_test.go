@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoveragePlateaued verifies that coverage has plateaued only if the most
+// recent measurements of the window are all equal.
+func TestCoveragePlateaued(t *testing.T) {
+	history := []TargetHistory{
+		{Date: "2025-07-15", Coverage: "60.0"},
+		{Date: "2025-07-14", Coverage: "60.0"},
+		{Date: "2025-07-13", Coverage: "60.0"},
+		{Date: "2025-07-12", Coverage: "55.5"},
+	}
+
+	assert.True(t, coveragePlateaued(history, 2))
+	assert.True(t, coveragePlateaued(history, 3))
+	assert.False(t, coveragePlateaued(history, 4))
+
+	// Too short a history cannot have plateaued.
+	assert.False(t, coveragePlateaued(history, 5))
+	assert.False(t, coveragePlateaued(nil, 2))
+}
+
+// TestFocusPolicyDeferred verifies that only targets whose coverage has
+// plateaued are deferred, and only until their rerun interval has elapsed.
+func TestFocusPolicyDeferred(t *testing.T) {
+	reportDir := t.TempDir()
+	writeFiles(t, reportDir, map[string]string{
+		"targets/parser/FuzzEval.json": `[
+			{"Date": "2025-07-15", "Coverage": "70.0"},
+			{"Date": "2025-07-14", "Coverage": "70.0"},
+			{"Date": "2025-07-13", "Coverage": "70.0"}
+		]`,
+		"targets/parser/FuzzLex.json": `[
+			{"Date": "2025-07-15", "Coverage": "70.0"},
+			{"Date": "2025-07-14", "Coverage": "70.0"},
+			{"Date": "2025-07-13", "Coverage": "70.0"}
+		]`,
+		"targets/tree/FuzzBuild.json": `[
+			{"Date": "2025-07-15", "Coverage": "42.0"},
+			{"Date": "2025-07-14", "Coverage": "40.0"},
+			{"Date": "2025-07-13", "Coverage": "40.0"}
+		]`,
+	})
+
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	statuses := []TargetStatus{
+		{PkgPath: "parser", Target: "FuzzEval",
+			LastRun: now.Add(-time.Hour)},
+		{PkgPath: "parser", Target: "FuzzLex",
+			LastRun: now.Add(-25 * time.Hour)},
+		{PkgPath: "tree", Target: "FuzzBuild",
+			LastRun: now.Add(-time.Hour)},
+	}
+	assert.NoError(t, saveTargetStatuses(filepath.Join(reportDir,
+		"status.json"), statuses))
+
+	cfg := &Config{
+		Project: Project{ReportDir: reportDir},
+		Fuzz: Fuzz{
+			PlateauWindow:        3,
+			PlateauRerunInterval: 24 * time.Hour,
+		},
+	}
+	focus, err := newFocusPolicy(cfg, now)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		pkg      string
+		target   string
+		deferred bool
+	}{
+		// Plateaued and ran recently.
+		{"parser", "FuzzEval", true},
+
+		// Plateaued, but due for a rerun.
+		{"parser", "FuzzLex", false},
+
+		// Still gaining coverage.
+		{"tree", "FuzzBuild", false},
+
+		// Without any history yet.
+		{"tree", "FuzzNew", false},
+	}
+
+	for _, tc := range tests {
+		deferred, err := focus.deferred(tc.pkg, tc.target)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.deferred, deferred, tc.target)
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/iterator"
+)
+
+// GCSStore manages the corpus and reports stored in a Google Cloud Storage
+// bucket, like S3Store does for S3. It stores the corpus as a single archive,
+// without the sharding, versioning and quarantine supported by S3Store.
+type GCSStore struct {
+	corpusArchiver
+
+	ctx           context.Context
+	client        *storage.Client
+	bucket        string
+	corpusKey     string
+	reportDir     string
+	mergeStrategy string
+	srcDir        string
+
+	// bandwidth throttles the uploads, and the downloads if
+	// limitDownload is set, or is nil if the bandwidth is unlimited.
+	bandwidth     *rate.Limiter
+	limitDownload bool
+}
+
+// NewGCSStore constructs a GCSStore for the given context, logger, and config,
+// authenticating with the application default credentials.
+func NewGCSStore(ctx context.Context, logger *slog.Logger,
+	cfg *Config) (*GCSStore, error) {
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+
+	return &GCSStore{
+		corpusArchiver: newCorpusArchiver(logger, cfg),
+		ctx:            ctx,
+		client:         client,
+		bucket:         cfg.Project.GCSBucketName,
+		corpusKey:      cfg.Project.CorpusKey,
+		reportDir:      cfg.Project.ReportDir,
+		mergeStrategy:  cfg.Project.CorpusMergeStrategy,
+		srcDir:         cfg.Project.SrcDir,
+		bandwidth:      newBandwidthLimiter(cfg.Project.BandwidthLimit),
+		limitDownload:  cfg.Project.LimitDownloadBandwidth,
+	}, nil
+}
+
+// downloadObject downloads the object stored under key in the bucket to the
+// local file at outPath. If the object does not exist, it returns true with a
+// nil error, indicating that the process should continue with an empty data.
+func (gcs *GCSStore) downloadObject(outPath, key string) (bool, error) {
+	reader, err := gcs.client.Bucket(gcs.bucket).Object(key).
+		NewReader(gcs.ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("downloading gs://%s/%s: %w",
+			gcs.bucket, key, err)
+	}
+	defer func() {
+		if err := reader.Close(); err != nil {
+			gcs.logger.Error("Failed to close object reader",
+				"error", err)
+		}
+	}()
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return false, fmt.Errorf("creating local file: %w", err)
+	}
+	defer func() {
+		if err := outFile.Close(); err != nil {
+			gcs.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	var r io.Reader = reader
+	if gcs.bandwidth != nil && gcs.limitDownload {
+		r = &rateLimitedReader{
+			ctx:     gcs.ctx,
+			r:       reader,
+			limiter: gcs.bandwidth,
+		}
+	}
+
+	n, err := io.Copy(outFile, r)
+	if err != nil {
+		return false, fmt.Errorf("downloading gs://%s/%s: %w",
+			gcs.bucket, key, err)
+	}
+
+	gcs.logger.Info("Downloaded object", "bytes", n, "gcsBucket",
+		gcs.bucket, "key", key, "destPath", outPath)
+
+	return false, nil
+}
+
+// uploadObject uploads the content read from fileReader to the bucket at the
+// specified key, setting its content type to contentType, and adds the
+// provided metadata (if any). The content is streamed in chunks, so it is
+// never held in memory as a whole. The upload is throttled if the bandwidth is
+// limited.
+func (gcs *GCSStore) uploadObject(fileReader io.Reader, key,
+	contentType string, metadata map[string]string) error {
+
+	if gcs.bandwidth != nil {
+		fileReader = &rateLimitedReader{
+			ctx:     gcs.ctx,
+			r:       fileReader,
+			limiter: gcs.bandwidth,
+		}
+	}
+
+	// Cancelling the context aborts the upload if copying fails, rather
+	// than committing a partial object on Close.
+	ctx, cancel := context.WithCancel(gcs.ctx)
+	defer cancel()
+
+	w := gcs.client.Bucket(gcs.bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	w.Metadata = metadata
+
+	if _, err := io.Copy(w, fileReader); err != nil {
+		cancel()
+		_ = w.Close()
+		return fmt.Errorf("uploading gs://%s/%s: %w", gcs.bucket, key,
+			err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("uploading gs://%s/%s: %w", gcs.bucket, key,
+			err)
+	}
+
+	gcs.logger.Info("Uploaded object to GCS", "gcsBucket", gcs.bucket,
+		"key", key)
+
+	return nil
+}
+
+// getLastMinimizedTime returns the "last-minimized" timestamp from the corpus
+// object's metadata. If the object does not exist or the "last-minimized"
+// metadata is missing or empty, it returns the current time.
+func (gcs *GCSStore) getLastMinimizedTime() (time.Time, error) {
+	attrs, err := gcs.client.Bucket(gcs.bucket).Object(gcs.corpusKey).
+		Attrs(gcs.ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return time.Now(), nil
+		}
+		return time.Time{}, fmt.Errorf("fetching metadata for key %q: "+
+			"%w", gcs.corpusKey, err)
+	}
+
+	lastMinStr, ok := attrs.Metadata["last-minimized"]
+	if !ok || lastMinStr == "" {
+		return time.Now(), nil
+	}
+
+	lastMinTime, err := time.Parse(time.RFC3339, lastMinStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last-minimized "+
+			"metadata for key %q: %w", gcs.corpusKey, err)
+	}
+
+	return lastMinTime, nil
+}
+
+// snapshotCorpusBaseline always fails, as corpus quarantine is not supported
+// with GCS, which the config validation already ensures.
+func (gcs *GCSStore) snapshotCorpusBaseline() error {
+	return errors.New("corpus quarantine is not supported with the gcs " +
+		"storage backend")
+}
+
+// downloadCorpus downloads the corpus archive from GCS and extracts it into
+// the local corpusDir. Returns true if the corpus is empty.
+func (gcs *GCSStore) downloadCorpus() (bool, error) {
+	empty, err := gcs.fetchArchive(gcs.corpusKey, gcs.downloadObject)
+	if err != nil {
+		return false, err
+	}
+
+	if empty {
+		gcs.logger.Info("Corpus object not found. Starting with empty "+
+			"corpus.", "gcsBucket", gcs.bucket, "key",
+			gcs.corpusKey)
+	}
+
+	return empty, nil
+}
+
+// downloadCorpusAndReports downloads the corpus from GCS, merges any corpus
+// already present locally into it according to the merge strategy, and then
+// downloads any associated reports (unless the downloaded corpus is empty).
+func (gcs *GCSStore) downloadCorpusAndReports() error {
+	empty, err := gcs.downloadMergedCorpus(gcs.ctx, gcs.srcDir,
+		gcs.mergeStrategy, gcs.downloadCorpus)
+	if err != nil {
+		return err
+	}
+
+	if empty {
+		return nil
+	}
+
+	if err := gcs.downloadReports(); err != nil {
+		return fmt.Errorf("reports download failed: %w", err)
+	}
+
+	gcs.logger.Info("Successfully downloaded reports", "gcsBucket",
+		gcs.bucket)
+
+	return nil
+}
+
+// downloadReports downloads all JSON report files from the bucket, saving each
+// under the reports directory.
+func (gcs *GCSStore) downloadReports() error {
+	it := gcs.client.Bucket(gcs.bucket).Objects(gcs.ctx, nil)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		// Skip any file that does not have a .json extension
+		if filepath.Ext(attrs.Name) != ".json" {
+			continue
+		}
+
+		localPath := filepath.Join(gcs.reportDir, attrs.Name)
+		err = EnsureDirExists(filepath.Dir(localPath))
+		if err != nil {
+			return fmt.Errorf("creating report directory: %w", err)
+		}
+
+		_, err = gcs.downloadObject(localPath, attrs.Name)
+		if err != nil {
+			return fmt.Errorf("download report %q: %w", attrs.Name,
+				err)
+		}
+	}
+}
+
+// uploadCorpusAndReports streams corpusDir as a corpus archive, uploads it to
+// GCS unless uploadCorpus is false, and then uploads any generated coverage
+// reports. Like with S3Store, the corpus and the reports are uploaded
+// independently.
+func (gcs *GCSStore) uploadCorpusAndReports(lastMinTime time.Time,
+	uploadCorpus bool) error {
+
+	var corpusErr error
+	if uploadCorpus {
+		corpusErr = gcs.streamArchive(gcs.corpusDir, gcs.corpusKey,
+			lastMinTime, gcs.uploadObject)
+	}
+	if corpusErr != nil {
+		corpusErr = fmt.Errorf("corpus upload failed: %w", corpusErr)
+	}
+
+	reportsErr := gcs.uploadReports()
+	if reportsErr != nil {
+		reportsErr = fmt.Errorf("reports upload failed: %w",
+			reportsErr)
+	}
+
+	return combineUploadErrors(gcs.logger.With("gcsBucket", gcs.bucket),
+		corpusErr, reportsErr)
+}
+
+// uploadReports uploads the files of the local reportDir to GCS, using each
+// file's path relative to reportDir as the object name, with the appropriate
+// content type. Files whose content is unchanged from the object already
+// stored under their name are skipped.
+func (gcs *GCSStore) uploadReports() error {
+	digests, err := gcs.listObjectMD5s()
+	if err != nil {
+		return err
+	}
+
+	keys, skipped, err := changedReports(gcs.reportDir, digests)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := gcs.uploadReport(key); err != nil {
+			return err
+		}
+	}
+
+	gcs.logger.Info("Uploaded reports", "gcsBucket", gcs.bucket,
+		"uploaded", len(keys), "unchanged", skipped)
+
+	return nil
+}
+
+// uploadReport uploads the report file stored under the given key, relative to
+// reportDir, with the appropriate content type.
+func (gcs *GCSStore) uploadReport(key string) error {
+	path := filepath.Join(gcs.reportDir, filepath.FromSlash(key))
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open report %q: %w", path, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			gcs.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	contentType := detectContentType(path)
+	if err := gcs.uploadObject(file, key, contentType, nil); err != nil {
+		return fmt.Errorf("upload report %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// listObjectMD5s returns the hex-encoded MD5 digests of all objects in the
+// bucket, keyed by their name, in the form of the ETags compared by
+// changedReports. Composite objects, which have no MD5 digest, are left out, so
+// the matching reports are always uploaded.
+func (gcs *GCSStore) listObjectMD5s() (map[string]string, error) {
+	digests := make(map[string]string)
+	it := gcs.client.Bucket(gcs.bucket).Objects(gcs.ctx, nil)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return digests, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w",
+				err)
+		}
+
+		if len(attrs.MD5) > 0 {
+			digests[attrs.Name] = hex.EncodeToString(attrs.MD5)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+)
+
+// fakeGCSObject is an object stored by fakeGCS.
+type fakeGCSObject struct {
+	content     []byte
+	contentType string
+	metadata    map[string]string
+}
+
+// fakeGCS is an in-memory fake of the subset of the GCS JSON and XML APIs used
+// by GCSStore, holding the objects of a single bucket.
+type fakeGCS struct {
+	mu       sync.Mutex
+	objects  map[string]fakeGCSObject
+	uploaded []string
+}
+
+// attrs returns the JSON resource describing the object with the given name.
+func (f *fakeGCS) attrs(name string, obj fakeGCSObject) map[string]any {
+	sum := md5.Sum(obj.content)
+	return map[string]any{
+		"bucket":      "bucket",
+		"name":        name,
+		"size":        fmt.Sprint(len(obj.content)),
+		"generation":  "1",
+		"contentType": obj.contentType,
+		"md5Hash":     sum[:],
+		"metadata":    obj.metadata,
+	}
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const (
+		listPath   = "/storage/v1/b/bucket/o"
+		uploadPath = "/upload/storage/v1/b/bucket/o"
+	)
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"code":404,"message":"Not Found"}}`)
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == uploadPath:
+		f.upload(w, r)
+
+	case r.URL.Path == listPath:
+		items := []map[string]any{}
+		for name, obj := range f.objects {
+			items = append(items, f.attrs(name, obj))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+
+	case strings.HasPrefix(r.URL.Path, listPath+"/"):
+		name := strings.TrimPrefix(r.URL.Path, listPath+"/")
+		obj, ok := f.objects[name]
+		if !ok {
+			notFound()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.attrs(name, obj))
+
+	case strings.HasPrefix(r.URL.Path, "/bucket/"):
+		obj, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/bucket/")]
+		if !ok {
+			notFound()
+			return
+		}
+		_, _ = w.Write(obj.content)
+
+	default:
+		notFound()
+	}
+}
+
+// upload stores the object of a multipart upload, made of the JSON resource
+// describing it followed by its content.
+func (f *fakeGCS) upload(w http.ResponseWriter, r *http.Request) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+
+	var parts [][]byte
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(part)
+		parts = append(parts, data)
+	}
+	if len(parts) != 2 {
+		http.Error(w, "expected two parts", http.StatusBadRequest)
+		return
+	}
+
+	var resource struct {
+		Name        string            `json:"name"`
+		ContentType string            `json:"contentType"`
+		Metadata    map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(parts[0], &resource); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	obj := fakeGCSObject{
+		content:     parts[1],
+		contentType: resource.ContentType,
+		metadata:    resource.Metadata,
+	}
+	f.objects[resource.Name] = obj
+	f.uploaded = append(f.uploaded, resource.Name)
+	_ = json.NewEncoder(w).Encode(f.attrs(resource.Name, obj))
+}
+
+// TestGCSStoreRoundTrip verifies that the corpus and reports uploaded to GCS,
+// along with the last minimization time, are downloaded back, and that the
+// reports unchanged since the last upload are not uploaded again.
+func TestGCSStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeGCS{objects: make(map[string]fakeGCSObject)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := storage.NewClient(ctx,
+		option.WithEndpoint(server.URL+"/storage/v1/"),
+		option.WithoutAuthentication())
+	assert.NoError(t, err)
+
+	newStore := func() *GCSStore {
+		corpusDir := filepath.Join(t.TempDir(), "corpus")
+
+		return &GCSStore{
+			corpusArchiver: corpusArchiver{
+				logger:        slog.New(slog.DiscardHandler),
+				corpusDir:     corpusDir,
+				archiveFormat: ArchiveFormatZip,
+			},
+			ctx:           ctx,
+			client:        client,
+			bucket:        "bucket",
+			corpusKey:     "repo_corpus.zip",
+			reportDir:     t.TempDir(),
+			mergeStrategy: CorpusMergeUnion,
+		}
+	}
+
+	// Without a corpus object, the corpus has never been minimized.
+	gcs := newStore()
+	lastMinTime, err := gcs.getLastMinimizedTime()
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), lastMinTime, time.Minute)
+
+	writeFiles(t, gcs.corpusDir, map[string]string{
+		"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+	})
+	writeFiles(t, gcs.reportDir, map[string]string{
+		"state.json": `{"state":1}`,
+		"index.html": "<html></html>",
+	})
+	minimized := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, gcs.uploadCorpusAndReports(minimized, true))
+	assert.ElementsMatch(t, []string{"repo_corpus.zip", "state.json",
+		"index.html"}, fake.uploaded)
+	assert.Equal(t, "text/html; charset=utf-8",
+		fake.objects["index.html"].contentType)
+
+	lastMinTime, err = gcs.getLastMinimizedTime()
+	assert.NoError(t, err)
+	assert.True(t, minimized.Equal(lastMinTime))
+
+	// Only the corpus and the changed report are uploaded again.
+	fake.uploaded = nil
+	writeFiles(t, gcs.reportDir, map[string]string{
+		"state.json": `{"state":2}`,
+	})
+	assert.NoError(t, gcs.uploadCorpusAndReports(minimized, true))
+	assert.ElementsMatch(t, []string{"repo_corpus.zip", "state.json"},
+		fake.uploaded)
+
+	// A fresh store gets back the corpus and the JSON reports.
+	gcs = newStore()
+	assert.NoError(t, gcs.downloadCorpusAndReports())
+
+	seed, err := os.ReadFile(filepath.Join(gcs.corpusDir, "pkg",
+		"testdata", "fuzz", "FuzzFoo", "seed"))
+	assert.NoError(t, err)
+	assert.Equal(t, "input", string(seed))
+
+	state, err := os.ReadFile(filepath.Join(gcs.reportDir, "state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"state":2}`, string(state))
+	assert.NoFileExists(t, filepath.Join(gcs.reportDir, "index.html"))
+}
@@ -2,26 +2,25 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/client"
 	"github.com/google/go-github/v72/github"
 	"golang.org/x/oauth2"
 )
 
-// GitHubRepo encapsulates the context, configuration, clients, and logger
-// for operating on a specific GitHub repository.
+// GitHubRepo is the crash tracker reporting crashes as issues of a specific
+// GitHub repository.
 type GitHubRepo struct {
-	ctx    context.Context
-	logger *slog.Logger
+	crashReporter
+
 	client *github.Client
-	cli    *client.Client
-	cfg    *Config
 	owner  string
 	repo   string
 }
@@ -31,31 +30,196 @@ type GitHubRepo struct {
 func NewGitHubRepo(ctx context.Context, logger *slog.Logger, cli *client.Client,
 	cfg *Config) (*GitHubRepo, error) {
 
-	u, err := url.Parse(cfg.Fuzz.CrashRepo)
+	owner, repo, token, err := parseCrashRepo(cfg.Fuzz.CrashRepo)
 	if err != nil {
-		return nil, fmt.Errorf("invalid repository URL: %w", err)
+		return nil, err
+	}
+
+	gh := &GitHubRepo{
+		client: createGitHubClient(ctx, token),
+		owner:  owner,
+		repo:   repo,
+	}
+	gh.crashReporter = newCrashReporter(ctx, logger, cli, cfg, gh)
+
+	return gh, nil
+}
+
+// checkGitHubAccess verifies that the token of the GitHub crash repository can
+// write its issues, so a misconfigured token is reported at startup rather than
+// when the first crash is reported, possibly hours later.
+func checkGitHubAccess(ctx context.Context, logger *slog.Logger,
+	cfg *Config) error {
+
+	ctx, cancel := context.WithTimeout(ctx, TrackerCheckTimeout)
+	defer cancel()
+
+	gh, err := NewGitHubRepo(ctx, logger, nil, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := gh.checkIssueAccess(); err != nil {
+		return fmt.Errorf("%w; fix the token in fuzz.crash-repo, or "+
+			"set fuzz.skip-tracker-check to skip this check", err)
+	}
+
+	return nil
+}
+
+// checkIssueAccess verifies that the repository can be read with the token,
+// has issues enabled, and that the token can write its issues. GitHub cannot
+// be asked whether an issue could be created without creating it, so this
+// checks the scopes of classic tokens, listed in the X-OAuth-Scopes header,
+// and the permissions on the repository of the token's user, which must at
+// least be allowed to triage issues to close them.
+func (gh *GitHubRepo) checkIssueAccess() error {
+	repo, resp, err := gh.client.Repositories.Get(gh.ctx, gh.owner,
+		gh.repo)
+	if err != nil {
+		switch {
+		case resp != nil && resp.StatusCode == http.StatusUnauthorized:
+			return fmt.Errorf("GitHub rejected the token of crash "+
+				"repository %s/%s as invalid or expired",
+				gh.owner, gh.repo)
+
+		case resp != nil && resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("crash repository %s/%s not found "+
+				"with the token; classic tokens need the repo "+
+				"scope (public_repo for public repositories), "+
+				"and fine-grained tokens access to the "+
+				"repository with read and write permission on "+
+				"issues", gh.owner, gh.repo)
+		}
+
+		return fmt.Errorf("failed to access crash repository %s/%s: "+
+			"%w", gh.owner, gh.repo, err)
+	}
+
+	if !repo.GetHasIssues() {
+		return fmt.Errorf("issues are disabled in crash repository "+
+			"%s/%s", gh.owner, gh.repo)
+	}
+
+	// Only classic tokens list their scopes, and creating issues requires
+	// the repo scope, or public_repo for public repositories.
+	header := http.CanonicalHeaderKey("X-OAuth-Scopes")
+	if _, ok := resp.Header[header]; ok {
+		scopes := make(map[string]bool)
+		for _, scope := range strings.Split(resp.Header.Get(header),
+			",") {
+
+			scopes[strings.TrimSpace(scope)] = true
+		}
+
+		if !scopes["repo"] &&
+			(repo.GetPrivate() || !scopes["public_repo"]) {
+
+			required := "repo"
+			if !repo.GetPrivate() {
+				required = "repo or public_repo"
+			}
+			return fmt.Errorf("token of crash repository %s/%s "+
+				"lacks the %s scope required to write issues, "+
+				"having scopes %q", gh.owner, gh.repo, required,
+				resp.Header.Get(header))
+		}
+	}
+
+	perms := repo.GetPermissions()
+	if len(perms) > 0 && !perms["admin"] && !perms["maintain"] &&
+		!perms["push"] && !perms["triage"] {
+
+		return fmt.Errorf("token of crash repository %s/%s lacks the "+
+			"triage permission required to close and reopen "+
+			"issues", gh.owner, gh.repo)
+	}
+
+	gh.logger.Info("Verified write access to crash repository issues",
+		"owner", gh.owner, "repo", gh.repo)
+
+	return nil
+}
+
+// newGitHubIssue converts a GitHub issue to a trackerIssue.
+func newGitHubIssue(issue *github.Issue) *trackerIssue {
+	return &trackerIssue{
+		number:   issue.GetNumber(),
+		title:    issue.GetTitle(),
+		body:     issue.GetBody(),
+		url:      issue.GetHTMLURL(),
+		closedAt: issue.GetClosedAt().Time,
+	}
+}
+
+// parseCrashRepo parses the owner, repository name, and access token from the
+// URL of the repository where crash issues are created. Returns an error if the
+// URL is invalid or carries no token.
+func parseCrashRepo(crashRepo string) (string, string, string, error) {
+	u, err := url.Parse(crashRepo)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid repository URL: %w", err)
 	}
 
 	owner, repo, err := extractOwnerRepo(u)
 	if err != nil {
-		return nil, err
+		return "", "", "", err
 	}
 
 	token := extractToken(u)
 	if token == "" {
-		return nil, fmt.Errorf("authentication token not provided in "+
-			"repository URL: %s", cfg.Fuzz.CrashRepo)
+		return "", "", "", fmt.Errorf("authentication token not "+
+			"provided in repository URL: %s",
+			SanitizeURL(crashRepo))
 	}
 
-	return &GitHubRepo{
-		ctx:    ctx,
-		logger: logger,
-		client: createGitHubClient(ctx, token),
-		cli:    cli,
-		cfg:    cfg,
-		owner:  owner,
-		repo:   repo,
-	}, nil
+	return owner, repo, token, nil
+}
+
+// repoOwner returns the host and owner of the repository at the given URL,
+// e.g. "github.com/owner", in lower case since GitHub owners are
+// case-insensitive.
+func repoOwner(repoURL string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL: %w", err)
+	}
+
+	owner, _, err := extractOwnerRepo(u)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(u.Host + "/" + owner), nil
+}
+
+// warnCrossOwnerReporting logs a warning if crash issues are created in a
+// repository of another owner than the fuzzed project, e.g. when fuzzing a
+// mirror and reporting to upstream, since the crashes (including their failing
+// inputs) are then disclosed to that owner. Crashes are always reproduced from
+// the fuzzed project, so this only affects where they are reported.
+func warnCrossOwnerReporting(logger *slog.Logger, cfg *Config) {
+	srcOwner, err := repoOwner(cfg.Project.SrcRepo)
+	if err != nil {
+		logger.Info("Unable to determine owner of project repository; "+
+			"skipping cross-repository reporting check", "error",
+			err)
+		return
+	}
+
+	crashOwner, err := repoOwner(cfg.Fuzz.CrashRepo)
+	if err != nil {
+		logger.Info("Unable to determine owner of crash repository; "+
+			"skipping cross-repository reporting check", "error",
+			err)
+		return
+	}
+
+	if srcOwner != crashOwner {
+		logger.Warn("Crash issues are reported to a repository of "+
+			"another owner than the fuzzed project", "srcOwner",
+			srcOwner, "crashOwner", crashOwner)
+	}
 }
 
 // extractToken retrieves the access token from the repository URL, if provided.
@@ -87,16 +251,25 @@ func createGitHubClient(ctx context.Context, token string) *github.Client {
 	return github.NewClient(tc)
 }
 
-// listOpenIssues retrieves all open GitHub issues in the repository that match
-// the exact title.
-func (gh *GitHubRepo) listOpenIssues(title string) ([]*github.Issue, error) {
+// listIssues searches for issues in the given state (IssueStateOpen or
+// IssueStateClosed) whose title matches the given title.
+func (gh *GitHubRepo) listIssues(title, state string) ([]*trackerIssue,
+	error) {
+
 	gh.logger.Info("Listing GitHub issues", "owner", gh.owner, "repo",
-		gh.repo, "title", title)
+		gh.repo, "title", title, "state", state)
 
 	// Build a search query that restricts to this repo and the exact title
 	// Perform the search
-	query := fmt.Sprintf(`repo:%s/%s is:issue is:open "%s"`, gh.owner,
-		gh.repo, title)
+	query := fmt.Sprintf(`repo:%s/%s is:issue is:%s "%s"`, gh.owner,
+		gh.repo, state, title)
+
+	// Only search the issues carrying all the configured labels, so the
+	// issues created by others are never touched.
+	for _, label := range gh.cfg.Fuzz.CrashIssueLabels {
+		query += fmt.Sprintf(` label:"%s"`, label)
+	}
+
 	results, _, err := gh.client.Search.Issues(gh.ctx, query,
 		&github.SearchOptions{})
 	if err != nil {
@@ -105,234 +278,242 @@ func (gh *GitHubRepo) listOpenIssues(title string) ([]*github.Issue, error) {
 		return nil, err
 	}
 
-	return results.Issues, nil
-}
-
-// issueExists checks whether an issue with the exact title already exists.
-func (gh *GitHubRepo) issueExists(title string) (bool, error) {
-	gh.logger.Info("Searching for existing issue", "owner", gh.owner,
-		"repo", gh.repo, "title", title)
-
-	issues, err := gh.listOpenIssues(title)
-	if err != nil {
-		gh.logger.Error("GitHub issue search failed", "err", err)
-		return false, err
-	}
-
-	if len(issues) > 0 {
-		gh.logger.Info("Issue already exists", "url",
-			issues[0].GetHTMLURL())
-		return true, nil
+	// The search matches the title as a phrase anywhere in the issue, so
+	// a crash in "x/parser/FuzzFoo" would also be returned when searching
+	// for "parser/FuzzFoo". Filter the results down to exact matches, so
+	// same-named targets in different packages never share issues.
+	var issues []*trackerIssue
+	for _, issue := range results.Issues {
+		if issueTitleMatches(issue.GetTitle(), title) {
+			issues = append(issues, newGitHubIssue(issue))
+		}
 	}
 
-	return false, nil
+	return issues, nil
 }
 
-// createIssue opens a new GitHub issue with the given title and body.
-func (gh *GitHubRepo) createIssue(title, body string) error {
+// createIssue opens a new GitHub issue with the given title and body, carrying
+// the configured labels and assigned to the configured assignees, along with
+// the member of the assignee rotation whose turn it is, if any. Assignment is
+// best-effort: if GitHub rejects the assignees, e.g. because a user does not
+// exist or cannot be assigned, the issue is created unassigned with a warning.
+func (gh *GitHubRepo) createIssue(title, body string) (*trackerIssue, error) {
 	gh.logger.Info("Creating new issue", "owner", gh.owner, "repo", gh.repo,
 		"title", title)
 
 	req := &github.IssueRequest{Title: &title, Body: &body}
-	issue, _, err := gh.client.Issues.Create(gh.ctx, gh.owner, gh.repo, req)
+	if labels := gh.cfg.Fuzz.CrashIssueLabels; len(labels) > 0 {
+		req.Labels = &labels
+	}
+	if assignees := gh.issueAssignees(); len(assignees) > 0 {
+		req.Assignees = &assignees
+	}
+
+	create := func() (*github.Issue, error) {
+		var issue *github.Issue
+		op := func() error {
+			var err error
+			issue, _, err = gh.client.Issues.Create(gh.ctx,
+				gh.owner, gh.repo, req)
+			return err
+		}
+		err := gh.retryOnSecondaryRateLimit("create issue", op)
+		return issue, err
+	}
+
+	issue, err := create()
+	var errResp *github.ErrorResponse
+	if err != nil && req.Assignees != nil && errors.As(err, &errResp) &&
+		errResp.Response.StatusCode == http.StatusUnprocessableEntity {
+
+		gh.logger.Warn("GitHub rejected issue assignees; creating "+
+			"issue unassigned", "assignees", *req.Assignees,
+			"error", err)
+		req.Assignees = nil
+		issue, err = create()
+	}
 	if err != nil {
 		gh.logger.Error("Issue creation failed", "err", err)
-		return err
+		return nil, err
 	}
 
 	gh.logger.Info("Issue created successfully", "url", issue.GetHTMLURL())
-	return nil
+	return newGitHubIssue(issue), nil
 }
 
-// closeIssue closes an existing GitHub issue by its number.
-func (gh *GitHubRepo) closeIssue(number int) error {
-	gh.logger.Info("Closing issue", "owner", gh.owner, "repo", gh.repo,
-		"issueNumber", number)
+// createComment posts a comment on the issue with the given number, retrying
+// on GitHub's secondary rate limit.
+func (gh *GitHubRepo) createComment(number int,
+	comment *github.IssueComment) error {
 
-	// Add a comment before closing the issue
-	closeIssueComment := fmt.Sprintf("Fuzz crash no longer reproducible, "+
-		"closing the issue.\n%s", waterMark)
-	comment := &github.IssueComment{Body: &closeIssueComment}
-
-	_, _, err := gh.client.Issues.CreateComment(gh.ctx, gh.owner, gh.repo,
-		number, comment)
-	if err != nil {
-		gh.logger.Error("Failed to add comment", "err", err)
+	return gh.retryOnSecondaryRateLimit("create comment", func() error {
+		_, _, err := gh.client.Issues.CreateComment(gh.ctx, gh.owner,
+			gh.repo, number, comment)
 		return err
+	})
+}
+
+// listComments returns the comments of the issue with the given number, oldest
+// first.
+func (gh *GitHubRepo) listComments(number int) ([]*trackerComment, error) {
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
-	req := &github.IssueRequest{State: github.Ptr("closed")}
-	issue, _, err := gh.client.Issues.Edit(gh.ctx, gh.owner, gh.repo,
-		number, req)
-	if err != nil {
-		gh.logger.Error("Issue closure failed", "err", err)
-		return err
+	var comments []*trackerComment
+	for {
+		results, resp, err := gh.client.Issues.ListComments(gh.ctx,
+			gh.owner, gh.repo, number, opts)
+		if err != nil {
+			gh.logger.Error("Failed to list comments",
+				"issueNumber", number, "err", err)
+			return nil, err
+		}
+
+		for _, comment := range results {
+			comments = append(comments, &trackerComment{
+				id:   comment.GetID(),
+				body: comment.GetBody(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	gh.logger.Info("Issue closed successfully", "url", issue.GetHTMLURL())
-	return nil
+// addComment posts a comment with the given body on the issue with the given
+// number.
+func (gh *GitHubRepo) addComment(number int, body string) error {
+	return gh.createComment(number, &github.IssueComment{Body: &body})
 }
 
-// handleCrash posts a GitHub issue for a new fuzz crash if one does not exist.
-// It computes a unique crash signature, formats a report, and avoids duplicates
-// by checking for an existing issue with the same title.
-func (gh *GitHubRepo) handleCrash(pkg, target string, fc fuzzCrash) error {
-	// Compute a short signature hash for the crash to help with
-	// deduplication.
-	crashHash := ComputeSHA256Short(fc.failureFileAndLine)
-
-	// Compose issue title and body
-	title := fmt.Sprintf("[fuzz/%s] Fuzzing crash in %s/%s", crashHash, pkg,
-		target)
-	body := formatCrashReport(fc.errorLogs, fc.failingInput)
-
-	// Check for existing issue to prevent duplicates
-	exists, err := gh.issueExists(title)
-	if err != nil {
-		return fmt.Errorf("checking existing GitHub issues: %w", err)
-	}
+// editComment replaces the body of the comment with the given ID, retrying on
+// GitHub's secondary rate limit. GitHub identifies comments across the whole
+// repository, so the issue number is not needed.
+func (gh *GitHubRepo) editComment(_ int, id int64, body string) error {
+	return gh.retryOnSecondaryRateLimit("edit comment", func() error {
+		_, _, err := gh.client.Issues.EditComment(gh.ctx, gh.owner,
+			gh.repo, id, &github.IssueComment{Body: &body})
+		return err
+	})
+}
 
-	if exists {
-		gh.logger.Info("Fuzz crash already reported", "signature",
-			crashHash)
-		return nil
+// getIssue returns the issue with the given number. Unlike the search results
+// of listIssues, its body is never cut short.
+func (gh *GitHubRepo) getIssue(number int) (*trackerIssue, error) {
+	issue, _, err := gh.client.Issues.Get(gh.ctx, gh.owner, gh.repo,
+		number)
+	if err != nil {
+		gh.logger.Error("Failed to get issue", "issueNumber", number,
+			"err", err)
+		return nil, err
 	}
 
-	// Create a new issue for this crash
-	if err = gh.createIssue(title, body); err != nil {
-		return fmt.Errorf("creating GitHub issue: %w", err)
-	}
+	return newGitHubIssue(issue), nil
+}
 
-	return nil
+// updateIssueBody replaces the body of the issue with the given number,
+// retrying on GitHub's secondary rate limit.
+func (gh *GitHubRepo) updateIssueBody(number int, body string) error {
+	_, err := gh.editIssue(number, &github.IssueRequest{Body: &body})
+	return err
 }
 
-// verifyAndCloseResolvedIssues checks open issues for a fuzz target, attempts
-// to reproduce them, and closes those that are no longer reproducible.
-func (gh *GitHubRepo) verifyAndCloseResolvedIssues(pkg, target string) error {
-	gh.logger.Info("Verifying open GitHub issues for fuzz target")
+// editIssue edits the issue with the given number, retrying on GitHub's
+// secondary rate limit.
+func (gh *GitHubRepo) editIssue(number int,
+	req *github.IssueRequest) (*github.Issue, error) {
 
-	// Listing GitHub issues with the exact same title
-	title := fmt.Sprintf("Fuzzing crash in %s/%s", pkg, target)
-	issues, err := gh.listOpenIssues(title)
-	if err != nil {
+	var issue *github.Issue
+	err := gh.retryOnSecondaryRateLimit("edit issue", func() error {
+		var err error
+		issue, _, err = gh.client.Issues.Edit(gh.ctx, gh.owner, gh.repo,
+			number, req)
 		return err
-	}
+	})
+	return issue, err
+}
 
-	for _, issue := range issues {
-		// Parse the failing input from the issue body
-		failingInput, err := parseIssueBody(*issue.Body)
-		if err != nil {
-			gh.logger.Info("No failing testcase found in body; "+
-				"skipping issue, possibly an unrelated issue "+
-				"with a similar title", "url",
-				issue.GetHTMLURL())
-			continue
-		}
+// retryOnSecondaryRateLimit runs the GitHub write operation op, retrying it up
+// to the configured number of times while it hits GitHub's secondary rate
+// limit, which write operations are particularly sensitive to. Each retry waits
+// for the duration given by the Retry-After header, or for an exponentially
+// increasing duration if absent.
+func (gh *GitHubRepo) retryOnSecondaryRateLimit(op string,
+	fn func() error) error {
 
-		// If the crash is due to a seed corpus input added via f.Add,
-		// this issue cannot be automatically verified and closed.
-		if failingInput == seedCorpusErrMsg {
-			gh.logger.Info("Seed corpus crash detected; manual "+
-				"verification required", "url",
-				issue.GetHTMLURL())
-			continue
-		}
+	for attempt := 0; ; attempt++ {
+		err := fn()
 
-		// Prepare directory and file for failing input
-		fuzzBinaryPath := filepath.Join(gh.cfg.Project.BinaryDir, pkg,
-			target)
-		failingDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz",
-			target)
-		if err := EnsureDirExists(failingDir); err != nil {
-			return fmt.Errorf("create testdata directory: %w", err)
-		}
+		var abuseErr *github.AbuseRateLimitError
+		if !errors.As(err, &abuseErr) ||
+			attempt >= gh.cfg.Fuzz.GitHubWriteRetries {
 
-		// Write the input to the target's testdata directory
-		fileHash := ComputeSHA256Short(failingInput)
-		failingFile := filepath.Join(failingDir, fileHash)
-		err = os.WriteFile(failingFile, []byte(failingInput), 0644)
-		if err != nil {
-			return fmt.Errorf("writing failing input to file: %w",
-				err)
+			return err
 		}
 
-		// Run the fuzz test for this input and attempt to reproduce the
-		// crash.
-		testCmd := []string{
-			fmt.Sprintf("./%s.test", target),
-			fmt.Sprintf("-test.run=%s", filepath.Join(target,
-				fileHash)),
-		}
+		wait := secondaryRateLimitWait(abuseErr.RetryAfter, attempt)
+		gh.logger.Warn("Hit GitHub secondary rate limit; retrying",
+			"operation", op, "attempt", attempt+1, "wait", wait)
 
-		// Attempt to reproduce the crash by running the test inside a
-		// container. This allows us to enforce fixed resource limits
-		// and prevent interference with other workers, for example, if
-		// one worker encounters an out-of-memory error.
-		err = gh.reproduceIssue(pkg, target, testCmd, issue)
-		if err != nil {
-			return fmt.Errorf("reproducing issue %d: %w",
-				issue.GetNumber(), err)
+		select {
+		case <-time.After(wait):
+		case <-gh.ctx.Done():
+			return err
 		}
+	}
+}
 
-		// After verification, remove the failing input file to clean up
-		// and avoid leaving any potentially problematic test data.
-		if err := os.Remove(failingFile); err != nil {
-			return fmt.Errorf("remove %q: %w", failingFile, err)
-		}
+// closeIssue closes an existing GitHub issue by its number, after commenting
+// on it with the given body.
+func (gh *GitHubRepo) closeIssue(number int, closeIssueComment string) error {
+	gh.logger.Info("Closing issue", "owner", gh.owner, "repo", gh.repo,
+		"issueNumber", number)
+
+	// Add a comment before closing the issue
+	comment := &github.IssueComment{Body: &closeIssueComment}
+
+	err := gh.createComment(number, comment)
+	if err != nil {
+		gh.logger.Error("Failed to add comment", "err", err)
+		return err
+	}
+
+	req := &github.IssueRequest{State: github.Ptr("closed")}
+	issue, err := gh.editIssue(number, req)
+	if err != nil {
+		gh.logger.Error("Issue closure failed", "err", err)
+		return err
 	}
 
+	gh.logger.Info("Issue closed successfully", "url", issue.GetHTMLURL())
 	return nil
 }
 
-// reproduceIssue attempts to reproduce a reported fuzzing issue for a given
-// package and target. It runs the fuzz test inside a Docker container using the
-// provided test command. If the issue is no longer reproducible, the associated
-// GitHub issue will be closed automatically.
-func (gh *GitHubRepo) reproduceIssue(pkg, target string, testCmd []string,
-	issue *github.Issue) error {
-
-	// Fuzzing container setup for the issue verification.
-	c := &Container{
-		ctx:    gh.ctx,
-		logger: gh.logger,
-		cli:    gh.cli,
-		fuzzBinaryPath: filepath.Join(gh.cfg.Project.BinaryDir, pkg,
-			target),
-		hostCorpusPath: filepath.Join(gh.cfg.Project.CorpusDir, pkg,
-			"testdata", "fuzz"),
-		cmd: testCmd,
-	}
-
-	// Start the container for issue verification.
-	containerID, err := c.Start()
+// reopenIssue reopens the issue with the given number after posting a comment
+// explaining why it was reopened.
+func (gh *GitHubRepo) reopenIssue(number int, reason string) error {
+	gh.logger.Info("Reopening issue", "owner", gh.owner, "repo", gh.repo,
+		"issueNumber", number)
+
+	comment := &github.IssueComment{Body: &reason}
+	err := gh.createComment(number, comment)
 	if err != nil {
-		return fmt.Errorf("failed to start verification container "+
-			"for %s/%s: %w", pkg, target, err)
+		gh.logger.Error("Failed to add comment", "err", err)
+		return err
 	}
-	defer func() {
-		if err := c.Stop(containerID); err != nil {
-			gh.logger.Error("Failed to stop container", "error",
-				err, "containerID", containerID)
-		}
-	}()
-
-	// After running the fuzzing container for this issue, if it crashes
-	// again (Wait returns an error), the crash is still reproducible and
-	// the GitHub issue is kept open. If the container exits cleanly, the
-	// crash is no longer reproducible and the corresponding GitHub issue
-	// is closed.
-	if err := c.Wait(containerID); err != nil {
-		gh.logger.Info("Crash still reproducible; keeping GitHub "+
-			"issue open", "url", issue.GetHTMLURL())
-	} else {
-		gh.logger.Info("Crash no longer reproducible; closing "+
-			"associated GitHub issue", "url", issue.GetHTMLURL())
-
-		// Close the issue if the crash is resolved
-		if err := gh.closeIssue(issue.GetNumber()); err != nil {
-			return fmt.Errorf("closing issue: %w", err)
-		}
+
+	req := &github.IssueRequest{State: github.Ptr("open")}
+	issue, err := gh.editIssue(number, req)
+	if err != nil {
+		gh.logger.Error("Issue reopening failed", "err", err)
+		return err
 	}
 
+	gh.logger.Info("Issue reopened successfully", "url",
+		issue.GetHTMLURL())
 	return nil
 }
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/xanzy/go-gitlab"
+)
+
+// GitLabRepo is the crash tracker reporting crashes as issues of a specific
+// GitLab project, on gitlab.com or a self-hosted instance.
+type GitLabRepo struct {
+	crashReporter
+
+	client *gitlab.Client
+
+	// project is the full path of the project, including its (possibly
+	// nested) groups, e.g. "group/subgroup/project".
+	project string
+}
+
+// NewGitLabRepo constructs a GitLabRepo instance by parsing the repository URL.
+// It extracts the API URL of the GitLab instance, the project path, and the
+// token for authentication.
+func NewGitLabRepo(ctx context.Context, logger *slog.Logger, cli *client.Client,
+	cfg *Config) (*GitLabRepo, error) {
+
+	apiURL, project, token, err := parseGitLabRepo(cfg.Fuzz.CrashRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	glClient, err := gitlab.NewClient(token, gitlab.WithBaseURL(apiURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitLab client: %w",
+			err)
+	}
+
+	gl := &GitLabRepo{
+		client:  glClient,
+		project: project,
+	}
+	gl.crashReporter = newCrashReporter(ctx, logger, cli, cfg, gl)
+
+	return gl, nil
+}
+
+// parseGitLabRepo parses the API URL of the GitLab instance, the project path,
+// and the access token from the URL of the project where crash issues are
+// created. Returns an error if the URL is invalid or carries no token.
+func parseGitLabRepo(crashRepo string) (string, string, string, error) {
+	u, err := url.Parse(crashRepo)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid repository URL: %w", err)
+	}
+
+	project := strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/")
+	if strings.Count(project, "/") < 1 {
+		return "", "", "", fmt.Errorf("invalid repository path")
+	}
+
+	token := extractToken(u)
+	if token == "" {
+		return "", "", "", fmt.Errorf("authentication token not "+
+			"provided in repository URL: %s",
+			SanitizeURL(crashRepo))
+	}
+
+	apiURL := fmt.Sprintf("%s://%s/api/v4", u.Scheme, u.Host)
+
+	return apiURL, project, token, nil
+}
+
+// newGitLabIssue converts a GitLab issue to a trackerIssue, identified by its
+// IID within the project.
+func newGitLabIssue(issue *gitlab.Issue) *trackerIssue {
+	ti := &trackerIssue{
+		number: issue.IID,
+		title:  issue.Title,
+		body:   issue.Description,
+		url:    issue.WebURL,
+	}
+	if issue.ClosedAt != nil {
+		ti.closedAt = *issue.ClosedAt
+	}
+
+	return ti
+}
+
+// listIssues searches for issues in the given state (IssueStateOpen or
+// IssueStateClosed) whose title matches the given title.
+func (gl *GitLabRepo) listIssues(title, state string) ([]*trackerIssue,
+	error) {
+
+	gl.logger.Info("Listing GitLab issues", "project", gl.project,
+		"title", title, "state", state)
+
+	// GitLab names the open state "opened".
+	glState := state
+	if state == IssueStateOpen {
+		glState = "opened"
+	}
+
+	opts := &gitlab.ListProjectIssuesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		State:       gitlab.Ptr(glState),
+		Search:      gitlab.Ptr(title),
+		In:          gitlab.Ptr("title"),
+	}
+
+	// Only list the issues carrying all the configured labels, so the
+	// issues created by others are never touched.
+	if labels := gl.cfg.Fuzz.CrashIssueLabels; len(labels) > 0 {
+		opts.Labels = gitlab.Ptr(gitlab.LabelOptions(labels))
+	}
+
+	var issues []*trackerIssue
+	for {
+		results, resp, err := gl.client.Issues.ListProjectIssues(
+			gl.project, opts, gitlab.WithContext(gl.ctx))
+		if err != nil {
+			gl.logger.Error("Failed to list GitLab issues", "err",
+				err)
+			return nil, err
+		}
+
+		// Like GitHub's search, GitLab's matches the title anywhere,
+		// so filter the results down to exact matches.
+		for _, issue := range results {
+			if issueTitleMatches(issue.Title, title) {
+				issues = append(issues, newGitLabIssue(issue))
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// createIssue opens a new GitLab issue with the given title and body, carrying
+// the configured labels and assigned to those of the configured assignees, and
+// of the member of the assignee rotation whose turn it is, if any, that exist.
+func (gl *GitLabRepo) createIssue(title, body string) (*trackerIssue, error) {
+	gl.logger.Info("Creating new issue", "project", gl.project, "title",
+		title)
+
+	opts := &gitlab.CreateIssueOptions{
+		Title:       &title,
+		Description: &body,
+	}
+	if labels := gl.cfg.Fuzz.CrashIssueLabels; len(labels) > 0 {
+		opts.Labels = gitlab.Ptr(gitlab.LabelOptions(labels))
+	}
+	if usernames := gl.issueAssignees(); len(usernames) > 0 {
+		opts.AssigneeIDs = gitlab.Ptr(gl.assigneeIDs(usernames))
+	}
+
+	issue, _, err := gl.client.Issues.CreateIssue(gl.project, opts,
+		gitlab.WithContext(gl.ctx))
+	if err != nil {
+		gl.logger.Error("Issue creation failed", "err", err)
+		return nil, err
+	}
+
+	gl.logger.Info("Issue created successfully", "url", issue.WebURL)
+	return newGitLabIssue(issue), nil
+}
+
+// assigneeIDs returns the IDs of the GitLab users with the given usernames, by
+// which GitLab assigns issues. Assignment is best-effort, so a user that does
+// not exist or cannot be looked up is left out with a warning.
+func (gl *GitLabRepo) assigneeIDs(usernames []string) []int {
+	var ids []int
+	for _, username := range usernames {
+		users, _, err := gl.client.Users.ListUsers(
+			&gitlab.ListUsersOptions{Username: &username},
+			gitlab.WithContext(gl.ctx))
+		if err != nil {
+			gl.logger.Warn("Failed to look up issue assignee; "+
+				"leaving it out", "assignee", username,
+				"error", err)
+			continue
+		}
+		if len(users) == 0 {
+			gl.logger.Warn("Issue assignee not found; leaving it "+
+				"out", "assignee", username)
+			continue
+		}
+
+		ids = append(ids, users[0].ID)
+	}
+
+	return ids
+}
+
+// closeIssue closes an existing GitLab issue by its IID, after commenting on it
+// with the given body.
+func (gl *GitLabRepo) closeIssue(number int, closeIssueComment string) error {
+	gl.logger.Info("Closing issue", "project", gl.project, "issueNumber",
+		number)
+
+	return gl.commentAndUpdateState(number, closeIssueComment, "close")
+}
+
+// reopenIssue reopens the GitLab issue with the given IID after posting a
+// comment explaining why it was reopened.
+func (gl *GitLabRepo) reopenIssue(number int, reason string) error {
+	gl.logger.Info("Reopening issue", "project", gl.project,
+		"issueNumber", number)
+
+	return gl.commentAndUpdateState(number, reason, "reopen")
+}
+
+// listComments returns the notes of the GitLab issue with the given IID, oldest
+// first.
+func (gl *GitLabRepo) listComments(number int) ([]*trackerComment, error) {
+	opts := &gitlab.ListIssueNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+	}
+
+	var comments []*trackerComment
+	for {
+		notes, resp, err := gl.client.Notes.ListIssueNotes(gl.project,
+			number, opts, gitlab.WithContext(gl.ctx))
+		if err != nil {
+			gl.logger.Error("Failed to list comments",
+				"issueNumber", number, "err", err)
+			return nil, err
+		}
+
+		for _, note := range notes {
+			comments = append(comments, &trackerComment{
+				id:   int64(note.ID),
+				body: note.Body,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			return comments, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// addComment posts a note with the given body on the GitLab issue with the
+// given IID.
+func (gl *GitLabRepo) addComment(number int, body string) error {
+	_, _, err := gl.client.Notes.CreateIssueNote(gl.project, number,
+		&gitlab.CreateIssueNoteOptions{Body: &body},
+		gitlab.WithContext(gl.ctx))
+	return err
+}
+
+// editComment replaces the body of the note with the given ID on the GitLab
+// issue with the given IID.
+func (gl *GitLabRepo) editComment(number int, id int64, body string) error {
+	_, _, err := gl.client.Notes.UpdateIssueNote(gl.project, number,
+		int(id), &gitlab.UpdateIssueNoteOptions{Body: &body},
+		gitlab.WithContext(gl.ctx))
+	return err
+}
+
+// getIssue returns the GitLab issue with the given IID, with its full
+// description.
+func (gl *GitLabRepo) getIssue(number int) (*trackerIssue, error) {
+	issue, _, err := gl.client.Issues.GetIssue(gl.project, number,
+		gitlab.WithContext(gl.ctx))
+	if err != nil {
+		gl.logger.Error("Failed to get issue", "issueNumber", number,
+			"err", err)
+		return nil, err
+	}
+
+	return newGitLabIssue(issue), nil
+}
+
+// updateIssueBody replaces the description of the GitLab issue with the given
+// IID.
+func (gl *GitLabRepo) updateIssueBody(number int, body string) error {
+	_, _, err := gl.client.Issues.UpdateIssue(gl.project, number,
+		&gitlab.UpdateIssueOptions{Description: &body},
+		gitlab.WithContext(gl.ctx))
+	return err
+}
+
+// commentAndUpdateState posts the given comment on the GitLab issue with the
+// given IID, and then applies the given state event ("close" or "reopen") to
+// it.
+func (gl *GitLabRepo) commentAndUpdateState(number int, comment,
+	stateEvent string) error {
+
+	if err := gl.addComment(number, comment); err != nil {
+		gl.logger.Error("Failed to add comment", "err", err)
+		return err
+	}
+
+	issue, _, err := gl.client.Issues.UpdateIssue(gl.project, number,
+		&gitlab.UpdateIssueOptions{StateEvent: &stateEvent},
+		gitlab.WithContext(gl.ctx))
+	if err != nil {
+		gl.logger.Error("Issue update failed", "stateEvent",
+			stateEvent, "err", err)
+		return err
+	}
+
+	gl.logger.Info("Issue updated successfully", "stateEvent", stateEvent,
+		"url", issue.WebURL)
+	return nil
+}
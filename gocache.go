@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// buildCacheFile is a file of the Go build cache, along with its size and last
+// use time.
+type buildCacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// pruneBuildCache bounds the size of the Go build cache (GOCACHE) used on the
+// host to build the fuzz binaries and measure coverage, which persists across
+// cycles, to maxSize bytes. It must only run between cycles, while no build
+// uses the cache.
+func pruneBuildCache(ctx context.Context, logger *slog.Logger,
+	maxSize int64) error {
+
+	output, err := runGoCommand(ctx, "", []string{"env", "GOCACHE"})
+	if err != nil {
+		return fmt.Errorf("locating Go build cache: %w", err)
+	}
+
+	cacheDir := strings.TrimSpace(output)
+	if cacheDir == "" || cacheDir == "off" {
+		logger.Info("Go build cache disabled; nothing to prune")
+		return nil
+	}
+
+	return evictBuildCache(logger, cacheDir, maxSize)
+}
+
+// evictBuildCache removes the least recently used entries of the Go build cache
+// in cacheDir until its size is at most maxSize bytes. The go command refreshes
+// the modification time of the cache entries it uses (at most once an hour),
+// so it orders the entries by last use. Only the entries stored in the cache's
+// subdirectories are evicted, keeping the files describing the cache itself.
+// A partially evicted entry is simply a cache miss.
+func evictBuildCache(logger *slog.Logger, cacheDir string,
+	maxSize int64) error {
+
+	var files []buildCacheFile
+	var total int64
+	err := filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || filepath.Dir(path) == cacheDir {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, buildCacheFile{
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		total += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("measuring Go build cache %q: %w", cacheDir,
+			err)
+	}
+
+	if total <= maxSize {
+		logger.Info("Go build cache within size limit", "path",
+			cacheDir, "size", total, "maxSize", maxSize)
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	size, evicted := total, 0
+	for _, file := range files {
+		if size <= maxSize {
+			break
+		}
+
+		err := os.Remove(file.path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("evicting Go build cache entry: %w",
+				err)
+		}
+		size -= file.size
+		evicted++
+	}
+
+	logger.Info("Pruned Go build cache", "path", cacheDir, "evicted",
+		evicted, "sizeBefore", total, "sizeAfter", size, "maxSize",
+		maxSize)
+
+	return nil
+}
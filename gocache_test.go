@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvictBuildCache verifies that the least recently used entries of the Go
+// build cache are evicted until it fits its size limit, keeping the files
+// describing the cache itself.
+func TestEvictBuildCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeFiles(t, cacheDir, map[string]string{
+		"README":      strings.Repeat("r", 100),
+		"trim.txt":    "1",
+		"00/old-a":    strings.Repeat("a", 40),
+		"00/old-d":    strings.Repeat("d", 40),
+		"ff/recent-a": strings.Repeat("a", 40),
+		"ff/recent-d": strings.Repeat("d", 40),
+	})
+
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"00/old-a":    4 * time.Hour,
+		"00/old-d":    3 * time.Hour,
+		"ff/recent-a": 2 * time.Hour,
+		"ff/recent-d": time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(cacheDir, name)
+		assert.NoError(t, os.Chtimes(path, now.Add(-age),
+			now.Add(-age)))
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+
+	// A cache within its limit is left untouched.
+	assert.NoError(t, evictBuildCache(logger, cacheDir, 160))
+	for name := range ages {
+		assert.FileExists(t, filepath.Join(cacheDir, name))
+	}
+
+	assert.NoError(t, evictBuildCache(logger, cacheDir, 100))
+	assert.NoFileExists(t, filepath.Join(cacheDir, "00", "old-a"))
+	assert.NoFileExists(t, filepath.Join(cacheDir, "00", "old-d"))
+	assert.FileExists(t, filepath.Join(cacheDir, "ff", "recent-a"))
+	assert.FileExists(t, filepath.Join(cacheDir, "ff", "recent-d"))
+	assert.FileExists(t, filepath.Join(cacheDir, "README"))
+	assert.FileExists(t, filepath.Join(cacheDir, "trim.txt"))
+}
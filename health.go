@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// healthState tracks the liveness and readiness of the daemon, reported on the
+// health endpoints.
+type healthState struct {
+	// alive is set while the main loop running the fuzzing cycles runs.
+	alive atomic.Bool
+
+	// ready is set once a cycle has cloned the project and downloaded the
+	// corpus for the first time, and stays set afterwards.
+	ready atomic.Bool
+}
+
+// daemonHealth is the health of the running daemon.
+var daemonHealth = &healthState{}
+
+// handler returns the handler of the health endpoints: /healthz, answering 200
+// while the main loop is alive, and /readyz, answering 200 once the daemon is
+// ready. Both answer 503 otherwise.
+func (h *healthState) handler() http.Handler {
+	probe := func(ok *atomic.Bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			if !ok.Load() {
+				http.Error(w, "not ok",
+					http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok\n"))
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", probe(&h.alive))
+	mux.Handle("/readyz", probe(&h.ready))
+
+	return mux
+}
+
+// startHealthServer serves the health endpoints of the given state at the given
+// address until the context is canceled, when the server is shut down. Returns
+// the address listened on, or an error if the given address cannot be listened
+// on.
+func startHealthServer(ctx context.Context, logger *slog.Logger, addr string,
+	h *healthState) (string, error) {
+
+	return startHTTPServer(ctx, logger, "health", addr, h.handler())
+}
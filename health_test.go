@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHealthServer verifies that /healthz and /readyz answer 200 only once the
+// daemon is alive and ready respectively, until the context is canceled.
+func TestHealthServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := &healthState{}
+	addr, err := startHealthServer(ctx, slog.New(slog.DiscardHandler),
+		"127.0.0.1:0", h)
+	assert.NoError(t, err)
+
+	status := func(path string) int {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			return 0
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+
+	h.alive.Store(true)
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+
+	h.ready.Store(true)
+	assert.Equal(t, http.StatusOK, status("/readyz"))
+	assert.Equal(t, http.StatusNotFound, status("/metrics"))
+
+	// The server is shut down once the context is canceled.
+	cancel()
+	assert.Eventually(t, func() bool {
+		return status("/healthz") == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
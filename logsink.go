@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// logSinkLine is a log line buffered for the log sink, along with the time it
+// was logged.
+type logSinkLine struct {
+	at   time.Time
+	text string
+}
+
+// logSink is an io.Writer shipping the log lines written to it to a remote log
+// aggregator, in batches sent from a background goroutine. Writing to it never
+// blocks: the lines that do not fit in its buffer, or that fail to be shipped,
+// are dropped and counted.
+type logSink struct {
+	// logger reports the failures of the sink itself, so it must not
+	// write to the sink.
+	logger *slog.Logger
+
+	url    *url.URL
+	client *http.Client
+
+	// conn is the connection to a tcp:// sink, dialed on first use and
+	// after any failure.
+	conn net.Conn
+
+	lines chan logSinkLine
+	stop  chan struct{}
+	done  chan struct{}
+
+	stopOnce sync.Once
+
+	// dropped is the number of lines dropped since they were last
+	// reported.
+	dropped atomic.Int64
+}
+
+// newLogSink returns a started logSink shipping log lines to the sink at the
+// given URL, reporting its own failures with the given logger.
+func newLogSink(logger *slog.Logger, sinkURL string) (*logSink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log sink URL: %w", err)
+	}
+
+	s := &logSink{
+		logger: logger,
+		url:    u,
+		client: &http.Client{Timeout: LogSinkTimeout},
+		lines:  make(chan logSinkLine, LogSinkBufferLines),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	return s, nil
+}
+
+// Write buffers each line of p to be shipped, dropping the lines that do not
+// fit in the buffer. It never fails.
+func (s *logSink) Write(p []byte) (int, error) {
+	now := time.Now()
+	for _, text := range strings.Split(strings.TrimRight(string(p), "\n"),
+		"\n") {
+
+		select {
+		case s.lines <- logSinkLine{at: now, text: text}:
+		default:
+			s.drop(1)
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close stops the sink after shipping the lines still buffered, waiting at
+// most LogSinkTimeout for them to be shipped.
+func (s *logSink) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+	case <-time.After(LogSinkTimeout):
+		s.logger.Warn("Timed out shipping remaining log lines to log " +
+			"sink")
+	}
+}
+
+// drop counts the given number of dropped lines.
+func (s *logSink) drop(n int) {
+	s.dropped.Add(int64(n))
+	logSinkDroppedLines.Add(float64(n))
+}
+
+// run ships the buffered lines whenever a full batch is buffered or every
+// LogSinkFlushInterval, until the sink is stopped.
+func (s *logSink) run() {
+	defer close(s.done)
+	defer func() {
+		if s.conn != nil {
+			_ = s.conn.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(LogSinkFlushInterval)
+	defer ticker.Stop()
+
+	var batch []logSinkLine
+	for {
+		select {
+		case line := <-s.lines:
+			batch = append(batch, line)
+			if len(batch) < LogSinkBatchLines {
+				continue
+			}
+
+		case <-ticker.C:
+
+		case <-s.stop:
+			// Ship the lines still buffered, in batches.
+			for {
+				select {
+				case line := <-s.lines:
+					batch = append(batch, line)
+					if len(batch) < LogSinkBatchLines {
+						continue
+					}
+					s.flush(batch)
+					batch = nil
+					continue
+				default:
+				}
+				break
+			}
+			s.flush(batch)
+			return
+		}
+
+		s.flush(batch)
+		batch = nil
+	}
+}
+
+// flush ships the given batch of lines, dropping them if this fails, and
+// reports the lines dropped since the last report, if any.
+func (s *logSink) flush(batch []logSinkLine) {
+	if len(batch) > 0 {
+		if err := s.ship(batch); err != nil {
+			s.logger.Warn("Failed to ship log lines to log sink",
+				"sink", SanitizeURL(s.url.String()), "error",
+				err)
+			s.drop(len(batch))
+		}
+	}
+
+	if n := s.dropped.Swap(0); n > 0 {
+		s.logger.Warn("Dropped log lines not shipped to log sink",
+			"sink", SanitizeURL(s.url.String()), "count", n)
+	}
+}
+
+// ship sends the given batch of lines to the sink: as newline-delimited lines
+// to a tcp:// sink, in Loki's format to the Loki push API, or as
+// newline-delimited lines posted to any other HTTP(S) sink.
+func (s *logSink) ship(batch []logSinkLine) error {
+	if s.url.Scheme == "tcp" {
+		return s.shipTCP(batch)
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	body, err := joinLogSinkLines(batch)
+	if strings.HasSuffix(s.url.Path, LogSinkLokiPath) {
+		contentType = "application/json"
+		body, err = lokiPushPayload(batch)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url.String(), contentType,
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s",
+			resp.Status)
+	}
+
+	return nil
+}
+
+// shipTCP writes the given batch of lines to the tcp:// sink, dialing it first
+// if not connected. On failure, the connection is closed so the next batch
+// dials again.
+func (s *logSink) shipTCP(batch []logSinkLine) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.url.Host,
+			LogSinkTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	body, err := joinLogSinkLines(batch)
+	if err != nil {
+		return err
+	}
+
+	err = s.conn.SetWriteDeadline(time.Now().Add(LogSinkTimeout))
+	if err == nil {
+		_, err = s.conn.Write(body)
+	}
+	if err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// joinLogSinkLines returns the given lines as newline-delimited text.
+func joinLogSinkLines(batch []logSinkLine) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, line := range batch {
+		buf.WriteString(line.text)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// lokiPushPayload returns the given lines as the JSON payload of Loki's push
+// API, in a single stream labeled with the job "go-continuous-fuzz".
+func lokiPushPayload(batch []logSinkLine) ([]byte, error) {
+	values := make([][2]string, 0, len(batch))
+	for _, line := range batch {
+		values = append(values, [2]string{
+			strconv.FormatInt(line.at.UnixNano(), 10), line.text,
+		})
+	}
+
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	payload := struct {
+		Streams []stream `json:"streams"`
+	}{
+		Streams: []stream{{
+			Stream: map[string]string{"job": "go-continuous-fuzz"},
+			Values: values,
+		}},
+	}
+
+	return json.Marshal(payload)
+}
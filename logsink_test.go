@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLogSinkHTTP verifies that log lines are posted as newline-delimited text
+// to an HTTP sink, and in Loki's format to the Loki push API.
+func TestLogSinkHTTP(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies = make(map[string][]string)
+		types  = make(map[string]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			mu.Lock()
+			defer mu.Unlock()
+			bodies[r.URL.Path] = append(bodies[r.URL.Path],
+				string(body))
+			types[r.URL.Path] = r.Header.Get("Content-Type")
+		}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	sink, err := newLogSink(logger, server.URL+"/ingest")
+	assert.NoError(t, err)
+	_, err = sink.Write([]byte("first line\nsecond line\n"))
+	assert.NoError(t, err)
+	sink.Close()
+
+	sink, err = newLogSink(logger, server.URL+LogSinkLokiPath)
+	assert.NoError(t, err)
+	_, err = sink.Write([]byte("fuzzer output\n"))
+	assert.NoError(t, err)
+	sink.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"first line\nsecond line\n"},
+		bodies["/ingest"])
+	assert.Equal(t, "text/plain; charset=utf-8", types["/ingest"])
+
+	assert.Len(t, bodies[LogSinkLokiPath], 1)
+	assert.Equal(t, "application/json", types[LogSinkLokiPath])
+
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(bodies[LogSinkLokiPath][0]),
+		&payload))
+	assert.Len(t, payload.Streams, 1)
+	assert.Equal(t, map[string]string{"job": "go-continuous-fuzz"},
+		payload.Streams[0].Stream)
+	assert.Len(t, payload.Streams[0].Values, 1)
+	assert.Equal(t, "fuzzer output", payload.Streams[0].Values[0][1])
+}
+
+// TestLogSinkTCP verifies that log lines are written as newline-delimited text
+// to a TCP sink.
+func TestLogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		var lines []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- lines
+	}()
+
+	sink, err := newLogSink(slog.New(slog.DiscardHandler),
+		"tcp://"+ln.Addr().String())
+	assert.NoError(t, err)
+	_, err = sink.Write([]byte("first line\n"))
+	assert.NoError(t, err)
+	_, err = sink.Write([]byte("second line\n"))
+	assert.NoError(t, err)
+	sink.Close()
+
+	assert.Equal(t, []string{"first line", "second line"}, <-received)
+}
+
+// TestLogSinkDrop verifies that writing to a full log sink drops the lines
+// rather than blocking, and that the lines that fail to be shipped are dropped
+// and reported.
+func TestLogSinkDrop(t *testing.T) {
+	var logs strings.Builder
+	sink := &logSink{
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		lines:  make(chan logSinkLine, 1),
+	}
+
+	n, err := sink.Write([]byte("kept\ndropped\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, len("kept\ndropped\n"), n)
+	assert.EqualValues(t, 1, sink.dropped.Load())
+
+	// Nothing listens on the sink, so the batch cannot be shipped.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.NoError(t, ln.Close())
+
+	sink, err = newLogSink(slog.New(slog.NewTextHandler(&logs, nil)),
+		"tcp://"+ln.Addr().String())
+	assert.NoError(t, err)
+	_, err = sink.Write([]byte("lost\n"))
+	assert.NoError(t, err)
+	sink.Close()
+
+	assert.Contains(t, logs.String(), "Failed to ship log lines")
+	assert.Contains(t, logs.String(), "Dropped log lines not shipped to "+
+		"log sink")
+	assert.Contains(t, logs.String(), "count=1")
+}
@@ -35,6 +35,9 @@ func run() int {
 			// help requested
 			return 0
 		}
+		if errors.Is(err, errVersionShown) {
+			return 0
+		}
 
 		// Print error if not due to help request.
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v", err)
@@ -51,8 +54,30 @@ func run() int {
 		Compress:   true,
 	}
 	multiWriter := io.MultiWriter(os.Stdout, logFile)
+
+	// Also ship the log lines, including the fuzzer output, to the log
+	// sink if one is configured. The sink reports its own failures through
+	// a logger that does not write to it.
+	if cfg.Fuzz.LogSink != "" {
+		sink, err := newLogSink(slog.New(slog.NewTextHandler(
+			multiWriter, nil)), cfg.Fuzz.LogSink)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create log sink: %v",
+				err)
+			return 1
+		}
+		defer sink.Close()
+
+		multiWriter = io.MultiWriter(multiWriter, sink)
+	}
 	logger := slog.New(slog.NewTextHandler(multiWriter, nil))
 
+	// Log the build of the binary, so crash reports and logs can be traced
+	// back to it.
+	version := currentVersion()
+	logger.Info("Starting go-continuous-fuzz", "version", version.version,
+		"commit", version.commit, "buildDate", version.buildDate)
+
 	defer cleanupWorkspace(logger, cfg)
 
 	// Create a cancellable context to manage the application's lifecycle.
@@ -75,10 +100,156 @@ func run() int {
 		cancelApp()
 	}()
 
+	// Run the requested subcommand instead of the fuzzing cycles, if any.
+	if cfg.Command == RestoreCorpusCmd {
+		if err := runRestoreCorpus(appCtx, logger, cfg); err != nil {
+			logger.Error("Failed to restore corpus", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if cfg.Command == PromoteCorpusCmd {
+		if err := runPromoteCorpus(appCtx, logger, cfg); err != nil {
+			logger.Error("Failed to promote corpus", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if cfg.Command == MigrateCorpusCmd {
+		err := runMigrateCorpus(appCtx, logger, cfg, os.Stdout)
+		if err != nil {
+			logger.Error("Failed to migrate corpus", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if cfg.Command == BisectCorpusCmd {
+		err := runBisectCorpus(appCtx, logger, cfg, os.Stdout)
+		if err != nil {
+			logger.Error("Failed to bisect corpus", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if cfg.Command == ReproduceCmd {
+		reproduced, err := runReproduce(appCtx, logger, cfg, os.Stdout)
+		if err != nil {
+			logger.Error("Failed to reproduce crash", "error", err)
+			return 1
+		}
+		if reproduced {
+			return 1
+		}
+		return 0
+	}
+
+	// On bounded runs, print a summary of the run to stdout once all
+	// cycles are done, e.g. for consumption by CI.
+	summary := NewRunSummary()
+	if cfg.Fuzz.Iterations > 0 || cfg.Fuzz.MaxRuntime > 0 {
+		defer func() {
+			err := summary.write(os.Stdout, cfg.JSONSummary)
+			if err != nil {
+				logger.Error("Failed to write run summary",
+					"error", err)
+			}
+		}()
+	}
+
+	// Serve the metrics of the fuzzing progress, if enabled, until the
+	// application shuts down.
+	if cfg.Metrics.ListenAddr != "" {
+		_, err := startMetricsServer(appCtx, logger,
+			cfg.Metrics.ListenAddr)
+		if err != nil {
+			logger.Error("Failed to start metrics server", "error",
+				err)
+			summary.finish(1, fmt.Sprintf("metrics server failed: "+
+				"%v", err))
+			return 1
+		}
+	}
+
+	// Send the metrics of the fuzzing progress to the StatsD agent, if
+	// enabled, until the application shuts down.
+	if cfg.Metrics.StatsDAddr != "" {
+		statsd, err := newStatsdClient(cfg.Metrics.StatsDAddr)
+		if err != nil {
+			logger.Error("Failed to start StatsD exporter", "error",
+				err)
+			summary.finish(1, fmt.Sprintf("StatsD exporter "+
+				"failed: %v", err))
+			return 1
+		}
+		setMetricsSink(statsd)
+		defer func() {
+			setMetricsSink(nil)
+			_ = statsd.Close()
+		}()
+	}
+
+	// Serve the health endpoints, if enabled, until the application shuts
+	// down.
+	if cfg.Health.ListenAddr != "" {
+		_, err := startHealthServer(appCtx, logger,
+			cfg.Health.ListenAddr, daemonHealth)
+		if err != nil {
+			logger.Error("Failed to start health server", "error",
+				err)
+			summary.finish(1, fmt.Sprintf("health server failed: "+
+				"%v", err))
+			return 1
+		}
+	}
+
+	// Fail fast if the fuzz containers cannot be run, before any cycle
+	// clones the project and downloads the corpus.
+	if !cfg.Fuzz.SkipRuntimeCheck {
+		if err := checkContainerRuntime(appCtx, logger); err != nil {
+			logger.Error("Container runtime check failed", "error",
+				err)
+			summary.finish(1, fmt.Sprintf("container runtime "+
+				"check failed: %v", err))
+			return 1
+		}
+	}
+
+	// Fail fast if crashes cannot be reported, rather than when the first
+	// crash is found.
+	if !cfg.Fuzz.SkipTrackerCheck &&
+		cfg.Fuzz.CrashTracker == CrashTrackerGitHub {
+
+		if err := checkGitHubAccess(appCtx, logger, cfg); err != nil {
+			logger.Error("Crash repository check failed", "error",
+				err)
+			summary.finish(1, fmt.Sprintf("crash repository check "+
+				"failed: %v", err))
+			return 1
+		}
+	}
+
 	// Start the continuous fuzzing cycles.
-	if err := runFuzzingCycles(appCtx, logger, cfg); err != nil {
+	err = runFuzzingCycles(appCtx, logger, cfg, summary)
+	switch {
+	case err != nil:
 		logger.Error("Failed to run fuzzing cycles", "error", err)
+		summary.finish(1, fmt.Sprintf("fuzzing cycles failed: %v", err))
 		return 1
+
+	case appCtx.Err() != nil:
+		summary.finish(0, "interrupted before completing all fuzzing "+
+			"cycles")
+
+	case cfg.Fuzz.MaxRuntime > 0 && (cfg.Fuzz.Iterations == 0 ||
+		summary.cycleCount() < cfg.Fuzz.Iterations):
+
+		summary.finish(0, fmt.Sprintf("reached the maximum runtime of "+
+			"%s after %d fuzzing cycles", cfg.Fuzz.MaxRuntime,
+			summary.cycleCount()))
+
+	default:
+		summary.finish(0, fmt.Sprintf("completed all %d fuzzing "+
+			"cycles", cfg.Fuzz.Iterations))
 	}
 
 	logger.Info("Program exited.")
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsNamespace is the namespace of all the metrics exposed on the metrics
+// endpoint.
+const metricsNamespace = "go_continuous_fuzz"
+
+// metricsSink receives every update of the metrics, in addition to the
+// Prometheus registry, e.g. to forward them to another monitoring backend.
+type metricsSink interface {
+	// count adds the given value to the counter with the given name and
+	// labels.
+	count(name string, value float64, labels map[string]string)
+
+	// gauge sets the gauge with the given name and labels to the given
+	// value.
+	gauge(name string, value float64, labels map[string]string)
+}
+
+// activeMetricsSink is the sink receiving the updates of the metrics, if any.
+var activeMetricsSink atomic.Pointer[metricsSink]
+
+// setMetricsSink makes the given sink receive the updates of the metrics, or
+// stops forwarding them if nil.
+func setMetricsSink(sink metricsSink) {
+	if sink == nil {
+		activeMetricsSink.Store(nil)
+		return
+	}
+	activeMetricsSink.Store(&sink)
+}
+
+// metricLabels returns the labels with the given names and values, or nil if
+// there are none.
+func metricLabels(names, values []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(names))
+	for i, name := range names {
+		labels[name] = values[i]
+	}
+	return labels
+}
+
+// counterMetric is a counter, optionally partitioned by labels, exposed to
+// Prometheus and forwarded to the metrics sink, so both backends share its
+// definition.
+type counterMetric struct {
+	name   string
+	labels []string
+	vec    *prometheus.CounterVec
+}
+
+// newCounterMetric returns the counter with the given name, help text and
+// label names.
+func newCounterMetric(name, help string, labels ...string) *counterMetric {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+
+	// Expose unpartitioned counters before their first increment.
+	if len(labels) == 0 {
+		vec.WithLabelValues()
+	}
+
+	return &counterMetric{name: name, labels: labels, vec: vec}
+}
+
+// Inc increments the counter with the given label values.
+func (m *counterMetric) Inc(labelValues ...string) {
+	m.Add(1, labelValues...)
+}
+
+// Add adds the given value to the counter with the given label values.
+func (m *counterMetric) Add(value float64, labelValues ...string) {
+	m.vec.WithLabelValues(labelValues...).Add(value)
+
+	if sink := activeMetricsSink.Load(); sink != nil {
+		(*sink).count(m.name, value, metricLabels(m.labels,
+			labelValues))
+	}
+}
+
+// gaugeMetric is a gauge, optionally partitioned by labels, exposed to
+// Prometheus and forwarded to the metrics sink, so both backends share its
+// definition.
+type gaugeMetric struct {
+	name   string
+	labels []string
+	vec    *prometheus.GaugeVec
+}
+
+// newGaugeMetric returns the gauge with the given name, help text and label
+// names.
+func newGaugeMetric(name, help string, labels ...string) *gaugeMetric {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+
+	// Expose unpartitioned gauges before they are first set.
+	if len(labels) == 0 {
+		vec.WithLabelValues()
+	}
+
+	return &gaugeMetric{name: name, labels: labels, vec: vec}
+}
+
+// Set sets the gauge with the given label values to the given value.
+func (m *gaugeMetric) Set(value float64, labelValues ...string) {
+	m.vec.WithLabelValues(labelValues...).Set(value)
+
+	if sink := activeMetricsSink.Load(); sink != nil {
+		(*sink).gauge(m.name, value, metricLabels(m.labels,
+			labelValues))
+	}
+}
+
+var (
+	// metricsRegistry holds the metrics exposed on the metrics endpoint,
+	// along with the standard Go runtime and process metrics.
+	metricsRegistry = prometheus.NewRegistry()
+
+	// targetsDiscovered is the number of fuzz targets discovered in the
+	// latest cycle.
+	targetsDiscovered = newGaugeMetric("targets_discovered",
+		"Number of fuzz targets discovered in the latest cycle.")
+
+	// cycleDuration is how long the latest fuzzing cycle took.
+	cycleDuration = newGaugeMetric("cycle_duration_seconds",
+		"Duration of the latest fuzzing cycle.")
+
+	// crashesFound counts the fuzz crashes found per target.
+	crashesFound = newCounterMetric("crashes_found_total",
+		"Number of fuzz crashes found.", "package", "target")
+
+	// issuesOpened counts the issues opened in the crash repository.
+	issuesOpened = newCounterMetric("issues_opened_total",
+		"Number of issues opened in the crash repository.")
+
+	// issuesClosed counts the issues closed in the crash repository once
+	// their crash no longer reproduced.
+	issuesClosed = newCounterMetric("issues_closed_total",
+		"Number of issues closed in the crash repository as no "+
+			"longer reproducible.")
+
+	// corpusBytesUploaded counts the bytes of the corpus archives uploaded
+	// to the corpus store.
+	corpusBytesUploaded = newCounterMetric("corpus_uploaded_bytes_total",
+		"Number of bytes of corpus archives uploaded.")
+
+	// logSinkDroppedLines counts the log lines dropped rather than shipped
+	// to the log sink.
+	logSinkDroppedLines = newCounterMetric("log_sink_dropped_lines_total",
+		"Number of log lines dropped rather than shipped to the log "+
+			"sink.")
+
+	// targetFuzzDuration is how long each target was last fuzzed.
+	targetFuzzDuration = newGaugeMetric("target_fuzz_duration_seconds",
+		"Duration of the latest fuzzing run of the target.", "package",
+		"target")
+
+	// targetCoverage is the latest coverage measured for each target.
+	targetCoverage = newGaugeMetric("target_coverage_percent",
+		"Latest statement coverage of the target's corpus.", "package",
+		"target")
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(
+			prometheus.ProcessCollectorOpts{},
+		),
+		targetsDiscovered.vec,
+		cycleDuration.vec,
+		crashesFound.vec,
+		issuesOpened.vec,
+		issuesClosed.vec,
+		corpusBytesUploaded.vec,
+		targetFuzzDuration.vec,
+		targetCoverage.vec,
+		logSinkDroppedLines.vec,
+	)
+}
+
+// startMetricsServer serves the metrics on /metrics at the given address until
+// the context is canceled, when the server is shut down. Returns the address
+// listened on, or an error if the given address cannot be listened on.
+func startMetricsServer(ctx context.Context, logger *slog.Logger,
+	addr string) (string, error) {
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry,
+		promhttp.HandlerOpts{}))
+
+	return startHTTPServer(ctx, logger, "metrics", addr, mux)
+}
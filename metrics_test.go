@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetricsServer verifies that the metrics are served on /metrics until the
+// context is canceled.
+func TestMetricsServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := startMetricsServer(ctx, slog.New(slog.DiscardHandler),
+		"127.0.0.1:0")
+	assert.NoError(t, err)
+
+	crashesFound.Inc("pkg", "FuzzFoo")
+	targetFuzzDuration.Set(90, "pkg", "FuzzFoo")
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	assert.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Contains(t, string(body), `go_continuous_fuzz_crashes_found_`+
+		`total{package="pkg",target="FuzzFoo"} 1`)
+	assert.Contains(t, string(body), `go_continuous_fuzz_target_fuzz_`+
+		`duration_seconds{package="pkg",target="FuzzFoo"} 90`)
+	assert.Contains(t, string(body), "go_continuous_fuzz_issues_opened_"+
+		"total")
+
+	// The server is shut down once the context is canceled.
+	cancel()
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// An invalid address cannot be listened on.
+	_, err = startMetricsServer(context.Background(),
+		slog.New(slog.DiscardHandler), "invalid-address")
+	assert.Error(t, err)
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// corpusMapping maps the corpus files under a directory of a corpus archive
+// laid out by another setup to the corpus directory of a fuzz target, in the
+// <pkg>/testdata/fuzz/<target> layout expected by go-continuous-fuzz.
+type corpusMapping struct {
+	// from holds the path segments of the source directory. A segment of
+	// the form {name} is a placeholder matching any single segment.
+	from []string
+
+	// to holds the path segments of the corpus directory of the fuzz
+	// target, which may refer to the placeholders of from.
+	to []string
+}
+
+// isPlaceholder reports whether the mapping path segment is a {name}
+// placeholder.
+func isPlaceholder(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") &&
+		strings.HasSuffix(segment, "}")
+}
+
+// splitMappingPath splits a path of a corpus mapping into its segments,
+// rejecting absolute paths and paths leaving the corpus.
+func splitMappingPath(p string) ([]string, error) {
+	if p == "" {
+		return nil, errors.New("empty path")
+	}
+	if path.IsAbs(p) {
+		return nil, fmt.Errorf("path %q must be relative", p)
+	}
+
+	cleaned := path.Clean(p)
+	if cleaned == "." || cleaned == ".." ||
+		strings.HasPrefix(cleaned, "../") {
+
+		return nil, fmt.Errorf("path %q must be inside the corpus", p)
+	}
+
+	return strings.Split(cleaned, "/"), nil
+}
+
+// parseCorpusMapping parses a "from=to" corpus mapping. The destination must
+// be the corpus directory of a fuzz target, <pkg>/testdata/fuzz/<target>, and
+// may only use the placeholders defined by the source.
+func parseCorpusMapping(mapping string) (corpusMapping, error) {
+	from, to, found := strings.Cut(mapping, "=")
+	if !found {
+		return corpusMapping{}, fmt.Errorf("mapping %q must be of the "+
+			"form from=to", mapping)
+	}
+
+	fromSegments, err := splitMappingPath(from)
+	if err != nil {
+		return corpusMapping{}, fmt.Errorf("mapping %q: %w", mapping,
+			err)
+	}
+	toSegments, err := splitMappingPath(to)
+	if err != nil {
+		return corpusMapping{}, fmt.Errorf("mapping %q: %w", mapping,
+			err)
+	}
+
+	placeholders := make(map[string]bool)
+	for _, segment := range fromSegments {
+		if !isPlaceholder(segment) {
+			continue
+		}
+		if placeholders[segment] {
+			return corpusMapping{}, fmt.Errorf("mapping %q: "+
+				"duplicate placeholder %s", mapping, segment)
+		}
+		placeholders[segment] = true
+	}
+	for _, segment := range toSegments {
+		if isPlaceholder(segment) && !placeholders[segment] {
+			return corpusMapping{}, fmt.Errorf("mapping %q: "+
+				"undefined placeholder %s", mapping, segment)
+		}
+	}
+
+	n := len(toSegments)
+	if n < 3 || toSegments[n-3] != "testdata" ||
+		toSegments[n-2] != "fuzz" {
+
+		return corpusMapping{}, fmt.Errorf("mapping %q: destination "+
+			"must be of the form <pkg>/testdata/fuzz/<target>",
+			mapping)
+	}
+
+	return corpusMapping{from: fromSegments, to: toSegments}, nil
+}
+
+// parseCorpusMappings parses the corpus mappings of the migrate-corpus
+// subcommand, in order of precedence.
+func parseCorpusMappings(mappings []string) ([]corpusMapping, error) {
+	parsed := make([]corpusMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		m, err := parseCorpusMapping(mapping)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, m)
+	}
+
+	return parsed, nil
+}
+
+// targetDir returns the corpus directory of the fuzz target, relative to the
+// corpus root, to which the file at the given slash-separated path is mapped,
+// if the file lies under the source directory of the mapping.
+func (m corpusMapping) targetDir(file string) (string, bool) {
+	segments := strings.Split(file, "/")
+	if len(segments) <= len(m.from) {
+		return "", false
+	}
+
+	values := make(map[string]string)
+	for i, segment := range m.from {
+		if isPlaceholder(segment) {
+			values[segment] = segments[i]
+			continue
+		}
+		if segments[i] != segment {
+			return "", false
+		}
+	}
+
+	dir := make([]string, len(m.to))
+	for i, segment := range m.to {
+		if value, ok := values[segment]; ok {
+			segment = value
+		}
+		dir[i] = segment
+	}
+
+	return strings.Join(dir, "/"), true
+}
+
+// expectedTargetDir returns the corpus directory of the fuzz target holding the
+// file at the given slash-separated path, if the file is already laid out as
+// <pkg>/testdata/fuzz/<target>/<file>.
+func expectedTargetDir(file string) (string, bool) {
+	segments := strings.Split(file, "/")
+	n := len(segments)
+	if n < 4 || segments[n-4] != "testdata" || segments[n-3] != "fuzz" {
+		return "", false
+	}
+
+	return path.Dir(file), true
+}
+
+// migrateCorpusLayout copies every corpus file of srcDir into dstDir, in the
+// corpus directory of the fuzz target given by the first matching mapping.
+// Files no mapping matches are kept in place if they already follow the
+// expected layout. Since the corpus directory of a fuzz target is flat, nested
+// files are moved to its root, shallower files keeping their name first, and
+// files whose name is already taken are named after their content instead.
+// Duplicate contents are only copied once. Returns the number of corpus files
+// of each fuzz target, or an error without copying anything if some files
+// cannot be mapped.
+func migrateCorpusLayout(srcDir, dstDir string,
+	mappings []corpusMapping) (map[string]int, error) {
+
+	dests := make(map[string][]string)
+	var unmapped []string
+	err := filepath.WalkDir(srcDir, func(p string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, m := range mappings {
+			if dir, ok := m.targetDir(rel); ok {
+				dests[dir] = append(dests[dir], rel)
+				return nil
+			}
+		}
+		if dir, ok := expectedTargetDir(rel); ok {
+			dests[dir] = append(dests[dir], rel)
+			return nil
+		}
+
+		unmapped = append(unmapped, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking corpus %q: %w", srcDir, err)
+	}
+	if len(unmapped) > 0 {
+		sort.Strings(unmapped)
+		return nil, fmt.Errorf("%d corpus files match no mapping, "+
+			"e.g. %q", len(unmapped), unmapped[0])
+	}
+
+	counts := make(map[string]int)
+	for dir, files := range dests {
+		sort.Slice(files, func(i, j int) bool {
+			di := strings.Count(files[i], "/")
+			dj := strings.Count(files[j], "/")
+			if di != dj {
+				return di < dj
+			}
+			return files[i] < files[j]
+		})
+
+		targetDir := filepath.Join(dstDir, filepath.FromSlash(dir))
+		if err := EnsureDirExists(targetDir); err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		for _, file := range files {
+			srcPath := filepath.Join(srcDir,
+				filepath.FromSlash(file))
+			copied, err := copyCorpusFile(srcPath, targetDir, seen)
+			if err != nil {
+				return nil, err
+			}
+			if copied {
+				counts[dir]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// copyCorpusFile copies the corpus file at srcPath into targetDir under its
+// base name, or under its content name if the base name is already taken.
+// Returns false without copying the file if its content is in seen, the
+// content names of the files already copied into targetDir.
+func copyCorpusFile(srcPath, targetDir string,
+	seen map[string]bool) (bool, error) {
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return false, fmt.Errorf("stat corpus file %q: %w", srcPath,
+			err)
+	}
+	// Symlinked directories are not corpus files.
+	if info.IsDir() {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return false, fmt.Errorf("reading corpus file %q: %w", srcPath,
+			err)
+	}
+
+	contentName := corpusFileName(data)
+	if seen[contentName] {
+		return false, nil
+	}
+	seen[contentName] = true
+
+	destPath := filepath.Join(targetDir, filepath.Base(srcPath))
+	if _, err := os.Lstat(destPath); err == nil {
+		destPath = filepath.Join(targetDir, contentName)
+	}
+
+	// The content name is only taken by another content on a hash
+	// collision, which O_EXCL reports rather than overwriting it.
+	f, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		0644)
+	if err != nil {
+		return false, fmt.Errorf("creating corpus file %q: %w",
+			destPath, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return false, fmt.Errorf("writing corpus file %q: %w",
+			destPath, err)
+	}
+	if err := f.Close(); err != nil {
+		return false, fmt.Errorf("closing corpus file %q: %w",
+			destPath, err)
+	}
+
+	return true, nil
+}
+
+// migrateCorpus downloads the corpus archive at sourceKey, rewrites it into the
+// expected layout using the given mappings and, unless dryRun is set, uploads
+// it as the canonical corpus. Returns the number of corpus files of each fuzz
+// target.
+func (s3s *S3Store) migrateCorpus(sourceKey string, mappings []corpusMapping,
+	dryRun bool) (map[string]int, error) {
+
+	// Extract the source archive on its own, so no local inputs end up in
+	// the migrated corpus.
+	if err := os.RemoveAll(s3s.corpusDir); err != nil {
+		return nil, fmt.Errorf("removing local corpus: %w", err)
+	}
+	if err := EnsureDirExists(s3s.corpusDir); err != nil {
+		return nil, err
+	}
+	empty, err := s3s.downloadArchive(sourceKey)
+	if err != nil {
+		return nil, fmt.Errorf("corpus download failed: %w", err)
+	}
+	if empty {
+		return nil, fmt.Errorf("no corpus archive found at s3://%s/%s",
+			s3s.bucket, sourceKey)
+	}
+
+	migratedDir := s3s.corpusDir + ".migrated"
+	if err := os.RemoveAll(migratedDir); err != nil {
+		return nil, fmt.Errorf("removing migrated corpus: %w", err)
+	}
+	defer os.RemoveAll(migratedDir)
+
+	counts, err := migrateCorpusLayout(s3s.corpusDir, migratedDir,
+		mappings)
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("corpus archive s3://%s/%s holds no "+
+			"corpus files", s3s.bucket, sourceKey)
+	}
+
+	// In sharded mode, only the shards of the configured packages are
+	// uploaded, so refuse to silently drop the corpus of other packages.
+	for dir := range counts {
+		if !s3s.promotableInput(dir) {
+			return nil, fmt.Errorf("fuzz target corpus %q is not "+
+				"part of a configured package", dir)
+		}
+	}
+
+	if dryRun {
+		return counts, nil
+	}
+
+	if err := os.RemoveAll(s3s.corpusDir); err != nil {
+		return nil, fmt.Errorf("removing local corpus: %w", err)
+	}
+	if err := os.Rename(migratedDir, s3s.corpusDir); err != nil {
+		return nil, fmt.Errorf("replacing local corpus: %w", err)
+	}
+
+	lastMinTime, err := s3s.getLastMinimizedTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if s3s.sharded {
+		err = s3s.uploadCorpusShards(lastMinTime)
+	} else {
+		err = s3s.uploadArchive(s3s.corpusDir, s3s.corpusKey,
+			lastMinTime)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("corpus upload failed: %w", err)
+	}
+
+	if s3s.versioned {
+		if err := s3s.archiveCorpus(time.Now()); err != nil {
+			return nil, fmt.Errorf("corpus archival failed: %w",
+				err)
+		}
+	}
+
+	return counts, nil
+}
+
+// runMigrateCorpus rewrites the corpus archive selected with the
+// migrate-corpus subcommand into the expected layout, and writes the number
+// of corpus files of each fuzz target to w.
+func runMigrateCorpus(ctx context.Context, logger *slog.Logger, cfg *Config,
+	w io.Writer) error {
+
+	if cfg.Project.StorageBackend != StorageBackendS3 {
+		return errors.New("migrating corpus archives is only " +
+			"supported with the s3 storage backend")
+	}
+
+	s3s, err := NewS3Store(ctx, logger, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 store: %w", err)
+	}
+
+	sourceKey := cfg.MigrateCorpus.SourceKey
+	if sourceKey == "" {
+		sourceKey = s3s.corpusKey
+	}
+
+	counts, err := s3s.migrateCorpus(sourceKey,
+		cfg.MigrateCorpus.CorpusMappings, cfg.MigrateCorpus.DryRun)
+	if err != nil {
+		return err
+	}
+
+	dirs := make([]string, 0, len(counts))
+	for dir := range counts {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		fmt.Fprintf(w, "%s: %d inputs\n", dir, counts[dir])
+	}
+
+	if cfg.MigrateCorpus.DryRun {
+		logger.Info("Dry run; leaving the corpus untouched",
+			"sourceKey", sourceKey, "targets", len(counts))
+		return nil
+	}
+
+	logger.Info("Migrated corpus archive", "s3Bucket", s3s.bucket,
+		"sourceKey", sourceKey, "targets", len(counts))
+
+	return nil
+}
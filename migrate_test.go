@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseCorpusMapping verifies that corpus mappings must map a relative
+// directory to the corpus directory of a fuzz target, only using placeholders
+// defined by their source.
+func TestParseCorpusMapping(t *testing.T) {
+	m, err := parseCorpusMapping("{pkg}/{target}=" +
+		"{pkg}/testdata/fuzz/{target}")
+	assert.NoError(t, err)
+	assert.Equal(t, corpusMapping{
+		from: []string{"{pkg}", "{target}"},
+		to:   []string{"{pkg}", "testdata", "fuzz", "{target}"},
+	}, m)
+
+	m, err = parseCorpusMapping("./corpus//FuzzEval/=testdata/fuzz/" +
+		"FuzzEval")
+	assert.NoError(t, err)
+	assert.Equal(t, corpusMapping{
+		from: []string{"corpus", "FuzzEval"},
+		to:   []string{"testdata", "fuzz", "FuzzEval"},
+	}, m)
+
+	invalid := []string{
+		"FuzzEval",
+		"=parser/testdata/fuzz/FuzzEval",
+		"FuzzEval=",
+		"/FuzzEval=parser/testdata/fuzz/FuzzEval",
+		"../FuzzEval=parser/testdata/fuzz/FuzzEval",
+		"FuzzEval=../testdata/fuzz/FuzzEval",
+		"FuzzEval=parser/FuzzEval",
+		"FuzzEval=parser/testdata/fuzz/FuzzEval/inputs",
+		"{target}/{target}=parser/testdata/fuzz/{target}",
+		"{target}=parser/testdata/fuzz/{name}",
+	}
+	for _, mapping := range invalid {
+		_, err := parseCorpusMapping(mapping)
+		assert.Error(t, err, mapping)
+	}
+}
+
+// TestCorpusMappingTargetDir verifies that files are mapped to the corpus
+// directory of a fuzz target only if they lie under the source directory of
+// the mapping, substituting its placeholders.
+func TestCorpusMappingTargetDir(t *testing.T) {
+	mappings, err := parseCorpusMappings([]string{
+		"corpus/{target}=parser/testdata/fuzz/{target}",
+		"{pkg}/{target}={pkg}/testdata/fuzz/{target}",
+	})
+	assert.NoError(t, err)
+
+	dir, ok := mappings[0].targetDir("corpus/FuzzEval/seed")
+	assert.True(t, ok)
+	assert.Equal(t, "parser/testdata/fuzz/FuzzEval", dir)
+
+	// Files must lie under the source directory, not be it.
+	_, ok = mappings[0].targetDir("corpus/FuzzEval")
+	assert.False(t, ok)
+	_, ok = mappings[0].targetDir("other/FuzzEval/seed")
+	assert.False(t, ok)
+
+	dir, ok = mappings[1].targetDir("tree/FuzzBuild/nested/seed")
+	assert.True(t, ok)
+	assert.Equal(t, "tree/testdata/fuzz/FuzzBuild", dir)
+}
+
+// TestMigrateCorpusLayout verifies that corpus files are copied into the
+// corpus directory of their fuzz target, flattening nested files, keeping
+// files already in the expected layout, and deduplicating identical content.
+func TestMigrateCorpusLayout(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "repo_corpus")
+	writeFiles(t, srcDir, map[string]string{
+		"FuzzEval/seed1":                     "1",
+		"FuzzEval/nested/seed1":              "2",
+		"FuzzEval/nested/seed2":              "1",
+		"tree/FuzzBuild/seed":                "3",
+		"parser/testdata/fuzz/FuzzLex/seed":  "4",
+		"parser/testdata/fuzz/FuzzEval/seed": "5",
+	})
+
+	mappings, err := parseCorpusMappings([]string{
+		"FuzzEval=parser/testdata/fuzz/FuzzEval",
+		"tree/{target}=tree/testdata/fuzz/{target}",
+	})
+	assert.NoError(t, err)
+
+	dstDir := filepath.Join(t.TempDir(), "migrated")
+	counts, err := migrateCorpusLayout(srcDir, dstDir, mappings)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"parser/testdata/fuzz/FuzzEval": 3,
+		"parser/testdata/fuzz/FuzzLex":  1,
+		"tree/testdata/fuzz/FuzzBuild":  1,
+	}, counts)
+
+	// The nested seed1 is named after its content, since the shallower
+	// seed1 keeps its name.
+	renamed := "parser/testdata/fuzz/FuzzEval/" +
+		corpusFileName([]byte("2"))
+	inputs, err := snapshotCorpus(dstDir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"parser/testdata/fuzz/FuzzEval/seed1": true,
+		renamed:                               true,
+		"parser/testdata/fuzz/FuzzEval/seed":  true,
+		"parser/testdata/fuzz/FuzzLex/seed":   true,
+		"tree/testdata/fuzz/FuzzBuild/seed":   true,
+	}, inputs)
+
+	data, err := os.ReadFile(filepath.Join(dstDir,
+		"parser/testdata/fuzz/FuzzEval/seed1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(data))
+
+	// Files no mapping matches abort the migration before anything is
+	// copied.
+	writeFiles(t, srcDir, map[string]string{"stray/seed": "6"})
+	dstDir = filepath.Join(t.TempDir(), "migrated")
+	_, err = migrateCorpusLayout(srcDir, dstDir, mappings)
+	assert.ErrorContains(t, err, "stray/seed")
+	assert.NoDirExists(t, dstDir)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// NotifyEventCrashOpened is the event emitted once an issue is opened
+	// for a new crash.
+	NotifyEventCrashOpened = "crash_opened"
+
+	// NotifyEventCrashClosed is the event emitted once the issue of a
+	// crash that is no longer reproducible is closed.
+	NotifyEventCrashClosed = "crash_closed"
+)
+
+// notifyEvent is a crash lifecycle event, posted as JSON to the notification
+// webhook.
+type notifyEvent struct {
+	Event     string    `json:"event"`
+	Package   string    `json:"package"`
+	Target    string    `json:"target"`
+	Signature string    `json:"signature"`
+	IssueURL  string    `json:"issue_url"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier notifies the configured Slack webhook and notification webhook of
+// crash lifecycle events. Notifications are best-effort: a failure to post one
+// is logged as a warning and never aborts the fuzzing cycle. A nil Notifier, or
+// one without any webhook, posts nothing.
+type Notifier struct {
+	logger        *slog.Logger
+	slackWebhook  string
+	webhookURL    string
+	webhookSecret string
+	client        *http.Client
+}
+
+// NewNotifier returns the Notifier posting to the webhooks configured in the
+// given config, if any.
+func NewNotifier(logger *slog.Logger, cfg *Config) *Notifier {
+	return &Notifier{
+		logger:        logger,
+		slackWebhook:  cfg.Notify.SlackWebhook,
+		webhookURL:    cfg.Notify.WebhookURL,
+		webhookSecret: cfg.Notify.WebhookSecret,
+		client:        &http.Client{Timeout: NotifyTimeout},
+	}
+}
+
+// emitEvent notifies the configured webhooks of the given event of the crash
+// with the given signature, tracked by the issue at the given URL.
+func (n *Notifier) emitEvent(ctx context.Context, event, pkg, target,
+	signature, issueURL string) {
+
+	if n == nil {
+		return
+	}
+
+	ev := notifyEvent{
+		Event:     event,
+		Package:   pkg,
+		Target:    target,
+		Signature: signature,
+		IssueURL:  issueURL,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if n.slackWebhook != "" {
+		if err := n.postSlack(ctx, slackText(ev)); err != nil {
+			n.logger.Warn("Failed to post Slack notification",
+				"event", event, "error", err)
+		}
+	}
+
+	if n.webhookURL != "" {
+		if err := n.postWebhook(ctx, ev); err != nil {
+			n.logger.Warn("Failed to post webhook notification",
+				"event", event, "error", err)
+		}
+	}
+}
+
+// slackText returns the Slack message of the given event.
+func slackText(ev notifyEvent) string {
+	if ev.Event == NotifyEventCrashClosed {
+		return fmt.Sprintf(":white_check_mark: Fuzz crash in `%s/%s` "+
+			"(signature `%s`) no longer reproducible; closed %s",
+			ev.Package, ev.Target, ev.Signature, ev.IssueURL)
+	}
+
+	return fmt.Sprintf(":rotating_light: New fuzz crash in `%s/%s` "+
+		"(signature `%s`): %s", ev.Package, ev.Target, ev.Signature,
+		ev.IssueURL)
+}
+
+// postSlack posts the given message to the Slack webhook.
+func (n *Notifier) postSlack(ctx context.Context, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("encoding Slack payload: %w", err)
+	}
+
+	return postJSON(ctx, n.client, n.slackWebhook, payload, nil, "")
+}
+
+// postWebhook posts the given event as JSON to the notification webhook,
+// signed with the webhook secret if set.
+func (n *Notifier) postWebhook(ctx context.Context, ev notifyEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	return postJSON(ctx, n.client, n.webhookURL, payload, nil,
+		n.webhookSecret)
+}
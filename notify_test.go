@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNotifier verifies that crash lifecycle events are posted to the Slack
+// webhook and, signed, to the notification webhook, and that failures to post
+// them are only logged.
+func TestNotifier(t *testing.T) {
+	var (
+		texts   []string
+		bodies  [][]byte
+		headers []http.Header
+		status  = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if r.URL.Path == "/slack" {
+				var payload struct {
+					Text string `json:"text"`
+				}
+				_ = json.Unmarshal(body, &payload)
+				texts = append(texts, payload.Text)
+			} else {
+				bodies = append(bodies, body)
+				headers = append(headers, r.Header)
+			}
+			w.WriteHeader(status)
+		}))
+	defer server.Close()
+
+	ctx := context.Background()
+	var logs strings.Builder
+	cfg := &Config{Notify: Notify{
+		SlackWebhook:  server.URL + "/slack",
+		WebhookURL:    server.URL + "/hook",
+		WebhookSecret: "secret",
+	}}
+	n := NewNotifier(slog.New(slog.NewTextHandler(&logs, nil)), cfg)
+
+	const issueURL = "https://github.com/owner/repo/issues/1"
+	n.emitEvent(ctx, NotifyEventCrashOpened, "pkg", "FuzzFoo", "abc123",
+		issueURL)
+	n.emitEvent(ctx, NotifyEventCrashClosed, "pkg", "FuzzFoo", "abc123",
+		issueURL)
+	assert.Equal(t, []string{
+		":rotating_light: New fuzz crash in `pkg/FuzzFoo` (signature " +
+			"`abc123`): " + issueURL,
+		":white_check_mark: Fuzz crash in `pkg/FuzzFoo` (signature " +
+			"`abc123`) no longer reproducible; closed " + issueURL,
+	}, texts)
+	assert.Empty(t, logs.String())
+
+	assert.Len(t, bodies, 2)
+	for i, event := range []string{
+		NotifyEventCrashOpened, NotifyEventCrashClosed,
+	} {
+		var ev notifyEvent
+		assert.NoError(t, json.Unmarshal(bodies[i], &ev))
+		assert.WithinDuration(t, time.Now(), ev.Timestamp, time.Minute)
+		ev.Timestamp = time.Time{}
+		assert.Equal(t, notifyEvent{
+			Event:     event,
+			Package:   "pkg",
+			Target:    "FuzzFoo",
+			Signature: "abc123",
+			IssueURL:  issueURL,
+		}, ev)
+
+		assert.Equal(t, "application/json",
+			headers[i].Get("Content-Type"))
+		assert.Equal(t, signWebhookPayload("secret", bodies[i]),
+			headers[i].Get(WebhookSignatureHeader))
+	}
+
+	// Without a secret, the payload is not signed.
+	bodies, headers = nil, nil
+	NewNotifier(slog.New(slog.DiscardHandler), &Config{Notify: Notify{
+		WebhookURL: server.URL + "/hook",
+	}}).emitEvent(ctx, NotifyEventCrashOpened, "pkg", "FuzzFoo", "abc123",
+		issueURL)
+	assert.Len(t, headers, 1)
+	assert.Empty(t, headers[0].Get(WebhookSignatureHeader))
+
+	// A failure to post is logged as a warning, without the webhook URL.
+	status = http.StatusInternalServerError
+	n.emitEvent(ctx, NotifyEventCrashOpened, "pkg", "FuzzFoo", "abc123",
+		issueURL)
+	assert.Contains(t, logs.String(), "Failed to post Slack notification")
+	assert.Contains(t, logs.String(), "Failed to post webhook "+
+		"notification")
+	assert.Contains(t, logs.String(), "500 Internal Server Error")
+
+	server.Close()
+	logs.Reset()
+	n.emitEvent(ctx, NotifyEventCrashOpened, "pkg", "FuzzFoo", "abc123",
+		issueURL)
+	assert.Contains(t, logs.String(), "Failed to post Slack notification")
+	assert.NotContains(t, logs.String(), "/slack")
+	assert.NotContains(t, logs.String(), "/hook")
+
+	// Without any webhook, or a Notifier, nothing is posted.
+	texts, bodies = nil, nil
+	NewNotifier(slog.New(slog.DiscardHandler), &Config{}).emitEvent(ctx,
+		NotifyEventCrashOpened, "pkg", "FuzzFoo", "abc123", issueURL)
+	var nilNotifier *Notifier
+	nilNotifier.emitEvent(ctx, NotifyEventCrashOpened, "pkg", "FuzzFoo",
+		"abc123", issueURL)
+	assert.Empty(t, texts)
+	assert.Empty(t, bodies)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// orphanedTarget is a fuzz target of a configured package that still has a
+// corpus, but is no longer discovered, e.g. after being renamed or removed.
+type orphanedTarget struct {
+	pkg    string
+	target string
+}
+
+// findOrphanedTargets returns the fuzz targets of the given packages that have
+// a corpus directory under corpusDir, but are not among the discovered targets,
+// sorted by package and target.
+func findOrphanedTargets(corpusDir string, pkgs []string,
+	discovered []TargetState) ([]orphanedTarget, error) {
+
+	var orphans []orphanedTarget
+	for _, pkg := range pkgs {
+		entries, err := os.ReadDir(filepath.Join(corpusDir, pkg,
+			"testdata", "fuzz"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading corpus of package %q: "+
+				"%w", pkg, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+
+			state := TargetState{PkgPath: pkg, Target: entry.Name()}
+			if !slices.Contains(discovered, state) {
+				orphans = append(orphans, orphanedTarget{
+					pkg:    pkg,
+					target: entry.Name(),
+				})
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+// handleOrphanedTargets applies the configured orphan corpus policy to the
+// corpus of every fuzz target of the given packages that is no longer
+// discovered, and closes their crash issues if enabled. Failing to close the
+// issues is only logged, since it does not affect fuzzing.
+func handleOrphanedTargets(ctx context.Context, logger *slog.Logger,
+	cfg *Config, pkgs []string, discovered []TargetState) error {
+
+	orphans, err := findOrphanedTargets(cfg.Project.CorpusDir, pkgs,
+		discovered)
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range orphans {
+		logger.Warn("Found corpus of fuzz target that is no longer "+
+			"discovered", "package", orphan.pkg, "target",
+			orphan.target, "policy", cfg.Fuzz.OrphanCorpusPolicy)
+
+		corpusPath := filepath.Join(cfg.Project.CorpusDir, orphan.pkg,
+			"testdata", "fuzz", orphan.target)
+
+		switch cfg.Fuzz.OrphanCorpusPolicy {
+		case OrphanCorpusArchive:
+			archivePath := filepath.Join(cfg.Project.CorpusDir,
+				orphan.pkg, "testdata", OrphanCorpusDir,
+				orphan.target)
+			err := archiveOrphanedCorpus(corpusPath, archivePath)
+			if err != nil {
+				return fmt.Errorf("archiving orphaned corpus: "+
+					"%w", err)
+			}
+
+		case OrphanCorpusDelete:
+			if err := os.RemoveAll(corpusPath); err != nil {
+				return fmt.Errorf("deleting orphaned corpus: "+
+					"%w", err)
+			}
+		}
+
+		if !cfg.Fuzz.CloseOrphanIssues {
+			continue
+		}
+
+		tracker, err := NewCrashTracker(ctx, logger.With("target",
+			orphan.target).With("package", orphan.pkg), nil, cfg)
+		if err != nil {
+			return fmt.Errorf("error initializing crash tracker "+
+				"client: %w", err)
+		}
+
+		closed, err := tracker.closeOrphanedIssues(orphan.pkg,
+			orphan.target)
+		if err != nil {
+			logger.Warn("Failed to close issues of fuzz target "+
+				"that is no longer discovered", "package",
+				orphan.pkg, "target", orphan.target, "error",
+				err)
+			continue
+		}
+		logger.Info("Closed issues of fuzz target that is no longer "+
+			"discovered", "package", orphan.pkg, "target",
+			orphan.target, "count", closed)
+	}
+
+	return nil
+}
+
+// archiveOrphanedCorpus moves the inputs of the orphaned corpus at corpusPath
+// into archivePath, which may already hold inputs archived under the same
+// target name, and removes the emptied corpus directory.
+func archiveOrphanedCorpus(corpusPath, archivePath string) error {
+	if err := EnsureDirExists(archivePath); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(corpusPath)
+	if err != nil {
+		return err
+	}
+
+	// Inputs are named after their content, so an input already archived
+	// under the same name is the same input.
+	for _, entry := range entries {
+		err := os.Rename(filepath.Join(corpusPath, entry.Name()),
+			filepath.Join(archivePath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.RemoveAll(corpusPath)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFindOrphanedTargets verifies that only the corpora of the configured
+// packages' targets that are no longer discovered are orphaned.
+func TestFindOrphanedTargets(t *testing.T) {
+	corpusDir := t.TempDir()
+	writeFiles(t, corpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzFoo/a":  "input",
+		"parser/testdata/fuzz/FuzzBar/b":  "input",
+		"parser/testdata/fuzz/README":     "not a corpus",
+		"lexer/testdata/fuzz/FuzzLex/c":   "input",
+		"ignored/testdata/fuzz/FuzzOld/d": "input",
+	})
+
+	orphans, err := findOrphanedTargets(corpusDir,
+		[]string{"parser", "lexer", "missing"}, []TargetState{
+			{PkgPath: "parser", Target: "FuzzFoo"},
+			{PkgPath: "lexer", Target: "FuzzBar"},
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []orphanedTarget{
+		{pkg: "parser", target: "FuzzBar"},
+		{pkg: "lexer", target: "FuzzLex"},
+	}, orphans)
+}
+
+// TestHandleOrphanedTargets verifies that the corpus of a target that is no
+// longer discovered is kept, archived or deleted as configured, leaving the
+// corpora of the discovered targets alone.
+func TestHandleOrphanedTargets(t *testing.T) {
+	discovered := []TargetState{{PkgPath: "parser", Target: "FuzzBar"}}
+	corpusPath := filepath.Join("parser", "testdata", "fuzz")
+	archivePath := filepath.Join("parser", "testdata", OrphanCorpusDir)
+
+	tests := []struct {
+		policy   string
+		exists   []string
+		notExist []string
+	}{
+		{
+			policy: OrphanCorpusKeep,
+			exists: []string{
+				filepath.Join(corpusPath, "FuzzFoo", "a"),
+			},
+			notExist: []string{archivePath},
+		},
+		{
+			policy: OrphanCorpusArchive,
+			exists: []string{
+				filepath.Join(archivePath, "FuzzFoo", "a"),
+				filepath.Join(archivePath, "FuzzFoo", "old"),
+			},
+			notExist: []string{
+				filepath.Join(corpusPath, "FuzzFoo"),
+			},
+		},
+		{
+			policy: OrphanCorpusDelete,
+			notExist: []string{
+				filepath.Join(corpusPath, "FuzzFoo"),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.policy, func(t *testing.T) {
+			corpusDir := t.TempDir()
+			writeFiles(t, corpusDir, map[string]string{
+				"parser/testdata/fuzz/FuzzFoo/a": "input",
+				"parser/testdata/fuzz/FuzzBar/b": "input",
+			})
+
+			// Inputs archived by a previous cycle are kept.
+			if tc.policy == OrphanCorpusArchive {
+				writeFiles(t, corpusDir, map[string]string{
+					"parser/testdata/" + OrphanCorpusDir +
+						"/FuzzFoo/old": "input",
+				})
+			}
+
+			cfg := &Config{
+				Project: Project{CorpusDir: corpusDir},
+				Fuzz: Fuzz{
+					PkgsPath:           []string{"parser"},
+					OrphanCorpusPolicy: tc.policy,
+				},
+			}
+			err := handleOrphanedTargets(context.Background(),
+				slog.New(slog.DiscardHandler), cfg,
+				cfg.Fuzz.PkgsPath, discovered)
+			assert.NoError(t, err)
+
+			assert.FileExists(t, filepath.Join(corpusDir,
+				corpusPath, "FuzzBar", "b"))
+			for _, path := range tc.exists {
+				assert.FileExists(t, filepath.Join(corpusDir,
+					path))
+			}
+			for _, path := range tc.notExist {
+				_, err := os.Stat(filepath.Join(corpusDir,
+					path))
+				assert.True(t, os.IsNotExist(err), path)
+			}
+		})
+	}
+}
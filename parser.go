@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 )
 
 var (
@@ -39,69 +41,150 @@ var (
 	fuzzFileLineRegex = regexp.MustCompile(
 		`\s*(?P<file>.*\.go):(?P<line>[0-9]+)`,
 	)
+
+	// fuzzOOMRegex matches lines indicating that the fuzzer ran out of
+	// memory or was killed, usually by the kernel for exhausting it. Only
+	// the whole lines printed by the Go runtime and by exec are matched,
+	// so that a fuzz target logging "out of memory" itself is not taken
+	// for an out-of-memory crash.
+	//
+	// It matches lines like:
+	//   "fatal error: runtime: out of memory"
+	//   "signal: killed"
+	fuzzOOMRegex = regexp.MustCompile(
+		`(?m)^fatal error: runtime: out of memory$|^signal: killed$`)
+
+	// errTestcaseNotFound is returned by parseIssueBody when the issue
+	// body has no failing testcase section.
+	errTestcaseNotFound = errors.New("failing testcase section not found")
+
+	// errTestcaseTruncated is returned by parseIssueBody when the failing
+	// testcase section of the issue body is incomplete, either because
+	// the body was cut off, or because the failing input was truncated to
+	// fit the issue body limit, so the input cannot be reproduced.
+	errTestcaseTruncated = errors.New("failing testcase section truncated")
 )
 
 // fuzzCrash represents information about a crash encountered during fuzz
-// testing. It captures the error logs, the input that caused the failure, and
-// the location in the code where the first error occurred.
+// testing. It captures the error logs, the input that caused the failure and
+// the name it was saved under, the location in the code where the first error
+// occurred, and the last progress line of the fuzzer before the crash, if any.
 type fuzzCrash struct {
 	errorLogs          string
 	failingInput       string
+	failingInputID     string
 	failureFileAndLine string
+	progress           string
 }
 
 // fuzzOutputProcessor handles parsing and logging of fuzzing output streams,
-// detecting failures, and capturing/logging failing input data.
+// detecting failures, and capturing/logging failing input data. It also keeps
+// the last lines of the output preceding any failure, to describe exits that
+// are not recognized as failures, and the last progress line of the fuzzer.
 type fuzzOutputProcessor struct {
 	// Logger for informational and error messages.
 	logger *slog.Logger
 
 	// Directory containing the fuzzing corpus.
 	corpusDir string
+
+	// Fuzzing engine whose output format is parsed.
+	engine fuzzEngine
+
+	// The last (at most OutputTailLines) lines of the output scanned
+	// while looking for a failure.
+	tail []string
+
+	// The last progress line of the fuzzer scanned while looking for a
+	// failure.
+	progress string
+
+	// Whether a line telling that the fuzzer ran out of memory was scanned
+	// while looking for a failure.
+	oom bool
+
+	// If set, receives the number of executions of every progress line of
+	// the fuzzer, so it can be read while the output is still processed.
+	execs *atomic.Int64
 }
 
-// NewFuzzOutputProcessor constructs a fuzzOutputProcessor for the given logger
-// and corpus directory.
-func NewFuzzOutputProcessor(logger *slog.Logger,
-	corpusDir string) *fuzzOutputProcessor {
+// NewFuzzOutputProcessor constructs a fuzzOutputProcessor for the given logger,
+// corpus directory, and fuzzing engine.
+func NewFuzzOutputProcessor(logger *slog.Logger, corpusDir string,
+	engine fuzzEngine) *fuzzOutputProcessor {
 
 	return &fuzzOutputProcessor{
 		logger:    logger,
 		corpusDir: corpusDir,
+		engine:    engine,
 	}
 }
 
 // processFuzzStream reads each line from the fuzzing output stream, logs all
-// lines, and captures failure details if a failure is detected.
+// lines, and captures failure details if a failure is detected. If the output
+// ends without a failure but tells that the fuzzer ran out of memory, an
+// out-of-memory crash located at OOMFailureLocation is returned instead.
 func (fp *fuzzOutputProcessor) processFuzzStream(stream io.Reader) (*fuzzCrash,
 	error) {
 
 	scanner := bufio.NewScanner(stream)
 
-	// Scan until a failure line is found; if not found, return nil.
+	// Scan until a failure line is found; if not found, only report an
+	// out-of-memory crash, if any.
 	if !fp.scanUntilFailure(scanner) {
-		return nil, nil
+		if !fp.oom {
+			return nil, nil
+		}
+
+		return &fuzzCrash{
+			errorLogs:          fp.outputTail() + "\n",
+			failureFileAndLine: OOMFailureLocation,
+			progress:           fp.progress,
+		}, nil
 	}
 
 	// Process and log failure lines, capturing error data.
 	return fp.processFailureLines(scanner)
 }
 
-// scanUntilFailure scans the output until a failure indicator (--- FAIL:) is
-// found. Returns true if a failure line is detected, false otherwise.
+// scanUntilFailure scans the output until a failure indicator of the fuzzing
+// engine (e.g. --- FAIL:) is found. Returns true if a failure line is detected,
+// false otherwise.
 func (fp *fuzzOutputProcessor) scanUntilFailure(scanner *bufio.Scanner) bool {
 	for scanner.Scan() {
 		line := scanner.Text()
 		fp.logger.Info("Fuzzer output", "message", line)
 
+		if len(fp.tail) == OutputTailLines {
+			fp.tail = fp.tail[1:]
+		}
+		fp.tail = append(fp.tail, line)
+
+		if fp.engine.isProgressLine(line) {
+			fp.progress = line
+			if fp.execs != nil {
+				fp.execs.Store(fp.engine.progressExecs(line))
+			}
+		}
+
+		if fuzzOOMRegex.MatchString(line) {
+			fp.oom = true
+		}
+
 		// Detect the start of a failure section.
-		if strings.Contains(line, "--- FAIL:") {
+		if fp.engine.isFailureLine(line) {
 			return true
 		}
 	}
 	return false
 }
 
+// outputTail returns the last lines of the output scanned while looking for a
+// failure.
+func (fp *fuzzOutputProcessor) outputTail() string {
+	return strings.Join(fp.tail, "\n")
+}
+
 // processFailureLines scans the fuzzer output line by line after a failure is
 // detected. It collects relevant log lines, extracts the location of the first
 // error for deduplication, attempts to read the failing input data (if
@@ -111,6 +194,7 @@ func (fp *fuzzOutputProcessor) processFailureLines(scanner *bufio.Scanner,
 
 	var failingLog string
 	var failingInputString string
+	var failingInputID string
 	var failingFileLine string
 
 	for scanner.Scan() {
@@ -147,7 +231,7 @@ func (fp *fuzzOutputProcessor) processFailureLines(scanner *bufio.Scanner,
 		//   failure while testing seed corpus entry: FuzzFoo/seed#0
 		//
 		// As a result, no error data will be printed.
-		target, id := parseFailureLine(line)
+		target, id := fp.engine.parseFailureLine(line)
 		// If either target or ID is empty, skip further processing.
 		if target == "" || id == "" {
 			continue
@@ -161,13 +245,16 @@ func (fp *fuzzOutputProcessor) processFailureLines(scanner *bufio.Scanner,
 			return nil,
 				fmt.Errorf("processing fuzz stream: %w", err)
 		}
+		failingInputID = id
 	}
 
 	// Send all captured fuzz crash data to notify the caller.
 	return &fuzzCrash{
 		errorLogs:          failingLog,
 		failingInput:       failingInputString,
+		failingInputID:     failingInputID,
 		failureFileAndLine: failingFileLine,
+		progress:           fp.progress,
 	}, nil
 }
 
@@ -225,15 +312,25 @@ func parseFailureLine(line string) (string, string) {
 
 // parseIssueBody extracts and returns the content of the "## Failing testcase"
 // section from the issue body. This section contains the input that caused a
-// crash in the given fuzz target.
+// crash in the given fuzz target. Returns errTestcaseNotFound if the body has
+// no such section, and errTestcaseTruncated if the section is incomplete.
 func parseIssueBody(body string) (string, error) {
 	// failingInputRegex matches an issue body and captures the text inside
 	// the "## Failing testcase" section.
+	const header = "## Failing testcase\n~~~sh\n"
 	failingInputRegex := regexp.MustCompile(
-		`(?s)## Failing testcase\n~~~sh\n(.*?)\n~~~`)
+		`(?s)` + header + `(.*?)\n~~~`)
 	match := failingInputRegex.FindStringSubmatch(body)
 	if len(match) < 2 {
-		return "", fmt.Errorf("failing testcase section not found")
+		// A section that is never closed was cut off.
+		if strings.Contains(body, header) {
+			return "", errTestcaseTruncated
+		}
+		return "", errTestcaseNotFound
+	}
+
+	if strings.HasSuffix(match[1], truncatedMarker) {
+		return "", errTestcaseTruncated
 	}
 
 	return match[1], nil
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"log/slog"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -122,7 +125,7 @@ func TestReadInputData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			processor := NewFuzzOutputProcessor(&slog.Logger{},
-				tt.corpusPath)
+				tt.corpusPath, &goFuzzEngine{})
 			actualData, err := processor.readFailingInput(
 				tt.fuzzTarget, tt.testcaseID)
 
@@ -169,6 +172,20 @@ func TestParseIssueBody(t *testing.T) {
 			expectedInput: "",
 			expectErrMsg:  "failing testcase section not found",
 		},
+		{
+			name: "unclosed section",
+			body: "## Error logs\n## Failing " +
+				"testcase\n~~~sh\ngo test fuzz v1",
+			expectedInput: "",
+			expectErrMsg:  "failing testcase section truncated",
+		},
+		{
+			name: "truncated input",
+			body: "## Failing testcase\n~~~sh\ngo test " +
+				truncatedMarker + "\n~~~\n" + waterMark + "\n",
+			expectedInput: "",
+			expectErrMsg:  "failing testcase section truncated",
+		},
 	}
 
 	for _, tt := range tests {
@@ -185,3 +202,147 @@ func TestParseIssueBody(t *testing.T) {
 		})
 	}
 }
+
+// TestOutputTail verifies that the processor keeps only the last
+// OutputTailLines lines of an output without a recognized failure.
+func TestOutputTail(t *testing.T) {
+	var lines []string
+	for i := 1; i <= OutputTailLines+5; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err := processor.processFuzzStream(strings.NewReader(
+		strings.Join(lines, "\n")))
+	assert.NoError(t, err)
+	assert.Nil(t, crash)
+	assert.Equal(t, strings.Join(lines[5:], "\n"), processor.outputTail())
+}
+
+// TestCrashProgress verifies that a crash carries the last progress line of
+// the fuzzer preceding it, and none if the fuzzer reported no progress.
+func TestCrashProgress(t *testing.T) {
+	output := "fuzz: elapsed: 0s, gathering baseline coverage: 0/3 " +
+		"completed\n" +
+		"fuzz: elapsed: 3s, execs: 1024 (341/sec), new interesting: " +
+		"2 (total: 5)\n" +
+		"fuzz: elapsed: 6s, execs: 2048 (341/sec), new interesting: " +
+		"4 (total: 7)\n" +
+		"--- FAIL: FuzzFoo (6.01s)\n" +
+		"    foo_test.go:12: unexpected result\n"
+
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err := processor.processFuzzStream(strings.NewReader(output))
+	assert.NoError(t, err)
+	assert.Equal(t, "fuzz: elapsed: 6s, execs: 2048 (341/sec), new "+
+		"interesting: 4 (total: 7)", crash.progress)
+
+	processor = NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err = processor.processFuzzStream(strings.NewReader(
+		"--- FAIL: FuzzFoo (0.00s)\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, crash.progress)
+}
+
+// TestProgressExecs verifies that the number of executions of the last
+// progress line of the fuzzer is published while the output is processed, even
+// if fuzzing ends without a crash.
+func TestProgressExecs(t *testing.T) {
+	output := "fuzz: elapsed: 0s, gathering baseline coverage: 0/3 " +
+		"completed\n" +
+		"fuzz: elapsed: 3s, execs: 1024 (341/sec), new interesting: " +
+		"2 (total: 5)\n" +
+		"PASS\n"
+
+	var execs atomic.Int64
+	execs.Store(-1)
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	processor.execs = &execs
+	crash, err := processor.processFuzzStream(strings.NewReader(output))
+	assert.NoError(t, err)
+	assert.Nil(t, crash)
+	assert.EqualValues(t, 1024, execs.Load())
+}
+
+// TestCrashFailingInputID verifies that a crash carries the name its failing
+// input was saved under, so other failing inputs of the run can be told apart.
+func TestCrashFailingInputID(t *testing.T) {
+	output := "--- FAIL: FuzzFoo (0.02s)\n" +
+		"    --- FAIL: FuzzFoo (0.00s)\n" +
+		"        foo_test.go:12: unexpected result\n\n" +
+		"    Failing input written to testdata/fuzz/FuzzFoo/" +
+		"771e938e4458e983\n"
+
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err := processor.processFuzzStream(strings.NewReader(output))
+	assert.NoError(t, err)
+	assert.Equal(t, "771e938e4458e983", crash.failingInputID)
+	assert.Equal(t, "go test fuzz v1\nstring(\"0\")\n", crash.failingInput)
+}
+
+// TestOOMCrash verifies that an output telling that the fuzzer ran out of
+// memory without a failure is reported as an out-of-memory crash, and that a
+// failure takes precedence over it.
+func TestOOMCrash(t *testing.T) {
+	output := "fuzz: elapsed: 3s, execs: 1024 (341/sec), new " +
+		"interesting: 2 (total: 5)\n" +
+		"fatal error: runtime: out of memory\n"
+
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err := processor.processFuzzStream(strings.NewReader(output))
+	assert.NoError(t, err)
+	assert.Equal(t, OOMFailureLocation, crash.failureFileAndLine)
+	assert.Equal(t, output, crash.errorLogs)
+	assert.Equal(t, "fuzz: elapsed: 3s, execs: 1024 (341/sec), new "+
+		"interesting: 2 (total: 5)", crash.progress)
+
+	processor = NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err = processor.processFuzzStream(strings.NewReader(
+		"signal: killed\n" +
+			"--- FAIL: FuzzFoo (0.00s)\n" +
+			"    foo_test.go:12: unexpected result\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo_test.go:12", crash.failureFileAndLine)
+}
+
+// TestOOMCrashTargetOutput verifies that a fuzz target logging "out of memory"
+// itself before panicking is not taken for an out-of-memory crash.
+func TestOOMCrashTargetOutput(t *testing.T) {
+	for _, line := range []string{
+		"    cache_test.go:20: cache out of memory; evicting",
+		"error: allocator signal: killed worker",
+		"  fatal error: runtime: out of memory",
+	} {
+		assert.False(t, fuzzOOMRegex.MatchString(line), line)
+	}
+
+	processor := NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err := processor.processFuzzStream(strings.NewReader(
+		"fuzz: elapsed: 3s, execs: 1024 (341/sec), new " +
+			"interesting: 2 (total: 5)\n" +
+			"    cache_test.go:20: cache out of memory; " +
+			"evicting\n" +
+			"panic: boom\n\n" +
+			"goroutine 1 [running]:\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, crash)
+
+	processor = NewFuzzOutputProcessor(slog.New(slog.DiscardHandler),
+		"testdata", &goFuzzEngine{})
+	crash, err = processor.processFuzzStream(strings.NewReader(
+		"    cache_test.go:20: cache out of memory; evicting\n" +
+			"--- FAIL: FuzzCache (0.00s)\n" +
+			"    --- FAIL: FuzzCache (0.00s)\n" +
+			"panic: boom [recovered]\n" +
+			"    cache_test.go:25: +0x1d\n"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, OOMFailureLocation, crash.failureFileAndLine)
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/sync/errgroup"
+)
+
+// snapshotCorpus returns the set of paths, relative to corpusDir and using
+// forward slashes, of the corpus inputs in corpusDir. A missing corpusDir holds
+// no inputs.
+func snapshotCorpus(corpusDir string) (map[string]bool, error) {
+	inputs := make(map[string]bool)
+	err := filepath.WalkDir(corpusDir, func(p string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == corpusDir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(corpusDir, p)
+		if err != nil {
+			return err
+		}
+		inputs[filepath.ToSlash(rel)] = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list corpus inputs: %w", err)
+	}
+
+	return inputs, nil
+}
+
+// newCorpusInputs returns the paths, relative to corpusDir, of the corpus
+// inputs in corpusDir that are not in the known set, in sorted order.
+func newCorpusInputs(corpusDir string, known map[string]bool) ([]string,
+	error) {
+
+	inputs, err := snapshotCorpus(corpusDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for input := range inputs {
+		if !known[input] {
+			added = append(added, input)
+		}
+	}
+	sort.Strings(added)
+
+	return added, nil
+}
+
+// corpusUnchanged reports whether the corpus inputs in corpusDir are the same
+// as those of the given snapshot, taken with snapshotCorpus: no input was added
+// or removed since. Since the inputs are named after their content, changing
+// an input adds one. A nil snapshot never matches.
+func corpusUnchanged(corpusDir string, snapshot map[string]bool) (bool,
+	error) {
+
+	if snapshot == nil {
+		return false, nil
+	}
+
+	inputs, err := snapshotCorpus(corpusDir)
+	if err != nil {
+		return false, err
+	}
+
+	return maps.Equal(snapshot, inputs), nil
+}
+
+// selectQuarantined returns the quarantined inputs, given by their paths
+// relative to the quarantine prefix, that match one of the approved paths:
+// either the path of the input itself or of one of its parent directories. If
+// no paths are approved, all quarantined inputs are selected.
+func selectQuarantined(inputs, approved []string) []string {
+	if len(approved) == 0 {
+		return inputs
+	}
+
+	var selected []string
+	for _, input := range inputs {
+		for _, a := range approved {
+			a = strings.Trim(a, "/")
+			if input == a || strings.HasPrefix(input, a+"/") {
+				selected = append(selected, input)
+				break
+			}
+		}
+	}
+
+	return selected
+}
+
+// snapshotCorpusBaseline records the inputs of the local corpus, typically
+// right after it is downloaded, so only the inputs added afterwards are
+// quarantined.
+func (s3s *S3Store) snapshotCorpusBaseline() error {
+	baseline, err := snapshotCorpus(s3s.corpusDir)
+	if err != nil {
+		return err
+	}
+	s3s.corpusBaseline = baseline
+
+	return nil
+}
+
+// uploadQuarantine uploads the inputs added to the corpus since its baseline
+// was recorded to the quarantine prefix, one object per input, in parallel,
+// rather than updating the canonical corpus.
+func (s3s *S3Store) uploadQuarantine() error {
+	inputs, err := newCorpusInputs(s3s.corpusDir, s3s.corpusBaseline)
+	if err != nil {
+		return err
+	}
+
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentShardTransfers)
+	for _, input := range inputs {
+		g.Go(func() error {
+			return s3s.uploadQuarantinedInput(input)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	s3s.logger.Info("Quarantined new corpus inputs", "s3Bucket",
+		s3s.bucket, "prefix", s3s.quarantinePrefix, "inputs",
+		len(inputs))
+
+	return nil
+}
+
+// uploadQuarantinedInput uploads the corpus input at the given path, relative
+// to corpusDir, under the quarantine prefix.
+func (s3s *S3Store) uploadQuarantinedInput(input string) error {
+	inputPath := filepath.Join(s3s.corpusDir, filepath.FromSlash(input))
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("open corpus input %q: %w", inputPath, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			s3s.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	return s3s.uploadObject(file, s3s.quarantinePrefix+input,
+		"application/octet-stream", nil)
+}
+
+// listQuarantine returns the paths of the quarantined inputs, relative to the
+// quarantine prefix, in sorted order. Keys that cannot be extracted safely into
+// the corpus are ignored.
+func (s3s *S3Store) listQuarantine() ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(s3s.client,
+		&s3.ListObjectsV2Input{
+			Bucket: &s3s.bucket,
+			Prefix: &s3s.quarantinePrefix,
+		})
+
+	var inputs []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s3s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w",
+				err)
+		}
+
+		for _, item := range page.Contents {
+			input := strings.TrimPrefix(*item.Key,
+				s3s.quarantinePrefix)
+			if !filepath.IsLocal(filepath.FromSlash(input)) {
+				s3s.logger.Warn("Ignoring invalid quarantined "+
+					"input", "key", *item.Key)
+				continue
+			}
+			inputs = append(inputs, input)
+		}
+	}
+	sort.Strings(inputs)
+
+	return inputs, nil
+}
+
+// promotableInput reports whether the quarantined input, given by its path
+// relative to the quarantine prefix, can be promoted to the canonical corpus.
+// In sharded mode, only the shards of the configured packages are uploaded, so
+// inputs of other packages are left in quarantine rather than lost.
+func (s3s *S3Store) promotableInput(input string) bool {
+	if !s3s.sharded {
+		return true
+	}
+
+	for _, pkg := range s3s.pkgs {
+		if strings.HasPrefix(input, path.Join(pkg, "testdata")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// promoteQuarantine merges the approved quarantined inputs (all of them if none
+// are given) into the canonical corpus and removes them from quarantine.
+// Returns the promoted inputs.
+func (s3s *S3Store) promoteQuarantine(approved []string) ([]string, error) {
+	inputs, err := s3s.listQuarantine()
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted []string
+	for _, input := range selectQuarantined(inputs, approved) {
+		if !s3s.promotableInput(input) {
+			s3s.logger.Warn("Quarantined input is not part of a "+
+				"configured package; leaving it in quarantine",
+				"input", input)
+			continue
+		}
+		promoted = append(promoted, input)
+	}
+	if len(promoted) == 0 {
+		return nil, errors.New("no quarantined inputs to promote")
+	}
+
+	lastMinTime, err := s3s.getLastMinimizedTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the canonical corpus on its own, so no local inputs are
+	// promoted along with the approved ones.
+	if err := os.RemoveAll(s3s.corpusDir); err != nil {
+		return nil, fmt.Errorf("removing local corpus: %w", err)
+	}
+	if err := EnsureDirExists(s3s.corpusDir); err != nil {
+		return nil, err
+	}
+	if _, err := s3s.downloadCorpus(); err != nil {
+		return nil, fmt.Errorf("corpus download failed: %w", err)
+	}
+
+	for _, input := range promoted {
+		inputPath := filepath.Join(s3s.corpusDir,
+			filepath.FromSlash(input))
+		if err := EnsureDirExists(filepath.Dir(inputPath)); err != nil {
+			return nil, err
+		}
+
+		_, err := s3s.downloadObject(inputPath,
+			s3s.quarantinePrefix+input)
+		if err != nil {
+			return nil, fmt.Errorf("quarantined input %q: %w",
+				input, err)
+		}
+	}
+
+	if s3s.sharded {
+		err = s3s.uploadCorpusShards(lastMinTime)
+	} else {
+		err = s3s.uploadArchive(s3s.corpusDir, s3s.corpusKey,
+			lastMinTime)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("corpus upload failed: %w", err)
+	}
+
+	if s3s.versioned {
+		if err := s3s.archiveCorpus(time.Now()); err != nil {
+			return nil, fmt.Errorf("corpus archival failed: %w",
+				err)
+		}
+	}
+
+	// Only remove the inputs from quarantine once they are part of the
+	// canonical corpus.
+	for _, input := range promoted {
+		key := s3s.quarantinePrefix + input
+		input := &s3.DeleteObjectInput{Bucket: &s3s.bucket, Key: &key}
+		_, err := s3s.client.DeleteObject(s3s.ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("deleting s3://%s/%s: %w",
+				s3s.bucket, key, err)
+		}
+	}
+
+	return promoted, nil
+}
+
+// runPromoteCorpus promotes the quarantined inputs approved with the
+// promote-corpus subcommand to the canonical corpus.
+func runPromoteCorpus(ctx context.Context, logger *slog.Logger,
+	cfg *Config) error {
+
+	if cfg.Project.StorageBackend != StorageBackendS3 {
+		return errors.New("promoting quarantined inputs is only " +
+			"supported with the s3 storage backend")
+	}
+
+	s3s, err := NewS3Store(ctx, logger, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 store: %w", err)
+	}
+
+	promoted, err := s3s.promoteQuarantine(cfg.PromoteCorpus.Inputs)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Promoted quarantined corpus inputs", "s3Bucket",
+		s3s.bucket, "inputs", len(promoted))
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewCorpusInputs verifies that only the corpus inputs added since the
+// corpus snapshot was taken are selected for quarantine.
+func TestNewCorpusInputs(t *testing.T) {
+	corpusDir := filepath.Join(t.TempDir(), "repo_corpus")
+
+	// A missing corpus holds no inputs.
+	known, err := snapshotCorpus(corpusDir)
+	assert.NoError(t, err)
+	assert.Empty(t, known)
+
+	writeFiles(t, corpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzEval/seed1": "1",
+		"parser/testdata/fuzz/FuzzEval/seed2": "2",
+	})
+	known, err = snapshotCorpus(corpusDir)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"parser/testdata/fuzz/FuzzEval/seed1": true,
+		"parser/testdata/fuzz/FuzzEval/seed2": true,
+	}, known)
+
+	writeFiles(t, corpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzEval/seed2": "changed",
+		"parser/testdata/fuzz/FuzzEval/new":   "3",
+		"tree/testdata/fuzz/FuzzBuild/new":    "4",
+	})
+	added, err := newCorpusInputs(corpusDir, known)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"parser/testdata/fuzz/FuzzEval/new",
+		"tree/testdata/fuzz/FuzzBuild/new",
+	}, added)
+}
+
+// TestCorpusUnchanged verifies that the corpus is only unchanged if no input
+// was added or removed since the corpus snapshot was taken.
+func TestCorpusUnchanged(t *testing.T) {
+	corpusDir := filepath.Join(t.TempDir(), "repo_corpus")
+	writeFiles(t, corpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzEval/seed1": "1",
+		"parser/testdata/fuzz/FuzzEval/seed2": "2",
+	})
+
+	// Without a snapshot, the corpus is never unchanged.
+	unchanged, err := corpusUnchanged(corpusDir, nil)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	snapshot, err := snapshotCorpus(corpusDir)
+	assert.NoError(t, err)
+	unchanged, err = corpusUnchanged(corpusDir, snapshot)
+	assert.NoError(t, err)
+	assert.True(t, unchanged)
+
+	writeFiles(t, corpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzEval/new": "3",
+	})
+	unchanged, err = corpusUnchanged(corpusDir, snapshot)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+
+	// Removing an input changes the corpus as well.
+	assert.NoError(t, os.Remove(filepath.Join(corpusDir, "parser",
+		"testdata", "fuzz", "FuzzEval", "new")))
+	assert.NoError(t, os.Remove(filepath.Join(corpusDir, "parser",
+		"testdata", "fuzz", "FuzzEval", "seed1")))
+	unchanged, err = corpusUnchanged(corpusDir, snapshot)
+	assert.NoError(t, err)
+	assert.False(t, unchanged)
+}
+
+// TestSelectQuarantined verifies that quarantined inputs are approved either
+// by their own path or by the path of one of their parent directories, and
+// that all inputs are selected if none are approved explicitly.
+func TestSelectQuarantined(t *testing.T) {
+	inputs := []string{
+		"parser/testdata/fuzz/FuzzEval/a1",
+		"parser/testdata/fuzz/FuzzEval/b2",
+		"parser/testdata/fuzz/FuzzEvalAll/c3",
+		"tree/testdata/fuzz/FuzzBuild/d4",
+	}
+
+	assert.Equal(t, inputs, selectQuarantined(inputs, nil))
+
+	assert.Equal(t, []string{
+		"parser/testdata/fuzz/FuzzEval/a1",
+		"parser/testdata/fuzz/FuzzEval/b2",
+		"tree/testdata/fuzz/FuzzBuild/d4",
+	}, selectQuarantined(inputs, []string{
+		"parser/testdata/fuzz/FuzzEval/",
+		"tree/testdata/fuzz/FuzzBuild/d4",
+	}))
+
+	assert.Empty(t, selectQuarantined(inputs, []string{"lexer"}))
+}
+
+// TestPromotableInput verifies that in sharded mode only the inputs of the
+// configured packages can be promoted.
+func TestPromotableInput(t *testing.T) {
+	s3s := &S3Store{pkgs: []string{"parser", "encoding/json"}}
+	assert.True(t, s3s.promotableInput("lexer/testdata/fuzz/FuzzLex/a"))
+
+	s3s.sharded = true
+	assert.True(t, s3s.promotableInput("parser/testdata/fuzz/FuzzEval/a"))
+	assert.True(t, s3s.promotableInput(
+		"encoding/json/testdata/fuzz/FuzzDecode/b"))
+	assert.False(t, s3s.promotableInput("lexer/testdata/fuzz/FuzzLex/a"))
+	assert.False(t, s3s.promotableInput(
+		"parser/sub/testdata/fuzz/FuzzSub/c"))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+const (
+	// dockerHubDomain is the domain of the images hosted on Docker Hub,
+	// e.g. golang:1.24.6.
+	dockerHubDomain = "docker.io"
+
+	// dockerHubAuthKey is the key of the Docker Hub credentials in the
+	// Docker CLI configuration file.
+	dockerHubAuthKey = "https://index.docker.io/v1/"
+)
+
+// dockerConfigFile is the subset of the Docker CLI configuration file holding
+// the registry credentials stored by docker login.
+type dockerConfigFile struct {
+	Auths map[string]registry.AuthConfig `json:"auths"`
+}
+
+// dockerConfigPath returns the path of the Docker CLI configuration file:
+// config.json in $DOCKER_CONFIG if set, or in ~/.docker otherwise.
+func dockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// registryAuthDomain returns the registry domain of a key of the auths of the
+// Docker CLI configuration file, e.g. registry.example.com for
+// https://registry.example.com/v2/, or docker.io for Docker Hub.
+func registryAuthDomain(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	domain, _, _ := strings.Cut(key, "/")
+
+	switch domain {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubDomain
+	}
+	return domain
+}
+
+// resolveRegistryAuth returns the encoded credentials with which the container
+// image is pulled, for the RegistryAuth of the image pull options. The
+// credentials are, in order of priority, the given username:password
+// credentials, or those stored for the image's registry in the Docker CLI
+// configuration file at the given path, if any. Returns an empty string if no
+// credentials apply, to pull the image anonymously.
+func resolveRegistryAuth(containerImage, credentials,
+	configPath string) (string, error) {
+
+	named, err := reference.ParseNormalizedNamed(containerImage)
+	if err != nil {
+		return "", fmt.Errorf("invalid container image %q: %w",
+			containerImage, err)
+	}
+	domain := reference.Domain(named)
+
+	serverAddress := domain
+	if domain == dockerHubDomain {
+		serverAddress = dockerHubAuthKey
+	}
+
+	if credentials != "" {
+		username, password, ok := strings.Cut(credentials, ":")
+		if !ok || username == "" || password == "" {
+			return "", errors.New("invalid registry auth: must " +
+				"be of the form username:password")
+		}
+
+		return registry.EncodeAuthConfig(registry.AuthConfig{
+			Username:      username,
+			Password:      password,
+			ServerAddress: serverAddress,
+		})
+	}
+
+	if configPath == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read Docker config: %w", err)
+	}
+
+	var config dockerConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("invalid Docker config %s: %w",
+			configPath, err)
+	}
+
+	for key, auth := range config.Auths {
+		if registryAuthDomain(key) != domain {
+			continue
+		}
+
+		// docker login stores the credentials as the base64 encoding
+		// of username:password, which the daemon does not decode.
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(
+				auth.Auth)
+			if err != nil {
+				return "", fmt.Errorf("invalid credentials of "+
+					"registry %s in Docker config: %w",
+					key, err)
+			}
+
+			username, password, ok := strings.Cut(
+				string(decoded), ":")
+			if !ok {
+				return "", fmt.Errorf("invalid credentials of "+
+					"registry %s in Docker config", key)
+			}
+			auth.Username, auth.Password = username, password
+			auth.Auth = ""
+		}
+
+		if auth.Username == "" && auth.IdentityToken == "" &&
+			auth.RegistryToken == "" {
+
+			continue
+		}
+
+		auth.ServerAddress = serverAddress
+		return registry.EncodeAuthConfig(auth)
+	}
+
+	return "", nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResolveRegistryAuth verifies that the image is pulled with the
+// configured credentials, or else with those stored by docker login for its
+// registry, and anonymously otherwise.
+func TestResolveRegistryAuth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+			"registry.example.com": {"auth": "YWxpY2U6czNjcjN0"},
+			"https://token.example.com/v2/": {"identitytoken": "tok"},
+			"helper.example.com": {}
+		},
+		"credsStore": "desktop"
+	}`), 0o600))
+
+	decode := func(encoded string) *registry.AuthConfig {
+		auth, err := registry.DecodeAuthConfig(encoded)
+		assert.NoError(t, err)
+		return auth
+	}
+
+	// The configured credentials take precedence over the Docker config.
+	encoded, err := resolveRegistryAuth("registry.example.com/fuzz:1",
+		"bob:hunter2", configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &registry.AuthConfig{
+		Username:      "bob",
+		Password:      "hunter2",
+		ServerAddress: "registry.example.com",
+	}, decode(encoded))
+
+	// The credentials stored by docker login are decoded.
+	encoded, err = resolveRegistryAuth("registry.example.com/fuzz:1", "",
+		configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &registry.AuthConfig{
+		Username:      "alice",
+		Password:      "s3cr3t",
+		ServerAddress: "registry.example.com",
+	}, decode(encoded))
+
+	encoded, err = resolveRegistryAuth("golang:1.24.6", "", configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &registry.AuthConfig{
+		Username:      "hub",
+		Password:      "secret",
+		ServerAddress: "https://index.docker.io/v1/",
+	}, decode(encoded))
+
+	encoded, err = resolveRegistryAuth("token.example.com/fuzz", "",
+		configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, &registry.AuthConfig{
+		IdentityToken: "tok",
+		ServerAddress: "token.example.com",
+	}, decode(encoded))
+
+	// Images of registries without stored credentials, or whose
+	// credentials are kept by a credential helper, are pulled
+	// anonymously, as are all images without a Docker config.
+	for _, tc := range []struct {
+		image, configPath string
+	}{
+		{"other.example.com/fuzz", configPath},
+		{"helper.example.com/fuzz", configPath},
+		{"golang:1.24.6", ""},
+		{"golang:1.24.6", filepath.Join(t.TempDir(), "config.json")},
+	} {
+		encoded, err := resolveRegistryAuth(tc.image, "", tc.configPath)
+		assert.NoError(t, err, tc.image)
+		assert.Empty(t, encoded, tc.image)
+	}
+
+	// Malformed credentials are rejected.
+	_, err = resolveRegistryAuth("golang:1.24.6", "bob", configPath)
+	assert.ErrorContains(t, err, "invalid registry auth")
+
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"auths": {`+
+		`"docker.io": {"auth": "not base64"}}}`), 0o600))
+	_, err = resolveRegistryAuth("golang:1.24.6", "", configPath)
+	assert.ErrorContains(t, err, "invalid credentials of registry")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -10,15 +11,27 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
-// MasterEntry represents an entry in the master index HTML file.
+// masterMu serializes the updates of the master state, the target statuses and
+// the master index, which read and rewrite files shared by all fuzzing cycles
+// using the same report directory.
+var masterMu sync.Mutex
+
+// MasterEntry represents an entry in the master index HTML file, along with
+// the status of the target's last fuzzing cycle (if any).
 type MasterEntry struct {
-	PkgPath  string
-	Target   string
-	LinkFile string
+	PkgPath    string
+	Target     string
+	LinkFile   string
+	Result     string
+	LastRun    string
+	Coverage   string
+	OpenIssues int
 }
 
 // TargetHistory stores the historical coverage data for a fuzzing target.
@@ -28,6 +41,15 @@ type TargetHistory struct {
 	ReportPath string
 }
 
+// CoveragePoint is a single coverage measurement of a fuzzing target, as
+// exported in the flat coverage time series for external charting tools.
+type CoveragePoint struct {
+	PkgPath  string  `json:"package"`
+	Target   string  `json:"target"`
+	Date     string  `json:"date"`
+	Coverage float64 `json:"coverage"`
+}
+
 // TargetState keeps track of registered fuzzing targets.
 type TargetState struct {
 	PkgPath string
@@ -45,6 +67,10 @@ type TargetPkgReport struct {
 	coverage       string
 	reportDir      string
 	reportHTMLPath string
+
+	// compactJSON writes the history file as compact JSON instead of
+	// indented JSON.
+	compactJSON bool
 }
 
 // loadMasterState loads the master state from a JSON file at the given path.
@@ -73,9 +99,21 @@ func loadMasterState(statePath string) ([]TargetState, error) {
 	return states, nil
 }
 
-// saveMasterState saves the master state to a JSON file at the given path.
-func saveMasterState(statePath string, states []TargetState) error {
-	stateData, err := json.MarshalIndent(states, "", "  ")
+// marshalReportJSON serializes v as compact JSON if compact is set, or as
+// indented JSON, which is easier to read, otherwise.
+func marshalReportJSON(v any, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// saveMasterState saves the master state to a JSON file at the given path, as
+// compact JSON if compact is set.
+func saveMasterState(statePath string, states []TargetState,
+	compact bool) error {
+
+	stateData, err := marshalReportJSON(states, compact)
 	if err != nil {
 		return fmt.Errorf("failed to serialize state: %w", err)
 	}
@@ -89,9 +127,13 @@ func saveMasterState(statePath string, states []TargetState) error {
 }
 
 // addToMaster adds new packages and targets to the master list, regenerates the
-// index.html report, and persists state changes.
+// index.html report, and persists state changes, as compact JSON if
+// compactJSON is set. It is safe for concurrent use.
 func addToMaster(projectName, reportDir string, newState []TargetState,
-	logger *slog.Logger) error {
+	compactJSON bool, logger *slog.Logger) error {
+
+	masterMu.Lock()
+	defer masterMu.Unlock()
 
 	// Load existing state
 	if err := EnsureDirExists(reportDir); err != nil {
@@ -130,16 +172,186 @@ func addToMaster(projectName, reportDir string, newState []TargetState,
 		return states[i].PkgPath < states[j].PkgPath
 	})
 
-	if err := saveMasterState(statePath, states); err != nil {
+	if err := saveMasterState(statePath, states, compactJSON); err != nil {
 		return fmt.Errorf("save master state to %q: %w", statePath, err)
 	}
 
+	return renderMasterIndex(projectName, reportDir, states, logger)
+}
+
+// updateMasterStatus applies the results of a fuzzing cycle to the persisted
+// target statuses (status.json), exports the coverage time series and
+// regenerates the index.html report. It is safe for concurrent use.
+func updateMasterStatus(projectName, reportDir string, status *cycleStatus,
+	logger *slog.Logger) error {
+
+	masterMu.Lock()
+	defer masterMu.Unlock()
+
+	statusPath := filepath.Join(reportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	statuses = status.merge(statuses)
+	if err := saveTargetStatuses(statusPath, statuses); err != nil {
+		return fmt.Errorf("save target statuses to %q: %w", statusPath,
+			err)
+	}
+
+	statePath := filepath.Join(reportDir, "state.json")
+	states, err := loadMasterState(statePath)
+	if err != nil {
+		return fmt.Errorf("load master state from %q: %w", statePath,
+			err)
+	}
+
+	if err := writeCoverageSeries(reportDir, states); err != nil {
+		return fmt.Errorf("write coverage time series: %w", err)
+	}
+
+	return renderMasterIndex(projectName, reportDir, states, logger)
+}
+
+// loadCoverageSeries collects the coverage history of the given targets from
+// their JSON history files into a flat time series, ordered by package, target
+// and date. Targets without a history file are skipped.
+func loadCoverageSeries(reportDir string,
+	states []TargetState) ([]CoveragePoint, error) {
+
+	series := []CoveragePoint{}
+	for _, s := range states {
+		history, err := loadTargetHistory(reportDir, s.PkgPath,
+			s.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		// The history is stored newest first.
+		for i := len(history) - 1; i >= 0; i-- {
+			coverage, err := strconv.ParseFloat(
+				history[i].Coverage, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid coverage %q "+
+					"of %s/%s: %w", history[i].Coverage,
+					s.PkgPath, s.Target, err)
+			}
+
+			series = append(series, CoveragePoint{
+				PkgPath:  s.PkgPath,
+				Target:   s.Target,
+				Date:     history[i].Date,
+				Coverage: coverage,
+			})
+		}
+	}
+
+	return series, nil
+}
+
+// loadTargetHistory loads the coverage history of the target from its JSON
+// history file, newest first. If the file does not exist, it returns an empty
+// slice.
+func loadTargetHistory(reportDir, pkg, target string) ([]TargetHistory,
+	error) {
+
+	jsonPath := filepath.Join(reportDir, "targets", pkg, target+".json")
+	historyData, err := os.ReadFile(jsonPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history file %q: %w", jsonPath,
+			err)
+	}
+
+	var history []TargetHistory
+	if err := json.Unmarshal(historyData, &history); err != nil {
+		return nil, fmt.Errorf("parse history JSON %q: %w", jsonPath,
+			err)
+	}
+
+	return history, nil
+}
+
+// writeCoverageSeries exports the coverage history of the given targets as a
+// flat time series, both as CSV (coverage.csv) and as a JSON array
+// (coverage.json), so it can be charted by external tools.
+func writeCoverageSeries(reportDir string, states []TargetState) error {
+	series, err := loadCoverageSeries(reportDir, states)
+	if err != nil {
+		return err
+	}
+
+	seriesData, err := json.MarshalIndent(series, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize coverage series: %w", err)
+	}
+	jsonPath := filepath.Join(reportDir, "coverage.json")
+	if err := os.WriteFile(jsonPath, seriesData, 0644); err != nil {
+		return fmt.Errorf("write coverage series %q: %w", jsonPath, err)
+	}
+
+	var csvData strings.Builder
+	w := csv.NewWriter(&csvData)
+	records := [][]string{{"package", "target", "date", "coverage"}}
+	for _, p := range series {
+		records = append(records, []string{p.PkgPath, p.Target, p.Date,
+			strconv.FormatFloat(p.Coverage, 'f', -1, 64)})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return fmt.Errorf("serialize coverage series: %w", err)
+	}
+	csvPath := filepath.Join(reportDir, "coverage.csv")
+	err = os.WriteFile(csvPath, []byte(csvData.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("write coverage series %q: %w", csvPath, err)
+	}
+
+	return nil
+}
+
+// renderMasterIndex regenerates the index.html report listing the given
+// targets, along with their persisted statuses.
+func renderMasterIndex(projectName, reportDir string, states []TargetState,
+	logger *slog.Logger) error {
+
+	statusPath := filepath.Join(reportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	statusByTarget := make(map[TargetState]TargetStatus, len(statuses))
+	for _, s := range statuses {
+		statusByTarget[TargetState{s.PkgPath, s.Target}] = s
+	}
+
 	// Generate index entries (index.html)
 	entries := make([]MasterEntry, len(states))
 	for i, s := range states {
 		linkFile := filepath.Join("targets", s.PkgPath,
 			s.Target+".html")
-		entries[i] = MasterEntry{s.PkgPath, s.Target, linkFile}
+		entries[i] = MasterEntry{
+			PkgPath:  s.PkgPath,
+			Target:   s.Target,
+			LinkFile: linkFile,
+		}
+
+		status, ok := statusByTarget[s]
+		if !ok {
+			continue
+		}
+		entries[i].Result = status.Result
+		entries[i].Coverage = status.Coverage
+		entries[i].OpenIssues = status.OpenIssues
+		if !status.LastRun.IsZero() {
+			entries[i].LastRun = status.LastRun.Format(
+				"2006-01-02 15:04 MST")
+		}
 	}
 
 	// Render master index template
@@ -201,7 +413,7 @@ func (r *TargetPkgReport) updateTarget() error {
 	history = append([]TargetHistory{newEntry}, history...)
 
 	// Save updated JSON history
-	historyData, err := json.MarshalIndent(history, "", "  ")
+	historyData, err := marshalReportJSON(history, r.compactJSON)
 	if err != nil {
 		return fmt.Errorf("serialize history for %q: %w", jsonPath, err)
 	}
@@ -233,34 +445,70 @@ func (r *TargetPkgReport) updateTarget() error {
 }
 
 // updateReport runs the fuzz target’s tests with coverage, generates an HTML
-// coverage report, and updates both the master index and the per-target history
-func updateReport(ctx context.Context, pkg, target string, cfg *Config,
-	logger *slog.Logger) error {
-
-	// Determine the package and corpus paths.
+// coverage report, and updates both the master index and the per-target
+// history. Returns the measured coverage percentage.
+//
+// The tests run in the given worker's staging directory rather than in the
+// package directory, which is shared by all workers: the test binary, the
+// package's testdata directory merged with the target's corpus, and the
+// coverage profile are all kept there, so concurrent coverage runs of targets
+// in the same package cannot interfere. Only the target's own seed corpus is
+// staged, so the cleanup of the seed corpora of the targets fuzzed by other
+// workers cannot interfere either.
+func updateReport(ctx context.Context, pkg, target string, workerID int,
+	cfg *Config, logger *slog.Logger) (string, error) {
+
+	// Determine the package, corpus, and staging paths. The staging
+	// directory only ever holds the worker's current coverage run.
 	pkgPath := filepath.Join(cfg.Project.SrcDir, pkg)
 	corpusSrc := filepath.Join(cfg.Project.CorpusDir, pkg, "testdata",
 		"fuzz", target)
-	corpusDst := filepath.Join(pkgPath, "testdata", "fuzz", target)
+	stageDir := filepath.Join(cfg.Project.CoverageDir,
+		fmt.Sprintf("worker-%d", workerID))
+	if err := os.RemoveAll(stageDir); err != nil {
+		return "", fmt.Errorf("clean staging directory: %w", err)
+	}
+	if err := EnsureDirExists(stageDir); err != nil {
+		return "", fmt.Errorf("create staging directory: %w", err)
+	}
+
+	// Build the package's test binary with coverage instrumentation into
+	// the staging directory.
+	testBinary := filepath.Join(stageDir, fmt.Sprintf("%s.test", target))
+	buildCmd := []string{"test", "-c", "-cover", "-covermode=count", "-o",
+		testBinary}
+	if _, err := runGoCommand(ctx, pkgPath, buildCmd); err != nil {
+		return "", fmt.Errorf("go test build failed for %q: %w ", pkg,
+			err)
+	}
 
-	// Copy any existing corpus files into the testdata directory.
+	// Stage the package's testdata directory, then copy any existing
+	// corpus files of the target into it.
+	err := copyTargetTestdata(pkgPath, filepath.Join(stageDir, "testdata"),
+		target)
+	if err != nil {
+		return "", fmt.Errorf("testdata copy failed: %w", err)
+	}
+
+	corpusDst := filepath.Join(stageDir, "testdata", "fuzz", target)
 	if err := copyData(corpusSrc, corpusDst); err != nil {
-		return fmt.Errorf("corpus copy failed: %w", err)
+		return "", fmt.Errorf("corpus copy failed: %w", err)
 	}
 
-	// Run `go test` for this target with coverage profiling enabled.
-	testCmd := []string{"test", fmt.Sprintf("-run=^%s$", target),
-		fmt.Sprintf("-coverprofile=%s.out", target), "-covermode=count"}
-	testOutput, err := runGoCommand(ctx, pkgPath, testCmd)
+	// Run the tests of this target with coverage profiling enabled.
+	profile := filepath.Join(stageDir, fmt.Sprintf("%s.out", target))
+	testCmd := []string{fmt.Sprintf("-test.run=^%s$", target),
+		fmt.Sprintf("-test.coverprofile=%s", profile)}
+	testOutput, err := runCommand(ctx, stageDir, testBinary, testCmd)
 	if err != nil {
-		return fmt.Errorf("go test failed for %q: %w ", pkg, err)
+		return "", fmt.Errorf("go test failed for %q: %w ", pkg, err)
 	}
 
 	// Parse the coverage percentage from the test output.
 	coverageRe := regexp.MustCompile(`coverage:\s+([\d.]+)%`)
 	matches := coverageRe.FindStringSubmatch(testOutput)
 	if len(matches) < 2 {
-		return fmt.Errorf("coverage not found in output:\n%s",
+		return "", fmt.Errorf("coverage not found in output:\n%s",
 			testOutput)
 	}
 	coveragePct := matches[1]
@@ -269,16 +517,20 @@ func updateReport(ctx context.Context, pkg, target string, cfg *Config,
 	targetReportDir := filepath.Join(cfg.Project.ReportDir, "targets",
 		pkg, target)
 	if err := EnsureDirExists(targetReportDir); err != nil {
-		return fmt.Errorf("create target report directory: %w", err)
+		return "", fmt.Errorf("create target report directory: %w",
+			err)
 	}
 
 	htmlFileName := time.Now().Format("2006-01-02") + ".html"
 	reportPath := filepath.Join(targetReportDir, htmlFileName)
 
+	// The cover tool resolves the profiled source files from the package
+	// directory, which it only reads.
 	coverCmd := []string{"tool", "cover",
-		fmt.Sprintf("-html=%s.out", target), "-o", reportPath}
+		fmt.Sprintf("-html=%s", profile), "-o", reportPath}
 	if _, err := runGoCommand(ctx, pkgPath, coverCmd); err != nil {
-		return fmt.Errorf("go tool cover failed for %q: %w ", pkg, err)
+		return "", fmt.Errorf("go tool cover failed for %q: %w ", pkg,
+			err)
 	}
 
 	covReport := &TargetPkgReport{
@@ -288,12 +540,13 @@ func updateReport(ctx context.Context, pkg, target string, cfg *Config,
 		coverage:       coveragePct,
 		reportDir:      cfg.Project.ReportDir,
 		reportHTMLPath: filepath.Join(target, htmlFileName),
+		compactJSON:    cfg.Fuzz.CompactJSON,
 	}
 
 	// Record this run in the target's history and regenerate its HTML.
 	if err := covReport.updateTarget(); err != nil {
-		return fmt.Errorf("target history update failed: %w", err)
+		return "", fmt.Errorf("target history update failed: %w", err)
 	}
 
-	return nil
+	return coveragePct, nil
 }
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAddToMasterSameTargetName verifies that same-named fuzz targets in
+// different packages are tracked as separate entries in the master state and
+// link to distinct per-target reports.
+func TestAddToMasterSameTargetName(t *testing.T) {
+	reportDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	states := []TargetState{
+		{PkgPath: "x/parser", Target: "FuzzFoo"},
+		{PkgPath: "parser", Target: "FuzzFoo"},
+	}
+	assert.NoError(t, addToMaster("repo", reportDir, states, false,
+		logger))
+
+	// Adding the same targets again must not duplicate them.
+	assert.NoError(t, addToMaster("repo", reportDir, states, false,
+		logger))
+
+	saved, err := loadMasterState(filepath.Join(reportDir, "state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []TargetState{
+		{PkgPath: "parser", Target: "FuzzFoo"},
+		{PkgPath: "x/parser", Target: "FuzzFoo"},
+	}, saved)
+
+	index, err := os.ReadFile(filepath.Join(reportDir, "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), "targets/parser/FuzzFoo.html")
+	assert.Contains(t, string(index), "targets/x/parser/FuzzFoo.html")
+}
+
+// TestCompactReportJSON verifies that, with compact JSON enabled, the master
+// state and the target history are written without indentation and are still
+// loaded back.
+func TestCompactReportJSON(t *testing.T) {
+	reportDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	states := []TargetState{{PkgPath: "parser", Target: "FuzzFoo"}}
+	assert.NoError(t, addToMaster("repo", reportDir, states, true, logger))
+
+	statePath := filepath.Join(reportDir, "state.json")
+	stateData, err := os.ReadFile(statePath)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"PkgPath":"parser","Target":"FuzzFoo"}]`,
+		string(stateData))
+
+	saved, err := loadMasterState(statePath)
+	assert.NoError(t, err)
+	assert.Equal(t, states, saved)
+
+	targetDir := filepath.Join(reportDir, "targets", "parser")
+	assert.NoError(t, os.MkdirAll(targetDir, 0o755))
+	report := &TargetPkgReport{
+		logger:         logger,
+		pkg:            "parser",
+		target:         "FuzzFoo",
+		coverage:       "42.0",
+		reportDir:      reportDir,
+		reportHTMLPath: "FuzzFoo/2025-07-15.html",
+		compactJSON:    true,
+	}
+	assert.NoError(t, report.updateTarget())
+
+	historyData, err := os.ReadFile(filepath.Join(targetDir,
+		"FuzzFoo.json"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(historyData), "\n")
+
+	history, err := loadTargetHistory(reportDir, "parser", "FuzzFoo")
+	assert.NoError(t, err)
+	assert.Equal(t, []TargetHistory{{
+		Date:       "2025-07-15",
+		Coverage:   "42.0",
+		ReportPath: "FuzzFoo/2025-07-15.html",
+	}}, history)
+}
+
+// TestMasterConcurrentUpdates verifies that concurrent cycles sharing a report
+// directory do not lose each other's updates of the master state and statuses.
+func TestMasterConcurrentUpdates(t *testing.T) {
+	reportDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const cycles = 32
+	var wg sync.WaitGroup
+	errs := make([]error, 2*cycles)
+	for i := range cycles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			pkg := fmt.Sprintf("pkg%d", i)
+			states := []TargetState{
+				{PkgPath: pkg, Target: "FuzzFoo"},
+			}
+			errs[2*i] = addToMaster("repo", reportDir, states,
+				false, logger)
+
+			status := newCycleStatus()
+			status.schedule(pkg, "FuzzFoo")
+			status.record(pkg, "FuzzFoo", TargetResultOK, "50.0", 0)
+			errs[2*i+1] = updateMasterStatus("repo", reportDir,
+				status, logger)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	states, err := loadMasterState(filepath.Join(reportDir, "state.json"))
+	assert.NoError(t, err)
+	assert.Len(t, states, cycles)
+
+	statuses, err := loadTargetStatuses(filepath.Join(reportDir,
+		"status.json"))
+	assert.NoError(t, err)
+	assert.Len(t, statuses, cycles)
+
+	index, err := os.ReadFile(filepath.Join(reportDir, "index.html"))
+	assert.NoError(t, err)
+	for i := range cycles {
+		assert.Contains(t, string(index),
+			fmt.Sprintf("targets/pkg%d/FuzzFoo.html", i))
+	}
+}
+
+// writeFiles writes the given files, keyed by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// TestUpdateReportConcurrentTargets verifies that coverage reports of targets
+// in the same package can be generated concurrently by different workers, each
+// staging its run outside the shared package directory.
+func TestUpdateReportConcurrentTargets(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := &Config{
+		Project: Project{
+			SrcDir:      filepath.Join(workspace, "project"),
+			CorpusDir:   filepath.Join(workspace, "corpus"),
+			ReportDir:   filepath.Join(workspace, "reports"),
+			CoverageDir: filepath.Join(workspace, "coverage"),
+		},
+	}
+	logger := slog.New(slog.DiscardHandler)
+
+	writeFiles(t, cfg.Project.SrcDir, map[string]string{
+		"go.mod": "module example.com/fuzzme\n\ngo 1.21\n",
+		"parser/parser.go": `package parser
+
+func Classify(data []byte) string {
+	if len(data) > 3 {
+		return "long"
+	}
+	return "short"
+}
+
+func Sum(data []byte) int {
+	total := 0
+	for _, b := range data {
+		total += int(b)
+	}
+	return total
+}
+`,
+		"parser/parser_test.go": `package parser
+
+import (
+	"os"
+	"testing"
+)
+
+func FuzzClassify(f *testing.F) {
+	if _, err := os.Stat("testdata/config.txt"); err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Classify(data)
+	})
+}
+
+func FuzzSum(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Sum(data)
+	})
+}
+`,
+		"parser/testdata/config.txt": "config\n",
+	})
+
+	corpusInput := "go test fuzz v1\n[]byte(\"hello\")\n"
+	writeFiles(t, cfg.Project.CorpusDir, map[string]string{
+		"parser/testdata/fuzz/FuzzClassify/input": corpusInput,
+		"parser/testdata/fuzz/FuzzSum/input":      corpusInput,
+	})
+
+	targets := []string{"FuzzClassify", "FuzzSum"}
+	coverages := make([]string, len(targets))
+	errs := make([]error, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			coverages[i], errs[i] = updateReport(
+				context.Background(), "parser", target, i+1,
+				cfg, logger)
+		}()
+	}
+	wg.Wait()
+
+	date := time.Now().Format("2006-01-02")
+	for i, target := range targets {
+		assert.NoError(t, errs[i], target)
+		assert.NotEmpty(t, coverages[i], target)
+		assert.FileExists(t, filepath.Join(cfg.Project.ReportDir,
+			"targets", "parser", target, date+".html"))
+	}
+
+	// The shared package directory must be left untouched.
+	entries, err := os.ReadDir(filepath.Join(cfg.Project.SrcDir,
+		"parser"))
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"parser.go", "parser_test.go", "testdata"},
+		names)
+	assert.NoDirExists(t, filepath.Join(cfg.Project.SrcDir, "parser",
+		"testdata", "fuzz"))
+}
+
+// TestCopyTargetTestdataCleanupRace verifies that staging the testdata
+// directory of a target only copies its own seed corpus, so it does not race
+// with another worker cleaning up the seed corpus of its target in the same
+// package.
+func TestCopyTargetTestdataCleanupRace(t *testing.T) {
+	pkgPath := t.TempDir()
+	writeFiles(t, pkgPath, map[string]string{
+		"testdata/config.txt":        "config\n",
+		"testdata/fuzz/FuzzSum/seed": "seed\n",
+	})
+
+	// Another worker repeatedly writes and cleans up the seed corpus of
+	// its target, like minimizing its corpus does.
+	otherDir := filepath.Join(pkgPath, "testdata", "fuzz", "FuzzClassify")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			_ = os.MkdirAll(otherDir, 0755)
+			for i := range 20 {
+				_ = os.WriteFile(filepath.Join(otherDir,
+					fmt.Sprintf("seed%d", i)), nil, 0644)
+			}
+			_ = os.RemoveAll(otherDir)
+		}
+	}()
+
+	for range 200 {
+		destPath := filepath.Join(t.TempDir(), "testdata")
+		err := copyTargetTestdata(pkgPath, destPath, "FuzzSum")
+		assert.NoError(t, err)
+		assert.FileExists(t, filepath.Join(destPath, "config.txt"))
+		assert.FileExists(t, filepath.Join(destPath, "fuzz", "FuzzSum",
+			"seed"))
+		assert.NoDirExists(t, filepath.Join(destPath, "fuzz",
+			"FuzzClassify"))
+	}
+	cancel()
+	<-done
+}
+
+// TestWriteCoverageSeries verifies that the coverage history of all targets is
+// exported oldest first as flat CSV and JSON time series, skipping targets
+// without history.
+func TestWriteCoverageSeries(t *testing.T) {
+	reportDir := t.TempDir()
+	writeFiles(t, reportDir, map[string]string{
+		"targets/parser/FuzzEval.json": `[
+			{"Date": "2025-07-13", "Coverage": "72.5"},
+			{"Date": "2025-07-12", "Coverage": "70"}
+		]`,
+		"targets/x/tree/FuzzBuild.json": `[
+			{"Date": "2025-07-13", "Coverage": "41.0"}
+		]`,
+	})
+
+	states := []TargetState{
+		{PkgPath: "lexer", Target: "FuzzLex"},
+		{PkgPath: "parser", Target: "FuzzEval"},
+		{PkgPath: "x/tree", Target: "FuzzBuild"},
+	}
+	assert.NoError(t, writeCoverageSeries(reportDir, states))
+
+	csvData, err := os.ReadFile(filepath.Join(reportDir, "coverage.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "package,target,date,coverage\n"+
+		"parser,FuzzEval,2025-07-12,70\n"+
+		"parser,FuzzEval,2025-07-13,72.5\n"+
+		"x/tree,FuzzBuild,2025-07-13,41\n", string(csvData))
+
+	jsonData, err := os.ReadFile(filepath.Join(reportDir, "coverage.json"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"package": "parser", "target": "FuzzEval",
+		 "date": "2025-07-12", "coverage": 70},
+		{"package": "parser", "target": "FuzzEval",
+		 "date": "2025-07-13", "coverage": 72.5},
+		{"package": "x/tree", "target": "FuzzBuild",
+		 "date": "2025-07-13", "coverage": 41}
+	]`, string(jsonData))
+
+	// Without any history, the series are empty rather than missing.
+	emptyDir := t.TempDir()
+	assert.NoError(t, writeCoverageSeries(emptyDir, states))
+	jsonData, err = os.ReadFile(filepath.Join(emptyDir, "coverage.json"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(jsonData))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+)
+
+// runReproduce runs the fuzz target given to the reproduce command against its
+// input, in a container like the one verifying open issues, and writes the
+// output of the run to w. Returns whether the crash reproduced, i.e. whether
+// the run failed. It consists of:
+//  1. Cloning the Git repository specified in cfg.Project.SrcRepo.
+//  2. Building the fuzz binary of the target and writing the input to its
+//     testdata directory.
+//  3. Running the target against the input in a Docker container.
+//
+// No crash tracker is involved, so no issue is ever created or closed.
+func runReproduce(ctx context.Context, logger *slog.Logger, cfg *Config,
+	w io.Writer) (bool, error) {
+
+	opts := cfg.Reproduce
+	logger = logger.With("target", opts.Target).With("package",
+		opts.Package)
+
+	// Read the input first, so a wrong path fails before any work is done.
+	input, err := os.ReadFile(opts.Input)
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	// Cleanup the directories created during previous runs.
+	cleanupTmpDirs(logger, cfg, false)
+
+	// 1. Clone the repository based on the provided configuration.
+	logger.Info("Cloning project repository", "url",
+		SanitizeURL(cfg.Project.SrcRepo), "path", cfg.Project.SrcDir)
+
+	_, err = cloneWithRetries(ctx, logger, cfg)
+	if err != nil {
+		return false, fmt.Errorf("failed to clone project repository: "+
+			"%w", err)
+	}
+
+	// 2. Build the fuzz binary and copy the package's testdata next to it,
+	// as is done for the fuzzing cycles.
+	engine := newFuzzEngine(cfg.Fuzz.Engine)
+	err = createFuzzBinary(ctx, logger, cfg, engine, opts.Package,
+		opts.Target)
+	if err != nil {
+		return false, err
+	}
+
+	fuzzBinaryPath := filepath.Join(cfg.Project.BinaryDir, opts.Package,
+		opts.Target)
+	err = copyData(filepath.Join(cfg.Project.SrcDir, opts.Package,
+		"testdata"), filepath.Join(fuzzBinaryPath, "testdata"))
+	if err != nil {
+		return false, fmt.Errorf("failed to copy testdata directory: "+
+			"%w", err)
+	}
+
+	// Write the input where the reproduce command of the engine expects
+	// it, under the same kind of name as the failing inputs of the issues
+	// being verified.
+	inputDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz",
+		opts.Target)
+	if err := EnsureDirExists(inputDir); err != nil {
+		return false, err
+	}
+	inputID := ReproduceInputPrefix + corpusFileName(input)
+	err = os.WriteFile(filepath.Join(inputDir, inputID), input, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to write input: %w", err)
+	}
+
+	// 3. Run the fuzz target against the input in a Docker container.
+	cli, err := client.NewClientWithOpts(client.FromEnv,
+		client.WithAPIVersionNegotiation())
+	if err != nil {
+		return false, fmt.Errorf("failed to start docker client: %w",
+			err)
+	}
+	defer func() {
+		if err := cli.Close(); err != nil {
+			logger.Error("Failed to stop docker client", "error",
+				err)
+		}
+	}()
+
+	err = pullContainerImage(ctx, logger, cli, cfg.Fuzz.ContainerImage,
+		cfg.Fuzz.ImagePullAuth)
+	if err != nil {
+		return false, err
+	}
+
+	hostCorpusPath := filepath.Join(cfg.Project.CorpusDir, opts.Package,
+		"testdata", "fuzz")
+	if err := EnsureDirExists(hostCorpusPath); err != nil {
+		return false, err
+	}
+
+	c := newContainer(ctx, logger, cli, &cfg.Fuzz, engine, opts.Package,
+		opts.Target)
+	c.fuzzBinaryPath = fuzzBinaryPath
+	c.hostCorpusPath = hostCorpusPath
+	c.cmd = engine.reproduceCmd(opts.Target, inputID)
+
+	return reproduceInContainer(c, w)
+}
+
+// reproduceInContainer runs the given container, which must be set up to run
+// a fuzz target against a single input, copying its output to w. Returns
+// whether the crash reproduced, i.e. whether the container exited with an
+// error.
+func reproduceInContainer(c *Container, w io.Writer) (bool, error) {
+	containerID, err := c.Start()
+	if err != nil {
+		return false, fmt.Errorf("failed to start reproduce "+
+			"container: %w", err)
+	}
+	defer func() {
+		if err := c.Stop(containerID); err != nil {
+			c.logger.Error("Failed to stop container", "error",
+				err, "containerID", containerID)
+		}
+	}()
+
+	// The log stream ends once the container exits.
+	logs, err := c.followLogs(containerID)
+	if err != nil {
+		return false, fmt.Errorf("unable to attach to logs for "+
+			"container %s: %w", containerID, err)
+	}
+	defer func() {
+		if err := logs.Close(); err != nil {
+			c.logger.Error("error closing logs reader",
+				"container", containerID, "error", err)
+		}
+	}()
+
+	if _, err := io.Copy(w, logs); err != nil {
+		return false, fmt.Errorf("failed to copy container output: "+
+			"%w", err)
+	}
+
+	// As for the verification of open issues, the crash reproduces if the
+	// run fails.
+	if err := c.Wait(containerID); err != nil {
+		if c.ctx.Err() != nil {
+			return false, c.ctx.Err()
+		}
+		c.logger.Info("Crash reproduced", "error", err)
+		return true, nil
+	}
+
+	c.logger.Info("Crash not reproduced")
+	return false, nil
+}
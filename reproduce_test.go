@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunReproduceMissingInput verifies that the reproduce command fails
+// before cloning the project if its input cannot be read.
+func TestRunReproduceMissingInput(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "project")
+	cfg := &Config{
+		Project: Project{SrcDir: srcDir},
+		Reproduce: ReproduceCommand{
+			Package: "pkg",
+			Target:  "FuzzFoo",
+			Input:   filepath.Join(t.TempDir(), "crash.bin"),
+		},
+	}
+
+	var out bytes.Buffer
+	reproduced, err := runReproduce(context.Background(),
+		slog.New(slog.DiscardHandler), cfg, &out)
+	assert.ErrorContains(t, err, "failed to read input")
+	assert.False(t, reproduced)
+	assert.Empty(t, out.String())
+	assert.NoDirExists(t, srcDir)
+}
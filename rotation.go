@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// AssigneeRotationFile is the file of the report directory persisting the
+// cursor of the assignee rotation across cycles.
+const AssigneeRotationFile = "assignee-rotation.json"
+
+// rotationMu serializes the updates of the assignee rotation cursor, since
+// the workers open issues concurrently.
+var rotationMu sync.Mutex
+
+// assigneeRotation is the persisted state of the assignee rotation.
+type assigneeRotation struct {
+	// Cursor is the index in the rotation of the member to which the next
+	// issue is assigned, wrapping around if the rotation shrank since.
+	Cursor int
+}
+
+// nextRotationAssignee returns the member of the rotation whose turn it is to
+// be assigned a new issue, and advances the rotation cursor persisted in the
+// report directory, so the issues are spread evenly across the rotation over
+// all cycles. A missing cursor starts the rotation with its first member.
+func nextRotationAssignee(reportDir string, rotation []string) (string,
+	error) {
+
+	rotationMu.Lock()
+	defer rotationMu.Unlock()
+
+	rotationPath := filepath.Join(reportDir, AssigneeRotationFile)
+	var state assigneeRotation
+	data, err := os.ReadFile(rotationPath)
+	switch {
+	case os.IsNotExist(err):
+
+	case err != nil:
+		return "", fmt.Errorf("failed to read rotation file %q: %w",
+			rotationPath, err)
+
+	default:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return "", fmt.Errorf("invalid JSON in rotation file "+
+				"%q: %w", rotationPath, err)
+		}
+	}
+
+	i := max(state.Cursor, 0) % len(rotation)
+	state.Cursor = (i + 1) % len(rotation)
+
+	data, err = json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize rotation: %w", err)
+	}
+	if err := EnsureDirExists(reportDir); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(rotationPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write rotation file %q: %w",
+			rotationPath, err)
+	}
+
+	return rotation[i], nil
+}
+
+// issueAssignees returns the users to which a new issue is assigned: the
+// configured assignees, along with the member of the assignee rotation whose
+// turn it is, if any. Failing to advance the rotation is logged, and leaves
+// the issue to the configured assignees only.
+func (cr *crashReporter) issueAssignees() []string {
+	assignees := cr.cfg.Fuzz.CrashIssueAssignees
+	rotation := cr.cfg.Fuzz.IssueAssigneeRotation
+	if len(rotation) == 0 {
+		return assignees
+	}
+
+	assignee, err := nextRotationAssignee(cr.cfg.Project.ReportDir,
+		rotation)
+	if err != nil {
+		cr.logger.Warn("Failed to advance assignee rotation; leaving "+
+			"it out", "error", err)
+		return assignees
+	}
+	cr.logger.Info("Assigning issue by rotation", "assignee", assignee)
+
+	if slices.Contains(assignees, assignee) {
+		return assignees
+	}
+	return append(slices.Clone(assignees), assignee)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNextRotationAssignee verifies that the rotation cycles through its
+// members, starting from the first one, and persists its cursor, wrapping
+// around if the rotation shrank since.
+func TestNextRotationAssignee(t *testing.T) {
+	reportDir := filepath.Join(t.TempDir(), "reports")
+	rotation := []string{"alice", "bob", "carol"}
+
+	var assignees []string
+	for range 4 {
+		assignee, err := nextRotationAssignee(reportDir, rotation)
+		assert.NoError(t, err)
+		assignees = append(assignees, assignee)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "alice"}, assignees)
+	assert.FileExists(t, filepath.Join(reportDir, AssigneeRotationFile))
+
+	// The cursor now points at the third member, past the end of a
+	// shorter rotation.
+	_, err := nextRotationAssignee(reportDir, rotation)
+	assert.NoError(t, err)
+	assignee, err := nextRotationAssignee(reportDir, rotation[:2])
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", assignee)
+
+	err = os.WriteFile(filepath.Join(reportDir, AssigneeRotationFile),
+		[]byte("{"), 0644)
+	assert.NoError(t, err)
+	_, err = nextRotationAssignee(reportDir, rotation)
+	assert.Error(t, err)
+}
+
+// TestIssueAssignees verifies that new issues are assigned to the configured
+// assignees along with the member of the rotation whose turn it is, once.
+func TestIssueAssignees(t *testing.T) {
+	cr := &crashReporter{
+		logger: slog.New(slog.DiscardHandler),
+		cfg: &Config{
+			Project: Project{ReportDir: t.TempDir()},
+			Fuzz: Fuzz{
+				CrashIssueAssignees:   []string{"alice"},
+				IssueAssigneeRotation: []string{"bob", "alice"},
+			},
+		},
+	}
+	assert.Equal(t, []string{"alice", "bob"}, cr.issueAssignees())
+	assert.Equal(t, []string{"alice"}, cr.issueAssignees())
+	assert.Equal(t, []string{"alice", "bob"}, cr.issueAssignees())
+
+	cr.cfg.Fuzz.IssueAssigneeRotation = nil
+	assert.Equal(t, []string{"alice"}, cr.issueAssignees())
+}
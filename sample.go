@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// samplePolicy selects the fuzz targets of a fuzzing cycle when sampling: in
+// projects with many targets, fuzzing only a random sample of them every cycle
+// gives each sampled target a larger share of the cycle. Sampling weighted by
+// staleness favors the targets not fuzzed for the longest time, so that over
+// time every target gets substantial fuzzing time.
+type samplePolicy struct {
+	// size is the number of targets sampled in every cycle.
+	size int
+
+	// strategy is how the targets are sampled, SampleStrategyStaleness or
+	// SampleStrategyUniform.
+	strategy string
+
+	// lastFuzzed holds the time each target was last fuzzed.
+	lastFuzzed map[TargetState]time.Time
+
+	// now is the time the cycle started.
+	now time.Time
+
+	// random returns a random number in [0, 1).
+	random func() float64
+}
+
+// newSamplePolicy returns the sample policy of a fuzzing cycle starting at now,
+// based on the target statuses in the report directory.
+func newSamplePolicy(cfg *Config, now time.Time) (*samplePolicy, error) {
+	statusPath := filepath.Join(cfg.Project.ReportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	// Statuses saved before the last fuzzed time was recorded only hold
+	// the last run time.
+	lastFuzzed := make(map[TargetState]time.Time, len(statuses))
+	for _, s := range statuses {
+		t := s.LastFuzzed
+		if t.IsZero() {
+			t = s.LastRun
+		}
+		lastFuzzed[TargetState{s.PkgPath, s.Target}] = t
+	}
+
+	return &samplePolicy{
+		size:       cfg.Fuzz.SampleSize,
+		strategy:   cfg.Fuzz.SampleStrategy,
+		lastFuzzed: lastFuzzed,
+		now:        now,
+		random:     rand.Float64,
+	}, nil
+}
+
+// sample returns the targets sampled out of the given ones, which are all
+// sampled if there are no more than the sample size.
+//
+// Targets are sampled without replacement using the keys of Efraimidis and
+// Spirakis: each target draws a key log(u)/w, where u is uniform in [0, 1) and
+// w its weight, and the targets with the largest keys are sampled. With the
+// staleness strategy, the weight of a target is the time since it was last
+// fuzzed, and targets never fuzzed draw a non-negative key u, so they are
+// sampled first. With the uniform strategy, every target draws a key u.
+func (p *samplePolicy) sample(targets []TargetState) map[TargetState]bool {
+	keys := make(map[TargetState]float64, len(targets))
+	for _, t := range targets {
+		u := p.random()
+
+		lastFuzzed, ok := p.lastFuzzed[t]
+		if p.strategy == SampleStrategyUniform || !ok ||
+			lastFuzzed.IsZero() {
+
+			keys[t] = u
+			continue
+		}
+
+		staleness := max(p.now.Sub(lastFuzzed), time.Second)
+		keys[t] = math.Log(u) / staleness.Seconds()
+	}
+
+	sorted := slices.Clone(targets)
+	slices.SortStableFunc(sorted, func(a, b TargetState) int {
+		return cmp.Compare(keys[b], keys[a])
+	})
+
+	sampled := make(map[TargetState]bool, p.size)
+	for _, t := range sorted[:min(p.size, len(sorted))] {
+		sampled[t] = true
+	}
+	return sampled
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSamplePolicy verifies that the sample size is honored, that targets
+// never fuzzed are always sampled first, and that sampling by staleness favors
+// the targets not fuzzed for the longest time.
+func TestSamplePolicy(t *testing.T) {
+	reportDir := t.TempDir()
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	statuses := []TargetStatus{
+		{PkgPath: "parser", Target: "FuzzFresh",
+			LastRun:    now.Add(-time.Hour),
+			LastFuzzed: now.Add(-time.Hour)},
+		{PkgPath: "parser", Target: "FuzzStale",
+			LastRun:    now.Add(-time.Hour),
+			LastFuzzed: now.Add(-100 * time.Hour)},
+
+		// Statuses saved before the last fuzzed time was recorded
+		// fall back to the last run time.
+		{PkgPath: "tree", Target: "FuzzOld",
+			LastRun: now.Add(-50 * time.Hour)},
+	}
+	assert.NoError(t, saveTargetStatuses(filepath.Join(reportDir,
+		"status.json"), statuses))
+
+	cfg := &Config{
+		Project: Project{ReportDir: reportDir},
+		Fuzz: Fuzz{
+			SampleSize:     1,
+			SampleStrategy: SampleStrategyStaleness,
+		},
+	}
+	sample, err := newSamplePolicy(cfg, now)
+	assert.NoError(t, err)
+	sample.random = rand.New(rand.NewPCG(1, 2)).Float64
+
+	fresh := TargetState{"parser", "FuzzFresh"}
+	stale := TargetState{"parser", "FuzzStale"}
+	old := TargetState{"tree", "FuzzOld"}
+	unknown := TargetState{"tree", "FuzzNew"}
+	assert.Equal(t, now.Add(-50*time.Hour), sample.lastFuzzed[old])
+
+	// Targets never fuzzed are sampled first.
+	for range 100 {
+		assert.Equal(t, map[TargetState]bool{unknown: true},
+			sample.sample([]TargetState{fresh, stale, old,
+				unknown}))
+	}
+
+	// The stalest target is sampled about 100 times more often than
+	// the freshest one.
+	counts := make(map[TargetState]int)
+	for range 1000 {
+		for s := range sample.sample([]TargetState{fresh, stale}) {
+			counts[s]++
+		}
+	}
+	assert.Equal(t, 1000, counts[fresh]+counts[stale])
+	assert.Greater(t, counts[stale], 950)
+
+	// Sampling uniformly ignores staleness.
+	sample.strategy = SampleStrategyUniform
+	counts = make(map[TargetState]int)
+	for range 1000 {
+		for s := range sample.sample([]TargetState{fresh, stale}) {
+			counts[s]++
+		}
+	}
+	assert.InDelta(t, 500, counts[stale], 100)
+
+	// All targets are sampled when there are no more than the sample
+	// size.
+	sample.size = 3
+	assert.Equal(t, map[TargetState]bool{fresh: true, stale: true},
+		sample.sample([]TargetState{fresh, stale}))
+	assert.Len(t, sample.sample([]TargetState{fresh, stale, old,
+		unknown}), 3)
+}
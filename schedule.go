@@ -0,0 +1,149 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// coverageGain returns the coverage gained by a target across the most recent
+// window measurements of its coverage history, newest first, in percentage
+// points, and whether the history holds at least two measurements to tell.
+// Coverage losses count as no gain.
+func coverageGain(history []TargetHistory, window int) (float64, bool) {
+	history = history[:min(len(history), window)]
+	if len(history) < 2 {
+		return 0, false
+	}
+
+	newest, err := strconv.ParseFloat(history[0].Coverage, 64)
+	if err != nil {
+		return 0, false
+	}
+	oldest, err := strconv.ParseFloat(history[len(history)-1].Coverage,
+		64)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(newest-oldest, 0), true
+}
+
+// scheduleWeights returns the weight of every given task in the weighted
+// schedule: one plus the coverage its target recently gained, capped at
+// MaxScheduleWeight, so targets still gaining coverage get more time, while
+// plateaued targets keep a share of it. Targets whose recent gain is unknown,
+// e.g. new targets, get the largest weight of the others.
+func scheduleWeights(reportDir string, tasks []Task,
+	window int) (map[Task]float64, error) {
+
+	weights := make(map[Task]float64, len(tasks))
+	var unknown []Task
+	largest := 1.0
+	for _, task := range tasks {
+		history, err := loadTargetHistory(reportDir, task.PackagePath,
+			task.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		gain, ok := coverageGain(history, window)
+		if !ok {
+			unknown = append(unknown, task)
+			continue
+		}
+
+		weights[task] = min(1+gain, MaxScheduleWeight)
+		largest = max(largest, weights[task])
+	}
+
+	for _, task := range unknown {
+		weights[task] = largest
+	}
+
+	return weights, nil
+}
+
+// scheduleMakespan returns the time the given number of workers take to fuzz
+// the given durations when taking them in order, each worker taking the next
+// one as soon as it is done with its previous one, as they do from the task
+// queue.
+func scheduleMakespan(durations []time.Duration,
+	numWorkers int) time.Duration {
+
+	done := make([]time.Duration, max(min(numWorkers, len(durations)), 1))
+	for _, d := range durations {
+		i := slices.Index(done, slices.Min(done))
+		done[i] += d
+	}
+
+	return slices.Max(done)
+}
+
+// weightedFuzzDurations returns the fuzz duration of every given task such that
+// the tasks share the time calculateFuzzSeconds allots them in proportion to
+// their weights, while the workers still fuzz all of them in no more time than
+// with an even split. Every task gets at least MinFuzzDuration, and the rest of
+// its even share is redistributed by weight, scaled down as needed for the
+// workers, taking the tasks in order, to fit. Returns nil if fuzzing is
+// infeasible, like calculateFuzzSeconds.
+func weightedFuzzDurations(syncFrequency, gracePeriod time.Duration,
+	numWorkers int, tasks []Task,
+	weights map[Task]float64) map[Task]time.Duration {
+
+	even := calculateFuzzSeconds(syncFrequency, gracePeriod, numWorkers,
+		len(tasks))
+	if even == 0 {
+		return nil
+	}
+
+	durations := make(map[Task]time.Duration, len(tasks))
+	if even <= MinFuzzDuration {
+		for _, task := range tasks {
+			durations[task] = even
+		}
+		return durations
+	}
+
+	// The time an even split takes the workers, and the time it leaves
+	// to redistribute by weight on top of the minimum of every task.
+	tasksPerWorker := (len(tasks) + numWorkers - 1) / numWorkers
+	budget := even * time.Duration(tasksPerWorker)
+	spare := float64(even-MinFuzzDuration) * float64(len(tasks))
+
+	var totalWeight float64
+	for _, task := range tasks {
+		totalWeight += weights[task]
+	}
+
+	scaled := func(scale float64) []time.Duration {
+		ds := make([]time.Duration, len(tasks))
+		for i, task := range tasks {
+			extra := time.Duration(scale * spare * weights[task] /
+				totalWeight)
+			ds[i] = (MinFuzzDuration + extra).Truncate(time.Second)
+		}
+		return ds
+	}
+
+	// An even split of the minimum always fits, so search for the largest
+	// share of the spare time that still fits.
+	lo, hi := 0.0, 1.0
+	if scheduleMakespan(scaled(hi), numWorkers) > budget {
+		for range 32 {
+			mid := (lo + hi) / 2
+			if scheduleMakespan(scaled(mid), numWorkers) > budget {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		hi = lo
+	}
+
+	for i, d := range scaled(hi) {
+		durations[tasks[i]] = d
+	}
+
+	return durations
+}
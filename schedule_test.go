@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoverageGain verifies that the coverage gain of a target is measured
+// across the most recent measurements of the window, losses counting as no
+// gain, and is unknown without two valid measurements.
+func TestCoverageGain(t *testing.T) {
+	history := []TargetHistory{
+		{Date: "2025-07-15", Coverage: "60.5"},
+		{Date: "2025-07-14", Coverage: "60.0"},
+		{Date: "2025-07-13", Coverage: "58.0"},
+		{Date: "2025-07-12", Coverage: "40.0"},
+	}
+
+	gain, ok := coverageGain(history, 3)
+	assert.True(t, ok)
+	assert.InDelta(t, 2.5, gain, 1e-9)
+
+	gain, ok = coverageGain(history, 10)
+	assert.True(t, ok)
+	assert.InDelta(t, 20.5, gain, 1e-9)
+
+	gain, ok = coverageGain([]TargetHistory{{Coverage: "50.0"},
+		{Coverage: "55.0"}}, 5)
+	assert.True(t, ok)
+	assert.Zero(t, gain)
+
+	_, ok = coverageGain(history[:1], 5)
+	assert.False(t, ok)
+	_, ok = coverageGain([]TargetHistory{{Coverage: "n/a"},
+		{Coverage: "55.0"}}, 5)
+	assert.False(t, ok)
+}
+
+// TestScheduleWeights verifies that targets are weighted by their recent
+// coverage gain, capped at MaxScheduleWeight, and that targets without a
+// history get the largest weight of the others.
+func TestScheduleWeights(t *testing.T) {
+	reportDir := t.TempDir()
+	writeFiles(t, reportDir, map[string]string{
+		"targets/parser/FuzzEval.json": `[
+			{"Date": "2025-07-15", "Coverage": "70.0"},
+			{"Date": "2025-07-14", "Coverage": "70.0"}
+		]`,
+		"targets/parser/FuzzLex.json": `[
+			{"Date": "2025-07-15", "Coverage": "72.0"},
+			{"Date": "2025-07-14", "Coverage": "70.0"}
+		]`,
+		"targets/tree/FuzzBuild.json": `[
+			{"Date": "2025-07-15", "Coverage": "50.0"},
+			{"Date": "2025-07-14", "Coverage": "30.0"}
+		]`,
+	})
+
+	tasks := []Task{
+		{PackagePath: "parser", Target: "FuzzEval"},
+		{PackagePath: "parser", Target: "FuzzLex"},
+		{PackagePath: "tree", Target: "FuzzBuild"},
+		{PackagePath: "tree", Target: "FuzzNew"},
+	}
+	weights, err := scheduleWeights(reportDir, tasks, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, map[Task]float64{
+		tasks[0]: 1,
+		tasks[1]: 3,
+		tasks[2]: MaxScheduleWeight,
+		tasks[3]: MaxScheduleWeight,
+	}, weights)
+
+	// Without any history, all targets weigh the same.
+	weights, err = scheduleWeights(t.TempDir(), tasks, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, weights[tasks[0]])
+	assert.Equal(t, 1.0, weights[tasks[3]])
+}
+
+// TestScheduleMakespan verifies that the makespan follows the workers taking
+// the tasks in order as soon as they are free.
+func TestScheduleMakespan(t *testing.T) {
+	durations := []time.Duration{10 * time.Second, 30 * time.Second,
+		5 * time.Second, 20 * time.Second}
+
+	assert.Equal(t, 65*time.Second, scheduleMakespan(durations, 1))
+	assert.Equal(t, 35*time.Second, scheduleMakespan(durations, 2))
+	assert.Equal(t, 30*time.Second, scheduleMakespan(durations, 8))
+	assert.Zero(t, scheduleMakespan(nil, 2))
+}
+
+// TestWeightedFuzzDurations verifies that the time of an even split is shared
+// by weight, scaled down so the workers take no longer than with the even
+// split, and that every target keeps at least MinFuzzDuration.
+func TestWeightedFuzzDurations(t *testing.T) {
+	a := Task{PackagePath: "parser", Target: "FuzzEval"}
+	b := Task{PackagePath: "parser", Target: "FuzzLex"}
+	c := Task{PackagePath: "tree", Target: "FuzzBuild"}
+
+	durations := weightedFuzzDurations(100*time.Second, 0, 1,
+		[]Task{a, b}, map[Task]float64{a: 3, b: 1})
+	assert.Equal(t, map[Task]time.Duration{
+		a: 74 * time.Second,
+		b: 25 * time.Second,
+	}, durations)
+
+	// The heaviest task comes last, after the first worker is done with
+	// its first task, so the spare time is scaled down to fit the time of
+	// the even split.
+	tasks := []Task{a, b, c}
+	durations = weightedFuzzDurations(100*time.Second, 0, 2, tasks,
+		map[Task]float64{a: 1, b: 1, c: 4})
+	assert.Equal(t, durations[a], durations[b])
+	assert.Greater(t, durations[c], 3*durations[a])
+	assert.LessOrEqual(t, scheduleMakespan([]time.Duration{durations[a],
+		durations[b], durations[c]}, 2), 100*time.Second)
+	assert.Greater(t, scheduleMakespan([]time.Duration{durations[a],
+		durations[b], durations[c]}, 2), 95*time.Second)
+
+	// Without time to spare, every task gets the minimum.
+	durations = weightedFuzzDurations(3*time.Second, time.Minute, 1,
+		tasks, map[Task]float64{a: 1, b: 1, c: 4})
+	assert.Equal(t, map[Task]time.Duration{
+		a: MinFuzzDuration,
+		b: MinFuzzDuration,
+		c: MinFuzzDuration,
+	}, durations)
+
+	assert.Nil(t, weightedFuzzDurations(0, 0, 1, tasks, nil))
+}
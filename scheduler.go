@@ -3,8 +3,11 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,76 +15,223 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"golang.org/x/sync/errgroup"
 )
 
 // runFuzzingCycles runs an infinite loop of fuzzing cycles. Each cycle consists
 // of:
-//  1. Cloning the Git repository specified in cfg.Project.SrcRepo.
+//  0. Pruning the Go build cache to cfg.Fuzz.GoCacheMaxBytes and running
+//     cfg.Fuzz.PreCycleHook, if set.
+//  1. Cloning the Git repository specified in cfg.Project.SrcRepo at
+//     cfg.Project.Ref, or reusing the checkout of the previous cycle if
+//     cfg.Fuzz.ReuseCheckout is set and the remote commit has not changed
+//     since. If cfg.Project.IncrementalClone is set, the clone of the
+//     previous cycle is updated instead of cloning the repository again.
 //  2. Downloading corpus and reports from S3 bucket specified in
-//     cfg.Project.S3BucketName.
-//  3. If the time since the last corpus minimization exceeds
-//     cfg.Fuzz.CorpusMinimizeInterval, then minimize the corpus.
+//     cfg.Project.S3BucketName, unless disabled by cfg.Project.CorpusSyncMode.
+//  3. Detecting whether the cycle is one of the cfg.Fuzz.BootstrapCycles
+//     bootstrap cycles of a new project. If the time since the last corpus
+//     minimization exceeds cfg.Fuzz.CorpusMinimizeInterval, then minimize the
+//     corpus.
 //  4. Launching scheduler goroutines to execute all fuzz targets for a portion
-//     of cfg.Fuzz.SyncFrequency.
+//     of cfg.Fuzz.SyncFrequency, shortened to end by cfg.Fuzz.MaxRuntime.
 //  5. Cleaning up the workspace.
-//  6. Uploading the updated corpus and reports to the S3 bucket.
+//  6. Uploading the updated corpus and reports to the S3 bucket, unless
+//     disabled by cfg.Project.CorpusSyncMode.
+//  7. Running cfg.Fuzz.PostCycleHook, if set.
 //
-// The loop repeats until the parent context is canceled. Errors in cloning or
-// target discovery are returned immediately.
+// The loop repeats until the parent context is canceled, cfg.Fuzz.Iterations
+// cycles completed, or cfg.Fuzz.MaxRuntime elapsed, shortening the last cycle
+// to end in time. Errors in the
+// pre-cycle hook, cloning or target discovery are returned immediately, while
+// errors in the post-cycle hook are only logged.
 func runFuzzingCycles(ctx context.Context, logger *slog.Logger,
-	cfg *Config) error {
+	cfg *Config, summary *runSummary) error {
+
+	// The daemon is alive for as long as the cycles run.
+	daemonHealth.alive.Store(true)
+	defer daemonHealth.alive.Store(false)
+
+	// Make reporting crashes to another owner's repository explicit.
+	warnCrossOwnerReporting(logger, cfg)
+
+	// Make the history missing from crash issues of a shallow clone
+	// explicit.
+	warnShallowBlame(logger, cfg)
 
 	// A non-positive number of iterations indicates we should run forever.
 	// Otherwise, run for the specified number of iterations.
 	runForever := cfg.Fuzz.Iterations <= 0
 	iterationsLeft := cfg.Fuzz.Iterations
 
-	for {
+	// With a maximum runtime, no cycle starts after the run deadline, and
+	// the cycle in progress at the deadline is shortened to end by then.
+	var runDeadline time.Time
+	if cfg.Fuzz.MaxRuntime > 0 {
+		runDeadline = time.Now().Add(cfg.Fuzz.MaxRuntime)
+	}
+
+	// checkout is the project checkout of the previous cycle, kept to be
+	// reused if cfg.Fuzz.ReuseCheckout is set.
+	var checkout *projectCheckout
+
+	// bootstrap tracks the initial cycles of a new project, which only
+	// establish the coverage baselines.
+	bootstrap := newBootstrapPolicy(cfg)
+
+	for cycle := 1; ; cycle++ {
+		if !runDeadline.IsZero() &&
+			time.Until(runDeadline) < MinFuzzDuration {
+
+			logger.Info("Maximum runtime reached; ending fuzzing "+
+				"cycles", "maxRuntime", cfg.Fuzz.MaxRuntime,
+				"count", cycle-1)
+			return nil
+		}
 		if !runForever {
 			if iterationsLeft <= 0 {
 				break
 			}
 			iterationsLeft--
 		}
+		cycleStart := time.Now()
 
 		// Cleanup the project, corpus, reports, and binaries directory
-		// created during previous runs.
-		cleanupTmpDirs(logger, cfg)
+		// created during previous runs, keeping any project checkout
+		// that may be reused or updated.
+		cleanupTmpDirs(logger, cfg, checkout != nil ||
+			cfg.Project.IncrementalClone)
 
-		// 1. Clone the repository based on the provided configuration.
-		logger.Info("Cloning project repository", "url",
-			SanitizeURL(cfg.Project.SrcRepo), "path",
-			cfg.Project.SrcDir)
+		// Bound the size of the Go build cache, which persists across
+		// cycles, while no build uses it. Failing to do so only risks
+		// running out of disk space later, so it does not abort.
+		if cfg.Fuzz.GoCacheMaxBytes > 0 {
+			err := pruneBuildCache(ctx, logger,
+				cfg.Fuzz.GoCacheMaxBytes)
+			if err != nil {
+				logger.Warn("Failed to prune Go build cache",
+					"error", err)
+			}
+		}
+
+		// 0. Run the pre-cycle hook, e.g. to refresh the credentials
+		//    used by the cycle.
+		if cfg.Fuzz.PreCycleHook != "" {
+			err := runCycleHook(ctx, logger, cfg, "pre-cycle",
+				cfg.Fuzz.PreCycleHook, cycle)
+			if err != nil {
+				logger.Error("Pre-cycle hook failed; " +
+					"aborting scheduler")
+				return err
+			}
+		}
+
+		// 1. Reuse the checkout of the previous cycle if the project
+		//    has not changed since, or clone the repository based on
+		//    the provided configuration. A clone updated incrementally
+		//    is kept.
+		if checkout != nil && !checkout.reusable(ctx, logger, cfg) {
+			if !cfg.Project.IncrementalClone {
+				err := os.RemoveAll(cfg.Project.SrcDir)
+				if err != nil {
+					logger.Error("project cleanup failed",
+						"error", err)
+				}
+			}
+			checkout = nil
+		}
 
-		_, err := git.PlainCloneContext(
-			ctx, cfg.Project.SrcDir, false, &git.CloneOptions{
-				URL: cfg.Project.SrcRepo,
-			},
-		)
+		var targetCache map[string][]string
+		if checkout != nil {
+			targetCache = checkout.targets
+		} else {
+			repo, err := cloneProject(ctx, logger, cfg)
+			if err != nil {
+				logger.Error("Failed to clone project " +
+					"repository; aborting scheduler")
+				return err
+			}
+
+			if cfg.Fuzz.ReuseCheckout {
+				checkout, err = newProjectCheckout(repo)
+				if err != nil {
+					logger.Warn("Project checkout cannot "+
+						"be reused", "error", err)
+				} else {
+					targetCache = checkout.targets
+				}
+			}
+		}
+
+		// 2. Download corpus and reports from the storage bucket.
+		store, err := NewCorpusStore(ctx, logger, cfg)
 		if err != nil {
-			logger.Error("Failed to clone project repository; " +
+			logger.Error("Failed to create storage client; " +
 				"aborting scheduler")
 			return err
 		}
 
-		// 2. Download corpus and reports from S3 bucket.
-		s3s, err := NewS3Store(ctx, logger, cfg)
-		if err != nil {
-			logger.Error("Failed to create S3 client; aborting" +
-				"scheduler")
+		if cfg.Project.downloadsCorpus() {
+			if err := store.downloadCorpusAndReports(); err != nil {
+				logger.Error("Failed to download corpus and " +
+					"reports; aborting scheduler")
+				return err
+			}
+		} else {
+			logger.Info("Skipping corpus and reports download",
+				"syncMode", cfg.Project.CorpusSyncMode)
+		}
+
+		// The daemon is ready once it cloned the project and
+		// downloaded the corpus.
+		daemonHealth.ready.Store(true)
+
+		// Record the inputs of the corpus before fuzzing, so only the
+		// inputs found by this cycle are quarantined.
+		if cfg.Project.CorpusQuarantine {
+			if err := store.snapshotCorpusBaseline(); err != nil {
+				logger.Error("Failed to record corpus " +
+					"baseline; aborting scheduler")
+				return err
+			}
+		}
+
+		// Record the inputs of the downloaded corpus, so the upload can
+		// be skipped if the cycle leaves them unchanged.
+		var corpusSnapshot map[string]bool
+		if cfg.Project.SkipUnchangedCorpus &&
+			cfg.Project.downloadsCorpus() {
+
+			corpusSnapshot, err = snapshotCorpus(
+				cfg.Project.CorpusDir)
+			if err != nil {
+				logger.Error("Failed to record corpus " +
+					"snapshot; aborting scheduler")
+				return err
+			}
+		}
+
+		// Check that the fuzz runs will be able to write to the
+		// corpus, rather than failing deep inside the containers.
+		if err := checkCorpusAccess(logger, cfg); err != nil {
+			logger.Error("Corpus directory is not writable; " +
+				"aborting scheduler")
 			return err
 		}
 
-		if err := s3s.downloadCorpusAndReports(); err != nil {
-			logger.Error("Failed to download corpus and reports; " +
+		// Detect whether the cycle only establishes the baselines of a
+		// new project, before fuzzing adds inputs to its corpus.
+		bootstrapping, err := bootstrap.next(logger, cfg, cycle)
+		if err != nil {
+			logger.Error("Failed to detect bootstrap cycle; " +
 				"aborting scheduler")
 			return err
 		}
 
 		shouldMinimizeCorpus := false
 		// Get the last time the corpus was pruned.
-		lastMinTime, err := s3s.getLastMinimizedTime()
+		lastMinTime, err := store.getLastMinimizedTime()
 		if err != nil {
 			logger.Error("Failed to get last minimized time of " +
 				"corpus; aborting scheduler")
@@ -100,13 +250,23 @@ func runFuzzingCycles(ctx context.Context, logger *slog.Logger,
 		// Channel to report any error that occurs during the cycle.
 		errChan := make(chan error, 1)
 
+		// Set up the duration of the cycle, shortened to end by the run
+		// deadline if any, and the grace period for all workers to
+		// finish their tasks.
+		syncFrequency, gracePeriod := cycleWindow(
+			cfg.Fuzz.SyncFrequency, cfg.Fuzz.GracePeriod,
+			runDeadline)
+		if syncFrequency != cfg.Fuzz.SyncFrequency {
+			logger.Info("Shortening cycle to end by maximum "+
+				"runtime", "syncFrequency", syncFrequency,
+				"gracePeriod", gracePeriod)
+		}
+		cycleDeadline := time.Now().Add(syncFrequency + gracePeriod)
+
 		// Launch the fuzz worker scheduler as a goroutine.
 		go scheduleFuzzing(schedulerCtx, logger, cfg, errChan,
-			shouldMinimizeCorpus)
-
-		// Set up the grace period for all workers to finish their
-		// tasks.
-		gracePeriod := min(cfg.Fuzz.SyncFrequency/3, 1*time.Hour)
+			syncFrequency, gracePeriod, shouldMinimizeCorpus,
+			bootstrapping, summary, targetCache)
 
 		// 4. Wait for either:
 		//    A) All workers finish early
@@ -114,7 +274,7 @@ func runFuzzingCycles(ctx context.Context, logger *slog.Logger,
 		//    C) Parent context cancellation
 		//    D) An error occurs
 		select {
-		case <-time.After(cfg.Fuzz.SyncFrequency + gracePeriod):
+		case <-time.After(time.Until(cycleDeadline)):
 			// Cancel the current cycle.
 			cancelCycle()
 
@@ -145,17 +305,55 @@ func runFuzzingCycles(ctx context.Context, logger *slog.Logger,
 					"scheduler")
 				return err
 			}
-			logger.Info("All workers completed early; cleaning " +
-				"up cycle")
+			logger.Info("All workers completed early; cleaning "+
+				"up cycle", "remaining",
+				time.Until(cycleDeadline).Round(time.Second))
 		}
 
 		// 5. Only upload the updated corpus and reports if the cycle
 		//    succeeded.
-		if err := s3s.uploadCorpusAndReports(lastMinTime); err != nil {
-			logger.Error("Failed to upload corpus and reports; " +
-				"aborting scheduler")
-			return err
+		//    The corpus itself is only uploaded if it changed, or if
+		//    a new minimization time must be recorded.
+		if cfg.Project.uploadsCorpus() {
+			unchanged, err := corpusUnchanged(cfg.Project.CorpusDir,
+				corpusSnapshot)
+			if err != nil {
+				logger.Error("Failed to compare corpus with " +
+					"snapshot; aborting scheduler")
+				return err
+			}
+
+			uploadCorpus := shouldMinimizeCorpus || !unchanged
+			if !uploadCorpus {
+				logger.Info("Corpus unchanged; skipping " +
+					"upload.")
+			}
+
+			err = store.uploadCorpusAndReports(lastMinTime,
+				uploadCorpus)
+			if err != nil {
+				logger.Error("Failed to upload corpus and " +
+					"reports; aborting scheduler")
+				return err
+			}
+		} else {
+			logger.Info("Skipping corpus and reports upload",
+				"syncMode", cfg.Project.CorpusSyncMode)
 		}
+
+		// 7. Run the post-cycle hook, e.g. to publish the artifacts of
+		//    the cycle. Its failure does not affect the next cycles.
+		if cfg.Fuzz.PostCycleHook != "" {
+			err := runCycleHook(ctx, logger, cfg, "post-cycle",
+				cfg.Fuzz.PostCycleHook, cycle)
+			if err != nil {
+				logger.Warn("Post-cycle hook failed", "error",
+					err)
+			}
+		}
+
+		cycleDuration.Set(time.Since(cycleStart).Seconds())
+		summary.recordCycle()
 	}
 
 	logger.Info("Completed all fuzzing cycles", "count",
@@ -163,100 +361,524 @@ func runFuzzingCycles(ctx context.Context, logger *slog.Logger,
 	return nil
 }
 
+// projectCheckout is the project checkout of a fuzzing cycle, along with the
+// fuzz targets discovered in it, which following cycles reuse as long as the
+// remote HEAD commit is unchanged.
+type projectCheckout struct {
+	repo   *git.Repository
+	commit plumbing.Hash
+
+	// targets maps each package path to the fuzz targets discovered in it.
+	targets map[string][]string
+}
+
+// newProjectCheckout returns a reusable projectCheckout for the given freshly
+// cloned repository, with no fuzz targets discovered yet.
+func newProjectCheckout(repo *git.Repository) (*projectCheckout, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	return &projectCheckout{
+		repo:    repo,
+		commit:  head.Hash(),
+		targets: make(map[string][]string),
+	}, nil
+}
+
+// reusable reports whether the checkout can be reused by the next cycle, i.e.
+// whether the remote commit of cfg.Project.Ref, or of the remote HEAD if unset,
+// is still the checked out one. If so, the checkout is reset to that commit,
+// discarding the changes of the previous cycle. Otherwise, or if checking
+// fails, the project must be cloned again.
+func (p *projectCheckout) reusable(ctx context.Context, logger *slog.Logger,
+	cfg *Config) bool {
+
+	_, head, err := resolveRemoteRef(ctx, cfg.Project.SrcRepo,
+		cfg.Project.Ref)
+	if err != nil {
+		logger.Warn("Failed to check remote commit of project "+
+			"repository; cloning again", "error", err)
+		return false
+	}
+
+	if head != p.commit {
+		logger.Info("Project repository changed; cloning again",
+			"previousCommit", p.commit.String(), "commit",
+			head.String())
+		return false
+	}
+
+	if err := resetCheckout(p.repo); err != nil {
+		logger.Warn("Failed to reset project checkout; cloning again",
+			"error", err)
+		return false
+	}
+
+	logger.Info("Project repository unchanged; reusing checkout",
+		"path", cfg.Project.SrcDir, "commit", p.commit.String())
+
+	return true
+}
+
+// cloneProject clones the project repository into cfg.Project.SrcDir and
+// records its checked out branch in cfg.Project.SrcBranch: the default branch,
+// unless another one is selected with cfg.Project.Ref. If
+// cfg.Project.IncrementalClone is set, the clone left by the previous cycle is
+// updated instead, and the repository is only cloned again if it is missing or
+// cannot be updated.
+func cloneProject(ctx context.Context, logger *slog.Logger,
+	cfg *Config) (*git.Repository, error) {
+
+	var repo *git.Repository
+	if cfg.Project.IncrementalClone {
+		var err error
+		repo, err = updateClone(ctx, cfg)
+		switch {
+		case err == nil:
+			logger.Info("Updated project repository", "url",
+				SanitizeURL(cfg.Project.SrcRepo), "path",
+				cfg.Project.SrcDir)
+
+		case ctx.Err() != nil:
+			return nil, err
+
+		default:
+			// The first cycle finds no clone to update.
+			if !errors.Is(err, git.ErrRepositoryNotExists) {
+				logger.Warn("Failed to update project "+
+					"repository; cloning again", "error",
+					err)
+			}
+
+			err := os.RemoveAll(cfg.Project.SrcDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove "+
+					"project clone: %w", err)
+			}
+		}
+	}
+
+	if repo == nil {
+		logger.Info("Cloning project repository", "url",
+			SanitizeURL(cfg.Project.SrcRepo), "path",
+			cfg.Project.SrcDir)
+
+		var err error
+		repo, err = cloneWithRetries(ctx, logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve the default branch of the project from the cloned remote
+	// HEAD. Failing to do so is not fatal, since fuzzing only needs the
+	// checked out commit. A tag selected with cfg.Project.Ref is checked
+	// out without any branch.
+	branch, err := checkedOutBranch(repo)
+	switch {
+	case err != nil && cfg.Project.Ref != "":
+		logger.Info("Checked out project repository at reference",
+			"ref", cfg.Project.Ref)
+
+	case err != nil:
+		logger.Warn("Failed to detect default branch of project "+
+			"repository", "error", err)
+
+	default:
+		logger.Info("Detected branch of project repository",
+			"branch", branch)
+	}
+	cfg.Project.SrcBranch = branch
+
+	return repo, nil
+}
+
+// fuzzCandidate is a fuzz target discovered in a fuzzing cycle and not
+// deferred, which is fuzzed unless not sampled, or only has its issues
+// verified if disabled.
+type fuzzCandidate struct {
+	TargetState
+
+	disabled bool
+}
+
 // scheduleFuzzing enqueues all discovered fuzz targets into a task queue and
-// spins up cfg.Fuzz.NumWorkers workers. Each worker runs until either:
+// spins up cfg.Fuzz.NumWorkers workers, splitting syncFrequency among the fuzz
+// targets, with gracePeriod left for them to finish. Each worker runs until
+// either:
 //   - All tasks are completed.
 //   - A worker returns an error (errgroup will cancel the others).
 //   - The cycle context (ctx) is canceled.
 //
+// The fuzz targets of each package are looked up in targetCache if non-nil,
+// and discovered and added to it otherwise. If bootstrapping, no coverage
+// issues are opened. In digest mode, the fuzz crashes found by the workers are
+// posted to the digest issue of the day once they are done.
+//
 // Returns an error if any worker fails.
 func scheduleFuzzing(ctx context.Context, logger *slog.Logger, cfg *Config,
-	errChan chan error, shouldMinimizeCorpus bool) {
+	errChan chan error, syncFrequency, gracePeriod time.Duration,
+	shouldMinimizeCorpus, bootstrapping bool,
+	summary *runSummary, targetCache map[string][]string) {
 
-	logger.Info("Starting fuzzing scheduler", "startTime", time.Now().
+	startTime := time.Now()
+	logger.Info("Starting fuzzing scheduler", "startTime", startTime.
 		Format(time.RFC1123))
 
 	// Discover fuzz targets, and create the binary, build the task queue
 	// and master state.
 	states := []TargetState{}
 	taskQueue := NewTaskQueue()
-	for _, pkgPath := range cfg.Fuzz.PkgsPath {
-		targets, err := listFuzzTargets(ctx, logger, cfg, pkgPath)
+	status := newCycleStatus()
+
+	// Select the fuzzing engine used to build and run the fuzz targets.
+	engine := newFuzzEngine(cfg.Fuzz.Engine)
+
+	// When focusing on active targets, the targets whose coverage has
+	// plateaued are only fuzzed once in a while.
+	var focus *focusPolicy
+	if cfg.Fuzz.FocusActiveTargets {
+		var err error
+		focus, err = newFocusPolicy(cfg, startTime)
 		if err != nil {
-			logger.Error("Failed to list fuzz targets", "package",
-				pkgPath)
-			errChan <- err
+			errChan <- fmt.Errorf("failed to load focus policy: %w",
+				err)
 			return
 		}
+	}
+	deferred := 0
 
-		// Path to the testdata directory inside the package, which
-		// must be copied after creating the target's binary.
-		srcTestDataPath := filepath.Join(cfg.Project.SrcDir, pkgPath,
-			"testdata")
+	// When auto-disabling crashing targets, the targets that crashed early
+	// in enough consecutive cycles are only verified, not fuzzed.
+	var disable *disablePolicy
+	if cfg.Fuzz.AutoDisableCrashing > 0 {
+		var err error
+		disable, err = newDisablePolicy(cfg)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to load disable policy: "+
+				"%w", err)
+			return
+		}
+	}
+	disabled := 0
 
-		for _, target := range targets {
-			// Create the fuzz binary for this target, to execute
-			// them inside a Docker container.
-			err := createFuzzBinary(ctx, logger, cfg, pkgPath,
-				target)
-			if err != nil {
+	// When requiring a minimum number of executions, the targets executing
+	// fewer are reported as starved, and may be granted extra time in the
+	// next cycle.
+	var starve *starvePolicy
+	if cfg.Fuzz.MinExecs > 0 {
+		var err error
+		starve, err = newStarvePolicy(cfg)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to load starve policy: "+
+				"%w", err)
+			return
+		}
+	}
+
+	// When sampling, only a sample of the targets is fuzzed in every cycle.
+	var sample *samplePolicy
+	if cfg.Fuzz.SampleSize > 0 {
+		var err error
+		sample, err = newSamplePolicy(cfg, startTime)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to load sample policy: "+
+				"%w", err)
+			return
+		}
+	}
+
+	// candidates holds the discovered targets to build, which are not
+	// deferred.
+	var candidates []fuzzCandidate
+
+	pkgs, err := discoverFuzzTargets(ctx, logger, cfg, targetCache)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	// targetPkgs maps each discovered fuzz target name to the package it
+	// was first found in, to detect same-named targets across packages.
+	targetPkgs := make(map[string]string)
+	for _, pkg := range pkgs {
+		pkgPath := pkg.path
+		for _, target := range pkg.targets {
+			// Same-named targets in different packages are fine,
+			// since every target-identifying key (binary and report
+			// paths, issue titles) includes the package path.
+			if firstPkg, ok := targetPkgs[target]; ok {
+				logger.Info("Fuzz target name shared across "+
+					"packages", "target", target,
+					"package", pkgPath, "otherPackage",
+					firstPkg)
+			} else {
+				targetPkgs[target] = pkgPath
+			}
+
+			// Register the target in master state, even if its
+			// binary fails to build, so its status is reported.
+			states = append(states, TargetState{pkgPath, target})
+			status.schedule(pkgPath, target)
+
+			// Disabled targets are still built, so the verification
+			// of their issues can re-enable them, but are never
+			// deferred, which would skip their verification.
+			isDisabled := disable != nil &&
+				disable.disabled(pkgPath, target)
+			if isDisabled {
+				logger.Info("Fuzz target disabled for "+
+					"crashing early; only verifying its "+
+					"issues", "package", pkgPath, "target",
+					target)
+			}
+
+			// Skip plateaued targets not due for a rerun, without
+			// building them. They are reported as skipped.
+			if focus != nil && !isDisabled {
+				skip, err := focus.deferred(pkgPath, target)
+				if err != nil {
+					errChan <- fmt.Errorf("failed to "+
+						"check coverage plateau: %w",
+						err)
+					return
+				}
+				if skip {
+					logger.Info("Deferring fuzz target "+
+						"with plateaued coverage",
+						"package", pkgPath, "target",
+						target)
+					deferred++
+					continue
+				}
+			}
+
+			candidates = append(candidates, fuzzCandidate{
+				TargetState: TargetState{pkgPath, target},
+				disabled:    isDisabled,
+			})
+		}
+	}
+
+	// When sampling, only a sample of the targets due for fuzzing is
+	// fuzzed. Disabled targets are only verified, so they are not sampled.
+	var sampled map[TargetState]bool
+	if sample != nil {
+		var due []TargetState
+		for _, c := range candidates {
+			if !c.disabled {
+				due = append(due, c.TargetState)
+			}
+		}
+		sampled = sample.sample(due)
+	}
+	unsampled := 0
+
+	// When prioritizing crashing targets, the targets with open crash
+	// issues are queued ahead of the others.
+	var tracker CrashTracker
+	if cfg.Fuzz.PrioritizeCrashing {
+		tracker, err = NewCrashTracker(ctx, logger, nil, cfg)
+		if err != nil {
+			errChan <- fmt.Errorf("error initializing crash "+
+				"tracker client: %w", err)
+			return
+		}
+	}
+
+	for _, c := range candidates {
+		pkgPath, target, isDisabled := c.PkgPath, c.Target, c.disabled
+
+		// Skip the targets not sampled in this cycle, without
+		// building them. They are reported as skipped.
+		if sampled != nil && !isDisabled && !sampled[c.TargetState] {
+			logger.Info("Skipping fuzz target not sampled in this "+
+				"cycle", "package", pkgPath, "target", target)
+			unsampled++
+			continue
+		}
+
+		// Create the fuzz binary for this target, to execute it inside
+		// a Docker container. A target that fails to build is skipped,
+		// so it does not prevent the other targets from being fuzzed.
+		err := createFuzzBinary(ctx, logger, cfg, engine, pkgPath,
+			target)
+		if err != nil {
+			if ctx.Err() != nil {
 				errChan <- fmt.Errorf("failed to create fuzz "+
 					"binary: %w", err)
 				return
 			}
 
-			// Copy the testdata directory for the given package
-			// into the fuzz binary path, so that tests depending on
-			// files from the testdata directory can fetch them
-			// properly.
-			//
-			// NOTE: We assume that all files needed by tests are
-			// placed under testdata/. If a test depends on files
-			// outside of testdata, those files will be ignored,
-			// which may cause GCF to report false positive errors,
-			// which GCF considers perfectly reasonable.
-			//
-			// NOTE: We need to copy the testdata into each target's
-			// directory because we can never be sure which tests
-			// will use which part of the testdata directory.
-			destTestDataPath := filepath.Join(cfg.Project.BinaryDir,
-				pkgPath, target, "testdata")
-			err = copyData(srcTestDataPath, destTestDataPath)
-			if err != nil {
-				errChan <- fmt.Errorf("failed to copy "+
-					"testdata directory: %w", err)
-				return
-			}
+			logger.Error("Failed to create fuzz binary; skipping "+
+				"target", "package", pkgPath, "target", target,
+				"error", err)
+			status.record(pkgPath, target, TargetResultBuildFail,
+				"", 0)
+			continue
+		}
 
-			// Enqueue all discovered fuzz targets.
-			taskQueue.Enqueue(Task{
-				PackagePath: pkgPath,
-				Target:      target,
-			})
+		// Copy the testdata directory of the target's package into the
+		// fuzz binary path, so that tests depending on files from the
+		// testdata directory can fetch them properly.
+		//
+		// NOTE: We assume that all files needed by tests are placed
+		// under testdata/. If a test depends on files outside of
+		// testdata, those files will be ignored, which may cause GCF
+		// to report false positive errors, which GCF considers
+		// perfectly reasonable.
+		//
+		// NOTE: We need to copy the testdata into each target's
+		// directory because we can never be sure which tests will use
+		// which part of the testdata directory. Only the seed corpus of
+		// the target itself is copied from testdata/fuzz.
+		destTestDataPath := filepath.Join(cfg.Project.BinaryDir,
+			pkgPath, target, "testdata")
+		err = copyTargetTestdata(filepath.Join(cfg.Project.SrcDir,
+			pkgPath), destTestDataPath, target)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to copy testdata "+
+				"directory: %w", err)
+			return
+		}
 
-			// Append all discovered fuzz targets in master state.
-			states = append(states, TargetState{pkgPath, target})
+		// Enqueue all targets to fuzz.
+		if isDisabled {
+			disabled++
+		}
+		priority := TaskPriorityNormal
+		if tracker != nil {
+			priority = crashPriority(logger, tracker, pkgPath,
+				target)
 		}
+		taskQueue.EnqueuePriority(Task{
+			PackagePath: pkgPath,
+			Target:      target,
+		}, priority)
 	}
 
-	if taskQueue.Length() == 0 {
+	targetsDiscovered.Set(float64(len(states)))
+
+	if len(states) == 0 {
 		errChan <- fmt.Errorf("No fuzz targets found; please add " +
 			"some fuzz targets.")
 		return
 	}
 
-	// Calculate the fuzzing time for each fuzz target.
-	perTargetTimeout := calculateFuzzSeconds(cfg.Fuzz.SyncFrequency,
-		cfg.Fuzz.NumWorkers, taskQueue.Length())
+	// Deal with the corpora of the targets that are no longer discovered,
+	// unless the discovery may have been cut short.
+	if ctx.Err() == nil {
+		pkgPaths := make([]string, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			pkgPaths = append(pkgPaths, pkg.path)
+		}
+		err := handleOrphanedTargets(ctx, logger, cfg, pkgPaths,
+			states)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to handle orphaned "+
+				"corpora: %w", err)
+			return
+		}
+	}
+
+	// If all targets were deferred, there is nothing to fuzz in this
+	// cycle, which unlike all targets failing to build is not an error.
+	if taskQueue.Length() == 0 && deferred == len(states) {
+		logger.Info("All fuzz targets have plateaued and are not due "+
+			"for a rerun; nothing to fuzz in this cycle", "count",
+			deferred)
+		errChan <- nil
+		return
+	}
+
+	if taskQueue.Length() == 0 {
+		errChan <- fmt.Errorf("all %d fuzz targets failed to build",
+			len(states)-deferred-unsampled)
+		return
+	}
 
+	// Targets are discovered in the order of the configured packages and
+	// of their declarations, which changes along with the code, so sort
+	// them, within their priority, if a deterministic order is requested.
+	if cfg.Fuzz.DeterministicOrder {
+		taskQueue.Sort()
+	}
+
+	// The time spent discovering the fuzz targets and building their
+	// binaries counts against the cycle, so only the remaining time is
+	// split among the fuzz targets.
+	setupElapsed := time.Since(startTime)
+	logger.Info("Fuzz target discovery and build completed", "elapsed",
+		setupElapsed)
+
+	// The extra time granted to the targets starved in the previous cycle
+	// is set aside before the rest of the cycle is split.
+	var extraTime time.Duration
+	if starve != nil {
+		extraTime = starve.totalExtraTime(taskQueue.Tasks(),
+			cfg.Fuzz.NumWorkers)
+	}
+
+	// Calculate the fuzzing time for each fuzz target. Disabled targets
+	// are not fuzzed unless re-enabled, so they do not get a share of the
+	// cycle.
+	perTargetTimeout := calculateFuzzSeconds(syncFrequency-
+		setupElapsed-extraTime, gracePeriod,
+		cfg.Fuzz.NumWorkers, max(taskQueue.Length()-disabled, 1))
+
+	// A cycle shortened to end by the maximum runtime may not leave
+	// enough time to fuzz, which only ends it early.
+	if perTargetTimeout == 0 && syncFrequency < cfg.Fuzz.SyncFrequency {
+		logger.Warn("No time left to fuzz before maximum runtime; "+
+			"ending cycle", "elapsed", setupElapsed,
+			"syncFrequency", syncFrequency)
+		errChan <- nil
+		return
+	}
 	if perTargetTimeout == 0 {
-		errChan <- fmt.Errorf("invalid fuzz duration: %s",
-			perTargetTimeout)
+		errChan <- fmt.Errorf("invalid fuzz duration: %s, discovery "+
+			"and build took %s of the %s cycle", perTargetTimeout,
+			setupElapsed, syncFrequency)
 		return
 	}
 
 	logger.Info("Per-target fuzz timeout calculated", "duration",
-		perTargetTimeout)
+		perTargetTimeout, "starvedExtraTime", extraTime)
+
+	// With the weighted schedule, the fuzz targets share the same time in
+	// proportion to the coverage they recently gained instead. Disabled
+	// targets that get re-enabled fall back to the even timeout.
+	var taskTimeouts map[Task]time.Duration
+	if cfg.Fuzz.ScheduleStrategy == ScheduleStrategyWeighted {
+		var tasks []Task
+		for _, task := range taskQueue.Tasks() {
+			if disable == nil || !disable.disabled(
+				task.PackagePath, task.Target) {
+
+				tasks = append(tasks, task)
+			}
+		}
+
+		weights, err := scheduleWeights(cfg.Project.ReportDir, tasks,
+			cfg.Fuzz.PlateauWindow)
+		if err != nil {
+			errChan <- fmt.Errorf("failed to weigh fuzz targets: "+
+				"%w", err)
+			return
+		}
+
+		taskTimeouts = weightedFuzzDurations(syncFrequency-
+			setupElapsed-extraTime, gracePeriod,
+			cfg.Fuzz.NumWorkers, tasks, weights)
+		for _, task := range tasks {
+			logger.Info("Weighted fuzz timeout calculated",
+				"package", task.PackagePath, "target",
+				task.Target, "weight", weights[task],
+				"duration", taskTimeouts[task])
+		}
+	}
 
 	// Create a Docker client for running containers.
 	cli, err := client.NewClientWithOpts(client.FromEnv,
@@ -272,29 +894,11 @@ func scheduleFuzzing(ctx context.Context, logger *slog.Logger, cfg *Config,
 		}
 	}()
 
-	// Pull the Docker image specified by ContainerImage.
-	reader, err := cli.ImagePull(ctx, ContainerImage,
-		image.PullOptions{})
+	// Pull the Docker image of the fuzz containers.
+	err = pullContainerImage(ctx, logger, cli, cfg.Fuzz.ContainerImage,
+		cfg.Fuzz.ImagePullAuth)
 	if err != nil {
-		errChan <- fmt.Errorf("failed to pull docker image: %w", err)
-		return
-	}
-	defer func() {
-		err := reader.Close()
-		if err != nil {
-			logger.Error("Failed to close image logs reader",
-				"error", err)
-		}
-	}()
-
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		logger.Info("Image Pull output", "message", line)
-	}
-	if err := scanner.Err(); err != nil {
-		errChan <- fmt.Errorf("error reading image-pull stream: %w",
-			err)
+		errChan <- err
 		return
 	}
 
@@ -308,7 +912,8 @@ func scheduleFuzzing(ctx context.Context, logger *slog.Logger, cfg *Config,
 	}
 
 	// Update the master index (index.html).
-	err = addToMaster(repo, cfg.Project.ReportDir, states, logger)
+	err = addToMaster(repo, cfg.Project.ReportDir, states,
+		cfg.Fuzz.CompactJSON, logger)
 	if err != nil {
 		errChan <- fmt.Errorf("master index update failed: %w", err)
 		return
@@ -322,15 +927,51 @@ func scheduleFuzzing(ctx context.Context, logger *slog.Logger, cfg *Config,
 		goGroup:              g,
 		cli:                  cli,
 		cfg:                  cfg,
+		engine:               engine,
 		taskQueue:            taskQueue,
 		taskTimeout:          perTargetTimeout,
+		taskTimeouts:         taskTimeouts,
 		shouldMinimizeCorpus: shouldMinimizeCorpus,
+		bootstrapping:        bootstrapping,
+		summary:              summary,
+		status:               status,
+		disable:              disable,
+		starve:               starve,
+	}
+	if cfg.Fuzz.ReportMode == ReportModeDigest {
+		wg.digest = newCrashDigest()
 	}
 
 	// Start and wait for all workers to finish or for the first
 	// error/cancellation.
-	if err := wg.WorkersStartAndWait(cfg.Fuzz.NumWorkers); err != nil {
-		errChan <- fmt.Errorf("fuzzing process failed: %w", err)
+	workersErr := wg.WorkersStartAndWait(cfg.Fuzz.NumWorkers)
+
+	// In digest mode, post the crashes found by the workers even if the
+	// cycle failed, since they are not reported otherwise.
+	if wg.digest != nil {
+		err := postCrashDigest(ctx, logger, cli, cfg, wg.digest)
+		if err != nil && workersErr == nil {
+			errChan <- fmt.Errorf("posting crash digest failed: %w",
+				err)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to post crash digest", "error",
+				err)
+		}
+	}
+
+	if workersErr != nil {
+		errChan <- fmt.Errorf("fuzzing process failed: %w",
+			workersErr)
+		return
+	}
+
+	// Persist the status of all targets of this cycle and render it into
+	// the master index.
+	err = updateMasterStatus(repo, cfg.Project.ReportDir, status, logger)
+	if err != nil {
+		errChan <- fmt.Errorf("target status update failed: %w", err)
 		return
 	}
 
@@ -338,12 +979,12 @@ func scheduleFuzzing(ctx context.Context, logger *slog.Logger, cfg *Config,
 	errChan <- nil
 }
 
-// createFuzzBinary builds a fuzz test binary for the specified package and
-// target. The binary is cross-compiled for Linux/amd64 to ensure compatibility
-// with the Docker container environment. The resulting binary is placed in the
-// configured binary directory.
+// createFuzzBinary builds a fuzz binary for the specified package and target
+// using the given fuzzing engine. The binary is cross-compiled for Linux/amd64
+// to ensure compatibility with the Docker container environment. The resulting
+// binary is placed in the configured binary directory.
 func createFuzzBinary(ctx context.Context, logger *slog.Logger, cfg *Config,
-	pkg, target string) error {
+	engine fuzzEngine, pkg, target string) error {
 
 	logger.Info("Building fuzz binary", "package", pkg, "target", target)
 
@@ -353,36 +994,224 @@ func createFuzzBinary(ctx context.Context, logger *slog.Logger, cfg *Config,
 	fuzzBinaryPath := filepath.Join(cfg.Project.BinaryDir, pkg, target,
 		fmt.Sprintf("%s.test", target))
 
-	// Prepare the command and environment to build the fuzz binary.
-	// Command arguments (explanations):
-	//
-	//   -fuzz=^%s$
-	// Run only the fuzz test function matching this regular expression.
-	//
-	//   -o %s
-	// Write the compiled test binary to the given output path
-	// (instead of running tests immediately).
-	//
-	//   -c
-	// Compile the test binary but do not run it. This is required so
-	// we can later run the binary directly in Docker container.
-	cmd := []string{"test", fmt.Sprintf("-fuzz=^%s$", target),
-		"-o", fuzzBinaryPath, "-c"}
-
-	// Run the go test command with GOOS and GOARCH set to build a
-	// linux/amd64 binary.
-	//
-	// GOOS is the target operating system (here "linux"), and GOARCH
-	// is the target architecture (here "amd64"). These values control
-	// the environment for the go toolchain when building and testing.
-	_, err := runGoCommand(ctx, pkgPath, cmd, "GOOS=linux", "GOARCH=amd64")
+	if err := EnsureDirExists(filepath.Dir(fuzzBinaryPath)); err != nil {
+		return err
+	}
+
+	// The build is bounded by its own timeout, so a hung build fails fast
+	// rather than silently consuming the cycle's fuzzing time.
+	buildCtx, cancel := withPhaseTimeout(ctx, cfg.Fuzz.BuildTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := engine.buildBinary(buildCtx, pkgPath, fuzzBinaryPath, target)
+	if phaseTimedOut(ctx, buildCtx) {
+		return fmt.Errorf("building fuzz binary for %q/%q timed out "+
+			"after %s", pkg, target, cfg.Fuzz.BuildTimeout)
+	}
 	if err != nil {
-		return fmt.Errorf("go test failed for %q: %w ", pkg, err)
+		return fmt.Errorf("building fuzz binary failed for %q: %w ",
+			pkg, err)
 	}
 
+	logger.Info("Built fuzz binary", "package", pkg, "target", target,
+		"elapsed", time.Since(start))
+
 	return nil
 }
 
+// fuzzPackage is a package whose fuzz targets are fuzzed, either listed in
+// cfg.Fuzz.PkgsPath or matched by one of its recursive patterns.
+type fuzzPackage struct {
+	path string
+
+	// matched is true if the package is only matched by a recursive
+	// pattern, rather than listed on its own.
+	matched bool
+
+	// targets holds the fuzz targets discovered in the package.
+	targets []string
+}
+
+// expandPkgsPath returns the packages of cfg.Fuzz.PkgsPath, in the same order,
+// with its recursive patterns (e.g. "./..." or "parser/...") expanded into the
+// packages they match in the project checkout, as listed by "go list". Every
+// package is only returned once, even if listed or matched several times.
+func expandPkgsPath(ctx context.Context, logger *slog.Logger,
+	cfg *Config) ([]fuzzPackage, error) {
+
+	var pkgs []fuzzPackage
+	seen := make(map[string]bool)
+	for _, pkgPath := range cfg.Fuzz.PkgsPath {
+		dir, recursive := recursivePkgDir(pkgPath)
+		if !recursive {
+			if !seen[path.Clean(pkgPath)] {
+				seen[path.Clean(pkgPath)] = true
+				pkgs = append(pkgs, fuzzPackage{path: pkgPath})
+			}
+			continue
+		}
+
+		matches, err := listPackages(ctx, cfg, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand package "+
+				"pattern %q: %w", pkgPath, err)
+		}
+		logger.Info("Expanded package pattern", "pattern", pkgPath,
+			"count", len(matches))
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				pkgs = append(pkgs, fuzzPackage{
+					path:    match,
+					matched: true,
+				})
+			}
+		}
+	}
+
+	return pkgs, nil
+}
+
+// listPackages returns the packages in the directory dir of the project
+// checkout and its subdirectories, as paths relative to the checkout. Like for
+// "go list ./...", directories named testdata or starting with "." or "_" are
+// left out, as are nested modules.
+func listPackages(ctx context.Context, cfg *Config, dir string) ([]string,
+	error) {
+
+	// Listing the packages loads them, which is bounded like the discovery
+	// of their fuzz targets.
+	listCtx, cancel := withPhaseTimeout(ctx, cfg.Fuzz.DiscoveryTimeout)
+	defer cancel()
+
+	cmd := []string{"list", "-f", "{{.Dir}}", "./..."}
+	output, err := runGoCommand(listCtx, filepath.Join(cfg.Project.SrcDir,
+		dir), cmd)
+	if phaseTimedOut(ctx, listCtx) {
+		return nil, fmt.Errorf("listing packages timed out after %s",
+			cfg.Fuzz.DiscoveryTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The go command reports the package directories with symbolic links
+	// resolved.
+	srcDir, err := filepath.EvalSymlinks(cfg.Project.SrcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []string
+	for _, line := range strings.Split(output, "\n") {
+		pkgDir := strings.TrimSpace(line)
+		if pkgDir == "" {
+			continue
+		}
+
+		pkg, err := filepath.Rel(srcDir, pkgDir)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, filepath.ToSlash(pkg))
+	}
+
+	return pkgs, nil
+}
+
+// crashPriority returns the priority of the target in the task queue when
+// prioritizing crashing targets: TaskPriorityCrashing if crash issues are open
+// for it, and TaskPriorityNormal otherwise. A failure to count its open issues
+// is logged, and leaves the target at TaskPriorityNormal.
+func crashPriority(logger *slog.Logger, tracker CrashTracker, pkg,
+	target string) int {
+
+	openIssues, err := tracker.countOpenIssues(pkg, target)
+	if err != nil {
+		logger.Warn("Failed to count open crash issues of fuzz "+
+			"target; not prioritizing it", "package", pkg,
+			"target", target, "error", err)
+		return TaskPriorityNormal
+	}
+
+	if openIssues == 0 {
+		return TaskPriorityNormal
+	}
+
+	logger.Info("Prioritizing fuzz target with open crash issues",
+		"package", pkg, "target", target, "openIssues", openIssues)
+
+	return TaskPriorityCrashing
+}
+
+// discoverFuzzTargets returns the packages of cfg.Fuzz.PkgsPath, with its
+// recursive patterns expanded, along with their fuzz targets. The targets of
+// each package are looked up in targetCache if non-nil, and otherwise
+// discovered and added to it. Since every discovery compiles the package's
+// tests, up to cfg.Fuzz.NumWorkers packages are discovered concurrently.
+func discoverFuzzTargets(ctx context.Context, logger *slog.Logger,
+	cfg *Config, targetCache map[string][]string) ([]fuzzPackage, error) {
+
+	pkgs, err := expandPkgsPath(ctx, logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g, discoveryCtx := errgroup.WithContext(ctx)
+	g.SetLimit(max(cfg.Fuzz.NumWorkers, 1))
+	for i := range pkgs {
+		pkg := &pkgs[i]
+		if targets, ok := targetCache[pkg.path]; ok {
+			logger.Info("Reusing discovered fuzz targets",
+				"package", pkg.path, "count", len(targets))
+			pkg.targets = targets
+			continue
+		}
+
+		g.Go(func() error {
+			targets, err := listFuzzTargets(discoveryCtx, logger,
+				cfg, pkg.path)
+			if err != nil {
+				logger.Error("Failed to list fuzz targets",
+					"package", pkg.path)
+				return err
+			}
+			pkg.targets = targets
+
+			// Many of the packages matched by a pattern usually
+			// have no fuzz targets, unlike those listed on their
+			// own.
+			switch {
+			case len(targets) > 0:
+			case pkg.matched:
+				logger.Debug("No fuzz targets found in "+
+					"matched package; skipping", "package",
+					pkg.path)
+			default:
+				logger.Warn("No valid fuzz targets found",
+					"package", pkg.path)
+			}
+
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Only cache complete discoveries, which a canceled cycle may have
+	// cut short.
+	if targetCache != nil && ctx.Err() == nil {
+		for _, pkg := range pkgs {
+			targetCache[pkg.path] = pkg.targets
+		}
+	}
+
+	return pkgs, nil
+}
+
 // listFuzzTargets discovers and returns a list of fuzz targets for the given
 // package. It uses "go test -list=^Fuzz" to list the functions and filters
 // those that start with "Fuzz".
@@ -400,8 +1229,19 @@ func listFuzzTargets(ctx context.Context, logger *slog.Logger, cfg *Config,
 	//
 	// Execute the command and check for errors, when the context wasn't
 	// canceled.
+	// The discovery is bounded by its own timeout, so a hung compilation
+	// fails fast rather than silently consuming the cycle's fuzzing time.
+	discoveryCtx, cancel := withPhaseTimeout(ctx,
+		cfg.Fuzz.DiscoveryTimeout)
+	defer cancel()
+
+	start := time.Now()
 	cmd := []string{"test", "-list=^Fuzz", "."}
-	output, err := runGoCommand(ctx, pkgPath, cmd)
+	output, err := runGoCommand(discoveryCtx, pkgPath, cmd)
+	if phaseTimedOut(ctx, discoveryCtx) {
+		return nil, fmt.Errorf("fuzz target discovery for %q timed "+
+			"out after %s", pkg, cfg.Fuzz.DiscoveryTimeout)
+	}
 	if err != nil && ctx.Err() == nil {
 		return nil, fmt.Errorf("go test failed for %q: %w ", pkg, err)
 	}
@@ -419,10 +1259,70 @@ func listFuzzTargets(ctx context.Context, logger *slog.Logger, cfg *Config,
 		}
 	}
 
-	// If no fuzz targets are found, log a warning to inform the user.
-	if len(targets) == 0 {
-		logger.Warn("No valid fuzz targets found", "package", pkg)
-	}
+	logger.Info("Discovered fuzz targets", "package", pkg, "count",
+		len(targets), "elapsed", time.Since(start))
 
 	return targets, nil
 }
+
+// checkContainerRuntime verifies that the Docker daemon running the fuzz
+// containers is reachable, so a misconfigured runtime is reported at startup
+// rather than once the project is cloned and the corpus is downloaded.
+func checkContainerRuntime(ctx context.Context, logger *slog.Logger) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv,
+		client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed to start docker client: %w; check "+
+			"the DOCKER_HOST, DOCKER_API_VERSION and "+
+			"DOCKER_CERT_PATH environment variables", err)
+	}
+	defer func() {
+		if err := cli.Close(); err != nil {
+			logger.Error("Failed to stop docker client", "error",
+				err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, RuntimeCheckTimeout)
+	defer cancel()
+
+	if _, err := cli.Ping(ctx); err != nil {
+		return fmt.Errorf("docker daemon at %s is unreachable: %w; "+
+			"ensure it is running and the user can access it, or "+
+			"set fuzz.skip-runtime-check to skip this check",
+			cli.DaemonHost(), err)
+	}
+
+	return nil
+}
+
+// pullContainerImage pulls the given Docker image with the given encoded
+// registry credentials, if any, logging the output of the pull.
+func pullContainerImage(ctx context.Context, logger *slog.Logger,
+	cli *client.Client, containerImage, registryAuth string) error {
+
+	reader, err := cli.ImagePull(ctx, containerImage, image.PullOptions{
+		RegistryAuth: registryAuth,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pull docker image: %w", err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			logger.Error("Failed to close image logs reader",
+				"error", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		logger.Info("Image Pull output", "message", line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading image-pull stream: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiscoverFuzzTargets verifies that recursive package patterns are
+// expanded into the packages they match, each package being listed once in the
+// order of the configured package paths, and that packages without fuzz
+// targets are kept without any.
+func TestDiscoverFuzzTargets(t *testing.T) {
+	cfg := &Config{
+		Project: Project{SrcDir: filepath.Join(t.TempDir(), "project")},
+		Fuzz: Fuzz{
+			PkgsPath:   []string{"parser/...", "./...", "parser"},
+			NumWorkers: 2,
+		},
+	}
+
+	fuzzTest := func(pkg, target string) string {
+		return "package " + pkg + "\n\nimport \"testing\"\n\n" +
+			"func " + target + "(f *testing.F) {\n" +
+			"\tf.Fuzz(func(t *testing.T, data []byte) {})\n}\n"
+	}
+	writeFiles(t, cfg.Project.SrcDir, map[string]string{
+		"go.mod": "module example.com/fuzzme\n\n" +
+			"go 1.21\n",
+		"parser/parser.go":           "package parser\n",
+		"parser/parser_test.go":      fuzzTest("parser", "FuzzParse"),
+		"parser/lexer/lexer.go":      "package lexer\n",
+		"parser/lexer/lexer_test.go": fuzzTest("lexer", "FuzzLex"),
+		"util/util.go":               "package util\n",
+
+		// Neither testdata directories nor nested modules are
+		// matched by patterns.
+		"parser/testdata/gen/gen.go": "package gen\n",
+		"tools/go.mod":               "module example.com/tools\n",
+		"tools/tools.go":             "package tools\n",
+	})
+
+	targetCache := make(map[string][]string)
+	pkgs, err := discoverFuzzTargets(context.Background(),
+		slog.New(slog.DiscardHandler), cfg, targetCache)
+	assert.NoError(t, err)
+	assert.Equal(t, []fuzzPackage{
+		{path: "parser", matched: true, targets: []string{"FuzzParse"}},
+		{path: "parser/lexer", matched: true,
+			targets: []string{"FuzzLex"}},
+		{path: "util", matched: true},
+	}, pkgs)
+	assert.Equal(t, map[string][]string{
+		"parser":       {"FuzzParse"},
+		"parser/lexer": {"FuzzLex"},
+		"util":         nil,
+	}, targetCache)
+
+	// Packages listed on their own are kept as is.
+	cfg.Fuzz.PkgsPath = []string{"./parser", "parser/..."}
+	pkgs, err = discoverFuzzTargets(context.Background(),
+		slog.New(slog.DiscardHandler), cfg, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []fuzzPackage{
+		{path: "./parser", targets: []string{"FuzzParse"}},
+		{path: "parser/lexer", matched: true,
+			targets: []string{"FuzzLex"}},
+	}, pkgs)
+
+	// Patterns of missing directories fail the discovery.
+	cfg.Fuzz.PkgsPath = []string{"missing/..."}
+	_, err = discoverFuzzTargets(context.Background(),
+		slog.New(slog.DiscardHandler), cfg, nil)
+	assert.ErrorContains(t, err, "failed to expand package pattern")
+}
+
+// TestDiscoverFuzzTargetsConcurrently verifies that discovering the packages
+// concurrently keeps them in the order of the configured package paths, reuses
+// and fills the target cache, and that the first failing discovery cancels the
+// others and fails the whole discovery without caching anything. Run it with
+// -race to check the concurrent discoveries.
+func TestDiscoverFuzzTargetsConcurrently(t *testing.T) {
+	cfg := &Config{
+		Project: Project{SrcDir: filepath.Join(t.TempDir(), "project")},
+		Fuzz:    Fuzz{NumWorkers: 4},
+	}
+
+	files := map[string]string{
+		"go.mod": "module example.com/fuzzme\n\ngo 1.21\n",
+	}
+	var expected []fuzzPackage
+	for i := range 8 {
+		pkg := fmt.Sprintf("pkg%d", i)
+		target := fmt.Sprintf("FuzzPkg%d", i)
+		files[pkg+"/fuzz_test.go"] = "package " + pkg + "\n\n" +
+			"import \"testing\"\n\n" +
+			"func " + target + "(f *testing.F) {}\n"
+		cfg.Fuzz.PkgsPath = append(cfg.Fuzz.PkgsPath, pkg)
+		expected = append(expected, fuzzPackage{path: pkg,
+			targets: []string{target}})
+	}
+	writeFiles(t, cfg.Project.SrcDir, files)
+
+	// The cached packages are reused as is, while the others are
+	// discovered and cached.
+	targetCache := map[string][]string{
+		"pkg1": {"FuzzCached1"},
+		"pkg6": {"FuzzCached6"},
+	}
+	expected[1].targets = []string{"FuzzCached1"}
+	expected[6].targets = []string{"FuzzCached6"}
+
+	for range 3 {
+		pkgs, err := discoverFuzzTargets(context.Background(),
+			slog.New(slog.DiscardHandler), cfg, targetCache)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, pkgs)
+	}
+	assert.Len(t, targetCache, 8)
+	for _, pkg := range expected {
+		assert.Equal(t, pkg.targets, targetCache[pkg.path])
+	}
+
+	// A package failing to compile cancels the discovery of a package
+	// whose test binary hangs, rather than waiting for it.
+	writeFiles(t, cfg.Project.SrcDir, map[string]string{
+		"broken/fuzz_test.go": "package broken\n\nfunc {\n",
+		"slow/fuzz_test.go": "package slow\n\n" +
+			"import \"time\"\n\n" +
+			"func init() { time.Sleep(2 * time.Minute) }\n",
+	})
+	cfg.Fuzz.PkgsPath = []string{"slow", "broken", "pkg0", "pkg2"}
+	targetCache = make(map[string][]string)
+
+	start := time.Now()
+	_, err := discoverFuzzTargets(context.Background(),
+		slog.New(slog.DiscardHandler), cfg, targetCache)
+	assert.ErrorContains(t, err, `go test failed for "broken"`)
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Empty(t, targetCache)
+}
+
+// TestCrashPriority verifies that only targets with open crash issues are
+// prioritized.
+func TestCrashPriority(t *testing.T) {
+	issues := &fakeIssueClient{}
+	tracker := &GitHubRepo{
+		crashReporter: crashReporter{
+			ctx:    context.Background(),
+			logger: slog.New(slog.DiscardHandler),
+			cfg:    &Config{},
+			issues: issues,
+		},
+	}
+	_, err := issues.createIssue("Fuzzing crash in parser/FuzzEval",
+		"body")
+	assert.NoError(t, err)
+
+	logger := slog.New(slog.DiscardHandler)
+	assert.Equal(t, TaskPriorityCrashing, crashPriority(logger, tracker,
+		"parser", "FuzzEval"))
+	assert.Equal(t, TaskPriorityNormal, crashPriority(logger, tracker,
+		"parser", "FuzzLex"))
+}
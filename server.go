@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// serverShutdownTimeout is how long the HTTP servers of the daemon wait for
+// their in-flight requests when shutting down.
+const serverShutdownTimeout = 5 * time.Second
+
+// startHTTPServer serves the given handler at the given address until the
+// context is canceled, when the server is shut down. The name of the server is
+// only used for logging. Returns the address listened on, or an error if the
+// given address cannot be listened on.
+func startHTTPServer(ctx context.Context, logger *slog.Logger, name,
+	addr string, handler http.Handler) (string, error) {
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("listening on %s: %w", addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "server", name,
+				"error", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(
+			context.Background(), serverShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down HTTP server",
+				"server", name, "error", err)
+		}
+	}()
+
+	logger.Info("Serving HTTP endpoints", "server", name, "address",
+		listener.Addr().String())
+
+	return listener.Addr().String(), nil
+}
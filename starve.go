@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// starvePolicy decides which fuzz targets are starved: a target that executed
+// fewer than a minimum number of inputs within its time slot, e.g. because
+// building and starting it ate most of the slot, made almost no progress
+// although it appears to complete. Starved targets are reported, and may be
+// granted extra time in the next cycle.
+type starvePolicy struct {
+	// minExecs is the minimum number of inputs a target must execute
+	// within its time slot not to be starved.
+	minExecs int64
+
+	// extraTime is the time added to the time slot of a target that was
+	// starved in the previous cycle.
+	extraTime time.Duration
+
+	// starved holds the targets that were starved the last time they were
+	// fuzzed.
+	starved map[TargetState]bool
+}
+
+// newStarvePolicy returns the starve policy of a fuzzing cycle, based on the
+// target statuses in the report directory.
+func newStarvePolicy(cfg *Config) (*starvePolicy, error) {
+	statusPath := filepath.Join(cfg.Project.ReportDir, "status.json")
+	statuses, err := loadTargetStatuses(statusPath)
+	if err != nil {
+		return nil, fmt.Errorf("load target statuses from %q: %w",
+			statusPath, err)
+	}
+
+	starved := make(map[TargetState]bool)
+	for _, s := range statuses {
+		if s.Starved {
+			starved[TargetState{s.PkgPath, s.Target}] = true
+		}
+	}
+
+	return &starvePolicy{
+		minExecs:  cfg.Fuzz.MinExecs,
+		extraTime: cfg.Fuzz.StarvedExtraTime,
+		starved:   starved,
+	}, nil
+}
+
+// starves reports whether the given number of executions reported by the
+// fuzzer within a time slot is below the minimum.
+func (p *starvePolicy) starves(execs int64) bool {
+	return execs < p.minExecs
+}
+
+// extraTimeFor returns the time added to the time slot of the target in this
+// cycle, which is the extra time if it was starved in the previous one.
+func (p *starvePolicy) extraTimeFor(pkg, target string) time.Duration {
+	if !p.starved[TargetState{pkg, target}] {
+		return 0
+	}
+	return p.extraTime
+}
+
+// totalExtraTime returns the extra time granted to the given tasks in this
+// cycle, per worker, rounded up to whole seconds, so it can be set aside from
+// the cycle before splitting the rest among the targets.
+func (p *starvePolicy) totalExtraTime(tasks []Task,
+	numWorkers int) time.Duration {
+
+	var total time.Duration
+	for _, task := range tasks {
+		total += p.extraTimeFor(task.PackagePath, task.Target)
+	}
+
+	perWorker := total / time.Duration(max(numWorkers, 1))
+	if perWorker.Truncate(time.Second) != perWorker {
+		perWorker = perWorker.Truncate(time.Second) + time.Second
+	}
+
+	return perWorker
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStarvePolicy verifies that targets executing fewer than the configured
+// minimum inputs are starved, and that only the targets starved in the
+// previous cycle are granted extra time, set aside per worker.
+func TestStarvePolicy(t *testing.T) {
+	reportDir := t.TempDir()
+	statuses := []TargetStatus{
+		{PkgPath: "parser", Target: "FuzzEval", Starved: true},
+		{PkgPath: "parser", Target: "FuzzLex"},
+		{PkgPath: "tree", Target: "FuzzBuild", Starved: true},
+	}
+	assert.NoError(t, saveTargetStatuses(filepath.Join(reportDir,
+		"status.json"), statuses))
+
+	cfg := &Config{
+		Project: Project{ReportDir: reportDir},
+		Fuzz: Fuzz{
+			MinExecs:         100,
+			StarvedExtraTime: 45 * time.Second,
+		},
+	}
+	starve, err := newStarvePolicy(cfg)
+	assert.NoError(t, err)
+
+	assert.True(t, starve.starves(0))
+	assert.True(t, starve.starves(99))
+	assert.False(t, starve.starves(100))
+
+	assert.Equal(t, 45*time.Second, starve.extraTimeFor("parser",
+		"FuzzEval"))
+	assert.Zero(t, starve.extraTimeFor("parser", "FuzzLex"))
+	assert.Zero(t, starve.extraTimeFor("tree", "FuzzNew"))
+
+	tasks := []Task{
+		{PackagePath: "parser", Target: "FuzzEval"},
+		{PackagePath: "parser", Target: "FuzzLex"},
+		{PackagePath: "tree", Target: "FuzzBuild"},
+	}
+	assert.Equal(t, 90*time.Second, starve.totalExtraTime(tasks, 1))
+	assert.Equal(t, 23*time.Second, starve.totalExtraTime(tasks, 4))
+	assert.Zero(t, starve.totalExtraTime(tasks[1:2], 2))
+}
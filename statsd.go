@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// statsdTagReplacer replaces the characters delimiting the fields and tags of
+// DogStatsD datagrams in tag values.
+var statsdTagReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_",
+	"\n", "_")
+
+// statsdClient is a metricsSink sending each metric update as a DogStatsD
+// datagram over UDP, e.g. to a Datadog agent or a StatsD server. Sending
+// never blocks the fuzzing, and updates failing to be sent are dropped, as is
+// customary for StatsD.
+type statsdClient struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newStatsdClient returns a statsdClient sending the metric updates to the
+// StatsD agent at the given host:port address.
+func newStatsdClient(addr string) (*statsdClient, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial StatsD agent %q: %w",
+			addr, err)
+	}
+
+	return &statsdClient{conn: conn}, nil
+}
+
+// count sends an increment of the counter with the given name and labels.
+func (c *statsdClient) count(name string, value float64,
+	labels map[string]string) {
+
+	c.send(formatStatsdMetric(name, value, "c", labels))
+}
+
+// gauge sends the value of the gauge with the given name and labels.
+func (c *statsdClient) gauge(name string, value float64,
+	labels map[string]string) {
+
+	c.send(formatStatsdMetric(name, value, "g", labels))
+}
+
+// send sends the given datagram, dropping it on failure.
+func (c *statsdClient) send(datagram string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, _ = c.conn.Write([]byte(datagram))
+}
+
+// Close closes the connection to the StatsD agent.
+func (c *statsdClient) Close() error {
+	return c.conn.Close()
+}
+
+// formatStatsdMetric formats the update of a metric of the given DogStatsD type
+// as a datagram, e.g. go_continuous_fuzz.crashes_found_total:1|c|#package:pkg,
+// target:FuzzFoo, with the labels as tags sorted by name.
+func formatStatsdMetric(name string, value float64, metricType string,
+	labels map[string]string) string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s.%s:%s|%s", metricsNamespace, name,
+		strconv.FormatFloat(value, 'f', -1, 64), metricType)
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for i, name := range names {
+		if i == 0 {
+			b.WriteString("|#")
+		} else {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%s:%s", name,
+			statsdTagReplacer.Replace(labels[name]))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormatStatsdMetric verifies that metric updates are formatted as
+// DogStatsD datagrams, with their labels as sorted and sanitized tags.
+func TestFormatStatsdMetric(t *testing.T) {
+	assert.Equal(t, "go_continuous_fuzz.issues_opened_total:1|c",
+		formatStatsdMetric("issues_opened_total", 1, "c", nil))
+
+	assert.Equal(t, "go_continuous_fuzz.target_coverage_percent:42.5|g|"+
+		"#package:pkg/sub,target:Fuzz_a_b_c",
+		formatStatsdMetric("target_coverage_percent", 42.5, "g",
+			map[string]string{
+				"target":  "Fuzz,a|b#c",
+				"package": "pkg/sub",
+			}))
+}
+
+// TestStatsdClient verifies that the metric updates are sent to the StatsD
+// agent once the client is installed as the metrics sink.
+func TestStatsdClient(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer agent.Close()
+
+	client, err := newStatsdClient(agent.LocalAddr().String())
+	assert.NoError(t, err)
+	defer client.Close()
+
+	setMetricsSink(client)
+	defer setMetricsSink(nil)
+
+	receive := func() string {
+		buf := make([]byte, 1024)
+		err := agent.SetReadDeadline(time.Now().Add(5 * time.Second))
+		assert.NoError(t, err)
+		n, _, err := agent.ReadFrom(buf)
+		assert.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	crashesFound.Inc("pkg", "FuzzFoo")
+	assert.Equal(t, "go_continuous_fuzz.crashes_found_total:1|c|"+
+		"#package:pkg,target:FuzzFoo", receive())
+
+	cycleDuration.Set(3600)
+	assert.Equal(t, "go_continuous_fuzz.cycle_duration_seconds:3600|g",
+		receive())
+}
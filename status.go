@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// TargetResultOK marks a target fuzzed without finding any crash.
+	TargetResultOK = "ok"
+
+	// TargetResultCrash marks a target for which a crash was found.
+	TargetResultCrash = "crash"
+
+	// TargetResultBuildFail marks a target whose fuzz binary failed to
+	// build.
+	TargetResultBuildFail = "build-fail"
+
+	// TargetResultSkip marks a target that was scheduled but not fuzzed,
+	// e.g. because the cycle ended before its turn.
+	TargetResultSkip = "skip"
+
+	// TargetResultOOM marks a target whose fuzz container was killed for
+	// running out of memory.
+	TargetResultOOM = "oom"
+
+	// TargetResultDisabled marks a target that was not fuzzed because it
+	// is disabled for crashing early, and its crash is not fixed yet.
+	TargetResultDisabled = "disabled"
+)
+
+// TargetStatus records the outcome of the last fuzzing cycle that scheduled a
+// fuzzing target, the number of consecutive cycles in which fuzzing it ended in
+// an early crash, and whether it executed fewer than the minimum number of
+// inputs the last time it was fuzzed.
+type TargetStatus struct {
+	PkgPath      string
+	Target       string
+	LastRun      time.Time
+	Result       string
+	Coverage     string
+	OpenIssues   int
+	EarlyCrashes int
+	LastFuzzed   time.Time
+	Starved      bool
+}
+
+// cycleStatus collects the status of every fuzzing target scheduled in a
+// fuzzing cycle. It is safe for concurrent use by the workers.
+type cycleStatus struct {
+	mu sync.Mutex
+
+	scheduled    []TargetState
+	results      map[TargetState]TargetStatus
+	earlyCrashes map[TargetState]bool
+	starved      map[TargetState]bool
+}
+
+// newCycleStatus returns an empty, initialized cycleStatus.
+func newCycleStatus() *cycleStatus {
+	return &cycleStatus{
+		results:      make(map[TargetState]TargetStatus),
+		earlyCrashes: make(map[TargetState]bool),
+		starved:      make(map[TargetState]bool),
+	}
+}
+
+// schedule registers a target scheduled in this cycle. Targets that are never
+// recorded are marked as skipped.
+func (cs *cycleStatus) schedule(pkg, target string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.scheduled = append(cs.scheduled, TargetState{pkg, target})
+}
+
+// record records the result of running a target, along with its coverage (if
+// measured) and the number of GitHub issues open for it.
+func (cs *cycleStatus) record(pkg, target, result, coverage string,
+	openIssues int) {
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.results[TargetState{pkg, target}] = TargetStatus{
+		PkgPath:    pkg,
+		Target:     target,
+		LastRun:    time.Now().UTC(),
+		Result:     result,
+		Coverage:   coverage,
+		OpenIssues: openIssues,
+	}
+}
+
+// recordEarlyCrash records that fuzzing the target ended in an early crash in
+// this cycle.
+func (cs *cycleStatus) recordEarlyCrash(pkg, target string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.earlyCrashes[TargetState{pkg, target}] = true
+}
+
+// recordStarved records that the target executed fewer than the minimum number
+// of inputs in this cycle.
+func (cs *cycleStatus) recordStarved(pkg, target string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.starved[TargetState{pkg, target}] = true
+}
+
+// merge applies the results of this cycle to the statuses of previous cycles.
+// The coverage and open issue count of a target are kept when they could not
+// be determined in this cycle, and skipped targets keep their last run time.
+// The early crashes of a target are counted up for an early crash, kept if it
+// was not fuzzed, and reset otherwise. The last fuzzed time of a target is its
+// last run time, and whether it was starved is only updated, if it was fuzzed.
+func (cs *cycleStatus) merge(statuses []TargetStatus) []TargetStatus {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	byTarget := make(map[TargetState]int, len(statuses))
+	merged := append([]TargetStatus{}, statuses...)
+	for i, s := range merged {
+		byTarget[TargetState{s.PkgPath, s.Target}] = i
+	}
+
+	for _, key := range cs.scheduled {
+		i, ok := byTarget[key]
+		if !ok {
+			merged = append(merged, TargetStatus{
+				PkgPath: key.PkgPath,
+				Target:  key.Target,
+			})
+			i = len(merged) - 1
+			byTarget[key] = i
+		}
+
+		result, ok := cs.results[key]
+		if !ok {
+			merged[i].Result = TargetResultSkip
+			continue
+		}
+
+		if result.Coverage == "" {
+			result.Coverage = merged[i].Coverage
+		}
+		if result.Result == TargetResultBuildFail {
+			result.OpenIssues = merged[i].OpenIssues
+		}
+		fuzzed := result.Result != TargetResultBuildFail &&
+			result.Result != TargetResultDisabled
+		switch {
+		case cs.earlyCrashes[key]:
+			result.EarlyCrashes = merged[i].EarlyCrashes + 1
+
+		case !fuzzed:
+			result.EarlyCrashes = merged[i].EarlyCrashes
+		}
+
+		result.LastFuzzed = result.LastRun
+		result.Starved = cs.starved[key]
+		if !fuzzed {
+			result.LastFuzzed = merged[i].LastFuzzed
+			result.Starved = merged[i].Starved
+		}
+		merged[i] = result
+	}
+
+	return merged
+}
+
+// loadTargetStatuses loads the target statuses from a JSON file at the given
+// path. If the file does not exist, it returns an empty slice.
+func loadTargetStatuses(statusPath string) ([]TargetStatus, error) {
+	statusData, err := os.ReadFile(statusPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read status file %q: %w",
+			statusPath, err)
+	}
+
+	var statuses []TargetStatus
+	if err := json.Unmarshal(statusData, &statuses); err != nil {
+		return nil, fmt.Errorf("invalid JSON in status file %q: %w",
+			statusPath, err)
+	}
+
+	return statuses, nil
+}
+
+// saveTargetStatuses saves the target statuses to a JSON file at the given
+// path.
+func saveTargetStatuses(statusPath string, statuses []TargetStatus) error {
+	statusData, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize statuses: %w", err)
+	}
+
+	if err := os.WriteFile(statusPath, statusData, 0644); err != nil {
+		return fmt.Errorf("failed to write status file %q: %w",
+			statusPath, err)
+	}
+
+	return nil
+}
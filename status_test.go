@@ -0,0 +1,149 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCycleStatusMerge verifies that the results of a cycle are applied to the
+// statuses of previous cycles, keeping what could not be determined again.
+func TestCycleStatusMerge(t *testing.T) {
+	lastRun := time.Date(2025, 7, 12, 10, 0, 0, 0, time.UTC)
+	previous := []TargetStatus{
+		{"parser", "FuzzEval", lastRun, TargetResultOK, "70.0", 0, 0,
+			lastRun, false},
+		{"tree", "FuzzBuild", lastRun, TargetResultCrash, "50.0", 2, 0,
+			lastRun, false},
+		{"old", "FuzzGone", lastRun, TargetResultOK, "10.0", 0, 0,
+			lastRun, false},
+	}
+
+	status := newCycleStatus()
+	status.schedule("parser", "FuzzEval")
+	status.schedule("tree", "FuzzBuild")
+	status.schedule("lexer", "FuzzLex")
+	status.schedule("lexer", "FuzzToken")
+	status.record("parser", "FuzzEval", TargetResultCrash, "", 1)
+	status.record("tree", "FuzzBuild", TargetResultBuildFail, "", 0)
+	status.record("lexer", "FuzzLex", TargetResultOK, "30.0", 0)
+
+	merged := status.merge(previous)
+	assert.Len(t, merged, 5)
+
+	// The coverage is kept when not measured in this cycle.
+	assert.Equal(t, TargetResultCrash, merged[0].Result)
+	assert.Equal(t, "70.0", merged[0].Coverage)
+	assert.Equal(t, 1, merged[0].OpenIssues)
+	assert.True(t, merged[0].LastRun.After(lastRun))
+	assert.Equal(t, merged[0].LastRun, merged[0].LastFuzzed)
+
+	// Issues are not verified for targets failing to build, which were
+	// not fuzzed either.
+	assert.Equal(t, TargetResultBuildFail, merged[1].Result)
+	assert.Equal(t, 2, merged[1].OpenIssues)
+	assert.Equal(t, lastRun, merged[1].LastFuzzed)
+
+	// Targets no longer scheduled are left untouched.
+	assert.Equal(t, previous[2], merged[2])
+
+	assert.Equal(t, TargetResultOK, merged[3].Result)
+	assert.Equal(t, "30.0", merged[3].Coverage)
+
+	// Scheduled targets that never ran are skipped.
+	assert.Equal(t, TargetStatus{PkgPath: "lexer", Target: "FuzzToken",
+		Result: TargetResultSkip}, merged[4])
+}
+
+// TestCycleStatusMergeEarlyCrashes verifies that the consecutive early crashes
+// of a target are counted up by an early crash, kept while it is not fuzzed,
+// and reset by any other result.
+func TestCycleStatusMergeEarlyCrashes(t *testing.T) {
+	previous := []TargetStatus{
+		{PkgPath: "pkg", Target: "FuzzEarly", EarlyCrashes: 1},
+		{PkgPath: "pkg", Target: "FuzzDisabled", EarlyCrashes: 3},
+		{PkgPath: "pkg", Target: "FuzzSkipped", EarlyCrashes: 2},
+		{PkgPath: "pkg", Target: "FuzzLate", EarlyCrashes: 2},
+		{PkgPath: "pkg", Target: "FuzzFixed", EarlyCrashes: 3},
+	}
+
+	status := newCycleStatus()
+	for _, s := range previous {
+		status.schedule(s.PkgPath, s.Target)
+	}
+	status.record("pkg", "FuzzEarly", TargetResultCrash, "", 1)
+	status.recordEarlyCrash("pkg", "FuzzEarly")
+	status.record("pkg", "FuzzDisabled", TargetResultDisabled, "", 1)
+	status.record("pkg", "FuzzLate", TargetResultCrash, "", 1)
+	status.record("pkg", "FuzzFixed", TargetResultOK, "", 0)
+
+	merged := status.merge(previous)
+	earlyCrashes := make([]int, len(merged))
+	for i, s := range merged {
+		earlyCrashes[i] = s.EarlyCrashes
+	}
+	assert.Equal(t, []int{2, 3, 2, 0, 0}, earlyCrashes)
+}
+
+// TestCycleStatusMergeStarved verifies that whether a target was starved is
+// updated when it is fuzzed, and kept while it is not.
+func TestCycleStatusMergeStarved(t *testing.T) {
+	previous := []TargetStatus{
+		{PkgPath: "pkg", Target: "FuzzStarved"},
+		{PkgPath: "pkg", Target: "FuzzFed", Starved: true},
+		{PkgPath: "pkg", Target: "FuzzBroken", Starved: true},
+		{PkgPath: "pkg", Target: "FuzzSkipped", Starved: true},
+	}
+
+	status := newCycleStatus()
+	for _, s := range previous {
+		status.schedule(s.PkgPath, s.Target)
+	}
+	status.record("pkg", "FuzzStarved", TargetResultOK, "", 0)
+	status.recordStarved("pkg", "FuzzStarved")
+	status.record("pkg", "FuzzFed", TargetResultOK, "", 0)
+	status.record("pkg", "FuzzBroken", TargetResultBuildFail, "", 0)
+
+	merged := status.merge(previous)
+	starved := make([]bool, len(merged))
+	for i, s := range merged {
+		starved[i] = s.Starved
+	}
+	assert.Equal(t, []bool{true, false, true, true}, starved)
+}
+
+// TestUpdateMasterStatus verifies that the target statuses are persisted to
+// status.json and rendered into the master index.
+func TestUpdateMasterStatus(t *testing.T) {
+	reportDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	states := []TargetState{
+		{PkgPath: "parser", Target: "FuzzEval"},
+		{PkgPath: "tree", Target: "FuzzBuild"},
+	}
+	assert.NoError(t, addToMaster("repo", reportDir, states, false,
+		logger))
+
+	status := newCycleStatus()
+	status.schedule("parser", "FuzzEval")
+	status.record("parser", "FuzzEval", TargetResultCrash, "75.0", 1)
+	assert.NoError(t, updateMasterStatus("repo", reportDir, status,
+		logger))
+
+	statuses, err := loadTargetStatuses(filepath.Join(reportDir,
+		"status.json"))
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, TargetResultCrash, statuses[0].Result)
+
+	index, err := os.ReadFile(filepath.Join(reportDir, "index.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), "status-crash")
+	assert.Contains(t, string(index), "75.0%")
+}
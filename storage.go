@@ -1,35 +1,74 @@
 package main
 
 import (
-	"archive/zip"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"mime"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
+// maxConcurrentShardTransfers is the maximum number of corpus shards uploaded
+// or downloaded in parallel.
+const maxConcurrentShardTransfers = 8
+
 // S3Store encapsulates the configuration and state needed to manage S3‑backed
-// operations, including context, logger, S3 client configuration, local
-// corpus/reports directory and ZIP file handling.
+// operations, including context, S3 client configuration, local reports
+// directory, the corpus archiver handling the local corpus, and the per-package
+// corpus shards (if sharding is enabled), dated corpus versions (if
+// versioning is enabled), quarantined corpus inputs (if quarantine is
+// enabled), and the bandwidth limiter throttling transfers (if limited).
 type S3Store struct {
-	ctx       context.Context
-	client    *s3.Client
-	logger    *slog.Logger
-	bucket    string
-	zipKey    string
-	corpusDir string
-	reportDir string
-	zipPath   string
+	corpusArchiver
+
+	ctx           context.Context
+	client        *s3.Client
+	bucket        string
+	corpusKey     string
+	reportDir     string
+	sharded       bool
+	shardPrefix   string
+	pkgs          []string
+	versioned     bool
+	versionPrefix string
+	mergeStrategy string
+	srcDir        string
+	logKeep       int
+	logMaxAge     time.Duration
+
+	// quarantine uploads the corpus inputs added since corpusBaseline was
+	// recorded under quarantinePrefix, instead of updating the canonical
+	// corpus.
+	quarantine       bool
+	quarantinePrefix string
+	corpusBaseline   map[string]bool
+
+	// bandwidth throttles the uploads, and the downloads if
+	// limitDownload is set, or is nil if the bandwidth is unlimited.
+	bandwidth     *rate.Limiter
+	limitDownload bool
+
+	// maxRetries is the number of times a failed download or upload is
+	// retried, waiting retryWait before the first retry and twice as long
+	// before every following one.
+	maxRetries int
+	retryWait  time.Duration
 }
 
 // NewS3Store constructs a S3Store for the given context, logger, and config.
@@ -41,20 +80,114 @@ func NewS3Store(ctx context.Context, logger *slog.Logger,
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	bandwidth := newBandwidthLimiter(cfg.Project.BandwidthLimit)
+
 	return &S3Store{
-		ctx:       ctx,
-		client:    s3.NewFromConfig(s3cfg),
-		logger:    logger,
-		bucket:    cfg.Project.S3BucketName,
-		zipKey:    cfg.Project.CorpusKey,
-		corpusDir: cfg.Project.CorpusDir,
-		reportDir: cfg.Project.ReportDir,
-		zipPath:   fmt.Sprintf("%s.zip", cfg.Project.CorpusDir),
+		corpusArchiver:   newCorpusArchiver(logger, cfg),
+		ctx:              ctx,
+		client:           s3.NewFromConfig(s3cfg),
+		bucket:           cfg.Project.S3BucketName,
+		corpusKey:        cfg.Project.CorpusKey,
+		reportDir:        cfg.Project.ReportDir,
+		sharded:          cfg.Project.CorpusSharding,
+		shardPrefix:      cfg.Project.CorpusShardPrefix,
+		pkgs:             cfg.Fuzz.PkgsPath,
+		versioned:        cfg.Project.CorpusVersions,
+		versionPrefix:    cfg.Project.CorpusVersionPrefix,
+		mergeStrategy:    cfg.Project.CorpusMergeStrategy,
+		srcDir:           cfg.Project.SrcDir,
+		logKeep:          cfg.Fuzz.FailureLogKeep,
+		logMaxAge:        cfg.Fuzz.FailureLogMaxAge,
+		quarantine:       cfg.Project.CorpusQuarantine,
+		quarantinePrefix: cfg.Project.CorpusQuarantinePrefix,
+		bandwidth:        bandwidth,
+		limitDownload:    cfg.Project.LimitDownloadBandwidth,
+		maxRetries:       cfg.Project.S3MaxRetries,
+		retryWait:        DefaultS3RetryWait,
 	}, nil
 }
 
+// corpusKeys returns the S3 object keys making up the canonical corpus: the
+// single corpus archive, or the shard of every package in sharded mode.
+func (s3s *S3Store) corpusKeys() []string {
+	if !s3s.sharded {
+		return []string{s3s.corpusKey}
+	}
+
+	keys := make([]string, 0, len(s3s.pkgs))
+	for _, pkg := range s3s.pkgs {
+		keys = append(keys, s3s.shardKey(pkg))
+	}
+	return keys
+}
+
+// versionKey returns the S3 object key under which the given version of the
+// corpus object stored at key is archived.
+func (s3s *S3Store) versionKey(version, key string) string {
+	return s3s.versionPrefix + version + "/" + key
+}
+
+// shardKey returns the S3 object key of the corpus shard holding the corpus of
+// the given package.
+func (s3s *S3Store) shardKey(pkg string) string {
+	return s3s.shardPrefix + pkg + "." + s3s.archiveFormat
+}
+
+// metadataKey returns the S3 object key whose metadata records the last corpus
+// minimization. In sharded mode, all shards are uploaded with the same metadata
+// so the shard of the first package is used.
+func (s3s *S3Store) metadataKey() string {
+	if s3s.sharded && len(s3s.pkgs) > 0 {
+		return s3s.shardKey(s3s.pkgs[0])
+	}
+	return s3s.corpusKey
+}
+
+// retryableS3Error reports whether the S3 operation that failed with the given
+// error is worth retrying: a missing object and the cancellation of the
+// context are final.
+func retryableS3Error(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+
+		return false
+	}
+
+	var nsk *types.NoSuchKey
+	return !errors.As(err, &nsk)
+}
+
+// withRetries runs the S3 operation op on the object at key, retrying it up to
+// the given number of times while it fails with a retryable error. Each retry
+// waits for an exponentially increasing duration, starting at retryWait.
+func (s3s *S3Store) withRetries(op, key string, retries int,
+	fn func() error) error {
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries ||
+			!retryableS3Error(s3s.ctx, err) {
+
+			return err
+		}
+
+		wait := exponentialBackoff(s3s.retryWait, MaxS3RetryWait,
+			attempt)
+		s3s.logger.Warn("S3 operation failed; retrying", "operation",
+			op, "key", key, "attempt", attempt+1, "wait", wait,
+			"error", err)
+
+		select {
+		case <-time.After(wait):
+		case <-s3s.ctx.Done():
+			return err
+		}
+	}
+}
+
 // downloadObject attempts to download an object from the specified S3 bucket
 // and key and saves it to the given destination path on the local filesystem.
+// Failed downloads are retried up to the configured number of times.
 //
 // If the object does not exist (NoSuchKey), it logs the event and returns true
 // with a nil error, indicating that the process should continue with an empty
@@ -71,10 +204,29 @@ func (s3s *S3Store) downloadObject(outPath, key string) (bool, error) {
 		}
 	}()
 
+	var w io.WriterAt = outFile
+	if s3s.bandwidth != nil && s3s.limitDownload {
+		w = &rateLimitedWriterAt{
+			ctx:     s3s.ctx,
+			w:       outFile,
+			limiter: s3s.bandwidth,
+		}
+	}
+
 	downloader := manager.NewDownloader(s3s.client)
-	n, err := downloader.Download(s3s.ctx, outFile, &s3.GetObjectInput{
-		Bucket: &s3s.bucket,
-		Key:    &key,
+	var n int64
+	err = s3s.withRetries("download", key, s3s.maxRetries, func() error {
+		// Discard what a failed attempt may have written.
+		if err := outFile.Truncate(0); err != nil {
+			return fmt.Errorf("truncating local file: %w", err)
+		}
+
+		var err error
+		n, err = downloader.Download(s3s.ctx, w, &s3.GetObjectInput{
+			Bucket: &s3s.bucket,
+			Key:    &key,
+		})
+		return err
 	})
 	if err != nil {
 		var nsk *types.NoSuchKey
@@ -93,17 +245,52 @@ func (s3s *S3Store) downloadObject(outPath, key string) (bool, error) {
 
 // uploadObject uploads the content read from fileReader to the S3Store's bucket
 // at the specified key, setting the Content-Type header to contentType, and
-// adds the provided metadata (if any).
+// adds the provided metadata (if any). The upload is throttled if the bandwidth
+// is limited. Failed uploads are retried up to the configured number of times
+// if fileReader is an io.Seeker, so its content can be read again.
 func (s3s *S3Store) uploadObject(fileReader io.Reader, key,
 	contentType string, metadata map[string]string) error {
 
+	retries := 0
+	var start int64
+	seeker, ok := fileReader.(io.Seeker)
+	if ok {
+		var err error
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			retries = s3s.maxRetries
+		}
+	}
+
+	body := fileReader
+	if s3s.bandwidth != nil {
+		body = &rateLimitedReader{
+			ctx:     s3s.ctx,
+			r:       fileReader,
+			limiter: s3s.bandwidth,
+		}
+	}
+
 	uploader := manager.NewUploader(s3s.client)
-	_, err := uploader.Upload(s3s.ctx, &s3.PutObjectInput{
-		Bucket:      &s3s.bucket,
-		Key:         &key,
-		Body:        fileReader,
-		ContentType: &contentType,
-		Metadata:    metadata,
+	attempts := 0
+	err := s3s.withRetries("upload", key, retries, func() error {
+		// Read the content again from the start on retries.
+		if attempts > 0 {
+			_, err := seeker.Seek(start, io.SeekStart)
+			if err != nil {
+				return fmt.Errorf("rewinding upload: %w", err)
+			}
+		}
+		attempts++
+
+		_, err := uploader.Upload(s3s.ctx, &s3.PutObjectInput{
+			Bucket:      &s3s.bucket,
+			Key:         &key,
+			Body:        body,
+			ContentType: &contentType,
+			Metadata:    metadata,
+		})
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("uploading s3://%s/%s: %w", s3s.bucket, key,
@@ -120,9 +307,10 @@ func (s3s *S3Store) uploadObject(fileReader io.Reader, key,
 // object's metadata. If the object does not exist or the "last-minimized"
 // metadata is missing or empty, it returns the current time.
 func (s3s *S3Store) getLastMinimizedTime() (time.Time, error) {
+	key := s3s.metadataKey()
 	resp, err := s3s.client.HeadObject(s3s.ctx, &s3.HeadObjectInput{
 		Bucket: &s3s.bucket,
-		Key:    &s3s.zipKey,
+		Key:    &key,
 	})
 	if err != nil {
 		var nsk *types.NoSuchKey
@@ -132,7 +320,7 @@ func (s3s *S3Store) getLastMinimizedTime() (time.Time, error) {
 			return time.Now(), nil
 		}
 		return time.Time{}, fmt.Errorf("fetching metadata for key %q: "+
-			"%w", s3s.zipKey, err)
+			"%w", key, err)
 	}
 
 	lastMinStr, ok := resp.Metadata["last-minimized"]
@@ -146,217 +334,269 @@ func (s3s *S3Store) getLastMinimizedTime() (time.Time, error) {
 	lastMinTime, err := time.Parse(time.RFC3339, lastMinStr)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid last-minimized "+
-			"metadata for key %q: %w", s3s.zipKey, err)
+			"metadata for key %q: %w", key, err)
 	}
 
 	return lastMinTime, nil
 }
 
-// unzip extracts the contents of the zip archive specified by zipPath into the
-// destination directory corpusDir.
+// uploadCorpusAndReports streams corpusDir as a corpus archive (or one archive
+// per package in sharded mode), uploads it to S3 unless uploadCorpus is false,
+// and then uploads any generated coverage reports.
 //
-// It preserves file permissions and directory structure.
-func (s3s *S3Store) unzip() error {
-	r, err := zip.OpenReader(s3s.zipPath)
-	if err != nil {
-		return fmt.Errorf("opening zip: %w", err)
+// The corpus and the reports are uploaded independently: both uploads are
+// attempted even if the other fails, so a transient failure of one does not
+// prevent persisting the other. The returned error lists every part that
+// failed.
+func (s3s *S3Store) uploadCorpusAndReports(lastMinTime time.Time,
+	uploadCorpus bool) error {
+
+	var corpusErr error
+	switch {
+	case !uploadCorpus:
+
+	case s3s.quarantine:
+		corpusErr = s3s.uploadQuarantine()
+	case s3s.sharded:
+		corpusErr = s3s.uploadCorpusShards(lastMinTime)
+	default:
+		corpusErr = s3s.uploadArchive(s3s.corpusDir, s3s.corpusKey,
+			lastMinTime)
 	}
-	defer func() {
-		if err := r.Close(); err != nil {
-			s3s.logger.Error("Failed to close file", "error", err)
+	if corpusErr != nil {
+		corpusErr = fmt.Errorf("corpus upload failed: %w", corpusErr)
+	} else if s3s.versioned && !s3s.quarantine && uploadCorpus {
+		// Only archive a corpus that was uploaded entirely.
+		if err := s3s.archiveCorpus(time.Now()); err != nil {
+			corpusErr = fmt.Errorf("corpus archival failed: %w",
+				err)
 		}
-	}()
+	}
 
-	for _, f := range r.File {
-		if err := func(f *zip.File) error {
-			fullPath := filepath.Join(filepath.Dir(s3s.corpusDir),
-				f.Name)
-
-			if f.FileInfo().IsDir() {
-				err := os.MkdirAll(fullPath, f.Mode())
-				if err != nil {
-					return fmt.Errorf("creating dir %q: %w",
-						fullPath, err)
-				}
-				return nil
-			}
+	reportsErr := s3s.uploadReports()
+	if reportsErr != nil {
+		reportsErr = fmt.Errorf("reports upload failed: %w",
+			reportsErr)
+	} else if s3s.logKeep > 0 || s3s.logMaxAge > 0 {
+		if err := s3s.pruneCrashLogs(time.Now()); err != nil {
+			reportsErr = fmt.Errorf("crash log pruning failed: %w",
+				err)
+		}
+	}
 
-			err := EnsureDirExists(filepath.Dir(fullPath))
-			if err != nil {
-				return fmt.Errorf("creating parent dir for "+
-					"%q: %w", fullPath, err)
-			}
+	return combineUploadErrors(s3s.logger.With("s3Bucket", s3s.bucket),
+		corpusErr, reportsErr)
+}
 
-			srcFile, err := f.Open()
-			if err != nil {
-				return fmt.Errorf("opening zip file %q: %w",
-					f.Name, err)
+// uploadArchive streams srcDir as a corpus archive and uploads it to S3 under
+// the given key, recording the last corpus minimization time in its metadata.
+// Since a streamed archive cannot be read again, failed uploads are retried
+// up to the configured number of times by streaming the archive anew.
+func (s3s *S3Store) uploadArchive(srcDir, key string,
+	lastMinTime time.Time) error {
+
+	return s3s.withRetries("upload", key, s3s.maxRetries, func() error {
+		return s3s.streamArchive(srcDir, key, lastMinTime,
+			s3s.uploadObject)
+	})
+}
+
+// uploadCorpusShards uploads the corpus of every configured package as its own
+// archive, in parallel. Packages without any local corpus are skipped.
+func (s3s *S3Store) uploadCorpusShards(lastMinTime time.Time) error {
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentShardTransfers)
+
+	for _, pkg := range s3s.pkgs {
+		srcDir := filepath.Join(s3s.corpusDir, pkg, "testdata")
+		if _, err := os.Stat(srcDir); err != nil {
+			if os.IsNotExist(err) {
+				s3s.logger.Info("No corpus for package; "+
+					"skipping shard upload", "package", pkg)
+				continue
 			}
-			defer func() {
-				if err := srcFile.Close(); err != nil {
-					s3s.logger.Error("Failed to close "+
-						"file", "error", err)
-				}
-			}()
-
-			destFile, err := os.OpenFile(fullPath,
-				os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode())
+			return fmt.Errorf("cannot stat corpus dir %q: %w",
+				srcDir, err)
+		}
+
+		g.Go(func() error {
+			return s3s.uploadArchive(srcDir, s3s.shardKey(pkg),
+				lastMinTime)
+		})
+	}
+
+	return g.Wait()
+}
+
+// downloadCorpusShards downloads and extracts the corpus shard of every
+// configured package, in parallel. The corpus of the packages without a shard
+// is taken from the single corpus archive, if any, so corpora stored before
+// sharding was enabled are not lost. Returns true if neither any shard nor the
+// single corpus archive exists.
+func (s3s *S3Store) downloadCorpusShards() (bool, error) {
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentShardTransfers)
+
+	missing := make([]bool, len(s3s.pkgs))
+	for i, pkg := range s3s.pkgs {
+		g.Go(func() error {
+			empty, err := s3s.downloadArchive(s3s.shardKey(pkg))
 			if err != nil {
-				return fmt.Errorf("creating file %q: %w",
-					fullPath, err)
-			}
-			defer func() {
-				if err := destFile.Close(); err != nil {
-					s3s.logger.Error("Failed to close "+
-						"file", "error", err)
-				}
-			}()
-
-			if _, err := io.Copy(destFile, srcFile); err != nil {
-				return fmt.Errorf("copying to file %q: %w",
-					fullPath, err)
+				return fmt.Errorf("shard for package %q: %w",
+					pkg, err)
 			}
+			missing[i] = empty
 			return nil
-		}(f); err != nil {
-			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return false, err
+	}
+
+	var missingPkgs []string
+	for i, pkg := range s3s.pkgs {
+		if missing[i] {
+			missingPkgs = append(missingPkgs, pkg)
 		}
 	}
+	if len(missingPkgs) == 0 {
+		return false, nil
+	}
 
-	return nil
+	empty, err := s3s.downloadShardFallback(missingPkgs)
+	if err != nil {
+		return false, fmt.Errorf("single corpus archive: %w", err)
+	}
+
+	return empty && len(missingPkgs) == len(s3s.pkgs), nil
 }
 
-// zipDir compresses the contents of the corpusDir into a ZIP archive and writes
-// the archive to the provided io.PipeWriter.
-//
-// It is typically run in a separate goroutine and paired with an io.PipeReader
-// for streaming uploads (to AWS S3).
-func (s3s *S3Store) zipDir(zipWriter *io.PipeWriter) error {
-	zw := zip.NewWriter(zipWriter)
+// downloadShardFallback extracts the single corpus archive into a temporary
+// directory and moves the corpus of the given packages, whose shards do not
+// exist, into corpusDir. The corpus of the other packages is left out, as
+// their shards are more recent. Returns true if the archive does not exist.
+func (s3s *S3Store) downloadShardFallback(pkgs []string) (bool, error) {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(s3s.corpusDir),
+		"corpus-fallback-*")
+	if err != nil {
+		return false, fmt.Errorf("creating temp dir: %w", err)
+	}
 	defer func() {
-		if err := zw.Close(); err != nil {
-			s3s.logger.Error("Failed to close zip writer", "error",
-				err)
+		if err := os.RemoveAll(tmpDir); err != nil {
+			s3s.logger.Error("Failed to remove dir", "error", err)
 		}
 	}()
 
-	baseDir := filepath.Clean(s3s.corpusDir)
-
-	err := filepath.Walk(baseDir, func(path string, info os.FileInfo,
-		walkErr error) error {
+	// Archive entries are rooted at the corpus directory's name, so extract
+	// the archive as the corpus of a store rooted in the temporary
+	// directory.
+	fallback := *s3s
+	fallback.corpusDir = filepath.Join(tmpDir,
+		filepath.Base(s3s.corpusDir))
 
-		if walkErr != nil {
-			return walkErr
-		}
+	empty, err := fallback.downloadArchive(s3s.corpusKey)
+	if err != nil || empty {
+		return empty, err
+	}
 
-		relPath, err := filepath.Rel(filepath.Dir(baseDir), path)
+	for _, pkg := range pkgs {
+		moved, err := moveShardCorpus(fallback.corpusDir, s3s.corpusDir,
+			pkg)
 		if err != nil {
-			return err
+			return false, err
 		}
-
-		relPath = filepath.ToSlash(relPath)
-
-		if info.IsDir() {
-			header := &zip.FileHeader{
-				Name:   relPath + "/",
-				Method: zip.Deflate,
-			}
-			header.SetMode(info.Mode())
-			_, err := zw.CreateHeader(header)
-			return err
+		if moved {
+			s3s.logger.Info("Using single corpus archive for "+
+				"package without shard", "package", pkg,
+				"key", s3s.corpusKey)
 		}
+	}
 
-		file, err := os.Open(path)
-		if err != nil {
-			return fmt.Errorf("opening file %q: %w", path, err)
-		}
-		defer func() {
-			if err := file.Close(); err != nil {
-				s3s.logger.Error("Failed to close file",
-					"error", err)
-			}
-		}()
-
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-		header.Name = relPath
-		header.Method = zip.Deflate
-		header.SetMode(info.Mode())
+	return false, nil
+}
 
-		writer, err := zw.CreateHeader(header)
-		if err != nil {
-			return err
+// moveShardCorpus moves the corpus of the given package, i.e. its testdata
+// directory, from the corpus directory srcDir to dstDir, replacing any corpus
+// of the package there. Returns false if srcDir holds no corpus for the
+// package.
+func moveShardCorpus(srcDir, dstDir, pkg string) (bool, error) {
+	src := filepath.Join(srcDir, pkg, "testdata")
+	if _, err := os.Stat(src); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
 		}
+		return false, fmt.Errorf("cannot stat corpus dir %q: %w", src,
+			err)
+	}
 
-		_, err = io.Copy(writer, file)
-		return err
-	})
-
-	if err != nil {
-		return err
+	dst := filepath.Join(dstDir, pkg, "testdata")
+	if err := os.RemoveAll(dst); err != nil {
+		return false, fmt.Errorf("removing %q: %w", dst, err)
+	}
+	if err := EnsureDirExists(filepath.Dir(dst)); err != nil {
+		return false, err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return false, fmt.Errorf("moving %q to %q: %w", src, dst, err)
 	}
 
-	return nil
+	return true, nil
 }
 
-// uploadCorpusAndReports streams corpusDir as a ZIP archive, uploads it to S3,
-// and then uploads any generated coverage reports.
-func (s3s *S3Store) uploadCorpusAndReports(lastMinTime time.Time) error {
-	// Stream the ZIP archive in a goroutine.
-	pr, pw := io.Pipe()
-	go func() {
-		err := s3s.zipDir(pw)
+// downloadArchive downloads the corpus archive stored under key and extracts it
+// into the corpus, falling back to the archive stored in the other format.
+// Returns true if neither object exists.
+func (s3s *S3Store) downloadArchive(key string) (bool, error) {
+	return s3s.fetchArchive(key, s3s.downloadObject)
+}
+
+// downloadCorpus downloads the corpus archive (or the per-package archives in
+// sharded mode) from S3 and extracts it into the local corpusDir. Returns true
+// if the corpus is empty.
+func (s3s *S3Store) downloadCorpus() (bool, error) {
+	if s3s.sharded {
+		empty, err := s3s.downloadCorpusShards()
 		if err != nil {
-			s3s.logger.Error("Failed to stream zip", "error", err)
+			return false, err
 		}
-		pw.CloseWithError(err)
-	}()
 
-	// Now upload the zipped corpus with updated metadata.
-	err := s3s.uploadObject(pr, s3s.zipKey, "application/zip",
-		map[string]string{
-			"last-minimized": lastMinTime.Format(time.RFC3339),
-		})
-	if err != nil {
-		return fmt.Errorf("corpus upload failed: %w", err)
-	}
+		if empty {
+			s3s.logger.Info("No corpus shards found. Starting "+
+				"with empty corpus.", "s3Bucket", s3s.bucket,
+				"prefix", s3s.shardPrefix)
+		}
 
-	s3s.logger.Info("Successfully zipped and uploaded corpus", "s3Bucket",
-		s3s.bucket, "key", s3s.zipKey)
+		return empty, nil
+	}
 
-	if err := s3s.uploadReports(); err != nil {
-		return fmt.Errorf("reports upload failed: %w", err)
+	empty, err := s3s.downloadArchive(s3s.corpusKey)
+	if err != nil {
+		return false, err
 	}
 
-	s3s.logger.Info("Successfully uploaded reports", "s3Bucket", s3s.bucket)
+	if empty {
+		s3s.logger.Info("Corpus object not found. Starting with empty "+
+			"corpus.", "s3Bucket", s3s.bucket, "key", s3s.corpusKey)
+	}
 
-	return nil
+	return empty, nil
 }
 
-// downloadCorpusAndReports downloads the ZIP archive from S3 and unzips it into
-// the local corpusDir (unless the archive is empty), and then downloads any
-// associated reports.
+// downloadCorpusAndReports downloads the corpus from S3, merges any corpus
+// already present locally into it according to the merge strategy, and then
+// downloads any associated reports (unless the downloaded corpus is empty).
 func (s3s *S3Store) downloadCorpusAndReports() error {
-	empty, err := s3s.downloadObject(s3s.zipPath, s3s.zipKey)
+	empty, err := s3s.downloadMergedCorpus(s3s.ctx, s3s.srcDir,
+		s3s.mergeStrategy, s3s.downloadCorpus)
 	if err != nil {
-		return fmt.Errorf("corpus download failed: %w", err)
+		return err
 	}
 
 	if empty {
-		s3s.logger.Info("Corpus object not found. Starting with empty "+
-			"corpus.", "s3Bucket", s3s.bucket, "key", s3s.zipKey)
-
 		return nil
 	}
 
-	if err := s3s.unzip(); err != nil {
-		return fmt.Errorf("corpus unzip failed: %w", err)
-	}
-
-	s3s.logger.Info("Successfully downloaded and unzipped corpus",
-		"s3Bucket", s3s.bucket, "key", s3s.zipKey)
-
 	if err := s3s.downloadReports(); err != nil {
 		return fmt.Errorf("reports download failed: %w", err)
 	}
@@ -408,11 +648,210 @@ func (s3s *S3Store) downloadReports() error {
 	return nil
 }
 
-// uploadReports walks the local reportDir, uploading each file to S3.
-// It preserves the directory structure by using each file's path relative to
-// reportDir as the S3 key.
+// uploadReports uploads the files of the local reportDir to S3, preserving the
+// directory structure by using each file's path relative to reportDir as the
+// S3 key. Files whose content is unchanged from the object already stored
+// under their key, such as historical coverage reports, are skipped.
 func (s3s *S3Store) uploadReports() error {
-	return filepath.Walk(s3s.reportDir, func(path string, info os.FileInfo,
+	etags, err := s3s.listObjectETags()
+	if err != nil {
+		return err
+	}
+
+	keys, skipped, err := changedReports(s3s.reportDir, etags)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := s3s.uploadReport(key); err != nil {
+			return err
+		}
+	}
+
+	s3s.logger.Info("Uploaded reports", "s3Bucket", s3s.bucket,
+		"uploaded", len(keys), "unchanged", skipped)
+
+	return nil
+}
+
+// uploadReport uploads the report file stored under the given key, relative to
+// reportDir, with the appropriate content type.
+func (s3s *S3Store) uploadReport(key string) error {
+	path := filepath.Join(s3s.reportDir, filepath.FromSlash(key))
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open report %q: %w", path, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			s3s.logger.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	contentType := detectContentType(path)
+	err = s3s.uploadObject(file, key, contentType, nil)
+	if err != nil {
+		return fmt.Errorf("upload report %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// listObjectETags returns the ETags of all objects in the bucket, keyed by
+// their S3 object key.
+func (s3s *S3Store) listObjectETags() (map[string]string, error) {
+	etags := make(map[string]string)
+	paginator := s3.NewListObjectsV2Paginator(s3s.client,
+		&s3.ListObjectsV2Input{Bucket: &s3s.bucket})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s3s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w",
+				err)
+		}
+
+		for _, item := range page.Contents {
+			if item.Key != nil && item.ETag != nil {
+				etags[*item.Key] = *item.ETag
+			}
+		}
+	}
+
+	return etags, nil
+}
+
+// crashLog describes the logs stored in S3 for a single crash of a fuzz target.
+type crashLog struct {
+	// target is the "<pkg>/<target>" the crash belongs to.
+	target string
+
+	// keys are the S3 object keys of the crash's log files.
+	keys []string
+
+	// modified is the time the most recent log file was last modified.
+	modified time.Time
+}
+
+// groupCrashLogs groups the given objects stored under CrashLogPrefix by the
+// crash they belong to, i.e. by their "<pkg>/<target>/<crash-hash>/" prefix.
+// Objects not matching that layout are ignored.
+func groupCrashLogs(objects []types.Object) []*crashLog {
+	byDir := make(map[string]*crashLog)
+	var logs []*crashLog
+	for _, obj := range objects {
+		if obj.Key == nil {
+			continue
+		}
+
+		rel, ok := strings.CutPrefix(*obj.Key, CrashLogPrefix)
+		if !ok {
+			continue
+		}
+
+		dir := path.Dir(rel)
+		target := path.Dir(dir)
+		if target == "." {
+			continue
+		}
+
+		crash, ok := byDir[dir]
+		if !ok {
+			crash = &crashLog{target: target}
+			byDir[dir] = crash
+			logs = append(logs, crash)
+		}
+
+		crash.keys = append(crash.keys, *obj.Key)
+		modified := obj.LastModified
+		if modified != nil && modified.After(crash.modified) {
+			crash.modified = *modified
+		}
+	}
+
+	return logs
+}
+
+// expiredCrashLogs returns the S3 object keys of the crash logs exceeding the
+// retention: beyond the keep most recent crashes of their target if keep is
+// positive, or modified more than maxAge before now if maxAge is positive.
+func expiredCrashLogs(logs []*crashLog, keep int, maxAge time.Duration,
+	now time.Time) []string {
+
+	byTarget := make(map[string][]*crashLog)
+	for _, crash := range logs {
+		byTarget[crash.target] = append(byTarget[crash.target], crash)
+	}
+
+	var expired []string
+	for _, targetLogs := range byTarget {
+		// Sort the crashes of the target, most recent first.
+		sort.Slice(targetLogs, func(i, j int) bool {
+			return targetLogs[i].modified.After(
+				targetLogs[j].modified)
+		})
+
+		for i, crash := range targetLogs {
+			tooMany := keep > 0 && i >= keep
+			tooOld := maxAge > 0 && now.Sub(crash.modified) > maxAge
+			if tooMany || tooOld {
+				expired = append(expired, crash.keys...)
+			}
+		}
+	}
+
+	sort.Strings(expired)
+	return expired
+}
+
+// pruneCrashLogs deletes the crash logs stored under CrashLogPrefix that
+// exceed the configured failure log retention as of now.
+func (s3s *S3Store) pruneCrashLogs(now time.Time) error {
+	prefix := CrashLogPrefix
+	paginator := s3.NewListObjectsV2Paginator(s3s.client,
+		&s3.ListObjectsV2Input{Bucket: &s3s.bucket, Prefix: &prefix})
+
+	var objects []types.Object
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s3s.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		objects = append(objects, page.Contents...)
+	}
+
+	expired := expiredCrashLogs(groupCrashLogs(objects), s3s.logKeep,
+		s3s.logMaxAge, now)
+	for _, key := range expired {
+		input := &s3.DeleteObjectInput{Bucket: &s3s.bucket, Key: &key}
+		_, err := s3s.client.DeleteObject(s3s.ctx, input)
+		if err != nil {
+			return fmt.Errorf("deleting s3://%s/%s: %w",
+				s3s.bucket, key, err)
+		}
+	}
+
+	s3s.logger.Info("Pruned crash logs", "s3Bucket", s3s.bucket,
+		"deleted", len(expired))
+
+	return nil
+}
+
+// changedReports walks reportDir and returns the S3 keys of the report files
+// that must be uploaded, i.e. whose content differs from the object with the
+// given ETag stored under their key, or that are not stored yet. Also returns
+// the number of unchanged files skipped.
+//
+// The ETag of an object uploaded in a single part without KMS encryption is the
+// MD5 digest of its content. Any other ETag never matches, so the file is
+// uploaded, which is always safe.
+func changedReports(reportDir string, etags map[string]string) ([]string,
+	int, error) {
+
+	var keys []string
+	skipped := 0
+	err := filepath.Walk(reportDir, func(path string, info os.FileInfo,
 		err error) error {
 
 		if err != nil || info.IsDir() {
@@ -420,41 +859,242 @@ func (s3s *S3Store) uploadReports() error {
 		}
 
 		// Compute the key by making the path relative to reportDir
-		relPath, err := filepath.Rel(s3s.reportDir, path)
+		relPath, err := filepath.Rel(reportDir, path)
 		if err != nil {
 			return fmt.Errorf("determine relative path: %w", err)
 		}
 		key := filepath.ToSlash(relPath)
 
-		file, err := os.Open(path)
-		if err != nil {
-			return fmt.Errorf("open report %q: %w", path, err)
-		}
-		defer func() {
-			if err := file.Close(); err != nil {
-				s3s.logger.Error("Failed to close file",
-					"error", err)
+		if etag, ok := etags[key]; ok {
+			sum, err := fileMD5(path)
+			if err != nil {
+				return err
+			}
+			if strings.Trim(etag, `"`) == sum {
+				skipped++
+				return nil
 			}
-		}()
-
-		// Upload the file to S3 with the appropriate content type
-		contentType := detectContentType(path)
-		err = s3s.uploadObject(file, key, contentType, nil)
-		if err != nil {
-			return fmt.Errorf("upload report %q: %w", key, err)
 		}
 
+		keys = append(keys, key)
 		return nil
 	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return keys, skipped, nil
+}
+
+// fileMD5 returns the hex-encoded MD5 digest of the content of the file at
+// path.
+func fileMD5(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open %q: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %q: %w", path, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // detectContentType returns the MIME type for filename based on its extension.
 // If the extension is unknown, it defaults to application/octet-stream.
 func detectContentType(filename string) string {
 	ext := filepath.Ext(filename)
+
+	// CSV is missing from Go's builtin MIME types, and may be missing from
+	// the system's too.
+	if ext == ".csv" {
+		return "text/csv; charset=utf-8"
+	}
+
 	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
 		return mimeType
 	}
 	// Fallback to a generic binary stream
 	return "application/octet-stream"
 }
+
+// objectExists reports whether an object is stored under key in the bucket.
+func (s3s *S3Store) objectExists(key string) (bool, error) {
+	_, err := s3s.client.HeadObject(s3s.ctx, &s3.HeadObjectInput{
+		Bucket: &s3s.bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		var nf *types.NotFound
+		if errors.As(err, &nsk) || errors.As(err, &nf) {
+			return false, nil
+		}
+		return false, fmt.Errorf("fetching metadata for key %q: %w",
+			key, err)
+	}
+
+	return true, nil
+}
+
+// copyObject copies the object stored under srcKey to dstKey within the
+// bucket, preserving its metadata.
+func (s3s *S3Store) copyObject(srcKey, dstKey string) error {
+	source := fmt.Sprintf("%s/%s", s3s.bucket, srcKey)
+	_, err := s3s.client.CopyObject(s3s.ctx, &s3.CopyObjectInput{
+		Bucket:     &s3s.bucket,
+		CopySource: &source,
+		Key:        &dstKey,
+	})
+	if err != nil {
+		return fmt.Errorf("copying s3://%s/%s to %s: %w", s3s.bucket,
+			srcKey, dstKey, err)
+	}
+
+	s3s.logger.Info("Copied object", "s3Bucket", s3s.bucket, "srcKey",
+		srcKey, "dstKey", dstKey)
+
+	return nil
+}
+
+// archiveCorpus copies the canonical corpus objects to the corpus version
+// named after the given date. Archiving the corpus more than once a day
+// overwrites that day's version.
+func (s3s *S3Store) archiveCorpus(now time.Time) error {
+	version := now.UTC().Format(CorpusVersionLayout)
+	for _, key := range s3s.corpusKeys() {
+		// Shards of packages without any corpus are never uploaded.
+		exists, err := s3s.objectExists(key)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		err = s3s.copyObject(key, s3s.versionKey(version, key))
+		if err != nil {
+			return err
+		}
+	}
+
+	s3s.logger.Info("Archived corpus version", "s3Bucket", s3s.bucket,
+		"version", version)
+
+	return nil
+}
+
+// listCorpusVersions returns the archived corpus versions, oldest first.
+func (s3s *S3Store) listCorpusVersions() ([]string, error) {
+	delimiter := "/"
+	paginator := s3.NewListObjectsV2Paginator(s3s.client,
+		&s3.ListObjectsV2Input{
+			Bucket:    &s3s.bucket,
+			Prefix:    &s3s.versionPrefix,
+			Delimiter: &delimiter,
+		})
+
+	var versions []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s3s.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w",
+				err)
+		}
+
+		for _, prefix := range page.CommonPrefixes {
+			version := strings.TrimSuffix(strings.TrimPrefix(
+				*prefix.Prefix, s3s.versionPrefix), delimiter)
+			versions = append(versions, version)
+		}
+	}
+
+	// Dates formatted according to CorpusVersionLayout sort
+	// chronologically.
+	sort.Strings(versions)
+
+	return versions, nil
+}
+
+// restoreCorpus promotes the given archived corpus version, or the most recent
+// one if version is LatestCorpusVersion, to the canonical corpus. Canonical
+// shards absent from the version are deleted, so the restored corpus matches
+// the version exactly. Returns the restored version.
+func (s3s *S3Store) restoreCorpus(version string) (string, error) {
+	if version == LatestCorpusVersion {
+		versions, err := s3s.listCorpusVersions()
+		if err != nil {
+			return "", err
+		}
+		if len(versions) == 0 {
+			return "", errors.New("no archived corpus versions " +
+				"found")
+		}
+		version = versions[len(versions)-1]
+	}
+
+	// Determine which corpus objects the version holds before modifying
+	// the canonical corpus, so an unknown version leaves it untouched.
+	keys := s3s.corpusKeys()
+	archived := make([]bool, len(keys))
+	found := false
+	for i, key := range keys {
+		exists, err := s3s.objectExists(s3s.versionKey(version, key))
+		if err != nil {
+			return "", err
+		}
+		archived[i] = exists
+		found = found || exists
+	}
+	if !found {
+		return "", fmt.Errorf("corpus version %q not found", version)
+	}
+
+	for i, key := range keys {
+		if archived[i] {
+			err := s3s.copyObject(s3s.versionKey(version, key), key)
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		input := &s3.DeleteObjectInput{Bucket: &s3s.bucket, Key: &key}
+		_, err := s3s.client.DeleteObject(s3s.ctx, input)
+		if err != nil {
+			return "", fmt.Errorf("deleting s3://%s/%s: %w",
+				s3s.bucket, key, err)
+		}
+	}
+
+	return version, nil
+}
+
+// runRestoreCorpus restores the corpus version selected by the restore-corpus
+// subcommand.
+func runRestoreCorpus(ctx context.Context, logger *slog.Logger,
+	cfg *Config) error {
+
+	if cfg.Project.StorageBackend != StorageBackendS3 {
+		return errors.New("restoring corpus versions is only " +
+			"supported with the s3 storage backend")
+	}
+
+	s3s, err := NewS3Store(ctx, logger, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 store: %w", err)
+	}
+
+	version, err := s3s.restoreCorpus(cfg.RestoreCorpus.Version)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Restored corpus version", "s3Bucket", s3s.bucket,
+		"version", version)
+
+	return nil
+}
@@ -1,106 +1,420 @@
 package main
 
 import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 )
 
-// TestZipAndUnzip validates that a directory can be compressed to a ZIP archive
-// using zipDir and subsequently decompressed using unzip to reproduce the
-// original directory structure and file contents.
-func TestZipAndUnZipDir(t *testing.T) {
-	// Create source directory with sample files.
-	sourceDir := filepath.Join(t.TempDir(), "test_corpus")
-	assert.NoError(t, os.Mkdir(sourceDir, 0o755))
-
-	fileContents := map[string][]byte{
-		"file1.txt": []byte("testing unzip"),
-		"file2.txt": []byte("testing zipDir"),
+// TestChangedReports verifies that changedReports only selects report files
+// that are not stored in S3 yet or whose content differs from the stored
+// object, based on the objects' ETags.
+func TestChangedReports(t *testing.T) {
+	reportDir := t.TempDir()
+	files := map[string]string{
+		"index.html":                           "<html>new</html>",
+		"targets/parser/FuzzEvalExpr.html":     "<html>history</html>",
+		"targets/parser/FuzzEvalExpr.json":     `{"history": []}`,
+		"targets/parser/2025-07-12.html":       "<html>report</html>",
+		"targets/parser/FuzzParseComplex.html": "<html>unknown</html>",
 	}
-	for name, data := range fileContents {
-		path := filepath.Join(sourceDir, name)
-		assert.NoError(t, os.WriteFile(path, data, 0o644))
+	for name, content := range files {
+		path := filepath.Join(reportDir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
 	}
 
-	// Initialize S3Store for zipping.
-	zipStore := &S3Store{
-		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
-		corpusDir: sourceDir,
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
 	}
+	etags := map[string]string{
+		// Changed since the last upload.
+		"index.html": `"` + md5Hex("<html>old</html>") + `"`,
 
-	// Stream ZIP archive into a pipe.
-	pr, pw := io.Pipe()
-	go func() {
-		err := zipStore.zipDir(pw)
-		pw.CloseWithError(err)
-	}()
+		// Unchanged since the last upload.
+		"targets/parser/FuzzEvalExpr.html": `"` +
+			md5Hex("<html>history</html>") + `"`,
+		"targets/parser/FuzzEvalExpr.json": `"` +
+			md5Hex(`{"history": []}`) + `"`,
+
+		// Uploaded in multiple parts, so it cannot be compared.
+		"targets/parser/2025-07-12.html": `"` +
+			md5Hex("<html>report</html>") + `-2"`,
+
+		// Stored objects without a local file are ignored.
+		"targets/parser/FuzzRemoved.html": `"0123"`,
+	}
+
+	keys, skipped, err := changedReports(reportDir, etags)
+	assert.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, []string{
+		"index.html",
+		"targets/parser/2025-07-12.html",
+		"targets/parser/FuzzParseComplex.html",
+	}, keys)
+	assert.Equal(t, 2, skipped)
+}
+
+// TestExpiredCrashLogs verifies that crash logs are grouped per crash and that
+// only the crashes beyond the retained count or age of their target expire.
+func TestExpiredCrashLogs(t *testing.T) {
+	now := time.Date(2025, 7, 12, 0, 0, 0, 0, time.UTC)
+	object := func(key string, age time.Duration) types.Object {
+		modified := now.Add(-age)
+		return types.Object{Key: &key, LastModified: &modified}
+	}
+
+	logs := groupCrashLogs([]types.Object{
+		object("crash-logs/pkg/a/FuzzA/h1/error.log", 72*time.Hour),
+		object("crash-logs/pkg/a/FuzzA/h1/failing-input.txt",
+			72*time.Hour),
+		object("crash-logs/pkg/a/FuzzA/h2/error.log", 48*time.Hour),
+		object("crash-logs/pkg/a/FuzzA/h3/error.log", 24*time.Hour),
+		object("crash-logs/pkg/b/FuzzB/h4/error.log", 96*time.Hour),
+		object("crash-logs/stray.log", 96*time.Hour),
+	})
+	assert.Len(t, logs, 4)
+
+	tests := []struct {
+		name     string
+		keep     int
+		maxAge   time.Duration
+		expected []string
+	}{
+		{
+			name: "no retention",
+		},
+		{
+			name: "keep most recent per target",
+			keep: 1,
+			expected: []string{
+				"crash-logs/pkg/a/FuzzA/h1/error.log",
+				"crash-logs/pkg/a/FuzzA/h1/failing-input.txt",
+				"crash-logs/pkg/a/FuzzA/h2/error.log",
+			},
+		},
+		{
+			name:   "maximum age",
+			maxAge: 60 * time.Hour,
+			expected: []string{
+				"crash-logs/pkg/a/FuzzA/h1/error.log",
+				"crash-logs/pkg/a/FuzzA/h1/failing-input.txt",
+				"crash-logs/pkg/b/FuzzB/h4/error.log",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expired := expiredCrashLogs(logs, tc.keep, tc.maxAge,
+				now)
+			assert.Equal(t, tc.expected, expired)
+		})
+	}
+}
+
+// TestUploadCorpusAndReportsIndependently verifies that the corpus and the
+// reports are both uploaded even if uploading the other fails, and that the
+// returned error names the part that failed.
+func TestUploadCorpusAndReportsIndependently(t *testing.T) {
+	tests := []struct {
+		name      string
+		failKey   string
+		uploaded  string
+		expectErr string
+	}{
+		{
+			name:      "corpus failure",
+			failKey:   "repo_corpus.zip",
+			uploaded:  "index.html",
+			expectErr: "corpus upload failed",
+		},
+		{
+			name:      "reports failure",
+			failKey:   "index.html",
+			uploaded:  "repo_corpus.zip",
+			expectErr: "reports upload failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var uploaded []string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				key := path.Base(r.URL.Path)
+				switch {
+				case r.Method == http.MethodGet:
+					// An empty bucket listing.
+					fmt.Fprint(w, "<ListBucketResult>"+
+						"</ListBucketResult>")
+
+				case key == tc.failKey:
+					w.WriteHeader(http.StatusForbidden)
+
+				default:
+					_, _ = io.Copy(io.Discard, r.Body)
+					mu.Lock()
+					uploaded = append(uploaded, key)
+					mu.Unlock()
+				}
+			}
+			server := httptest.NewServer(http.HandlerFunc(handler))
+			defer server.Close()
+
+			corpusDir := filepath.Join(t.TempDir(), "repo_corpus")
+			reportDir := t.TempDir()
+			writeFiles(t, corpusDir, map[string]string{
+				"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+			})
+			writeFiles(t, reportDir, map[string]string{
+				"index.html": "<html></html>",
+			})
+
+			client := s3.New(s3.Options{
+				BaseEndpoint:     &server.URL,
+				UsePathStyle:     true,
+				Region:           "us-east-1",
+				Credentials:      aws.AnonymousCredentials{},
+				RetryMaxAttempts: 1,
+			})
+			s3s := &S3Store{
+				corpusArchiver: corpusArchiver{
+					logger: slog.New(
+						slog.DiscardHandler),
+					corpusDir:     corpusDir,
+					archiveFormat: ArchiveFormatZip,
+				},
+				ctx:       context.Background(),
+				client:    client,
+				bucket:    "bucket",
+				corpusKey: "repo_corpus.zip",
+				reportDir: reportDir,
+			}
+
+			err := s3s.uploadCorpusAndReports(time.Now(), true)
+			assert.ErrorContains(t, err, tc.expectErr)
+			assert.Equal(t, []string{tc.uploaded}, uploaded)
+		})
+	}
+}
+
+// TestDownloadCorpusShardsFallback verifies that, in sharded mode, the corpus
+// of the packages without a shard is taken from the single corpus archive,
+// while the packages with a shard only get the shard's corpus.
+func TestDownloadCorpusShardsFallback(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	// Archive a single corpus holding both packages, and a more recent
+	// shard of the first one.
+	singleDir := filepath.Join(t.TempDir(), "repo_corpus")
+	writeFiles(t, singleDir, map[string]string{
+		"pkg1/testdata/fuzz/FuzzFoo/old": "old pkg1 input",
+		"pkg2/testdata/fuzz/FuzzBar/old": "old pkg2 input",
+	})
+	shardDir := filepath.Join(t.TempDir(), "repo_corpus")
+	writeFiles(t, shardDir, map[string]string{
+		"pkg1/testdata/fuzz/FuzzFoo/new": "new pkg1 input",
+	})
 
-	// Write the archive to a separate temporary workspace.
 	archiveDir := t.TempDir()
-	zipPath := filepath.Join(archiveDir, "out.zip")
+	singlePath := filepath.Join(archiveDir, "single.zip")
+	writeArchiveToFile(t, &corpusArchiver{logger: logger,
+		corpusDir: singleDir},
+		singlePath)
+	shardPath := filepath.Join(archiveDir, "shard.zip")
+	writeArchiveToFile(t, &corpusArchiver{logger: logger,
+		corpusDir: shardDir},
+		shardPath)
 
-	zipFile, err := os.Create(zipPath)
+	newStore := func(t *testing.T, objects map[string]string) *S3Store {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+			objectPath, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "<Error><Code>NoSuchKey</Code>"+
+					"</Error>")
+				return
+			}
+			http.ServeFile(w, r, objectPath)
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		t.Cleanup(server.Close)
+
+		client := s3.New(s3.Options{
+			BaseEndpoint:     &server.URL,
+			UsePathStyle:     true,
+			Region:           "us-east-1",
+			Credentials:      aws.AnonymousCredentials{},
+			RetryMaxAttempts: 1,
+		})
+
+		corpusDir := filepath.Join(t.TempDir(), "repo_corpus")
+
+		return &S3Store{
+			corpusArchiver: corpusArchiver{
+				logger:        logger,
+				corpusDir:     corpusDir,
+				archiveFormat: ArchiveFormatZip,
+			},
+			ctx:         context.Background(),
+			client:      client,
+			bucket:      "bucket",
+			corpusKey:   "repo_corpus.zip",
+			sharded:     true,
+			shardPrefix: "repo_corpus/",
+			pkgs:        []string{"pkg1", "pkg2"},
+		}
+	}
+
+	s3s := newStore(t, map[string]string{
+		"repo_corpus.zip":      singlePath,
+		"repo_corpus/pkg1.zip": shardPath,
+	})
+	empty, err := s3s.downloadCorpusShards()
 	assert.NoError(t, err)
+	assert.False(t, empty)
 
-	_, err = io.Copy(zipFile, pr)
+	inputs, err := snapshotCorpus(s3s.corpusDir)
 	assert.NoError(t, err)
-	assert.NoError(t, zipFile.Close())
+	assert.Equal(t, map[string]bool{
+		"pkg1/testdata/fuzz/FuzzFoo/new": true,
+		"pkg2/testdata/fuzz/FuzzBar/old": true,
+	}, inputs)
 
-	// Initialize S3Store for unzipping.
-	unzipStore := &S3Store{
-		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
-		corpusDir: filepath.Join(archiveDir, "test_corpus"),
-		zipPath:   zipPath,
+	// Without any shard nor single archive, the corpus is empty.
+	s3s = newStore(t, nil)
+	empty, err = s3s.downloadCorpusShards()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+}
+
+// TestS3StoreRetries verifies that failed downloads and uploads are retried up
+// to the configured number of times, that uploads are retried with their whole
+// content, and that missing objects and canceled contexts are not retried.
+func TestS3StoreRetries(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	uploaded := make(map[string]string)
+	failures := map[string]int{
+		"flaky.bin":       2,
+		"broken.bin":      100,
+		"upload.txt":      1,
+		"repo_corpus.zip": 1,
 	}
 
-	// Perform unzip operation.
-	assert.NoError(t, unzipStore.unzip())
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		body, _ := io.ReadAll(r.Body)
 
-	// Validate directory entries.
-	parent := filepath.Dir(unzipStore.corpusDir)
-	entries, err := os.ReadDir(parent)
-	assert.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		requests[key]++
+
+		switch {
+		case requests[key] <= failures[key]:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<Error><Code>SlowDown</Code></Error>")
+
+		case r.Method == http.MethodPut:
+			uploaded[key] = string(body)
+
+		case key == "flaky.bin":
+			http.ServeContent(w, r, key, time.Time{},
+				strings.NewReader("content"))
 
-	// Expect exactly the ZIP file and the extracted directory
-	assert.Len(t, entries, 2)
-	for _, e := range entries {
-		switch e.Name() {
-		case "out.zip":
-			assert.False(t, e.IsDir(), "out.zip should not be a "+
-				"directory")
-		case "test_corpus":
-			assert.True(t, e.IsDir(), "test_corpus should be a "+
-				"directory")
 		default:
-			assert.Fail(t, "unexpected entry %q in %s", e.Name(),
-				parent)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
 		}
 	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	client := s3.New(s3.Options{
+		BaseEndpoint:     &server.URL,
+		UsePathStyle:     true,
+		Region:           "us-east-1",
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	corpusDir := filepath.Join(t.TempDir(), "repo_corpus")
+	writeFiles(t, corpusDir, map[string]string{
+		"pkg/testdata/fuzz/FuzzFoo/seed": "input",
+	})
+	s3s := &S3Store{
+		corpusArchiver: corpusArchiver{
+			logger:        slog.New(slog.DiscardHandler),
+			corpusDir:     corpusDir,
+			archiveFormat: ArchiveFormatZip,
+		},
+		ctx:        context.Background(),
+		client:     client,
+		bucket:     "bucket",
+		maxRetries: 3,
+		retryWait:  time.Millisecond,
+	}
+	outPath := filepath.Join(t.TempDir(), "object")
 
-	// Validate contents of the extracted directory.
-	files, err := os.ReadDir(unzipStore.corpusDir)
+	// A download failing transiently is retried until it succeeds.
+	empty, err := s3s.downloadObject(outPath, "flaky.bin")
+	assert.NoError(t, err)
+	assert.False(t, empty)
+	content, err := os.ReadFile(outPath)
 	assert.NoError(t, err)
-	assert.Len(t, files, len(fileContents))
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, 3, requests["flaky.bin"])
 
-	var fileNames []string
-	for _, f := range files {
-		fileNames = append(fileNames, f.Name())
-		assert.False(t, f.IsDir(), "%s should be a file", f.Name())
-	}
-	sort.Strings(fileNames)
-	assert.Equal(t, []string{"file1.txt", "file2.txt"}, fileNames)
-
-	// Verify file content.
-	for name, expected := range fileContents {
-		path := filepath.Join(unzipStore.corpusDir, name)
-		actual, err := os.ReadFile(path)
-		assert.NoError(t, err)
-		assert.Equal(t, expected, actual)
-	}
+	// A download failing persistently is given up after the retries.
+	_, err = s3s.downloadObject(outPath, "broken.bin")
+	assert.ErrorContains(t, err, "downloading s3://bucket/broken.bin")
+	assert.Equal(t, 4, requests["broken.bin"])
+
+	// A missing object is not an error, nor retried.
+	empty, err = s3s.downloadObject(outPath, "missing.bin")
+	assert.NoError(t, err)
+	assert.True(t, empty)
+	assert.Equal(t, 1, requests["missing.bin"])
+
+	// A failed upload is retried with its whole content.
+	err = s3s.uploadObject(strings.NewReader("report"), "upload.txt",
+		"text/plain", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "report", uploaded["upload.txt"])
+	assert.Equal(t, 2, requests["upload.txt"])
+
+	// A failed archive upload is retried by streaming the archive anew.
+	err = s3s.uploadArchive(corpusDir, "repo_corpus.zip", time.Now())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, uploaded["repo_corpus.zip"])
+	assert.Equal(t, 2, requests["repo_corpus.zip"])
+
+	// Nothing is retried once the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s3s.ctx = ctx
+	s3s.retryWait = time.Hour
+	_, err = s3s.downloadObject(outPath, "broken.bin")
+	assert.ErrorIs(t, err, context.Canceled)
 }
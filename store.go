@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CorpusStore is a storage backend holding the corpus and the coverage reports
+// of the project between fuzzing cycles, and the full crash logs linked from
+// crash issues.
+type CorpusStore interface {
+	// downloadCorpusAndReports downloads the corpus, merges any corpus
+	// already present locally into it, and downloads the JSON reports.
+	downloadCorpusAndReports() error
+
+	// uploadCorpusAndReports uploads the local corpus, recording the
+	// given last corpus minimization time, unless uploadCorpus is false,
+	// and the local reports.
+	uploadCorpusAndReports(lastMinTime time.Time, uploadCorpus bool) error
+
+	// getLastMinimizedTime returns the last corpus minimization time
+	// recorded with the stored corpus, or the current time if none is.
+	getLastMinimizedTime() (time.Time, error)
+
+	// snapshotCorpusBaseline records the inputs of the local corpus, so
+	// only the inputs added afterwards are quarantined.
+	snapshotCorpusBaseline() error
+
+	// uploadObject uploads the content read from r under key, with the
+	// given content type and metadata.
+	uploadObject(r io.Reader, key, contentType string,
+		metadata map[string]string) error
+}
+
+// NewCorpusStore constructs the storage backend selected in the config for the
+// given context and logger.
+func NewCorpusStore(ctx context.Context, logger *slog.Logger,
+	cfg *Config) (CorpusStore, error) {
+
+	// The constructors are not returned directly, so that a failure
+	// returns a nil interface rather than one holding a nil pointer.
+	switch cfg.Project.StorageBackend {
+	case StorageBackendGCS:
+		gcs, err := NewGCSStore(ctx, logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return gcs, nil
+
+	case StorageBackendAzure:
+		abs, err := NewAzureBlobStore(ctx, logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return abs, nil
+	}
+
+	s3s, err := NewS3Store(ctx, logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3s, nil
+}
+
+// restoreLocalCorpus moves the local corpus moved aside to localDir back to
+// corpusDir, discarding any partially downloaded corpus.
+func (a *corpusArchiver) restoreLocalCorpus(localDir string) {
+	if err := os.RemoveAll(a.corpusDir); err != nil {
+		a.logger.Error("Failed to remove downloaded corpus", "error",
+			err)
+		return
+	}
+
+	if err := os.Rename(localDir, a.corpusDir); err != nil {
+		a.logger.Error("Failed to restore local corpus", "error", err)
+	}
+}
+
+// downloadMergedCorpus downloads the corpus into corpusDir with the given
+// download function, which returns true if the stored corpus is empty, then
+// merges any corpus already present locally into it according to the merge
+// strategy. Returns true if the stored corpus is empty.
+func (a *corpusArchiver) downloadMergedCorpus(ctx context.Context, srcDir,
+	mergeStrategy string, download func() (bool, error)) (bool, error) {
+
+	// Move the local corpus (if any) aside, so the downloaded corpus can
+	// be extracted on its own and merged with it afterwards.
+	localDir := a.corpusDir + ".local"
+	if err := os.RemoveAll(localDir); err != nil {
+		return false, fmt.Errorf("removing stale local corpus: %w", err)
+	}
+
+	err := os.Rename(a.corpusDir, localDir)
+	hasLocal := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("moving local corpus aside: %w", err)
+	}
+
+	empty, err := download()
+	if err != nil {
+		// Put the local corpus back, so it is not lost.
+		if hasLocal {
+			a.restoreLocalCorpus(localDir)
+		}
+		return false, fmt.Errorf("corpus download failed: %w", err)
+	}
+
+	if hasLocal {
+		err := mergeCorpus(ctx, a.logger, srcDir, localDir, a.corpusDir,
+			mergeStrategy)
+		if err != nil {
+			return false, fmt.Errorf("corpus merge failed: %w", err)
+		}
+
+		if err := os.RemoveAll(localDir); err != nil {
+			return false, fmt.Errorf("removing local corpus: %w",
+				err)
+		}
+	}
+
+	return empty, nil
+}
+
+// fetchArchive downloads the corpus archive stored under key into a temporary
+// file with the given download function, which returns true if the object does
+// not exist, and extracts it into the corpus, detecting its format from the
+// key's extension. If the object does not exist, the archive stored in the
+// other format is downloaded instead, so corpora archived before the archive
+// format was changed are not lost. Returns true if neither object exists.
+func (a *corpusArchiver) fetchArchive(key string,
+	download func(outPath, key string) (bool, error)) (bool, error) {
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(a.corpusDir),
+		"corpus-archive-*")
+	if err != nil {
+		return false, fmt.Errorf("creating temp file: %w", err)
+	}
+	archivePath := tmpFile.Name()
+	if err := tmpFile.Close(); err != nil {
+		return false, fmt.Errorf("closing temp file: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(archivePath); err != nil {
+			a.logger.Error("Failed to remove file", "error", err)
+		}
+	}()
+
+	empty, err := download(archivePath, key)
+	if err != nil {
+		return false, err
+	}
+
+	if empty {
+		key = alternateArchiveKey(key)
+		empty, err = download(archivePath, key)
+		if err != nil || empty {
+			return empty, err
+		}
+
+		a.logger.Info("Using corpus archive in previous format", "key",
+			key)
+	}
+
+	err = a.extractArchive(archivePath, archiveFormatOf(key))
+	if err != nil {
+		return false, fmt.Errorf("extract %q: %w", key, err)
+	}
+
+	a.logger.Info("Successfully downloaded and extracted corpus archive",
+		"key", key)
+
+	return false, nil
+}
+
+// streamArchive streams srcDir as a corpus archive to the given upload
+// function under key, recording the last corpus minimization time in its
+// metadata, without buffering the whole archive in memory.
+func (a *corpusArchiver) streamArchive(srcDir, key string,
+	lastMinTime time.Time, upload func(r io.Reader, key,
+		contentType string, metadata map[string]string) error) error {
+
+	// Stream the archive in a goroutine.
+	pr, pw := io.Pipe()
+	go func() {
+		err := a.writeArchive(pw, srcDir)
+		if err != nil {
+			a.logger.Error("Failed to stream archive", "error", err)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	// Now upload the archived corpus with updated metadata, counting its
+	// bytes.
+	contentType := archiveContentType(a.archiveFormat)
+	archive := &countingReader{r: pr}
+	err := upload(archive, key, contentType, map[string]string{
+		"last-minimized": lastMinTime.Format(time.RFC3339),
+	})
+	if err != nil {
+		// Stop the archive goroutine, which may still be writing.
+		pr.CloseWithError(err)
+		return err
+	}
+
+	corpusBytesUploaded.Add(float64(archive.n))
+	a.logger.Info("Successfully archived and uploaded corpus", "key", key,
+		"bytes", archive.n)
+
+	return nil
+}
+
+// countingReader is an io.Reader counting the bytes read from the underlying
+// reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// combineUploadErrors returns the error of a corpus and reports upload whose
+// parts were attempted independently, listing every part that failed, and
+// logs which parts succeeded.
+func combineUploadErrors(logger *slog.Logger, corpusErr,
+	reportsErr error) error {
+
+	switch {
+	case corpusErr != nil && reportsErr != nil:
+		return errors.Join(corpusErr, reportsErr)
+
+	case corpusErr != nil:
+		logger.Warn("Partially uploaded corpus and reports",
+			"succeeded", "reports", "error", corpusErr)
+		return corpusErr
+
+	case reportsErr != nil:
+		logger.Warn("Partially uploaded corpus and reports",
+			"succeeded", "corpus", "error", reportsErr)
+		return reportsErr
+	}
+
+	logger.Info("Successfully uploaded corpus and reports")
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// crashReport describes the outcome of reporting a fuzz crash: its signature,
+// its ClusterFuzz-compatible type and state (if computed), the URL of the issue
+// tracking it, and whether the issue was newly created or reopened.
+type crashReport struct {
+	Package    string `json:"package"`
+	Target     string `json:"target"`
+	Signature  string `json:"signature"`
+	CrashType  string `json:"crash_type,omitempty"`
+	CrashState string `json:"crash_state,omitempty"`
+	IssueURL   string `json:"issue_url"`
+	New        bool   `json:"new"`
+	Reopened   bool   `json:"reopened"`
+}
+
+// targetSummary holds the per-target results collected over a run.
+type targetSummary struct {
+	Package  string `json:"package"`
+	Target   string `json:"target"`
+	Runs     int    `json:"runs"`
+	Coverage string `json:"coverage,omitempty"`
+}
+
+// runSummary collects the results of all fuzzing cycles of a run, so they can
+// be printed once the run ends. It is safe for concurrent use by the workers.
+type runSummary struct {
+	mu sync.Mutex
+
+	cycles   int
+	targets  map[string]*targetSummary
+	crashes  []crashReport
+	exitCode int
+	reason   string
+}
+
+// NewRunSummary returns an empty, initialized runSummary.
+func NewRunSummary() *runSummary {
+	return &runSummary{
+		targets: make(map[string]*targetSummary),
+	}
+}
+
+// recordCycle records the completion of a fuzzing cycle.
+func (s *runSummary) recordCycle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cycles++
+}
+
+// cycleCount returns the number of completed fuzzing cycles.
+func (s *runSummary) cycleCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cycles
+}
+
+// recordTarget records a completed fuzzing run of the target, along with its
+// latest coverage (if measured).
+func (s *runSummary) recordTarget(pkg, target, coverage string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pkg + "/" + target
+	ts, ok := s.targets[key]
+	if !ok {
+		ts = &targetSummary{Package: pkg, Target: target}
+		s.targets[key] = ts
+	}
+
+	ts.Runs++
+	if coverage != "" {
+		ts.Coverage = coverage
+	}
+}
+
+// recordCrash records a reported fuzz crash.
+func (s *runSummary) recordCrash(cr crashReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.crashes = append(s.crashes, cr)
+}
+
+// finish records the exit code of the run and the reason for it.
+func (s *runSummary) finish(exitCode int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.exitCode = exitCode
+	s.reason = reason
+}
+
+// summaryJSON is the machine-parseable representation of a runSummary.
+type summaryJSON struct {
+	Cycles   int             `json:"cycles"`
+	Targets  []targetSummary `json:"targets"`
+	Crashes  []crashReport   `json:"crashes"`
+	ExitCode int             `json:"exit_code"`
+	Reason   string          `json:"reason"`
+}
+
+// snapshot returns a copy of the collected results, with the targets sorted
+// by package and target name.
+func (s *runSummary) snapshot() summaryJSON {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	targets := make([]targetSummary, 0, len(s.targets))
+	for _, ts := range s.targets {
+		targets = append(targets, *ts)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Package == targets[j].Package {
+			return targets[i].Target < targets[j].Target
+		}
+		return targets[i].Package < targets[j].Package
+	})
+
+	return summaryJSON{
+		Cycles:   s.cycles,
+		Targets:  targets,
+		Crashes:  append([]crashReport{}, s.crashes...),
+		ExitCode: s.exitCode,
+		Reason:   s.reason,
+	}
+}
+
+// write prints the summary to w, either as a human-readable report or, if
+// asJSON is set, as a single line of JSON.
+func (s *runSummary) write(w io.Writer, asJSON bool) error {
+	snap := s.snapshot()
+
+	if asJSON {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return fmt.Errorf("failed to serialize summary: %w",
+				err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "=== go-continuous-fuzz summary ===\n"+
+		"Cycles completed: %d\nTargets fuzzed: %d\n", snap.Cycles,
+		len(snap.Targets))
+	if err != nil {
+		return err
+	}
+
+	for _, ts := range snap.Targets {
+		coverage := "n/a"
+		if ts.Coverage != "" {
+			coverage = ts.Coverage + "%"
+		}
+		_, err := fmt.Fprintf(w, "  %s/%s: runs=%d coverage=%s\n",
+			ts.Package, ts.Target, ts.Runs, coverage)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "Crashes found: %d\n", len(snap.Crashes))
+	if err != nil {
+		return err
+	}
+
+	for _, cr := range snap.Crashes {
+		status := "existing"
+		switch {
+		case cr.New:
+			status = "new"
+		case cr.Reopened:
+			status = "reopened"
+		}
+		_, err := fmt.Fprintf(w, "  [fuzz/%s] %s/%s %s (%s)\n",
+			cr.Signature, cr.Package, cr.Target, cr.IssueURL,
+			status)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "Exit status: %d (%s)\n", snap.ExitCode,
+		snap.Reason)
+	return err
+}
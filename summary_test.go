@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunSummaryWrite verifies that the run summary aggregates the results of
+// all cycles and renders them both as text and as JSON.
+func TestRunSummaryWrite(t *testing.T) {
+	summary := NewRunSummary()
+	summary.recordTarget("tree", "FuzzBuildTree", "")
+	summary.recordTarget("parser", "FuzzEvalExpr", "70.0")
+	summary.recordCycle()
+	summary.recordTarget("parser", "FuzzEvalExpr", "75.0")
+	summary.recordCycle()
+	summary.recordCrash(crashReport{
+		Package:   "tree",
+		Target:    "FuzzBuildTree",
+		Signature: "cfec419a119b189c",
+		IssueURL:  "https://github.com/OWNER/REPO/issues/1",
+		New:       true,
+	})
+	summary.recordCrash(crashReport{
+		Package:   "parser",
+		Target:    "FuzzEvalExpr",
+		Signature: "0a1b2c3d4e5f6a7b",
+		IssueURL:  "https://github.com/OWNER/REPO/issues/2",
+		Reopened:  true,
+	})
+	summary.finish(0, "completed all 2 fuzzing cycles")
+
+	var text bytes.Buffer
+	assert.NoError(t, summary.write(&text, false))
+	assert.Equal(t, "=== go-continuous-fuzz summary ===\n"+
+		"Cycles completed: 2\n"+
+		"Targets fuzzed: 2\n"+
+		"  parser/FuzzEvalExpr: runs=2 coverage=75.0%\n"+
+		"  tree/FuzzBuildTree: runs=1 coverage=n/a\n"+
+		"Crashes found: 2\n"+
+		"  [fuzz/cfec419a119b189c] tree/FuzzBuildTree "+
+		"https://github.com/OWNER/REPO/issues/1 (new)\n"+
+		"  [fuzz/0a1b2c3d4e5f6a7b] parser/FuzzEvalExpr "+
+		"https://github.com/OWNER/REPO/issues/2 (reopened)\n"+
+		"Exit status: 0 (completed all 2 fuzzing cycles)\n",
+		text.String())
+
+	var jsonOut bytes.Buffer
+	assert.NoError(t, summary.write(&jsonOut, true))
+
+	var parsed summaryJSON
+	assert.NoError(t, json.Unmarshal(jsonOut.Bytes(), &parsed))
+	assert.Equal(t, summary.snapshot(), parsed)
+}
@@ -62,6 +62,26 @@ const masterHTML = `<!DOCTYPE html>
       a:hover {
         text-decoration: underline;
       }
+      /* Target status badges */
+      .status {
+        display: inline-block;
+        padding: 0.1rem 0.5rem;
+        border-radius: 0.25rem;
+        font-size: 0.875rem;
+        color: #fff;
+        background: #7f8c8d;
+      }
+      .status-ok {
+        background: #27ae60;
+      }
+      .status-crash,
+      .status-build-fail {
+        background: #c0392b;
+      }
+      .status-oom,
+      .status-disabled {
+        background: #d35400;
+      }
       /* Footer */
       footer {
         text-align: center;
@@ -87,6 +107,10 @@ const masterHTML = `<!DOCTYPE html>
           <tr>
             <th>Package Path</th>
             <th>Target</th>
+            <th>Status</th>
+            <th>Last Run</th>
+            <th>Coverage</th>
+            <th>Open Issues</th>
           </tr>
         </thead>
         <tbody>
@@ -94,6 +118,23 @@ const masterHTML = `<!DOCTYPE html>
           <tr>
             <td>{{ .PkgPath }}</td>
             <td><a href="{{ .LinkFile }}">{{ .Target }}</a></td>
+            {{- if .Result }}
+            <td>
+              <span class="status status-{{ .Result }}">{{ .Result }}</span>
+            </td>
+            <td>
+              {{- if .LastRun }}{{ .LastRun }}{{ else }}&mdash;{{ end -}}
+            </td>
+            <td>
+              {{- if .Coverage }}{{ .Coverage }}%{{ else }}&mdash;{{ end -}}
+            </td>
+            <td>{{ .OpenIssues }}</td>
+            {{- else }}
+            <td>&mdash;</td>
+            <td>&mdash;</td>
+            <td>&mdash;</td>
+            <td>&mdash;</td>
+            {{- end }}
           </tr>
           {{- end }}
         </tbody>
@@ -0,0 +1,1135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/client"
+)
+
+// errIssueBodyTooLarge is returned when the body of an issue exceeds the issue
+// body fetch limit, so its failing input is not parsed.
+var errIssueBodyTooLarge = errors.New("issue body exceeds fetch limit")
+
+// CrashTracker is an issue tracker to which the crashes found by fuzzing are
+// reported, and whose issues are verified and closed once their crash is no
+// longer reproducible.
+type CrashTracker interface {
+	// handleCrash reports the fuzz crash of the target, unless an issue
+	// is already open for it, and returns the report of the crash.
+	handleCrash(pkg, target string, fc fuzzCrash) (*crashReport, error)
+
+	// handleUnknownFailure reports the target's fuzz container that
+	// exited with a non-zero status without a recognized crash.
+	handleUnknownFailure(pkg, target string,
+		exitErr *containerExitError) (*crashReport, error)
+
+	// handleOOM reports the target's fuzz container that was killed for
+	// running out of memory.
+	handleOOM(pkg, target string,
+		exitErr *containerExitError) (*crashReport, error)
+
+	// handleFlakyCoverage reports the target whose coverage varied across
+	// the given measurements of the same corpus.
+	handleFlakyCoverage(pkg, target string,
+		measurements []int) (*crashReport, error)
+
+	// handleDisabledTarget reports the target disabled for crashing early
+	// in the given number of consecutive cycles, last with the crash of
+	// the given report, unless a tracking issue is already open for it.
+	handleDisabledTarget(pkg, target string, cycles int,
+		crash crashReport) (*crashReport, error)
+
+	// closeDisabledIssue closes the tracking issue of the target disabled
+	// for crashing early, if any, once its crashes are fixed. Returns
+	// whether an issue was closed.
+	closeDisabledIssue(pkg, target string) (bool, error)
+
+	// digestCrash adds the fuzz crash of the target to the digest instead
+	// of opening an issue for it, and returns the report of the crash.
+	digestCrash(pkg, target string, fc fuzzCrash,
+		digest *crashDigest) *crashReport
+
+	// postDigest lists the given crashes in the digest issue of the day
+	// of the given time, and returns its URL and the number of crashes
+	// added to it.
+	postDigest(entries []digestEntry, now time.Time) (string, int, error)
+
+	// crashSignature computes the short signature of the crash used to
+	// deduplicate its issues, along with its fingerprint if enabled.
+	crashSignature(fc fuzzCrash) (string, *crashFingerprint)
+
+	// verifyAndCloseResolvedIssues closes the open issues of the target
+	// whose crash is no longer reproducible, and returns the number of
+	// issues left open.
+	verifyAndCloseResolvedIssues(pkg, target string) (int, error)
+
+	// countOpenIssues returns the number of open crash issues of the
+	// target.
+	countOpenIssues(pkg, target string) (int, error)
+
+	// issueExists reports whether an open issue has the exact title.
+	issueExists(title string) (bool, error)
+
+	// createIssue opens a new issue with the given title and body.
+	createIssue(title, body string) (*trackerIssue, error)
+
+	// closeIssue closes the issue with the given number, after commenting
+	// on it with the given body.
+	closeIssue(number int, closeIssueComment string) error
+
+	// closeOrphanedIssues closes the open crash issues of the target that
+	// is no longer discovered, and returns the number of closed issues.
+	closeOrphanedIssues(pkg, target string) (int, error)
+}
+
+// issueClient holds the operations on the issues of a tracker's repository
+// that the crash reports rely on, implemented by each tracker.
+type issueClient interface {
+	// listIssues returns the issues in the given state (IssueStateOpen or
+	// IssueStateClosed) whose title matches the given title exactly,
+	// optionally prefixed by a crash signature.
+	listIssues(title, state string) ([]*trackerIssue, error)
+
+	// createIssue opens a new issue with the given title and body.
+	createIssue(title, body string) (*trackerIssue, error)
+
+	// closeIssue closes the issue with the given number, after commenting
+	// on it with the given body.
+	closeIssue(number int, closeIssueComment string) error
+
+	// reopenIssue reopens the issue with the given number, after
+	// commenting on it with the given reason.
+	reopenIssue(number int, reason string) error
+
+	// listComments returns the comments of the issue with the given
+	// number, oldest first.
+	listComments(number int) ([]*trackerComment, error)
+
+	// addComment posts a comment with the given body on the issue with the
+	// given number.
+	addComment(number int, body string) error
+
+	// editComment replaces the body of the comment with the given ID on the
+	// issue with the given number.
+	editComment(number int, id int64, body string) error
+
+	// updateIssueBody replaces the body of the issue with the given
+	// number.
+	updateIssueBody(number int, body string) error
+
+	// getIssue returns the issue with the given number, with its full
+	// body.
+	getIssue(number int) (*trackerIssue, error)
+}
+
+// trackerIssue is an issue of a crash tracker, with the fields the crash
+// reports rely on.
+type trackerIssue struct {
+	// number identifies the issue in its repository, e.g. the IID of a
+	// GitLab issue.
+	number int
+
+	title    string
+	body     string
+	url      string
+	closedAt time.Time
+}
+
+// trackerComment is a comment on an issue of a crash tracker.
+type trackerComment struct {
+	// id identifies the comment, e.g. the ID of a GitLab note.
+	id int64
+
+	body string
+}
+
+// crashReporter reports the crashes of fuzz targets to a crash tracker, and
+// verifies and closes the tracker's issues once their crash is resolved, using
+// the operations of the given issueClient. It is embedded by every crash
+// tracker, so they format and deduplicate their issues identically.
+type crashReporter struct {
+	ctx    context.Context
+	logger *slog.Logger
+	cli    *client.Client
+	cfg    *Config
+	engine fuzzEngine
+	issues issueClient
+
+	// notifier notifies of the issues opened for new crashes and closed
+	// for resolved ones.
+	notifier *Notifier
+}
+
+// newCrashReporter returns the crashReporter for the given context, logger,
+// Docker client and config, operating on issues through the given client.
+func newCrashReporter(ctx context.Context, logger *slog.Logger,
+	cli *client.Client, cfg *Config, issues issueClient) crashReporter {
+
+	return crashReporter{
+		ctx:      ctx,
+		logger:   logger,
+		cli:      cli,
+		cfg:      cfg,
+		engine:   newFuzzEngine(cfg.Fuzz.Engine),
+		issues:   issues,
+		notifier: NewNotifier(logger, cfg),
+	}
+}
+
+// NewCrashTracker constructs the crash tracker selected in the config for the
+// repository where crash issues are created.
+func NewCrashTracker(ctx context.Context, logger *slog.Logger,
+	cli *client.Client, cfg *Config) (CrashTracker, error) {
+
+	// The constructors are not returned directly, so that a failure
+	// returns a nil interface rather than one holding a nil pointer.
+	if cfg.Fuzz.CrashTracker == CrashTrackerGitLab {
+		gl, err := NewGitLabRepo(ctx, logger, cli, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return gl, nil
+	}
+
+	gh, err := NewGitHubRepo(ctx, logger, cli, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return gh, nil
+}
+
+// crashTrackerFor returns the tracker of the repository at the given URL:
+// CrashTrackerGitLab for gitlab.com, and CrashTrackerGitHub otherwise. Issue
+// trackers of self-hosted GitLab instances must be selected explicitly.
+func crashTrackerFor(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err == nil && strings.EqualFold(u.Hostname(), "gitlab.com") {
+		return CrashTrackerGitLab
+	}
+	return CrashTrackerGitHub
+}
+
+// issueExists reports whether an open issue with the exact title exists.
+func (cr *crashReporter) issueExists(title string) (bool, error) {
+	issue, err := cr.findExistingIssue(title)
+	if err != nil {
+		return false, err
+	}
+	return issue != nil, nil
+}
+
+// listOpenIssues retrieves all open issues in the repository that match the
+// exact title, optionally prefixed by a crash signature.
+func (cr *crashReporter) listOpenIssues(title string) ([]*trackerIssue, error) {
+	return cr.issues.listIssues(title, IssueStateOpen)
+}
+
+// countOpenIssues returns the number of open crash issues of the target,
+// without verifying them.
+func (cr *crashReporter) countOpenIssues(pkg, target string) (int, error) {
+	title := fmt.Sprintf("Fuzzing crash in %s/%s", pkg, target)
+	issues, err := cr.listOpenIssues(title)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(issues), nil
+}
+
+// findExistingIssue returns the open issue with the exact title, or nil if no
+// such issue exists.
+func (cr *crashReporter) findExistingIssue(title string) (*trackerIssue,
+	error) {
+
+	cr.logger.Info("Searching for existing issue", "title", title)
+
+	issues, err := cr.listOpenIssues(title)
+	if err != nil {
+		cr.logger.Error("Issue search failed", "err", err)
+		return nil, err
+	}
+
+	if len(issues) > 0 {
+		cr.logger.Info("Issue already exists", "url",
+			issues[0].url)
+		return issues[0], nil
+	}
+
+	return nil, nil
+}
+
+// findLatestClosedIssue returns the most recently closed issue with the given
+// title, or nil if there is none.
+func (cr *crashReporter) findLatestClosedIssue(title string) (*trackerIssue,
+	error) {
+
+	issues, err := cr.issues.listIssues(title, IssueStateClosed)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *trackerIssue
+	for _, issue := range issues {
+		if latest == nil ||
+			issue.closedAt.After(latest.closedAt) {
+
+			latest = issue
+		}
+	}
+
+	return latest, nil
+}
+
+// handleCrash posts an issue for a new fuzz crash if one does not exist.
+// It computes a unique crash signature, formats a report, and avoids duplicates
+// by checking for an existing issue with the same title. Returns the signature
+// and the issue tracking the crash.
+func (cr *crashReporter) handleCrash(pkg, target string,
+	fc fuzzCrash) (*crashReport, error) {
+
+	// Compute a short signature hash for the crash to help with
+	// deduplication.
+	crashHash, fingerprint := cr.crashSignature(fc)
+	crashesFound.Inc(pkg, target)
+
+	// Compose issue title and body
+	title := fmt.Sprintf("[fuzz/%s] Fuzzing crash in %s/%s", crashHash, pkg,
+		target)
+	body := cr.crashReportBody(pkg, target, crashHash, fingerprint, fc,
+		cr.cfg.Fuzz.IssueBodyLimit)
+
+	report := &crashReport{
+		Package:   pkg,
+		Target:    target,
+		Signature: crashHash,
+	}
+	if fingerprint != nil {
+		report.CrashType = fingerprint.crashType
+		report.CrashState = fingerprint.state()
+	}
+
+	// Check for existing issue to prevent duplicates
+	issue, err := cr.findExistingIssue(title)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing issues: %w", err)
+	}
+
+	if issue != nil {
+		cr.logger.Info("Fuzz crash already reported", "signature",
+			crashHash)
+		report.IssueURL = issue.url
+
+		// Tracking the recurrences is only a convenience for the
+		// developer triaging the crash, so failures do not abort it.
+		if cr.cfg.Fuzz.TrackRecurrences {
+			if err := cr.trackRecurrence(issue); err != nil {
+				cr.logger.Warn("Failed to track crash "+
+					"recurrence", "url", issue.url,
+					"error", err)
+			}
+		}
+
+		return report, nil
+	}
+
+	// If enabled, reopen the issue previously closed for this crash rather
+	// than creating a duplicate.
+	if cr.cfg.Fuzz.ReopenIssues {
+		issue, err := cr.findLatestClosedIssue(title)
+		if err != nil {
+			return nil, fmt.Errorf("checking closed issues: %w",
+				err)
+		}
+
+		if issue != nil {
+			reopened, err := cr.reopenClosedIssue(issue)
+			if err != nil {
+				return nil, fmt.Errorf("reopening issue: %w",
+					err)
+			}
+			report.IssueURL = issue.url
+			report.Reopened = reopened
+			return report, nil
+		}
+	}
+
+	// Create a new issue for this crash
+	issue, err = cr.issues.createIssue(title, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating issue: %w", err)
+	}
+	report.IssueURL = issue.url
+	report.New = true
+	issuesOpened.Inc()
+	cr.notifier.emitEvent(cr.ctx, NotifyEventCrashOpened, pkg, target,
+		crashHash, issue.url)
+
+	return report, nil
+}
+
+// crashSignature computes the short signature of the crash used to deduplicate
+// its issues, either from the location of the failure or, if enabled, from its
+// ClusterFuzz-compatible fingerprint, which is then returned as well.
+// Out-of-memory crashes, which have no meaningful location, all share the
+// OOMFailureLocation signature.
+func (cr *crashReporter) crashSignature(fc fuzzCrash) (string,
+	*crashFingerprint) {
+
+	if fc.failureFileAndLine == OOMFailureLocation {
+		return OOMFailureLocation, nil
+	}
+
+	if !cr.cfg.Fuzz.ClusterFuzzSignature {
+		return ComputeSHA256Short(fc.failureFileAndLine), nil
+	}
+
+	fingerprint := clusterFuzzFingerprint(fc.errorLogs)
+	return fingerprint.signature(), fingerprint
+}
+
+// handleUnknownFailure posts an issue for a fuzz container of the target
+// that exited with a non-zero status without a recognized crash, unless one is
+// already open. Since there is no failing input, such issues are never verified
+// and closed automatically. Returns the report of the failure.
+func (cr *crashReporter) handleUnknownFailure(pkg, target string,
+	exitErr *containerExitError) (*crashReport, error) {
+
+	body := formatUnknownFailureReport(exitErr.statusCode,
+		exitErr.outputTail, cr.cfg.Fuzz.IssueBodyLimit)
+
+	return cr.reportExitFailure(pkg, target, UnknownFailureSignature, body)
+}
+
+// handleOOM posts an issue for a fuzz container of the target that was
+// killed for running out of memory, unless one is already open. Like unknown
+// failures, such issues are never verified and closed automatically. Returns
+// the report of the failure.
+func (cr *crashReporter) handleOOM(pkg, target string,
+	exitErr *containerExitError) (*crashReport, error) {
+
+	body := formatOOMReport(exitErr.statusCode, exitErr.memoryLimit,
+		exitErr.outputTail, cr.cfg.Fuzz.IssueBodyLimit)
+
+	return cr.reportExitFailure(pkg, target, OOMSignature, body)
+}
+
+// handleFlakyCoverage posts an issue for the target whose coverage varied
+// across the given measurements of the same corpus, unless one is already
+// open. Like unknown failures, such issues are never verified and closed
+// automatically. Returns the report of the failure.
+func (cr *crashReporter) handleFlakyCoverage(pkg, target string,
+	measurements []int) (*crashReport, error) {
+
+	body := formatFlakyCoverageReport(measurements)
+
+	return cr.reportExitFailure(pkg, target, FlakyCoverageSignature, body)
+}
+
+// handleDisabledTarget posts an issue tracking the target disabled for crashing
+// early in the given number of consecutive cycles, last with the crash of the
+// given report, unless one is already open. Unlike the other failures, such
+// issues are closed once the target's crashes are fixed. Returns the report of
+// the failure.
+func (cr *crashReporter) handleDisabledTarget(pkg, target string, cycles int,
+	crash crashReport) (*crashReport, error) {
+
+	body := formatDisabledTargetReport(cycles, crash.IssueURL)
+
+	return cr.reportExitFailure(pkg, target, DisabledTargetSignature, body)
+}
+
+// closeDisabledIssue closes the open issue tracking the target disabled for
+// crashing early, if any, with a comment telling that it is re-enabled at the
+// current commit. Returns whether an issue was closed.
+func (cr *crashReporter) closeDisabledIssue(pkg, target string) (bool,
+	error) {
+
+	title := fmt.Sprintf("[%s] %s/%s", DisabledTargetSignature, pkg,
+		target)
+	issue, err := cr.findExistingIssue(title)
+	if err != nil {
+		return false, fmt.Errorf("checking existing issues: %w", err)
+	}
+	if issue == nil {
+		return false, nil
+	}
+
+	comment := formatReenableComment(headCommit(cr.cfg.Project.SrcDir))
+	if err := cr.issues.closeIssue(issue.number, comment); err != nil {
+		return false, fmt.Errorf("closing issue: %w", err)
+	}
+	issuesClosed.Inc()
+
+	return true, nil
+}
+
+// reportExitFailure posts an issue with the given body for a failure of
+// the target's fuzz container without a failing input, titled after the given
+// signature, unless one is already open. Returns the report of the failure.
+func (cr *crashReporter) reportExitFailure(pkg, target, signature,
+	body string) (*crashReport, error) {
+
+	title := fmt.Sprintf("[%s] %s/%s", signature, pkg, target)
+
+	report := &crashReport{
+		Package:   pkg,
+		Target:    target,
+		Signature: signature,
+	}
+
+	issue, err := cr.findExistingIssue(title)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing issues: %w", err)
+	}
+
+	if issue != nil {
+		cr.logger.Info("Fuzz failure already reported", "signature",
+			signature, "url", issue.url)
+		report.IssueURL = issue.url
+		return report, nil
+	}
+
+	issue, err = cr.issues.createIssue(title, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating issue: %w", err)
+	}
+	report.IssueURL = issue.url
+	report.New = true
+	issuesOpened.Inc()
+
+	return report, nil
+}
+
+// crashReportBody returns the body of the issue reporting the crash, starting
+// with its fingerprint if non-nil and, if enabled, the last progress of the
+// fuzzer before the crash. If the body would exceed limit characters, e.g. the
+// 65536 characters GitHub accepts, the full error logs and failing input are
+// uploaded to the storage bucket and linked from the body, with their inline
+// versions truncated to fit.
+func (cr *crashReporter) crashReportBody(pkg, target, crashHash string,
+	fingerprint *crashFingerprint, fc fuzzCrash, limit int) string {
+
+	var header string
+	if fingerprint != nil {
+		header = fingerprint.markdown()
+	}
+	if cr.cfg.Fuzz.IssueIncludeProgress {
+		header += formatProgressSection(fc.progress)
+	}
+	header += cr.crashCoverage(pkg, target)
+
+	commits := cr.crashCommits(pkg, fc.failureFileAndLine)
+	body := header + formatCrashReport(fc.errorLogs, fc.failingInput,
+		commits, "")
+
+	if utf8.RuneCountInString(body) <= limit {
+		return body
+	}
+
+	cr.logger.Info("Crash report exceeds issue body limit; truncating",
+		"signature", crashHash, "length", utf8.RuneCountInString(body),
+		"limit", limit)
+
+	note := cr.storeCrashLogs(pkg, target, crashHash, fc)
+	return header + truncateCrashReport(fc.errorLogs, fc.failingInput,
+		commits, note, limit-utf8.RuneCountInString(header))
+}
+
+// crashCoverage returns the markdown section of a crash report holding the
+// target's coverage from its latest coverage report, to help triagers gauge
+// how well-exercised the crashing code is. Failing to load the coverage is
+// logged, and the section omitted, but does not fail the crash report.
+func (cr *crashReporter) crashCoverage(pkg, target string) string {
+	history, err := loadTargetHistory(cr.cfg.Project.ReportDir, pkg,
+		target)
+	if err != nil {
+		cr.logger.Error("Failed to load coverage history; omitting "+
+			"coverage from crash report", "package", pkg, "target",
+			target, "error", err)
+		return ""
+	}
+
+	return formatCoverageSection(history)
+}
+
+// storeCrashLogs uploads the full error logs and failing input of the crash to
+// the storage bucket, and returns a markdown note linking to them. Failing to
+// upload them is logged and noted, but does not fail the crash report, so the
+// issue is still created with the truncated logs.
+func (cr *crashReporter) storeCrashLogs(pkg, target, crashHash string,
+	fc fuzzCrash) string {
+
+	failed := "The full error logs and failing testcase could not be " +
+		"stored; check the go-continuous-fuzz logs."
+
+	store, err := NewCorpusStore(cr.ctx, cr.logger, cr.cfg)
+	if err != nil {
+		cr.logger.Error("Failed to create store for crash logs",
+			"error", err)
+		return failed
+	}
+
+	prefix := fmt.Sprintf("%s%s/%s/%s/", CrashLogPrefix, pkg, target,
+		crashHash)
+	files := []struct {
+		name    string
+		key     string
+		content string
+	}{
+		{"Error logs", prefix + "error.log", fc.errorLogs},
+		{"Failing testcase", prefix + "failing-input.txt",
+			fc.failingInput},
+	}
+
+	note := "The error logs and failing testcase above may be " +
+		"truncated. Full versions:"
+	for _, f := range files {
+		// A seed corpus crash has no failing input to store.
+		if f.content == "" {
+			continue
+		}
+
+		err := store.uploadObject(strings.NewReader(f.content), f.key,
+			"text/plain; charset=utf-8", nil)
+		if err != nil {
+			cr.logger.Error("Failed to upload crash logs", "key",
+				f.key, "error", err)
+			return failed
+		}
+		note += fmt.Sprintf("\n- [%s](%s)", f.name,
+			cr.crashLogURL(f.key))
+	}
+
+	return note
+}
+
+// crashLogURL returns the URL of the stored object with the given key, under
+// the configured S3 base URL, or if none is configured, as an s3:// or gs://
+// URI, or the blob's URL in the Azure storage account.
+func (cr *crashReporter) crashLogURL(key string) string {
+	if cr.cfg.Project.S3BaseURL == "" {
+		switch cr.cfg.Project.StorageBackend {
+		case StorageBackendGCS:
+			return fmt.Sprintf("gs://%s/%s",
+				cr.cfg.Project.GCSBucketName, key)
+
+		case StorageBackendAzure:
+			return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(
+				cr.cfg.Project.AzureAccountURL, "/"),
+				cr.cfg.Project.AzureContainer, key)
+		}
+		return fmt.Sprintf("s3://%s/%s", cr.cfg.Project.S3BucketName,
+			key)
+	}
+
+	return strings.TrimSuffix(cr.cfg.Project.S3BaseURL, "/") + "/" + key
+}
+
+// reopenClosedIssue reopens the closed issue of a crash that reproduced again,
+// unless it was closed less than the reopen cooldown ago, to avoid flapping
+// issues for nondeterministic crashes. Returns whether the issue was reopened.
+func (cr *crashReporter) reopenClosedIssue(issue *trackerIssue) (bool, error) {
+	closedFor := time.Since(issue.closedAt)
+	if closedFor < cr.cfg.Fuzz.ReopenCooldown {
+		cr.logger.Info("Crash reproduced within reopen cooldown; "+
+			"leaving issue closed", "url", issue.url,
+			"closedFor", closedFor.Truncate(time.Second),
+			"cooldown", cr.cfg.Fuzz.ReopenCooldown)
+		return false, nil
+	}
+
+	err := cr.issues.reopenIssue(issue.number, formatReopenComment(
+		headCommit(cr.cfg.Project.SrcDir)))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// closeOrphanedIssues closes the open crash issues of the fuzz target of the
+// given package that is no longer discovered, e.g. after being renamed, with a
+// comment explaining why, since their crashes can no longer be verified.
+// Returns the number of closed issues.
+func (cr *crashReporter) closeOrphanedIssues(pkg, target string) (int,
+	error) {
+
+	title := fmt.Sprintf("Fuzzing crash in %s/%s", pkg, target)
+	issues, err := cr.listOpenIssues(title)
+	if err != nil {
+		return 0, err
+	}
+
+	comment := formatOrphanComment(pkg, target)
+	for _, issue := range issues {
+		err := cr.issues.closeIssue(issue.number, comment)
+		if err != nil {
+			return 0, fmt.Errorf("closing issue: %w", err)
+		}
+		issuesClosed.Inc()
+	}
+
+	return len(issues), nil
+}
+
+// trackRecurrence counts a new occurrence of the crash of the given open issue
+// in the comment tracking its recurrences, which is edited in place so that the
+// issue is not flooded with a comment per occurrence. The comment is only
+// posted if the issue has none yet, e.g. on the first recurrence.
+func (cr *crashReporter) trackRecurrence(issue *trackerIssue) error {
+	comments, err := cr.issues.listComments(issue.number)
+	if err != nil {
+		return fmt.Errorf("listing comments: %w", err)
+	}
+
+	// Only the latest tracking comment is kept up to date, should there be
+	// several of them.
+	var tracking *trackerComment
+	for _, comment := range comments {
+		if isRecurrenceComment(comment.body) {
+			tracking = comment
+		}
+	}
+
+	// The issue itself reports the first occurrence of the crash.
+	count := 1
+	if tracking != nil {
+		count = parseRecurrenceCount(tracking.body)
+	}
+	body := formatRecurrenceComment(count+1,
+		headCommit(cr.cfg.Project.SrcDir), time.Now())
+
+	if tracking == nil {
+		cr.logger.Info("Posting crash recurrence comment", "url",
+			issue.url, "count", count+1)
+		return cr.issues.addComment(issue.number, body)
+	}
+
+	cr.logger.Info("Updating crash recurrence comment", "url", issue.url,
+		"count", count+1)
+	return cr.issues.editComment(issue.number, tracking.id, body)
+}
+
+// warnShallowBlame logs a warning if recent commits are included in crash
+// issues while only the last cfg.Project.CloneDepth commits of the project are
+// cloned, since older commits touching the crashing file are then missing.
+func warnShallowBlame(logger *slog.Logger, cfg *Config) {
+	if cfg.Fuzz.IssueIncludeBlame <= 0 || cfg.Project.CloneDepth <= 0 {
+		return
+	}
+
+	logger.Warn("Recent commits in crash issues are limited to the "+
+		"shallow clone of the project", "cloneDepth",
+		cfg.Project.CloneDepth, "issueIncludeBlame",
+		cfg.Fuzz.IssueIncludeBlame)
+}
+
+// crashCommits returns the most recent commits touching the file where the
+// crash occurred, if including them in crash issues is enabled. Failures to
+// resolve the file or read the git log are logged and otherwise ignored, since
+// this context is only a convenience for the developer triaging the crash.
+func (cr *crashReporter) crashCommits(pkg, fileAndLine string) []string {
+	limit := cr.cfg.Fuzz.IssueIncludeBlame
+	if limit <= 0 {
+		return nil
+	}
+
+	file := resolveRepoFile(cr.cfg.Project.SrcDir, pkg, fileAndLine)
+	if file == "" {
+		cr.logger.Info("Crashing file not found in project; skipping "+
+			"recent commits", "location", fileAndLine)
+		return nil
+	}
+
+	commits, err := recentCommits(cr.cfg.Project.SrcDir, file, limit)
+	if err != nil {
+		cr.logger.Error("Failed to collect recent commits", "file",
+			file, "error", err)
+		return nil
+	}
+
+	return commits
+}
+
+// issueFailingInput returns the failing input from the body of the issue. The
+// body an issue is listed with may be cut short, e.g. by search results, so if
+// it lacks a complete failing testcase section, the issue is fetched again to
+// parse its full body. Returns errIssueBodyTooLarge if the body exceeds the
+// issue body fetch limit, and the errors of parseIssueBody if the full body
+// has no complete failing testcase section either.
+func (cr *crashReporter) issueFailingInput(issue *trackerIssue) (string,
+	error) {
+
+	limit := cr.cfg.Fuzz.IssueBodyFetchLimit
+	if utf8.RuneCountInString(issue.body) > limit {
+		return "", errIssueBodyTooLarge
+	}
+
+	failingInput, err := parseIssueBody(issue.body)
+	if err == nil {
+		return failingInput, nil
+	}
+
+	full, err := cr.issues.getIssue(issue.number)
+	if err != nil {
+		return "", fmt.Errorf("get issue %d: %w", issue.number, err)
+	}
+	if utf8.RuneCountInString(full.body) > limit {
+		return "", errIssueBodyTooLarge
+	}
+
+	return parseIssueBody(full.body)
+}
+
+// verifyAndCloseResolvedIssues checks open issues for a fuzz target, attempts
+// to reproduce them, and closes those that are no longer reproducible.
+func (cr *crashReporter) verifyAndCloseResolvedIssues(pkg, target string) (int,
+	error) {
+
+	cr.logger.Info("Verifying open issues for fuzz target")
+
+	// Listing issues with the exact same title
+	title := fmt.Sprintf("Fuzzing crash in %s/%s", pkg, target)
+	issues, err := cr.listOpenIssues(title)
+	if err != nil {
+		return 0, err
+	}
+
+	// Count the issues that remain open after verification.
+	openIssues := len(issues)
+
+	// With batch verification, the failing inputs of all issues are
+	// written first, and then reproduced together.
+	var batch []reproduceInput
+	for _, issue := range issues {
+		// Parse the failing input from the issue body
+		failingInput, err := cr.issueFailingInput(issue)
+		switch {
+		case errors.Is(err, errTestcaseNotFound):
+			cr.logger.Info("No failing testcase found in body; "+
+				"skipping issue, possibly an unrelated issue "+
+				"with a similar title", "url",
+				issue.url)
+			continue
+
+		case errors.Is(err, errTestcaseTruncated):
+			cr.logger.Warn("Failing testcase in issue body is "+
+				"truncated; manual verification required",
+				"url", issue.url)
+			continue
+
+		case errors.Is(err, errIssueBodyTooLarge):
+			cr.logger.Warn("Issue body exceeds fetch limit; "+
+				"manual verification required", "url",
+				issue.url, "limit",
+				cr.cfg.Fuzz.IssueBodyFetchLimit)
+			continue
+
+		case err != nil:
+			cr.logger.Error("Failed to fetch issue body; skipping "+
+				"issue", "url", issue.url, "error", err)
+			continue
+		}
+
+		// If the crash is due to a seed corpus input added via f.Add,
+		// this issue cannot be automatically verified and closed.
+		if failingInput == seedCorpusErrMsg {
+			cr.logger.Info("Seed corpus crash detected; manual "+
+				"verification required", "url",
+				issue.url)
+			continue
+		}
+
+		// Prepare directory and file for failing input. The paths only
+		// depend on the layout of the fuzzed project, never on the
+		// crash repository the issue was found in.
+		fuzzBinaryPath := filepath.Join(cr.cfg.Project.BinaryDir, pkg,
+			target)
+		failingDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz",
+			target)
+		if err := EnsureDirExists(failingDir); err != nil {
+			return 0, fmt.Errorf("create testdata directory: %w",
+				err)
+		}
+
+		// Write the input to the target's testdata directory, under a
+		// name that cannot clash with the seed corpus copied from the
+		// project's testdata directory, which must not be overwritten
+		// or removed below.
+		fileHash := ReproduceInputPrefix +
+			corpusFileName([]byte(failingInput))
+		failingFile := filepath.Join(failingDir, fileHash)
+		err = os.WriteFile(failingFile, []byte(failingInput), 0644)
+		if err != nil {
+			return 0, fmt.Errorf("writing failing input to file: "+
+				"%w", err)
+		}
+
+		if cr.cfg.Fuzz.BatchVerify {
+			batch = append(batch, reproduceInput{
+				issue: issue,
+				id:    fileHash,
+				path:  failingFile,
+			})
+			continue
+		}
+
+		// Run the fuzz test for this input and attempt to reproduce the
+		// crash.
+		testCmd := cr.engine.reproduceCmd(target, fileHash)
+
+		// Attempt to reproduce the crash by running the test inside a
+		// container. This allows us to enforce fixed resource limits
+		// and prevent interference with other workers, for example, if
+		// one worker encounters an out-of-memory error.
+		closed, err := cr.reproduceIssue(pkg, target, testCmd, issue)
+		if err != nil {
+			return 0, fmt.Errorf("reproducing issue %d: %w",
+				issue.number, err)
+		}
+		if closed {
+			openIssues--
+		}
+
+		// After verification, remove the failing input file to clean up
+		// and avoid leaving any potentially problematic test data.
+		if err := os.Remove(failingFile); err != nil {
+			return 0, fmt.Errorf("remove %q: %w", failingFile,
+				err)
+		}
+	}
+
+	if len(batch) > 0 {
+		closed, err := cr.reproduceIssueBatch(pkg, target, batch)
+		if err != nil {
+			return 0, err
+		}
+		openIssues -= closed
+
+		// Issues with the same failing input share its file, so it may
+		// already be removed.
+		for _, input := range batch {
+			err := os.Remove(input.path)
+			if err != nil && !os.IsNotExist(err) {
+				return 0, fmt.Errorf("remove %q: %w",
+					input.path, err)
+			}
+		}
+	}
+
+	return openIssues, nil
+}
+
+// reproduceInput is the failing input of an open issue, written to the
+// testdata directory of its fuzz target for verification.
+type reproduceInput struct {
+	issue *trackerIssue
+	id    string
+	path  string
+}
+
+// reproduceIssueBatch attempts to reproduce the crashes of the given issues of
+// a fuzz target by running all their failing inputs in a single container,
+// closing the issues that are no longer reproducible. Since a panicking input
+// aborts the run, the inputs left without a result are run again in a single
+// container, for as long as this makes progress. The inputs still left, e.g.
+// after a fatal error, or if the engine cannot run several inputs at once, are
+// then reproduced one per container. Returns the number of closed issues.
+func (cr *crashReporter) reproduceIssueBatch(pkg, target string,
+	inputs []reproduceInput) (int, error) {
+
+	closed := 0
+	for len(inputs) > 0 {
+		ids := make([]string, 0, len(inputs))
+		for _, input := range inputs {
+			ids = append(ids, input.id)
+		}
+		testCmd := cr.engine.batchReproduceCmd(target, ids)
+		if testCmd == nil {
+			break
+		}
+
+		results, err := cr.runReproduceBatch(pkg, target, testCmd)
+		if err != nil {
+			return 0, err
+		}
+
+		var pending []reproduceInput
+		for _, input := range inputs {
+			passed, ok := results[input.id]
+			switch {
+			case !ok:
+				pending = append(pending, input)
+
+			case passed:
+				err := cr.closeResolvedIssue(pkg, target,
+					input.issue)
+				if err != nil {
+					return 0, fmt.Errorf("verifying issue "+
+						"%d: %w",
+						input.issue.number, err)
+				}
+				closed++
+
+			default:
+				cr.logger.Info("Crash still reproducible; "+
+					"keeping issue open", "url",
+					input.issue.url)
+			}
+		}
+
+		if len(pending) == len(inputs) {
+			break
+		}
+		inputs = pending
+	}
+
+	for _, input := range inputs {
+		testCmd := cr.engine.reproduceCmd(target, input.id)
+		resolved, err := cr.reproduceIssue(pkg, target, testCmd,
+			input.issue)
+		if err != nil {
+			return 0, fmt.Errorf("reproducing issue %d: %w",
+				input.issue.number, err)
+		}
+		if resolved {
+			closed++
+		}
+	}
+
+	return closed, nil
+}
+
+// runReproduceBatch runs the given batch reproduce command for a fuzz target
+// in a verification container, and returns whether each input whose result is
+// reported passed, keyed by input ID.
+func (cr *crashReporter) runReproduceBatch(pkg, target string,
+	testCmd []string) (map[string]bool, error) {
+
+	c := cr.verificationContainer(pkg, target, testCmd)
+	containerID, err := c.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start verification "+
+			"container for %s/%s: %w", pkg, target, err)
+	}
+	defer func() {
+		if err := c.Stop(containerID); err != nil {
+			cr.logger.Error("Failed to stop container", "error",
+				err, "containerID", containerID)
+		}
+	}()
+
+	// The log stream ends once the container exits. Its exit status is
+	// not needed, since the output reports the result of each input.
+	logs, err := c.followLogs(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to attach to logs for "+
+			"container %s: %w", containerID, err)
+	}
+	defer func() {
+		if err := logs.Close(); err != nil {
+			cr.logger.Error("error closing logs reader",
+				"container", containerID, "error", err)
+		}
+	}()
+
+	return cr.engine.parseReproduceResults(logs, target)
+}
+
+// verificationContainer returns the container running the given command to
+// verify the open issues of a fuzz target.
+func (cr *crashReporter) verificationContainer(pkg, target string,
+	testCmd []string) *Container {
+
+	c := newContainer(cr.ctx, cr.logger, cr.cli, &cr.cfg.Fuzz, cr.engine,
+		pkg, target)
+	c.fuzzBinaryPath = filepath.Join(cr.cfg.Project.BinaryDir, pkg, target)
+	c.hostCorpusPath = filepath.Join(cr.cfg.Project.CorpusDir, pkg,
+		"testdata", "fuzz")
+	c.cmd = testCmd
+
+	return c
+}
+
+// reproduceIssue attempts to reproduce a reported fuzzing issue for a given
+// package and target. It runs the fuzz test inside a Docker container using the
+// provided test command. If the issue is no longer reproducible, the associated
+// issue will be closed automatically. Returns whether the issue was
+// closed.
+func (cr *crashReporter) reproduceIssue(pkg, target string, testCmd []string,
+	issue *trackerIssue) (bool, error) {
+
+	// Fuzzing container setup for the issue verification.
+	c := cr.verificationContainer(pkg, target, testCmd)
+
+	// Start the container for issue verification.
+	containerID, err := c.Start()
+	if err != nil {
+		return false, fmt.Errorf("failed to start verification "+
+			"container for %s/%s: %w", pkg, target, err)
+	}
+	defer func() {
+		if err := c.Stop(containerID); err != nil {
+			cr.logger.Error("Failed to stop container", "error",
+				err, "containerID", containerID)
+		}
+	}()
+
+	// After running the fuzzing container for this issue, if it crashes
+	// again (Wait returns an error), the crash is still reproducible and
+	// the issue is kept open. If the container exits cleanly, the
+	// crash is no longer reproducible and the corresponding issue
+	// is closed.
+	if err := c.Wait(containerID); err != nil {
+		cr.logger.Info("Crash still reproducible; keeping issue "+
+			"open", "url", issue.url)
+		return false, nil
+	}
+
+	if err := cr.closeResolvedIssue(pkg, target, issue); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// closeResolvedIssue closes the issue of a crash of a fuzz target that is no
+// longer reproducible, with the comment rendered from the configured template.
+func (cr *crashReporter) closeResolvedIssue(pkg, target string,
+	issue *trackerIssue) error {
+
+	cr.logger.Info("Crash no longer reproducible; closing associated "+
+		"issue", "url", issue.url)
+
+	// Render the closing comment from the configured template.
+	closeComment, err := formatCloseComment(
+		cr.cfg.Fuzz.CloseCommentTemplate, closeCommentData{
+			Package:   pkg,
+			Target:    target,
+			Signature: issueSignature(issue.title),
+			Commit:    headCommit(cr.cfg.Project.SrcDir),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("formatting close comment: %w", err)
+	}
+
+	// Close the issue if the crash is resolved
+	if err := cr.issues.closeIssue(issue.number, closeComment); err != nil {
+		return fmt.Errorf("closing issue: %w", err)
+	}
+	issuesClosed.Inc()
+	cr.notifier.emitEvent(cr.ctx, NotifyEventCrashClosed, pkg, target,
+		issueSignature(issue.title), issue.url)
+
+	return nil
+}
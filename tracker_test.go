@@ -0,0 +1,176 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCrashSignature verifies that crashes are deduplicated by the location of
+// their failure, or by their fingerprint if ClusterFuzz signatures are enabled,
+// except for out-of-memory crashes.
+func TestCrashSignature(t *testing.T) {
+	cr := &crashReporter{cfg: &Config{}}
+
+	first := fuzzCrash{
+		errorLogs:          indexPanicLogs,
+		failureFileAndLine: "complex.go:42",
+	}
+	second := fuzzCrash{
+		errorLogs:          "panic: unexpected token\n",
+		failureFileAndLine: "complex.go:42",
+	}
+
+	signature, fingerprint := cr.crashSignature(first)
+	assert.Equal(t, ComputeSHA256Short("complex.go:42"), signature)
+	assert.Nil(t, fingerprint)
+
+	other, _ := cr.crashSignature(second)
+	assert.Equal(t, signature, other)
+
+	cr.cfg.Fuzz.ClusterFuzzSignature = true
+	signature, fingerprint = cr.crashSignature(first)
+	assert.Equal(t, fingerprint.signature(), signature)
+	assert.Equal(t, "Index out of range", fingerprint.crashType)
+
+	other, _ = cr.crashSignature(second)
+	assert.NotEqual(t, signature, other)
+
+	// Out-of-memory crashes share a readable signature either way.
+	signature, fingerprint = cr.crashSignature(fuzzCrash{
+		errorLogs:          "fatal error: runtime: out of memory\n",
+		failureFileAndLine: OOMFailureLocation,
+	})
+	assert.Equal(t, OOMFailureLocation, signature)
+	assert.Nil(t, fingerprint)
+}
+
+// TestCrashCoverage verifies that crash reports hold the coverage of the target
+// from its latest coverage report, if any.
+func TestCrashCoverage(t *testing.T) {
+	reportDir := t.TempDir()
+	writeFiles(t, reportDir, map[string]string{
+		"targets/parser/FuzzEval.json": `[
+			{"Date": "2025-07-15", "Coverage": "72.5"},
+			{"Date": "2025-07-14", "Coverage": "70.0"}
+		]`,
+		"targets/parser/FuzzBroken.json": `not json`,
+	})
+
+	cr := &crashReporter{
+		logger: slog.New(slog.DiscardHandler),
+		cfg:    &Config{Project: Project{ReportDir: reportDir}},
+	}
+
+	assert.Equal(t, "## Target coverage\nThe target's latest coverage "+
+		"report, from 2025-07-15, measured 72.5% coverage.\n",
+		cr.crashCoverage("parser", "FuzzEval"))
+	assert.Contains(t, cr.crashCoverage("parser", "FuzzLex"),
+		"No coverage has been reported for this target yet.")
+	assert.Empty(t, cr.crashCoverage("parser", "FuzzBroken"))
+}
+
+// TestIssueFailingInput verifies that the failing input of an issue is parsed
+// from its full body when the body it was listed with is cut short, and that
+// truncated failing inputs and bodies exceeding the fetch limit are reported.
+func TestIssueFailingInput(t *testing.T) {
+	const title = "Fuzzing crash in parser/FuzzEval"
+	input := "go test fuzz v1\nstring(\"0\")\n"
+	issues := &fakeIssueClient{listedBodyLimit: 64}
+	cr := &crashReporter{
+		logger: slog.New(slog.DiscardHandler),
+		cfg:    &Config{Fuzz: Fuzz{IssueBodyFetchLimit: 4096}},
+		issues: issues,
+	}
+
+	create := func(body string) *trackerIssue {
+		_, err := issues.createIssue(title, body)
+		assert.NoError(t, err)
+		listed, err := issues.listIssues(title, IssueStateOpen)
+		assert.NoError(t, err)
+		return listed[len(listed)-1]
+	}
+
+	// The listed body is cut off within the failing testcase section.
+	issue := create(formatCrashReport("panic: boom\n", input, nil, ""))
+	_, err := parseIssueBody(issue.body)
+	assert.ErrorIs(t, err, errTestcaseTruncated)
+	got, err := cr.issueFailingInput(issue)
+	assert.NoError(t, err)
+	assert.Equal(t, input, got)
+
+	// An input truncated to fit the issue body limit is not reproduced.
+	issue = create(formatCrashReport("panic: boom\n",
+		truncateRunes(input, 8), nil, ""))
+	_, err = cr.issueFailingInput(issue)
+	assert.ErrorIs(t, err, errTestcaseTruncated)
+
+	issue = create(strings.Repeat("x", 64))
+	_, err = cr.issueFailingInput(issue)
+	assert.ErrorIs(t, err, errTestcaseNotFound)
+
+	issue = create(formatCrashReport(strings.Repeat("x", 4096), input,
+		nil, ""))
+	_, err = cr.issueFailingInput(issue)
+	assert.ErrorIs(t, err, errIssueBodyTooLarge)
+}
+
+// TestCrashTrackerFor verifies that crash repositories on gitlab.com default to
+// the GitLab tracker, and any other repository to the GitHub tracker.
+func TestCrashTrackerFor(t *testing.T) {
+	assert.Equal(t, CrashTrackerGitLab, crashTrackerFor("https://oauth2:"+
+		"secret@GitLab.com/group/project.git"))
+	assert.Equal(t, CrashTrackerGitHub, crashTrackerFor("https://oauth2:"+
+		"secret@github.com/OWNER/REPO.git"))
+	assert.Equal(t, CrashTrackerGitHub, crashTrackerFor("https://oauth2:"+
+		"secret@gitlab.example.com/group/project.git"))
+}
+
+// TestWarnShallowBlame verifies that a warning is only logged if recent commits
+// are included in crash issues of a shallow clone.
+func TestWarnShallowBlame(t *testing.T) {
+	tests := []struct {
+		cloneDepth int
+		blame      int
+		warn       bool
+	}{
+		{0, 0, false},
+		{0, 5, false},
+		{1, 0, false},
+		{1, 5, true},
+	}
+
+	for _, tc := range tests {
+		var logs strings.Builder
+		cfg := &Config{
+			Project: Project{CloneDepth: tc.cloneDepth},
+			Fuzz:    Fuzz{IssueIncludeBlame: tc.blame},
+		}
+		warnShallowBlame(slog.New(slog.NewTextHandler(&logs, nil)), cfg)
+		assert.Equal(t, tc.warn, strings.Contains(logs.String(),
+			"level=WARN"), "cloneDepth=%d blame=%d", tc.cloneDepth,
+			tc.blame)
+	}
+}
+
+// TestVerificationContainerLimits verifies that the containers verifying open
+// issues are limited like the fuzz containers of their target.
+func TestVerificationContainerLimits(t *testing.T) {
+	cr := &crashReporter{cfg: &Config{Fuzz: Fuzz{
+		ContainerMemory:   2 << 30,
+		ContainerNanoCPUs: 1_500_000_000,
+		TargetMemoryLimits: map[string]int64{
+			"parser/FuzzParse": 4 << 30,
+		},
+	}}}
+
+	c := cr.verificationContainer("parser", "FuzzParse", nil)
+	assert.Equal(t, int64(4<<30), c.memory)
+	assert.Equal(t, int64(1_500_000_000), c.nanoCPUs)
+
+	c = cr.verificationContainer("parser", "FuzzLex", nil)
+	assert.Equal(t, int64(2<<30), c.memory)
+	assert.Equal(t, int64(1_500_000_000), c.nanoCPUs)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
@@ -12,9 +13,20 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 	cp "github.com/otiai10/copy"
 )
 
@@ -22,28 +34,71 @@ const (
 	waterMark = "\n> _<small>Generated by [go-continuous-fuzz](https://" +
 		"github.com/go-continuous-fuzz/go-continuous-fuzz)</small>_"
 
+	// recurrenceMarker is the hidden marker identifying, along with the
+	// watermark, the comment tracking the recurrences of a crash.
+	recurrenceMarker = "<!-- go-continuous-fuzz:recurrence -->"
+
+	// commandWaitDelay is how long to wait for the output of a killed
+	// command to be closed before giving up on it.
+	commandWaitDelay = 5 * time.Second
+
+	// truncatedMarker is appended to error logs and failing inputs that
+	// were truncated to fit into the body of a crash issue.
+	truncatedMarker = "\n[... truncated ...]\n"
+
 	seedCorpusErrMsg = "Failure occurred while testing the seed corpus; " +
 		"please check the entries added via f.Add."
+
+	// defaultCloseComment is the comment posted when closing an issue whose
+	// crash is no longer reproducible, unless a custom template is set.
+	defaultCloseComment = "Fuzz crash no longer reproducible, closing " +
+		"the issue."
 )
 
-// cleanupTmpDirs deletes the project, corpus, reports, and binaries directory
-// to restart the fuzzing cycle.
-func cleanupTmpDirs(logger *slog.Logger, cfg *Config) {
-	if err := os.RemoveAll(cfg.Project.SrcDir); err != nil {
-		logger.Error("project cleanup failed", "error", err)
-	}
+// closeCommentData holds the values available to the issue close comment
+// template.
+type closeCommentData struct {
+	// Package is the package path of the fuzz target.
+	Package string
 
-	if err := os.RemoveAll(cfg.Project.CorpusDir); err != nil {
-		logger.Error("corpus cleanup failed", "error", err)
+	// Target is the name of the fuzz target.
+	Target string
+
+	// Signature is the crash signature from the issue title.
+	Signature string
+
+	// Commit is the hash of the commit in which the crash was verified as
+	// no longer reproducible.
+	Commit string
+}
+
+// cleanupTmpDirs deletes the project, reports, binaries, and coverage directory
+// to restart the fuzzing cycle. The project directory is kept if keepSrcDir is
+// set, so its checkout can be reused. The reports directory is only deleted if
+// it is going to be downloaded again. The corpus directory is always kept: it
+// is either merged with the downloaded corpus or the source of truth itself.
+func cleanupTmpDirs(logger *slog.Logger, cfg *Config, keepSrcDir bool) {
+	if !keepSrcDir {
+		if err := os.RemoveAll(cfg.Project.SrcDir); err != nil {
+			logger.Error("project cleanup failed", "error", err)
+		}
 	}
 
-	if err := os.RemoveAll(cfg.Project.ReportDir); err != nil {
-		logger.Error("reports cleanup failed", "error", err)
+	// The corpus is kept, so it can be merged with the downloaded corpus
+	// (if any), which avoids losing inputs that were never uploaded.
+	if cfg.Project.downloadsCorpus() {
+		if err := os.RemoveAll(cfg.Project.ReportDir); err != nil {
+			logger.Error("reports cleanup failed", "error", err)
+		}
 	}
 
 	if err := os.RemoveAll(cfg.Project.BinaryDir); err != nil {
 		logger.Error("binary cleanup failed", "error", err)
 	}
+
+	if err := os.RemoveAll(cfg.Project.CoverageDir); err != nil {
+		logger.Error("coverage cleanup failed", "error", err)
+	}
 }
 
 // cleanupWorkspace deletes the temp directory to reset the workspace state.
@@ -78,6 +133,23 @@ func EnsureDirExists(dirPath string) error {
 	return nil
 }
 
+// listFileNames returns the set of names of the regular files in the directory.
+func listFileNames(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names[entry.Name()] = true
+		}
+	}
+
+	return names, nil
+}
+
 // SanitizeURL parses the given raw URL string and returns a sanitized version
 // in which any user credentials (e.g., a GitHub Personal Access Token) are
 // replaced with a placeholder ("*****"). This ensures that sensitive
@@ -98,16 +170,110 @@ func SanitizeURL(rawURL string) string {
 	return parsed.String()
 }
 
+// secondaryRateLimitWait returns how long to wait before the given
+// (zero-based) retry of a request that hit GitHub's secondary rate limit. The
+// Retry-After duration is used if provided; otherwise the wait starts at
+// DefaultSecondaryRateLimitWait and doubles on every attempt, capped at
+// MaxSecondaryRateLimitWait.
+func secondaryRateLimitWait(retryAfter *time.Duration,
+	attempt int) time.Duration {
+
+	if retryAfter != nil {
+		return *retryAfter
+	}
+
+	return exponentialBackoff(DefaultSecondaryRateLimitWait,
+		MaxSecondaryRateLimitWait, attempt)
+}
+
+// exponentialBackoff returns the wait before the given (zero-based) retry of an
+// operation, starting at initial and doubling on every attempt, capped at
+// maxWait.
+func exponentialBackoff(initial, maxWait time.Duration,
+	attempt int) time.Duration {
+
+	wait := initial
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	return min(wait, maxWait)
+}
+
+// withPhaseTimeout returns a child context of ctx bounded by the given phase
+// timeout. A non-positive timeout disables the bound.
+func withPhaseTimeout(ctx context.Context,
+	timeout time.Duration) (context.Context, context.CancelFunc) {
+
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// phaseTimedOut reports whether phaseCtx, created by withPhaseTimeout from ctx,
+// expired because of its own timeout rather than ctx being done.
+func phaseTimedOut(ctx, phaseCtx context.Context) bool {
+	return ctx.Err() == nil &&
+		errors.Is(phaseCtx.Err(), context.DeadlineExceeded)
+}
+
+// cycleGracePeriod returns the default grace period granted on top of
+// syncFrequency for all workers to finish their tasks in a fuzzing cycle.
+func cycleGracePeriod(syncFrequency time.Duration) time.Duration {
+	return min(syncFrequency/3, 1*time.Hour)
+}
+
+// cycleWindow returns the sync frequency and grace period of a fuzzing cycle
+// starting now. They are the configured ones, unless the cycle would not end
+// before the given run deadline, if non-zero. The cycle is then shortened to
+// end at the deadline, keeping at most the grace period a cycle of its length
+// gets by default.
+func cycleWindow(syncFrequency, gracePeriod time.Duration,
+	runDeadline time.Time) (time.Duration, time.Duration) {
+
+	if runDeadline.IsZero() {
+		return syncFrequency, gracePeriod
+	}
+
+	remaining := max(time.Until(runDeadline), 0)
+	if syncFrequency+gracePeriod <= remaining {
+		return syncFrequency, gracePeriod
+	}
+
+	gracePeriod = min(gracePeriod, cycleGracePeriod(remaining))
+	return remaining - gracePeriod, gracePeriod
+}
+
 // calculateFuzzSeconds returns the per-target fuzz duration such that all fuzz
 // targets can be processed within the given syncFrequency. It calculates the
 // duration by dividing syncFrequency by the maximum number of tasks assigned to
-// any worker.
-func calculateFuzzSeconds(syncFrequency time.Duration, numWorkers int,
-	totalTargets int) time.Duration {
+// any worker, truncated to whole seconds.
+//
+// If the truncation leaves no time for a target, MinFuzzDuration is used
+// instead, as long as all targets still fit into syncFrequency plus the given
+// cycle grace period. Otherwise fuzzing is infeasible and zero is returned.
+func calculateFuzzSeconds(syncFrequency, gracePeriod time.Duration,
+	numWorkers int, totalTargets int) time.Duration {
+
+	if numWorkers <= 0 || totalTargets <= 0 || syncFrequency <= 0 {
+		return 0
+	}
 
 	tasksPerWorker := (totalTargets + numWorkers - 1) / numWorkers
-	perTargetSeconds := int(syncFrequency.Seconds()) / tasksPerWorker
-	return time.Duration(perTargetSeconds) * time.Second
+	perTarget := (syncFrequency / time.Duration(tasksPerWorker)).
+		Truncate(time.Second)
+	if perTarget >= MinFuzzDuration {
+		return perTarget
+	}
+
+	// Enforce the floor only if every worker can still fuzz all of its
+	// targets before the cycle deadline.
+	budget := syncFrequency + gracePeriod
+	if time.Duration(tasksPerWorker) > budget/MinFuzzDuration {
+		return 0
+	}
+
+	return MinFuzzDuration
 }
 
 // ComputeSHA256Short computes a SHA-256 hash of the error data(*.go:<line>),
@@ -117,6 +283,18 @@ func ComputeSHA256Short(errorData string) string {
 	return hex.EncodeToString(hash[:])[:16]
 }
 
+// issueTitleMatches reports whether an issue title matches the given title,
+// either exactly or preceded by a "[fuzz/<signature>] " prefix.
+func issueTitleMatches(issueTitle, title string) bool {
+	if issueTitle == title {
+		return true
+	}
+
+	prefix, found := strings.CutSuffix(issueTitle, " "+title)
+	return found && strings.HasPrefix(prefix, "[fuzz/") &&
+		strings.HasSuffix(prefix, "]")
+}
+
 // FileExistsInDir checks whether a file with the specified name exists
 // directly within the given directory.
 func FileExistsInDir(dirPath, fileName string) (bool, error) {
@@ -151,8 +329,12 @@ func extractRepo(srcURL string) (string, error) {
 }
 
 // formatCrashReport constructs a markdown-formatted report containing the error
-// logs, the failing test case, and a watermark.
-func formatCrashReport(failingLog, failingInputString string) string {
+// logs, the failing test case, the recent commits touching the crashing file
+// (if any), the note on where the full logs are stored (if any), and a
+// watermark.
+func formatCrashReport(failingLog, failingInputString string,
+	recentCommits []string, fullLogsNote string) string {
+
 	// Build the "Error logs" section.
 	logSection := fmt.Sprintf("## Error logs\n~~~sh\n%s~~~", failingLog)
 
@@ -167,11 +349,745 @@ func formatCrashReport(failingLog, failingInputString string) string {
 	failingTcSection := fmt.Sprintf("## Failing testcase\n~~~sh\n%s\n~~~",
 		failingInputString)
 
+	// Build the optional "Recent commits" section, listing the latest
+	// commits that touched the file where the crash occurred.
+	if len(recentCommits) > 0 {
+		failingTcSection += fmt.Sprintf("\n## Recent commits\n~~~sh\n"+
+			"%s\n~~~", strings.Join(recentCommits, "\n"))
+	}
+
+	// Build the optional "Full logs" section, pointing to the untruncated
+	// error logs and failing input.
+	if fullLogsNote != "" {
+		failingTcSection += fmt.Sprintf("\n## Full logs\n%s",
+			fullLogsNote)
+	}
+
 	// Combine sections with the watermark at the end.
 	return fmt.Sprintf("%s\n%s\n%s\n", logSection, failingTcSection,
 		waterMark)
 }
 
+// formatProgressSection constructs the markdown section of a crash report
+// holding the last progress line of the fuzzer before the crash, telling how
+// deep into fuzzing the crash was found.
+func formatProgressSection(progress string) string {
+	if progress == "" {
+		return "## Fuzzer progress\nNo progress was reported " +
+			"before the crash, so it was found in the seed " +
+			"corpus or right after fuzzing started.\n"
+	}
+
+	return fmt.Sprintf("## Fuzzer progress\nLast progress reported "+
+		"before the crash:\n~~~sh\n%s\n~~~\n", progress)
+}
+
+// formatCoverageSection constructs the markdown section of a crash report
+// holding the target's coverage from its latest coverage report, given its
+// coverage history, newest first. Low coverage suggests a shallow crash, while
+// high coverage suggests a subtler bug.
+func formatCoverageSection(history []TargetHistory) string {
+	if len(history) == 0 || history[0].Coverage == "" {
+		return "## Target coverage\nNo coverage has been reported " +
+			"for this target yet.\n"
+	}
+
+	return fmt.Sprintf("## Target coverage\nThe target's latest "+
+		"coverage report, from %s, measured %s%% coverage.\n",
+		history[0].Date, history[0].Coverage)
+}
+
+// formatUnknownFailureReport constructs a markdown-formatted report of a fuzz
+// container that exited with the given status without a recognized crash,
+// containing the tail of its output and a watermark. The output is cut at its
+// start, where it is least relevant, so the report has at most limit
+// characters.
+func formatUnknownFailureReport(statusCode int64, outputTail string,
+	limit int) string {
+
+	intro := fmt.Sprintf("The fuzz container exited with status %d "+
+		"without a recognized crash. It is not verified "+
+		"automatically, so close this issue once resolved.",
+		statusCode)
+
+	return formatOutputTailReport(intro, outputTail, limit)
+}
+
+// formatOOMReport constructs a markdown-formatted report of a fuzz container
+// that was killed for running out of memory, like formatUnknownFailureReport,
+// suggesting to raise the container's memory limit of memoryLimit bytes.
+func formatOOMReport(statusCode, memoryLimit int64, outputTail string,
+	limit int) string {
+
+	intro := fmt.Sprintf("The fuzz container was killed for running out "+
+		"of memory (status %d), exceeding its limit of %d MiB. "+
+		"Unless the target leaks memory, consider raising the "+
+		"container's memory limit or bounding the size of the "+
+		"inputs the target processes. It is not verified "+
+		"automatically, so close this issue once resolved.",
+		statusCode, memoryLimit/(1024*1024))
+
+	return formatOutputTailReport(intro, outputTail, limit)
+}
+
+// formatFlakyCoverageReport constructs a markdown-formatted report of a fuzz
+// target whose coverage bits varied across the given measurements of the same
+// corpus, advising to make the target deterministic.
+func formatFlakyCoverageReport(measurements []int) string {
+	bits := make([]string, len(measurements))
+	for i, m := range measurements {
+		bits[i] = strconv.Itoa(m)
+	}
+
+	return fmt.Sprintf("The coverage of the fuzz target varied across %d "+
+		"runs of the same corpus, measuring %s coverage bits. "+
+		"Coverage-guided fuzzing and corpus minimization rely on "+
+		"each input reaching the same coverage on every run, so "+
+		"inputs of a nondeterministic target are kept or dropped "+
+		"by chance. Make the target deterministic for a given input, "+
+		"e.g. by not depending on map iteration order, time, "+
+		"randomness or global state left over from previous inputs. "+
+		"It is not verified automatically, so close this issue once "+
+		"resolved.\n%s\n", len(measurements),
+		strings.Join(bits, ", "), waterMark)
+}
+
+// formatDisabledTargetReport constructs the body of the issue tracking a fuzz
+// target disabled for crashing early in the given number of consecutive
+// cycles, last with the crash of the issue at the given URL, if known.
+func formatDisabledTargetReport(cycles int, crashURL string) string {
+	crash := "the same crash"
+	if crashURL != "" {
+		crash = fmt.Sprintf("the crash of %s", crashURL)
+	}
+
+	return fmt.Sprintf("Fuzzing the target ended in a crash within its "+
+		"first executions in %d consecutive cycles, so it spent its "+
+		"time slot reporting %s rather than gaining coverage. The "+
+		"target is no longer fuzzed, but its open crash issues are "+
+		"still verified every cycle. Once they are all closed, "+
+		"either because their crashes no longer reproduce or "+
+		"manually, the target is re-enabled and this issue is "+
+		"closed.\n%s\n", cycles, crash, waterMark)
+}
+
+// formatReenableComment returns the comment posted when closing the issue of a
+// fuzz target disabled for crashing early, whose crashes are fixed at the given
+// commit of the project, which may be empty if unknown.
+func formatReenableComment(commit string) string {
+	at := "the latest commit"
+	if commit != "" {
+		at = fmt.Sprintf("commit %s", commit)
+	}
+
+	return fmt.Sprintf("The crashes of the fuzz target are fixed at %s, "+
+		"re-enabling it and closing the issue.\n%s", at, waterMark)
+}
+
+// formatOutputTailReport constructs a markdown-formatted report starting with
+// the given introduction, followed by the tail of a fuzz container's output
+// and a watermark. The output is cut at its start, where it is least relevant,
+// so the report has at most limit characters.
+func formatOutputTailReport(intro, outputTail string, limit int) string {
+	format := "%s\n## Output tail\n~~~sh\n%s\n~~~\n%s\n"
+
+	report := fmt.Sprintf(format, intro, outputTail, waterMark)
+	excess := utf8.RuneCountInString(report) - limit
+	if excess <= 0 {
+		return report
+	}
+
+	runes := []rune(outputTail)
+	cut := min(excess+utf8.RuneCountInString(truncatedMarker), len(runes))
+	outputTail = truncatedMarker + string(runes[cut:])
+
+	return fmt.Sprintf(format, intro, outputTail, waterMark)
+}
+
+// truncateCrashReport constructs the crash report like formatCrashReport, but
+// truncates the error logs and the failing input so the report has at most
+// limit characters. The available space is split evenly between the two, with
+// any space not needed by one of them given to the other. Both are truncated
+// at their end, since the start of the error logs holds the failure message.
+func truncateCrashReport(failingLog, failingInputString string,
+	recentCommits []string, fullLogsNote string, limit int) string {
+
+	report := formatCrashReport(failingLog, failingInputString,
+		recentCommits, fullLogsNote)
+	if utf8.RuneCountInString(report) <= limit {
+		return report
+	}
+
+	// Measure the report without the error logs and failing input, leaving
+	// room for the markers of both being truncated. An empty failing input
+	// is not truncated, since the seed corpus message replaces it.
+	overhead := utf8.RuneCountInString(formatCrashReport("", "",
+		recentCommits, fullLogsNote)) + 2*len(truncatedMarker)
+	if failingInputString != "" {
+		overhead -= utf8.RuneCountInString(seedCorpusErrMsg)
+	}
+	available := max(limit-overhead, 0)
+
+	logLen := utf8.RuneCountInString(failingLog)
+	inputLen := utf8.RuneCountInString(failingInputString)
+	inputBudget := min(inputLen, available/2)
+	logBudget := min(logLen, available-inputBudget)
+	inputBudget = min(inputLen, available-logBudget)
+
+	return formatCrashReport(truncateRunes(failingLog, logBudget),
+		truncateRunes(failingInputString, inputBudget), recentCommits,
+		fullLogsNote)
+}
+
+// truncateRunes returns s cut down to its first n characters, followed by
+// truncatedMarker if anything was cut.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:n]) + truncatedMarker
+}
+
+// formatReopenComment returns the comment posted when reopening the issue of a
+// crash that reproduced again at the given commit of the project, which may be
+// empty if unknown.
+func formatReopenComment(commit string) string {
+	at := "the latest commit"
+	if commit != "" {
+		at = fmt.Sprintf("commit %s", commit)
+	}
+
+	return fmt.Sprintf("Fuzz crash reproduced again at %s, reopening the "+
+		"issue.\n%s", at, waterMark)
+}
+
+// recurrenceCountRegex matches the number of times a crash was seen in the
+// comment tracking its recurrences.
+var recurrenceCountRegex = regexp.MustCompile(`Fuzz crash seen (\d+) times`)
+
+// formatRecurrenceComment returns the comment tracking the recurrences of a
+// crash, seen the given number of times in total, last at the given commit of
+// the project, which may be empty if unknown, and at the given time.
+func formatRecurrenceComment(count int, commit string,
+	seenAt time.Time) string {
+
+	at := "the latest commit"
+	if commit != "" {
+		at = fmt.Sprintf("commit %s", commit)
+	}
+
+	return fmt.Sprintf("%s\nFuzz crash seen %d times, last at %s on "+
+		"%s.\n%s", recurrenceMarker, count, at,
+		seenAt.UTC().Format(time.RFC1123), waterMark)
+}
+
+// isRecurrenceComment reports whether the comment with the given body is the
+// comment tracking the recurrences of a crash.
+func isRecurrenceComment(body string) bool {
+	return strings.Contains(body, recurrenceMarker) &&
+		strings.Contains(body, waterMark)
+}
+
+// parseRecurrenceCount returns the number of times a crash was seen according
+// to the body of the comment tracking its recurrences, or 1 (its first report)
+// if the count cannot be parsed.
+func parseRecurrenceCount(body string) int {
+	match := recurrenceCountRegex.FindStringSubmatch(body)
+	if match == nil {
+		return 1
+	}
+
+	count, err := strconv.Atoi(match[1])
+	if err != nil || count < 1 {
+		return 1
+	}
+
+	return count
+}
+
+// formatOrphanComment returns the comment posted when closing an issue of the
+// fuzz target of the given package that is no longer discovered.
+func formatOrphanComment(pkg, target string) string {
+	return fmt.Sprintf("Fuzz target %s/%s no longer exists, e.g. after "+
+		"being renamed or removed, so this crash can no longer be "+
+		"verified; closing the issue.\n%s", pkg, target, waterMark)
+}
+
+// formatCloseComment renders the comment posted when closing a resolved issue
+// from the given text/template, falling back to the default comment if the
+// template is empty. The watermark is always appended for identification.
+func formatCloseComment(tmplText string, data closeCommentData) (string,
+	error) {
+
+	if tmplText == "" {
+		return fmt.Sprintf("%s\n%s", defaultCloseComment,
+			waterMark), nil
+	}
+
+	tmpl, err := template.New("close-comment").Parse(tmplText)
+	if err != nil {
+		return "", fmt.Errorf("parse close comment template: %w", err)
+	}
+
+	var comment strings.Builder
+	if err := tmpl.Execute(&comment, data); err != nil {
+		return "", fmt.Errorf("execute close comment template: %w", err)
+	}
+
+	return fmt.Sprintf("%s\n%s", comment.String(), waterMark), nil
+}
+
+// issueSignature extracts the crash signature from an issue title of the form
+// "[fuzz/<signature>] Fuzzing crash in <pkg>/<target>". Returns an empty
+// string if the title carries no signature.
+func issueSignature(title string) string {
+	rest, found := strings.CutPrefix(title, "[fuzz/")
+	if !found {
+		return ""
+	}
+
+	signature, _, found := strings.Cut(rest, "]")
+	if !found {
+		return ""
+	}
+
+	return signature
+}
+
+// cloneProjectOnce clones the project repository into cfg.Project.SrcDir at
+// cfg.Project.Ref, or at the remote's HEAD if unset, fetching only the last
+// cfg.Project.CloneDepth commits if positive. With either set, only the history
+// of the checked out reference is fetched.
+func cloneProjectOnce(ctx context.Context, cfg *Config) (*git.Repository,
+	error) {
+
+	opts := &git.CloneOptions{
+		URL:          cfg.Project.SrcRepo,
+		Depth:        cfg.Project.CloneDepth,
+		SingleBranch: cfg.Project.CloneDepth > 0,
+	}
+
+	if cfg.Project.Ref != "" {
+		name, _, err := resolveRemoteRef(ctx, cfg.Project.SrcRepo,
+			cfg.Project.Ref)
+		if err != nil {
+			return nil, err
+		}
+		opts.ReferenceName = name
+		opts.SingleBranch = true
+	}
+
+	return git.PlainCloneContext(ctx, cfg.Project.SrcDir, false, opts)
+}
+
+// updateClone updates the clone of the project repository kept in
+// cfg.Project.SrcDir by a previous cycle, like "git fetch" followed by "git
+// reset --hard origin/<ref>": cfg.Project.Ref, or the remote's HEAD if unset,
+// is fetched from cfg.Project.SrcRepo, fetching only its last
+// cfg.Project.CloneDepth commits if positive, and checked out, discarding the
+// changes and untracked files of the previous cycle. Returns an error if the
+// directory holds no valid clone or updating it fails.
+func updateClone(ctx context.Context, cfg *Config) (*git.Repository, error) {
+	repo, err := git.PlainOpen(cfg.Project.SrcDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open clone: %w", err)
+	}
+
+	name, _, err := resolveRemoteRef(ctx, cfg.Project.SrcRepo,
+		cfg.Project.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like a clone, branches are fetched into their remote-tracking
+	// branch, while tags are fetched as is.
+	fetched := name
+	if name.IsBranch() {
+		fetched = plumbing.NewRemoteReferenceName(
+			git.DefaultRemoteName, name.Short())
+	}
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteURL: cfg.Project.SrcRepo,
+		RefSpecs: []gitconfig.RefSpec{
+			gitconfig.RefSpec(fmt.Sprintf("+%s:%s", name, fetched)),
+		},
+		Depth: cfg.Project.CloneDepth,
+		Tags:  git.NoTags,
+		Force: true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+
+	commit, err := repo.ResolveRevision(plumbing.Revision(fetched))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", fetched,
+			err)
+	}
+
+	// Check out the fetched branch, or detach HEAD at the fetched tag.
+	head := plumbing.NewHashReference(plumbing.HEAD, *commit)
+	if name.IsBranch() {
+		err := repo.Storer.SetReference(plumbing.NewHashReference(name,
+			*commit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", name,
+				err)
+		}
+		head = plumbing.NewSymbolicReference(plumbing.HEAD, name)
+	}
+	if err := repo.Storer.SetReference(head); err != nil {
+		return nil, fmt.Errorf("failed to update HEAD: %w", err)
+	}
+
+	if err := resetCheckout(repo); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// cloneWithRetries clones the project repository into the source directory,
+// retrying up to the configured number of times with an exponentially
+// increasing wait, e.g. on transient DNS or network errors. Failures that
+// retrying cannot fix, i.e. rejected credentials, missing repositories and
+// references, and the cancellation of the context are returned right away.
+// Once the retries are exhausted, the error of the last attempt is returned.
+func cloneWithRetries(ctx context.Context, logger *slog.Logger,
+	cfg *Config) (*git.Repository, error) {
+
+	for attempt := 0; ; attempt++ {
+		repo, err := cloneProjectOnce(ctx, cfg)
+		if err == nil {
+			return repo, nil
+		}
+		if attempt >= cfg.Project.CloneRetries ||
+			!retryableCloneError(ctx, err) {
+
+			return nil, err
+		}
+
+		wait := exponentialBackoff(DefaultCloneRetryWait,
+			MaxCloneRetryWait, attempt)
+		logger.Warn("Failed to clone project repository; retrying",
+			"attempt", attempt+1, "wait", wait, "error", err)
+
+		// A failed clone may leave a partial repository behind, which
+		// the next attempt cannot clone into.
+		if err := os.RemoveAll(cfg.Project.SrcDir); err != nil {
+			return nil, fmt.Errorf("failed to remove partial "+
+				"clone: %w", err)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// retryableCloneError reports whether the clone that failed with the given
+// error is worth retrying: rejected credentials, missing repositories and
+// references, and the cancellation of the context are final.
+func retryableCloneError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for _, final := range []error{
+		context.Canceled,
+		context.DeadlineExceeded,
+		transport.ErrAuthenticationRequired,
+		transport.ErrAuthorizationFailed,
+		transport.ErrInvalidAuthMethod,
+		transport.ErrRepositoryNotFound,
+		transport.ErrEmptyRemoteRepository,
+		errRemoteRefNotFound,
+	} {
+		if errors.Is(err, final) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkedOutBranch returns the name of the branch checked out in the given
+// freshly cloned or updated repository. Unless another reference is selected,
+// this is the branch of the remote's HEAD, i.e. its default branch. Returns an
+// error if HEAD cannot be resolved or is not a branch, e.g. because the
+// remote's HEAD is detached.
+func checkedOutBranch(repo *git.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is not a branch: %s", head.Name())
+	}
+
+	return head.Name().Short(), nil
+}
+
+// errRemoteRefNotFound is returned when the remote repository has no
+// reference with the requested name.
+var errRemoteRefNotFound = errors.New("remote reference not found")
+
+// resolveRemoteRef returns the full name of the reference of the remote
+// repository at repoURL named by ref, and the commit it points to. ref is a
+// branch or tag name, branches taking precedence, or a full reference name; if
+// empty, the remote's HEAD is resolved. Like "git ls-remote", it only lists the
+// remote's references, without fetching any objects.
+func resolveRemoteRef(ctx context.Context, repoURL,
+	ref string) (plumbing.ReferenceName, plumbing.Hash, error) {
+
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoURL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("failed to list "+
+			"remote references: %w", err)
+	}
+
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference)
+	for _, r := range refs {
+		byName[r.Name()] = r
+	}
+
+	candidates := []plumbing.ReferenceName{plumbing.HEAD}
+	switch {
+	case strings.HasPrefix(ref, "refs/"):
+		candidates = []plumbing.ReferenceName{
+			plumbing.ReferenceName(ref),
+		}
+
+	case ref != "":
+		candidates = []plumbing.ReferenceName{
+			plumbing.NewBranchReferenceName(ref),
+			plumbing.NewTagReferenceName(ref),
+		}
+	}
+
+	for _, name := range candidates {
+		r, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		// HEAD is usually a symbolic reference to the default branch,
+		// so follow it to the branch's commit.
+		for ok && r.Type() == plumbing.SymbolicReference {
+			r, ok = byName[r.Target()]
+		}
+		if !ok {
+			break
+		}
+
+		// Annotated tags point to a tag object, and remotes advertising
+		// peeled references list the commit they point to as such.
+		if peeled, ok := byName[r.Name()+"^{}"]; ok {
+			return r.Name(), peeled.Hash(), nil
+		}
+
+		return r.Name(), r.Hash(), nil
+	}
+
+	if ref == "" {
+		ref = plumbing.HEAD.String()
+	}
+	return "", plumbing.ZeroHash, fmt.Errorf("%w: %s",
+		errRemoteRefNotFound, ref)
+}
+
+// resetCheckout restores the worktree of the given repository to its HEAD
+// commit, reverting modified files and removing untracked ones, such as the
+// corpus files copied into it to generate coverage reports.
+func resetCheckout(repo *git.Repository) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	err = wt.Reset(&git.ResetOptions{Mode: git.HardReset})
+	if err != nil {
+		return fmt.Errorf("failed to reset worktree: %w", err)
+	}
+
+	if err := wt.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("failed to clean worktree: %w", err)
+	}
+
+	return nil
+}
+
+// headCommit returns the hash of the HEAD commit of the git repository located
+// at repoDir, or an empty string if it cannot be determined.
+func headCommit(repoDir string) string {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return ""
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+
+	return head.Hash().String()
+}
+
+// resolveRepoFile maps the "<file>.go:<line>" location of a crash to the path
+// of the file relative to the project root in srcDir. The file may either be
+// an absolute path taken from a stack trace or a bare file name reported by
+// the testing package, in which case it is looked up inside the package.
+// Returns an empty string if the file cannot be located in the project.
+func resolveRepoFile(srcDir, pkg, fileAndLine string) string {
+	file := fileAndLine
+	if idx := strings.LastIndex(fileAndLine, ":"); idx != -1 {
+		file = fileAndLine[:idx]
+	}
+	file = strings.TrimSpace(file)
+	if file == "" {
+		return ""
+	}
+
+	// Stack traces contain absolute paths of the files on the host, since
+	// the fuzz binaries are built from the cloned project.
+	if filepath.IsAbs(file) {
+		relPath, err := filepath.Rel(srcDir, file)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return ""
+		}
+		return filepath.ToSlash(relPath)
+	}
+
+	// Errors reported via t.Errorf only contain the file name, so look for
+	// it inside the package directory.
+	relPath := filepath.Join(pkg, file)
+	if _, err := os.Stat(filepath.Join(srcDir, relPath)); err != nil {
+		return ""
+	}
+
+	return filepath.ToSlash(relPath)
+}
+
+// recentCommits returns up to limit of the most recent commits touching the
+// given file in the git repository located at repoDir. Each commit is
+// formatted as "<short hash> <author> <subject>". In a shallow clone, only the
+// commits of the cloned history are returned.
+func recentCommits(repoDir, file string, limit int) ([]string, error) {
+	repo, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository %q: %w", repoDir,
+			err)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{FileName: &file})
+	if err != nil {
+		return nil, fmt.Errorf("reading git log for %q: %w", file, err)
+	}
+	defer iter.Close()
+
+	var commits []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) >= limit {
+			return storer.ErrStop
+		}
+
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		commits = append(commits, fmt.Sprintf("%s %s %s",
+			c.Hash.String()[:12], c.Author.Name, subject))
+		return nil
+	})
+
+	// The parents of the oldest commits of a shallow clone are missing,
+	// which ends the history.
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("iterating git log for %q: %w", file,
+			err)
+	}
+
+	return commits, nil
+}
+
+// runCycleHook runs the given cycle hook as a shell command, bounded by the
+// hook timeout, with the cycle number and the workspace directories passed in
+// GCF_* environment variables. The hook's output is logged. Returns an error
+// if the hook fails, times out, or exits with a non-zero status.
+func runCycleHook(ctx context.Context, logger *slog.Logger, cfg *Config,
+	name, hook string, cycle int) error {
+
+	logger.Info("Running cycle hook", "hook", name, "cycle", cycle)
+
+	hookCtx, cancel := withPhaseTimeout(ctx, cfg.Fuzz.HookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := runCommand(hookCtx, "", "sh", []string{"-c", hook},
+		fmt.Sprintf("GCF_CYCLE=%d", cycle),
+		fmt.Sprintf("GCF_SRC_DIR=%s", cfg.Project.SrcDir),
+		fmt.Sprintf("GCF_CORPUS_DIR=%s", cfg.Project.CorpusDir),
+		fmt.Sprintf("GCF_REPORT_DIR=%s", cfg.Project.ReportDir))
+	if phaseTimedOut(ctx, hookCtx) {
+		return fmt.Errorf("%s hook timed out after %s", name,
+			cfg.Fuzz.HookTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+
+	logger.Info("Cycle hook completed", "hook", name, "cycle", cycle,
+		"elapsed", time.Since(start), "output", output)
+
+	return nil
+}
+
+// runCorpusHook runs the given corpus loader or saver of the target as a shell
+// command in the package's source directory, bounded by the hook timeout, with
+// the target and its directories passed in GCF_* environment variables. The
+// hook's output is logged. Returns an error if the hook fails, times out, or
+// exits with a non-zero status.
+func runCorpusHook(ctx context.Context, logger *slog.Logger, cfg *Config,
+	name, hook, pkg, target string) error {
+
+	logger.Info("Running corpus hook", "hook", name, "package", pkg,
+		"target", target)
+
+	hookCtx, cancel := withPhaseTimeout(ctx, cfg.Fuzz.HookTimeout)
+	defer cancel()
+
+	pkgDir := filepath.Join(cfg.Project.SrcDir, pkg)
+	targetCorpusDir := filepath.Join(cfg.Project.CorpusDir, pkg,
+		"testdata", "fuzz", target)
+
+	start := time.Now()
+	output, err := runCommand(hookCtx, pkgDir, "sh", []string{"-c", hook},
+		fmt.Sprintf("GCF_PACKAGE=%s", pkg),
+		fmt.Sprintf("GCF_TARGET=%s", target),
+		fmt.Sprintf("GCF_PKG_DIR=%s", pkgDir),
+		fmt.Sprintf("GCF_TARGET_CORPUS_DIR=%s", targetCorpusDir))
+	if phaseTimedOut(ctx, hookCtx) {
+		return fmt.Errorf("%s timed out after %s", name,
+			cfg.Fuzz.HookTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	logger.Info("Corpus hook completed", "hook", name, "package", pkg,
+		"target", target, "elapsed", time.Since(start), "output",
+		output)
+
+	return nil
+}
+
 // runGoCommand executes a `go` command with the given arguments in the
 // specified working directory. It appends any additional environment variables
 // provided via extraEnv to the current environment and returns the standard
@@ -179,22 +1095,67 @@ func formatCrashReport(failingLog, failingInputString string) string {
 func runGoCommand(ctx context.Context, workDir string, args []string,
 	extraEnv ...string) (string, error) {
 
-	cmd := exec.CommandContext(ctx, "go", args...)
+	return runCommand(ctx, workDir, "go", args, extraEnv...)
+}
+
+// runCommand executes the named command with the given arguments in the
+// specified working directory. It appends any additional environment variables
+// provided via extraEnv to the current environment and returns the standard
+// output as a string or an error if the command fails.
+func runCommand(ctx context.Context, workDir, name string, args []string,
+	extraEnv ...string) (string, error) {
+
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = workDir
 
+	// Once the context is done and the command killed, stop waiting for
+	// its output after a while, since children it spawned may still hold
+	// the output pipes open.
+	cmd.WaitDelay = commandWaitDelay
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	cmd.Env = append(os.Environ(), extraEnv...)
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("go command failed: %w\nStderr: %s", err,
-			stderr.String())
+		return "", fmt.Errorf("%s command failed: %w\nStderr: %s", name,
+			err, stderr.String())
 	}
 
 	return stdout.String(), nil
 }
 
+// copyTargetTestdata copies the testdata directory of the package at pkgPath
+// into destPath for the given fuzz target, leaving out the seed corpora of the
+// package's other fuzz targets under testdata/fuzz. The workers clean up the
+// seed corpus of the target they fuzz in the shared project checkout, e.g.
+// when minimizing its corpus, so copying only the target's own seed corpus
+// cannot race with the cleanup of the targets fuzzed by other workers. If the
+// package has no testdata directory, no error is returned.
+func copyTargetTestdata(pkgPath, destPath, target string) error {
+	srcPath := filepath.Join(pkgPath, "testdata")
+	if _, err := os.Stat(srcPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot stat src path %q: %w", srcPath, err)
+	}
+
+	fuzzDir := filepath.Join(srcPath, "fuzz")
+	err := cp.Copy(srcPath, destPath, cp.Options{
+		Skip: func(_ os.FileInfo, src, _ string) (bool, error) {
+			return src == fuzzDir, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return copyData(filepath.Join(fuzzDir, target),
+		filepath.Join(destPath, "fuzz", target))
+}
+
 // copyData copies the contents of the src path into the dest path.
 // The contents of the source path are recursively copied into the dest.
 // If the src path is missing, no error is returned.
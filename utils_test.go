@@ -1,9 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,14 +71,75 @@ func TestCalculateFuzzSeconds(t *testing.T) {
 	syncFrequency, err := time.ParseDuration("3h37m53s")
 	assert.NoError(t, err, "failed to parse syncFrequency")
 
-	actualDuration := calculateFuzzSeconds(syncFrequency, totalWorkers,
-		totalTargets)
+	actualDuration := calculateFuzzSeconds(syncFrequency,
+		cycleGracePeriod(syncFrequency), totalWorkers, totalTargets)
 
 	assert.Equal(t, expectedDuration, actualDuration,
 		"calculated fuzz duration does not match expected value",
 	)
 }
 
+// FuzzCalculateFuzzSeconds verifies the invariants of the per-target fuzz
+// duration across a wide range of sync frequencies, worker counts, and target
+// counts:
+//   - The time allocated to the busiest worker never exceeds the sync
+//     frequency plus the cycle grace period.
+//   - No target gets zero time unless fuzzing all targets is infeasible even
+//     with MinFuzzDuration per target.
+//   - The duration is a whole number of seconds.
+func FuzzCalculateFuzzSeconds(f *testing.F) {
+	f.Add(int64(3*time.Hour+37*time.Minute+53*time.Second), 7, 43)
+	f.Add(int64(24*time.Hour), 1, 1)
+	f.Add(int64(30*time.Second), 4, 100)
+	f.Add(int64(time.Minute), 1, 61)
+	f.Add(int64(time.Minute), 1, 81)
+	f.Add(int64(1500*time.Millisecond), 2, 3)
+	f.Add(int64(0), 1, 1)
+
+	f.Fuzz(func(t *testing.T, syncNanos int64, numWorkers,
+		totalTargets int) {
+
+		// Bound the inputs to realistic configurations.
+		if syncNanos < 0 || syncNanos > int64(365*24*time.Hour) ||
+			numWorkers <= 0 || numWorkers > 1024 ||
+			totalTargets <= 0 || totalTargets > 100_000 {
+
+			t.Skip()
+		}
+
+		syncFrequency := time.Duration(syncNanos)
+		gracePeriod := cycleGracePeriod(syncFrequency)
+		perTarget := calculateFuzzSeconds(syncFrequency, gracePeriod,
+			numWorkers, totalTargets)
+
+		tasksPerWorker := time.Duration((totalTargets + numWorkers -
+			1) / numWorkers)
+		budget := syncFrequency + gracePeriod
+
+		assert.Zero(t, perTarget%time.Second, "duration %s is not "+
+			"whole seconds", perTarget)
+		assert.LessOrEqual(t, tasksPerWorker*perTarget, budget,
+			"allocated time exceeds sync frequency plus grace")
+
+		if perTarget == 0 {
+			assert.Greater(t, tasksPerWorker*MinFuzzDuration,
+				budget, "zero duration although feasible")
+		} else {
+			assert.GreaterOrEqual(t, perTarget, MinFuzzDuration)
+		}
+	})
+}
+
+// BenchmarkCalculateFuzzSeconds measures the cost of computing the per-target
+// fuzz duration.
+func BenchmarkCalculateFuzzSeconds(b *testing.B) {
+	syncFrequency := 3*time.Hour + 37*time.Minute + 53*time.Second
+	for b.Loop() {
+		calculateFuzzSeconds(syncFrequency,
+			cycleGracePeriod(syncFrequency), 7, 43)
+	}
+}
+
 // TestComputeSHA256Short verifies that ComputeSHA256Short correctly computes a
 // short SHA256 hash based on the error data. This test ensures that
 // deduplication logic based on this hash remains stable and predictable.
@@ -133,6 +206,8 @@ func TestFormatCrashReport(t *testing.T) {
 		name               string
 		failingLog         string
 		failingInputString string
+		recentCommits      []string
+		fullLogsNote       string
 		expectedReport     string
 	}{
 		{
@@ -160,13 +235,914 @@ func TestFormatCrashReport(t *testing.T) {
 				"~~~sh\n" + seedCorpusErrMsg +
 				"\n~~~\n" + waterMark + "\n",
 		},
+		{
+			name:               "with recent commits",
+			failingLog:         "--- FAIL: FuzzParseComplex\n",
+			failingInputString: "go test fuzz v1\nstring(\"0\")\n",
+			recentCommits: []string{
+				"0123456789ab Alice fix parser",
+				"ba9876543210 Bob add parser",
+			},
+			expectedReport: "## Error logs\n" +
+				"~~~sh\n" +
+				"--- FAIL: FuzzParseComplex\n" +
+				"~~~\n" +
+				"## Failing testcase\n" +
+				"~~~sh\n" +
+				"go test fuzz v1\n" +
+				"string(\"0\")\n\n" +
+				"~~~\n" +
+				"## Recent commits\n" +
+				"~~~sh\n" +
+				"0123456789ab Alice fix parser\n" +
+				"ba9876543210 Bob add parser\n" +
+				"~~~\n" + waterMark + "\n",
+		},
+		{
+			name:               "with full logs note",
+			failingLog:         "--- FAIL: FuzzParseComplex\n",
+			failingInputString: "go test fuzz v1\nstring(\"0\")\n",
+			fullLogsNote:       "- [Error logs](s3://bucket/log)",
+			expectedReport: "## Error logs\n" +
+				"~~~sh\n" +
+				"--- FAIL: FuzzParseComplex\n" +
+				"~~~\n" +
+				"## Failing testcase\n" +
+				"~~~sh\n" +
+				"go test fuzz v1\n" +
+				"string(\"0\")\n\n" +
+				"~~~\n" +
+				"## Full logs\n" +
+				"- [Error logs](s3://bucket/log)\n" +
+				waterMark + "\n",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			report := formatCrashReport(tt.failingLog,
-				tt.failingInputString)
+				tt.failingInputString, tt.recentCommits,
+				tt.fullLogsNote)
 			assert.Equal(t, tt.expectedReport, report)
 		})
 	}
 }
+
+// TestTruncateCrashReport verifies that truncateCrashReport keeps reports
+// within the limit untouched, and otherwise truncates the error logs and
+// failing input so the report fits into the limit, giving the space not needed
+// by a short failing input to the error logs.
+func TestTruncateCrashReport(t *testing.T) {
+	const limit = MinIssueBodyLimit
+	note := "- [Error logs](s3://bucket/log)"
+	longLog := strings.Repeat("goroutine 1 [running]:\n", 1000)
+	longInput := strings.Repeat("ü", 3*limit)
+
+	tests := []struct {
+		name           string
+		failingLog     string
+		failingInput   string
+		truncatedLog   bool
+		truncatedInput bool
+	}{
+		{
+			name:         "within limit",
+			failingLog:   "--- FAIL: FuzzParseComplex\n",
+			failingInput: "go test fuzz v1\nstring(\"0\")\n",
+		},
+		{
+			name:         "long error logs",
+			failingLog:   longLog,
+			failingInput: "go test fuzz v1\nstring(\"0\")\n",
+			truncatedLog: true,
+		},
+		{
+			name:           "long logs and input",
+			failingLog:     longLog,
+			failingInput:   longInput,
+			truncatedLog:   true,
+			truncatedInput: true,
+		},
+		{
+			name:         "long logs of seed corpus crash",
+			failingLog:   longLog,
+			truncatedLog: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := truncateCrashReport(tt.failingLog,
+				tt.failingInput, nil, note, limit)
+			assert.LessOrEqual(t, utf8.RuneCountInString(report),
+				limit)
+			assert.Contains(t, report, note)
+
+			if !tt.truncatedLog && !tt.truncatedInput {
+				assert.Equal(t, formatCrashReport(
+					tt.failingLog, tt.failingInput, nil,
+					note), report)
+				return
+			}
+
+			assert.Equal(t, tt.truncatedLog,
+				!strings.Contains(report, tt.failingLog))
+			assert.Equal(t, tt.truncatedInput,
+				tt.failingInput != "" &&
+					!strings.Contains(report,
+						tt.failingInput))
+
+			markers := 0
+			if tt.truncatedLog {
+				markers++
+			}
+			if tt.truncatedInput {
+				markers++
+			}
+			assert.Equal(t, markers, strings.Count(report,
+				truncatedMarker))
+
+			// The space left over by a short failing input is used
+			// for the error logs, so the report fills the limit,
+			// except for the room reserved for the markers.
+			assert.GreaterOrEqual(t, utf8.RuneCountInString(report),
+				limit-2*len(truncatedMarker))
+		})
+	}
+}
+
+// TestFormatUnknownFailureReport verifies that the unknown failure report holds
+// the exit status and output tail, which is cut at its start to fit the limit.
+func TestFormatUnknownFailureReport(t *testing.T) {
+	report := formatUnknownFailureReport(137, "line 1\nline 2", 4096)
+	assert.Contains(t, report, "exited with status 137")
+	assert.Contains(t, report, "## Output tail\n~~~sh\nline 1\nline 2\n~~~")
+	assert.True(t, strings.HasSuffix(report, waterMark+"\n"))
+
+	full := formatUnknownFailureReport(1, strings.Repeat("x", 50)+"end",
+		4096)
+	limit := utf8.RuneCountInString(full) - 20
+	report = formatUnknownFailureReport(1, strings.Repeat("x", 50)+"end",
+		limit)
+	assert.Equal(t, limit, utf8.RuneCountInString(report))
+	assert.Contains(t, report, truncatedMarker+strings.Repeat("x", 30-
+		len(truncatedMarker))+"end\n~~~")
+}
+
+// TestFormatOOMReport verifies that the report of a fuzz container killed for
+// running out of memory suggests raising its memory limit and is cut like
+// unknown failure reports.
+func TestFormatOOMReport(t *testing.T) {
+	report := formatOOMReport(137, ContainerMemoryLimit,
+		"line 1\nline 2", 4096)
+	assert.Contains(t, report, "out of memory (status 137), exceeding "+
+		"its limit of 2048 MiB")
+
+	report = formatOOMReport(137, 8<<30, "line 1\nline 2", 4096)
+	assert.Contains(t, report, "exceeding its limit of 8192 MiB")
+	assert.Contains(t, report, "raising the container's memory limit")
+	assert.Contains(t, report, "## Output tail\n~~~sh\nline 1\nline 2\n~~~")
+
+	output := strings.Repeat("x", 50) + "end"
+	full := formatOOMReport(137, ContainerMemoryLimit, output, 4096)
+	limit := utf8.RuneCountInString(full) - 20
+	report = formatOOMReport(137, ContainerMemoryLimit, output, limit)
+	assert.Equal(t, limit, utf8.RuneCountInString(report))
+	assert.True(t, strings.HasSuffix(report, waterMark+"\n"))
+}
+
+// TestFormatFlakyCoverageReport verifies that the report of a target with
+// unstable coverage lists its measurements and advises to make it
+// deterministic.
+func TestFormatFlakyCoverageReport(t *testing.T) {
+	report := formatFlakyCoverageReport([]int{120, 118, 120})
+	assert.Contains(t, report, "varied across 3 runs of the same corpus, "+
+		"measuring 120, 118, 120 coverage bits")
+	assert.Contains(t, report, "Make the target deterministic")
+	assert.True(t, strings.HasSuffix(report, waterMark+"\n"))
+}
+
+// TestFormatDisabledTargetReport verifies that the report of a target disabled
+// for crashing early tells for how many cycles, links its crash if known, and
+// explains how it is re-enabled.
+func TestFormatDisabledTargetReport(t *testing.T) {
+	url := "https://github.com/owner/repo/issues/7"
+	report := formatDisabledTargetReport(3, url)
+	assert.Contains(t, report, "in 3 consecutive cycles")
+	assert.Contains(t, report, "reporting the crash of "+url)
+	assert.Contains(t, report, "the target is re-enabled")
+	assert.True(t, strings.HasSuffix(report, waterMark+"\n"))
+
+	report = formatDisabledTargetReport(2, "")
+	assert.Contains(t, report, "reporting the same crash")
+}
+
+// TestResolveRepoFile verifies that resolveRepoFile maps crash locations from
+// both stack traces and testing error output to paths inside the project.
+func TestResolveRepoFile(t *testing.T) {
+	srcDir := t.TempDir()
+	pkgDir := filepath.Join(srcDir, "parser")
+	assert.NoError(t, os.MkdirAll(pkgDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgDir,
+		"parser_test.go"), []byte("package parser"), 0o644))
+
+	tests := []struct {
+		name         string
+		fileAndLine  string
+		expectedPath string
+	}{
+		{
+			name:         "bare file name from testing output",
+			fileAndLine:  "parser_test.go:17",
+			expectedPath: "parser/parser_test.go",
+		},
+		{
+			name: "absolute path from stack trace",
+			fileAndLine: filepath.Join(pkgDir, "parser.go") +
+				":42",
+			expectedPath: "parser/parser.go",
+		},
+		{
+			name: "file outside of the project",
+			fileAndLine: "/usr/local/go/src/testing/fuzz.go" +
+				":322",
+			expectedPath: "",
+		},
+		{
+			name:         "file missing from the package",
+			fileAndLine:  "missing_test.go:3",
+			expectedPath: "",
+		},
+		{
+			name:         "empty location",
+			fileAndLine:  "",
+			expectedPath: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveRepoFile(srcDir, "parser", tt.fileAndLine)
+			assert.Equal(t, tt.expectedPath, got)
+		})
+	}
+}
+
+// TestIssueTitleMatches verifies that issue titles of same-named fuzz targets
+// in different packages are never confused with each other.
+func TestIssueTitleMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		issueTitle string
+		title      string
+		expected   bool
+	}{
+		{
+			name: "signature prefixed title",
+			issueTitle: "[fuzz/cfec419a119b189c] Fuzzing crash " +
+				"in parser/FuzzFoo",
+			title:    "Fuzzing crash in parser/FuzzFoo",
+			expected: true,
+		},
+		{
+			name: "exact title",
+			issueTitle: "[fuzz/cfec419a119b189c] Fuzzing crash " +
+				"in parser/FuzzFoo",
+			title: "[fuzz/cfec419a119b189c] Fuzzing crash in " +
+				"parser/FuzzFoo",
+			expected: true,
+		},
+		{
+			name: "same target in a nested package",
+			issueTitle: "[fuzz/cfec419a119b189c] Fuzzing crash " +
+				"in x/parser/FuzzFoo",
+			title:    "Fuzzing crash in parser/FuzzFoo",
+			expected: false,
+		},
+		{
+			name: "same target in a parent package",
+			issueTitle: "[fuzz/cfec419a119b189c] Fuzzing crash " +
+				"in parser/FuzzFoo",
+			title:    "Fuzzing crash in x/parser/FuzzFoo",
+			expected: false,
+		},
+		{
+			name:       "unrelated issue mentioning the target",
+			issueTitle: "Fuzzing crash in parser/FuzzFoo is flaky",
+			title:      "Fuzzing crash in parser/FuzzFoo",
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := issueTitleMatches(tt.issueTitle, tt.title)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+// TestFormatCloseComment verifies that the issue close comment is rendered
+// from the configured template, or falls back to the default comment, and
+// always carries the watermark.
+func TestFormatCloseComment(t *testing.T) {
+	data := closeCommentData{
+		Package:   "parser",
+		Target:    "FuzzFoo",
+		Signature: "cfec419a119b189c",
+		Commit:    "0123456789abcdef",
+	}
+
+	tests := []struct {
+		name            string
+		template        string
+		expectedComment string
+		expectErrMsg    string
+	}{
+		{
+			name:     "default comment",
+			template: "",
+			expectedComment: defaultCloseComment + "\n" +
+				waterMark,
+		},
+		{
+			name: "custom template",
+			template: "{{.Package}}/{{.Target}} ({{.Signature}}) " +
+				"fixed in {{.Commit}}",
+			expectedComment: "parser/FuzzFoo (cfec419a119b189c) " +
+				"fixed in 0123456789abcdef\n" + waterMark,
+		},
+		{
+			name:         "invalid template",
+			template:     "{{.Package",
+			expectErrMsg: "parse close comment template",
+		},
+		{
+			name:         "unknown field",
+			template:     "{{.Unknown}}",
+			expectErrMsg: "execute close comment template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatCloseComment(tt.template, data)
+			if tt.expectErrMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedComment, got)
+		})
+	}
+}
+
+// TestIssueSignature verifies that the crash signature is extracted from
+// issue titles.
+func TestIssueSignature(t *testing.T) {
+	assert.Equal(t, "cfec419a119b189c", issueSignature("[fuzz/"+
+		"cfec419a119b189c] Fuzzing crash in parser/FuzzFoo"))
+	assert.Equal(t, "", issueSignature("Fuzzing crash in parser/FuzzFoo"))
+	assert.Equal(t, "", issueSignature("[fuzz/cfec419a119b189c"))
+}
+
+// TestFormatReopenComment verifies that the reopen comment names the commit at
+// which the crash reproduced again, if known, and carries the watermark.
+func TestFormatReopenComment(t *testing.T) {
+	assert.Equal(t, "Fuzz crash reproduced again at commit abc123, "+
+		"reopening the issue.\n"+waterMark,
+		formatReopenComment("abc123"))
+	assert.Equal(t, "Fuzz crash reproduced again at the latest commit, "+
+		"reopening the issue.\n"+waterMark, formatReopenComment(""))
+}
+
+// TestFormatReenableComment verifies that the comment closing the issue of a
+// re-enabled target names the commit fixing its crashes, if known.
+func TestFormatReenableComment(t *testing.T) {
+	assert.Equal(t, "The crashes of the fuzz target are fixed at commit "+
+		"abc123, re-enabling it and closing the issue.\n"+waterMark,
+		formatReenableComment("abc123"))
+	assert.Contains(t, formatReenableComment(""), "fixed at the latest "+
+		"commit")
+}
+
+// TestRecurrenceComment verifies that the comment tracking the recurrences of a
+// crash is recognized, and that the number of times the crash was seen is
+// parsed back from it.
+func TestRecurrenceComment(t *testing.T) {
+	seenAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	comment := formatRecurrenceComment(3, "abc123", seenAt)
+	assert.Equal(t, recurrenceMarker+"\nFuzz crash seen 3 times, last at "+
+		"commit abc123 on Thu, 02 Jan 2025 03:04:05 UTC.\n"+waterMark,
+		comment)
+	assert.True(t, isRecurrenceComment(comment))
+	assert.Equal(t, 3, parseRecurrenceCount(comment))
+
+	assert.Contains(t, formatRecurrenceComment(2, "", seenAt),
+		"last at the latest commit")
+
+	// Other comments, even generated ones, do not track recurrences.
+	assert.False(t, isRecurrenceComment(formatReopenComment("abc123")))
+	assert.False(t, isRecurrenceComment(recurrenceMarker))
+
+	// A tracking comment edited beyond recognition counts as the first
+	// report.
+	assert.Equal(t, 1, parseRecurrenceCount(recurrenceMarker+waterMark))
+}
+
+// TestFormatOrphanComment verifies that the comment closing the issues of a
+// target that is no longer discovered names the target and carries the
+// watermark.
+func TestFormatOrphanComment(t *testing.T) {
+	comment := formatOrphanComment("parser", "FuzzFoo")
+	assert.True(t, strings.HasPrefix(comment, "Fuzz target "+
+		"parser/FuzzFoo no longer exists"))
+	assert.True(t, strings.HasSuffix(comment, "\n"+waterMark))
+}
+
+// TestPhaseTimedOut verifies that a phase only counts as timed out when its own
+// timeout expired, not when the parent context is done.
+func TestPhaseTimedOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Without a timeout, the phase never times out on its own.
+	phaseCtx, phaseCancel := withPhaseTimeout(ctx, 0)
+	defer phaseCancel()
+	_, hasDeadline := phaseCtx.Deadline()
+	assert.False(t, hasDeadline)
+	assert.False(t, phaseTimedOut(ctx, phaseCtx))
+
+	timedCtx, timedCancel := withPhaseTimeout(ctx, time.Nanosecond)
+	defer timedCancel()
+	<-timedCtx.Done()
+	assert.True(t, phaseTimedOut(ctx, timedCtx))
+
+	// Once the parent is canceled, the expiry is attributed to it.
+	cancel()
+	assert.False(t, phaseTimedOut(ctx, timedCtx))
+}
+
+// TestSecondaryRateLimitWait verifies that the Retry-After duration is honored
+// and that the fallback wait doubles on every attempt up to the maximum.
+func TestSecondaryRateLimitWait(t *testing.T) {
+	retryAfter := 42 * time.Second
+	assert.Equal(t, retryAfter, secondaryRateLimitWait(&retryAfter, 3))
+
+	assert.Equal(t, DefaultSecondaryRateLimitWait,
+		secondaryRateLimitWait(nil, 0))
+	assert.Equal(t, 4*DefaultSecondaryRateLimitWait,
+		secondaryRateLimitWait(nil, 2))
+	assert.Equal(t, MaxSecondaryRateLimitWait,
+		secondaryRateLimitWait(nil, 10))
+}
+
+// TestExponentialBackoff verifies that the wait doubles on every attempt up to
+// the maximum.
+func TestExponentialBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, exponentialBackoff(time.Second,
+		30*time.Second, 0))
+	assert.Equal(t, 8*time.Second, exponentialBackoff(time.Second,
+		30*time.Second, 3))
+	assert.Equal(t, 30*time.Second, exponentialBackoff(time.Second,
+		30*time.Second, 5))
+	assert.Equal(t, 30*time.Second, exponentialBackoff(time.Second,
+		30*time.Second, 100))
+}
+
+// TestRunCycleHook verifies that runCycleHook passes the cycle number and the
+// workspace directories to the hook, and reports non-zero exit statuses and
+// timeouts as errors.
+func TestRunCycleHook(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	cfg := &Config{
+		Project: Project{
+			SrcDir:    "/workspace/project",
+			CorpusDir: "/workspace/corpus",
+			ReportDir: "/workspace/reports",
+		},
+		Fuzz: Fuzz{
+			HookTimeout: time.Minute,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		hook   string
+		errMsg string
+	}{
+		{
+			name: "environment passed",
+			hook: `test "$GCF_CYCLE" = 3 && ` +
+				`test "$GCF_SRC_DIR" = /workspace/project &&` +
+				` test "$GCF_CORPUS_DIR" = /workspace/corpus` +
+				` && test "$GCF_REPORT_DIR" = ` +
+				`/workspace/reports`,
+		},
+		{
+			name:   "non-zero exit status",
+			hook:   "echo refresh failed >&2; exit 3",
+			errMsg: "refresh failed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := runCycleHook(context.Background(), logger, cfg,
+				"pre-cycle", tc.hook, 3)
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, "pre-cycle hook failed")
+			assert.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		cfg := *cfg
+		cfg.Fuzz.HookTimeout = 10 * time.Millisecond
+
+		err := runCycleHook(context.Background(), logger, &cfg,
+			"post-cycle", "exec sleep 10", 1)
+		assert.EqualError(t, err, "post-cycle hook timed out after "+
+			"10ms")
+	})
+}
+
+// TestRunCorpusHook verifies that corpus hooks run in the package's source
+// directory with the target and its corpus directory in their environment, and
+// that their failures are reported.
+func TestRunCorpusHook(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	srcDir := t.TempDir()
+	pkgDir := filepath.Join(srcDir, "parser")
+	assert.NoError(t, os.Mkdir(pkgDir, 0o755))
+
+	cfg := &Config{
+		Project: Project{
+			SrcDir:    srcDir,
+			CorpusDir: "/workspace/corpus",
+		},
+		Fuzz: Fuzz{
+			HookTimeout: time.Minute,
+		},
+	}
+
+	hook := `test "$(pwd)" = "$GCF_PKG_DIR" && ` +
+		`test "$GCF_PKG_DIR" = "` + pkgDir + `" && ` +
+		`test "$GCF_PACKAGE" = parser && ` +
+		`test "$GCF_TARGET" = FuzzParse && ` +
+		`test "$GCF_TARGET_CORPUS_DIR" = ` +
+		`/workspace/corpus/parser/testdata/fuzz/FuzzParse`
+	assert.NoError(t, runCorpusHook(context.Background(), logger, cfg,
+		"corpus loader", hook, "parser", "FuzzParse"))
+
+	err := runCorpusHook(context.Background(), logger, cfg,
+		"corpus saver", "echo cannot save >&2; exit 1", "parser",
+		"FuzzParse")
+	assert.ErrorContains(t, err, "corpus saver failed")
+	assert.ErrorContains(t, err, "cannot save")
+}
+
+// TestCheckedOutBranch verifies that checkedOutBranch resolves the default
+// branch of the remote from a fresh clone, even if it is not named "master".
+func TestCheckedOutBranch(t *testing.T) {
+	originDir := t.TempDir()
+	origin, err := git.PlainInitWithOptions(originDir,
+		&git.PlainInitOptions{
+			InitOptions: git.InitOptions{
+				DefaultBranch: plumbing.NewBranchReferenceName(
+					"trunk"),
+			},
+		})
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(originDir, "go.mod"),
+		[]byte("module example.com/origin\n"), 0644)
+	assert.NoError(t, err)
+
+	wt, err := origin.Worktree()
+	assert.NoError(t, err)
+	_, err = wt.Add("go.mod")
+	assert.NoError(t, err)
+	_, err = wt.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Alice",
+			Email: "alice@example.com",
+			When:  time.Now(),
+		},
+	})
+	assert.NoError(t, err)
+
+	repo, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{
+		URL: originDir,
+	})
+	assert.NoError(t, err)
+
+	branch, err := checkedOutBranch(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "trunk", branch)
+}
+
+// TestCloneWithRetries verifies that the project repository is cloned, and that
+// failures retrying cannot fix are returned right away, preserving the error.
+func TestCloneWithRetries(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	first := commitFile(t, origin, originDir, "go.mod",
+		"module example.com/origin\n")
+
+	cfg := &Config{Project: Project{
+		SrcRepo:      originDir,
+		SrcDir:       filepath.Join(t.TempDir(), "project"),
+		CloneRetries: 3,
+	}}
+	_, err = cloneWithRetries(context.Background(), logger, cfg)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(cfg.Project.SrcDir, "go.mod"))
+
+	// A selected branch is cloned on its own.
+	err = origin.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("release"), first))
+	assert.NoError(t, err)
+	commitFile(t, origin, originDir, "main.go", "package main\n")
+
+	cfg.Project.SrcDir = filepath.Join(t.TempDir(), "project")
+	cfg.Project.Ref = "release"
+	repo, err := cloneWithRetries(context.Background(), logger, cfg)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, first, head.Hash())
+	assert.NoFileExists(t, filepath.Join(cfg.Project.SrcDir, "main.go"))
+	_, err = repo.Reference(plumbing.NewRemoteReferenceName(
+		git.DefaultRemoteName, "master"), false)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+	// A shallow clone only fetches the given number of commits.
+	cfg.Project.SrcDir = filepath.Join(t.TempDir(), "project")
+	cfg.Project.Ref = ""
+	cfg.Project.CloneDepth = 1
+	repo, err = cloneWithRetries(context.Background(), logger, cfg)
+	assert.NoError(t, err)
+	shallow, err := repo.Storer.Shallow()
+	assert.NoError(t, err)
+	assert.Len(t, shallow, 1)
+
+	// The recent commits of crash issues are limited to the cloned
+	// history.
+	_, err = recentCommits(cfg.Project.SrcDir, "go.mod", 5)
+	assert.NoError(t, err)
+	cfg.Project.CloneDepth = 0
+
+	// A missing reference is not retried either.
+	cfg.Project.SrcDir = filepath.Join(t.TempDir(), "project")
+	cfg.Project.Ref = "missing"
+	start := time.Now()
+	_, err = cloneWithRetries(context.Background(), logger, cfg)
+	assert.ErrorIs(t, err, errRemoteRefNotFound)
+	assert.Less(t, time.Since(start), DefaultCloneRetryWait)
+	cfg.Project.Ref = ""
+
+	// A missing repository is not retried, which would take seconds.
+	cfg.Project.SrcRepo = filepath.Join(t.TempDir(), "missing")
+	cfg.Project.SrcDir = filepath.Join(t.TempDir(), "project")
+	start = time.Now()
+	_, err = cloneWithRetries(context.Background(), logger, cfg)
+	assert.ErrorIs(t, err, transport.ErrRepositoryNotFound)
+	assert.Less(t, time.Since(start), DefaultCloneRetryWait)
+}
+
+// TestUpdateClone verifies that the clone of a previous cycle is updated to the
+// new commits of the selected reference, discarding the changes left in the
+// checkout, and that a missing clone is reported as such.
+func TestUpdateClone(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	first := commitFile(t, origin, originDir, "go.mod",
+		"module example.com/origin\n")
+
+	cfg := &Config{Project: Project{
+		SrcRepo: originDir,
+		SrcDir:  filepath.Join(t.TempDir(), "project"),
+	}}
+	_, err = updateClone(context.Background(), cfg)
+	assert.ErrorIs(t, err, git.ErrRepositoryNotExists)
+
+	_, err = cloneWithRetries(context.Background(), logger, cfg)
+	assert.NoError(t, err)
+	writeFiles(t, cfg.Project.SrcDir, map[string]string{
+		"go.mod":                   "module example.com/changed\n",
+		"testdata/fuzz/FuzzX/seed": "seed",
+	})
+
+	// The default branch is updated to its new commits.
+	err = origin.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("release"), first))
+	assert.NoError(t, err)
+	second := commitFile(t, origin, originDir, "main.go", "package main\n")
+
+	repo, err := updateClone(context.Background(), cfg)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, second, head.Hash())
+	assert.FileExists(t, filepath.Join(cfg.Project.SrcDir, "main.go"))
+	assert.NoDirExists(t, filepath.Join(cfg.Project.SrcDir, "testdata"))
+	data, err := os.ReadFile(filepath.Join(cfg.Project.SrcDir, "go.mod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "module example.com/origin\n", string(data))
+
+	// Selecting another branch checks it out.
+	cfg.Project.Ref = "release"
+	repo, err = updateClone(context.Background(), cfg)
+	assert.NoError(t, err)
+	branch, err := checkedOutBranch(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, "release", branch)
+	head, err = repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, first, head.Hash())
+	assert.NoFileExists(t, filepath.Join(cfg.Project.SrcDir, "main.go"))
+
+	// Tags are checked out without any branch.
+	err = origin.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewTagReferenceName("v1.0.0"), second))
+	assert.NoError(t, err)
+	cfg.Project.Ref = "v1.0.0"
+	repo, err = updateClone(context.Background(), cfg)
+	assert.NoError(t, err)
+	head, err = repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, plumbing.HEAD, head.Name())
+	assert.Equal(t, second, head.Hash())
+}
+
+// TestRetryableCloneError verifies that transient clone failures are retried,
+// unlike rejected credentials, missing repositories and canceled contexts.
+func TestRetryableCloneError(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, retryableCloneError(ctx, errors.New("dial tcp: "+
+		"lookup github.com: i/o timeout")))
+	assert.False(t, retryableCloneError(ctx, fmt.Errorf("clone: %w",
+		transport.ErrAuthenticationRequired)))
+	assert.False(t, retryableCloneError(ctx,
+		transport.ErrAuthorizationFailed))
+	assert.False(t, retryableCloneError(ctx,
+		transport.ErrRepositoryNotFound))
+	assert.False(t, retryableCloneError(ctx, context.DeadlineExceeded))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, retryableCloneError(canceled, errors.New("dial tcp: "+
+		"operation was canceled")))
+}
+
+// commitFile writes the given file into the worktree of repo, located at dir,
+// and commits it, returning the hash of the new commit.
+func commitFile(t *testing.T, repo *git.Repository, dir, name,
+	content string) plumbing.Hash {
+
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	assert.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = wt.Add(name)
+	assert.NoError(t, err)
+	hash, err := wt.Commit("update "+name, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Alice",
+			Email: "alice@example.com",
+			When:  time.Now(),
+		},
+	})
+	assert.NoError(t, err)
+
+	return hash
+}
+
+// TestResolveRemoteRef verifies that resolveRemoteRef follows the remote HEAD
+// to the commit of the default branch and notices new commits, and resolves
+// branches, tags and full reference names to their commit.
+func TestResolveRemoteRef(t *testing.T) {
+	ctx := context.Background()
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+
+	first := commitFile(t, origin, originDir, "go.mod",
+		"module example.com/origin\n")
+	name, head, err := resolveRemoteRef(ctx, originDir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, plumbing.Master, name)
+	assert.Equal(t, first, head)
+
+	_, err = origin.CreateTag("v1.0.0", first, nil)
+	assert.NoError(t, err)
+	err = origin.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName("release"), first))
+	assert.NoError(t, err)
+
+	second := commitFile(t, origin, originDir, "main.go",
+		"package main\n")
+	_, head, err = resolveRemoteRef(ctx, originDir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, second, head)
+
+	name, head, err = resolveRemoteRef(ctx, originDir, "release")
+	assert.NoError(t, err)
+	assert.Equal(t, plumbing.NewBranchReferenceName("release"), name)
+	assert.Equal(t, first, head)
+
+	for _, ref := range []string{"v1.0.0", "refs/tags/v1.0.0"} {
+		name, head, err = resolveRemoteRef(ctx, originDir, ref)
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.NewTagReferenceName("v1.0.0"), name)
+		assert.Equal(t, first, head)
+	}
+
+	_, _, err = resolveRemoteRef(ctx, originDir, "v2.0.0")
+	assert.ErrorIs(t, err, errRemoteRefNotFound)
+
+	_, _, err = resolveRemoteRef(ctx, filepath.Join(originDir, "missing"),
+		"")
+	assert.Error(t, err)
+}
+
+// TestResetCheckout verifies that resetCheckout reverts modified files and
+// removes untracked files and directories from the worktree.
+func TestResetCheckout(t *testing.T) {
+	originDir := t.TempDir()
+	origin, err := git.PlainInit(originDir, false)
+	assert.NoError(t, err)
+	commitFile(t, origin, originDir, "go.mod",
+		"module example.com/origin\n")
+
+	srcDir := t.TempDir()
+	repo, err := git.PlainClone(srcDir, false, &git.CloneOptions{
+		URL: originDir,
+	})
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(srcDir, "go.mod"),
+		[]byte("module example.com/modified\n"), 0644)
+	assert.NoError(t, err)
+
+	corpusDir := filepath.Join(srcDir, "testdata", "fuzz", "FuzzFoo")
+	assert.NoError(t, os.MkdirAll(corpusDir, 0755))
+	err = os.WriteFile(filepath.Join(corpusDir, "input"), []byte("x"),
+		0644)
+	assert.NoError(t, err)
+
+	assert.NoError(t, resetCheckout(repo))
+
+	content, err := os.ReadFile(filepath.Join(srcDir, "go.mod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "module example.com/origin\n", string(content))
+	assert.NoDirExists(t, filepath.Join(srcDir, "testdata"))
+}
+
+// TestCycleWindow verifies that a cycle is only shortened if it would not end
+// before the run deadline, and then ends at the deadline.
+func TestCycleWindow(t *testing.T) {
+	syncFrequency, gracePeriod := cycleWindow(time.Hour, 20*time.Minute,
+		time.Time{})
+	assert.Equal(t, time.Hour, syncFrequency)
+	assert.Equal(t, 20*time.Minute, gracePeriod)
+
+	syncFrequency, gracePeriod = cycleWindow(time.Hour, 20*time.Minute,
+		time.Now().Add(2*time.Hour))
+	assert.Equal(t, time.Hour, syncFrequency)
+	assert.Equal(t, 20*time.Minute, gracePeriod)
+
+	// The grace period of a 30-minute cycle is at most a third of it.
+	syncFrequency, gracePeriod = cycleWindow(24*time.Hour, time.Hour,
+		time.Now().Add(30*time.Minute))
+	assert.Equal(t, 10*time.Minute, gracePeriod.Round(time.Minute))
+	assert.Equal(t, 20*time.Minute, syncFrequency.Round(time.Minute))
+
+	// A shorter configured grace period is kept.
+	syncFrequency, gracePeriod = cycleWindow(24*time.Hour, time.Minute,
+		time.Now().Add(30*time.Minute))
+	assert.Equal(t, time.Minute, gracePeriod)
+	assert.Equal(t, 29*time.Minute, syncFrequency.Round(time.Minute))
+
+	// A passed deadline leaves no time.
+	syncFrequency, gracePeriod = cycleWindow(time.Hour, time.Minute,
+		time.Now().Add(-time.Minute))
+	assert.Zero(t, syncFrequency)
+	assert.Zero(t, gracePeriod)
+}
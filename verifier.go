@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+)
+
+// verification is the outcome of verifying the open issues of a fuzz target:
+// the crash tracker used, which fuzzing the target then reports its crashes
+// with, and the number of issues that remain open. It is only available once
+// done is closed.
+type verification struct {
+	done       chan struct{}
+	tracker    CrashTracker
+	openIssues int
+	err        error
+}
+
+// verifyTask initializes a crash tracker for the task's fuzz target, and
+// verifies and closes its resolved issues with it. Returns the tracker and the
+// number of issues that remain open.
+func (wg *WorkerGroup) verifyTask(task Task) (CrashTracker, int, error) {
+	// Initialize a crash tracker client for issue verification.
+	tracker, err := NewCrashTracker(wg.ctx, wg.logger.With(
+		"target", task.Target).With("package", task.PackagePath),
+		wg.cli, wg.cfg)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error initializing crash tracker "+
+			"client: %w", err)
+	}
+
+	// Verify and close any open issues related to the fuzz target.
+	openIssues, err := tracker.verifyAndCloseResolvedIssues(
+		task.PackagePath, task.Target)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to verify and close open "+
+			"issues: %w", err)
+	}
+
+	return tracker, openIssues, nil
+}
+
+// startVerifiers starts the given number of verification workers in the worker
+// group, which verify the open issues of the given tasks in order, in the
+// background while the fuzzing workers fuzz the tasks already verified. Returns
+// the pending verification of each task, which the fuzzing workers wait for
+// before fuzzing it. A failed verification is returned to the fuzzing worker
+// of its task, so the verification workers never fail themselves.
+func (wg *WorkerGroup) startVerifiers(tasks []Task,
+	numVerifiers int) map[Task]*verification {
+
+	queue := NewTaskQueue()
+	verifications := make(map[Task]*verification, len(tasks))
+	for _, task := range tasks {
+		if _, ok := verifications[task]; ok {
+			continue
+		}
+
+		queue.Enqueue(task)
+		verifications[task] = &verification{
+			done: make(chan struct{}),
+		}
+	}
+
+	for verifierID := 1; verifierID <= numVerifiers; verifierID++ {
+		wg.goGroup.Go(func() error {
+			for wg.ctx.Err() == nil {
+				task, ok := queue.Dequeue()
+				if !ok {
+					return nil
+				}
+
+				wg.logger.Info("Verifier starting issue "+
+					"verification", "verifierID",
+					verifierID, "package",
+					task.PackagePath, "target",
+					task.Target)
+
+				v := verifications[task]
+				v.tracker, v.openIssues, v.err =
+					wg.verifyTask(task)
+				close(v.done)
+			}
+
+			return nil
+		})
+	}
+
+	return verifications
+}
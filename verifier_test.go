@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+)
+
+// TestVerifiers verifies that the verification workers verify every task in
+// the background, handing failures to the fuzzing workers of their tasks, and
+// that waiting for a verification stops when the worker context is canceled.
+func TestVerifiers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g := &errgroup.Group{}
+	wg := &WorkerGroup{
+		ctx:     ctx,
+		logger:  slog.New(slog.DiscardHandler),
+		goGroup: g,
+		cfg: &Config{Fuzz: Fuzz{
+			// Without a token, no crash tracker can be created.
+			CrashRepo: "https://github.com/owner/repo.git",
+		}},
+	}
+
+	tasks := []Task{
+		{PackagePath: "pkg", Target: "FuzzFoo"},
+		{PackagePath: "pkg", Target: "FuzzBar"},
+		{PackagePath: "pkg", Target: "FuzzFoo"},
+	}
+	wg.verifications = wg.startVerifiers(tasks, 2)
+	assert.Len(t, wg.verifications, 2)
+
+	// The verification workers never fail themselves.
+	assert.NoError(t, g.Wait())
+	for _, task := range tasks {
+		tracker, openIssues, err := wg.awaitVerification(1, task)
+		assert.ErrorContains(t, err, "error initializing crash "+
+			"tracker client")
+		assert.Nil(t, tracker)
+		assert.Zero(t, openIssues)
+	}
+
+	// A pending verification is no longer waited for once canceled.
+	task := Task{PackagePath: "pkg", Target: "FuzzBaz"}
+	wg.verifications[task] = &verification{done: make(chan struct{})}
+	cancel()
+	tracker, openIssues, err := wg.awaitVerification(1, task)
+	assert.NoError(t, err)
+	assert.Nil(t, tracker)
+	assert.Zero(t, openIssues)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// The build metadata of the binary, populated at build time with -ldflags, e.g.
+//
+//	go build -ldflags "-X main.Version=v1.2.3 -X main.Commit=$(git rev-parse
+//	HEAD) -X main.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// as done by `make build`. Without them, the commit is taken from the VCS
+// information embedded by the Go toolchain, if any.
+var (
+	// Version is the semantic version of the build.
+	Version = "v0.0.0-dev"
+
+	// Commit is the Git commit the binary was built from.
+	Commit = ""
+
+	// BuildDate is the UTC date and time the binary was built at.
+	BuildDate = ""
+)
+
+// buildVersion holds the build metadata of the running binary.
+type buildVersion struct {
+	version   string
+	commit    string
+	buildDate string
+	goVersion string
+}
+
+// currentVersion returns the build metadata of the running binary, falling
+// back on the build information embedded by the Go toolchain for the fields
+// not set with -ldflags.
+func currentVersion() buildVersion {
+	v := buildVersion{
+		version:   Version,
+		commit:    Commit,
+		buildDate: BuildDate,
+		goVersion: runtime.Version(),
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+
+	// Binaries installed with `go install <module>@<version>` carry the
+	// version of their module.
+	if v.version == "v0.0.0-dev" && info.Main.Version != "" &&
+		info.Main.Version != "(devel)" {
+
+		v.version = info.Main.Version
+	}
+
+	if v.commit == "" {
+		var modified bool
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				v.commit = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if v.commit != "" && modified {
+			v.commit += "-dirty"
+		}
+	}
+
+	return v
+}
+
+// String renders the build metadata on a single line, marking the unknown
+// fields as such.
+func (v buildVersion) String() string {
+	orUnknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+
+	return fmt.Sprintf("go-continuous-fuzz version %s commit=%s "+
+		"build_date=%s go=%s", v.version, orUnknown(v.commit),
+		orUnknown(v.buildDate), v.goVersion)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersion verifies that the build metadata set with -ldflags is reported,
+// and that the unknown fields are marked as such.
+func TestVersion(t *testing.T) {
+	oldVersion, oldCommit, oldBuildDate := Version, Commit, BuildDate
+	t.Cleanup(func() {
+		Version, Commit, BuildDate = oldVersion, oldCommit, oldBuildDate
+	})
+
+	Version = "v1.2.3"
+	Commit = "0123456789abcdef"
+	BuildDate = "2025-07-15T10:00:00Z"
+	assert.Equal(t, "go-continuous-fuzz version v1.2.3 "+
+		"commit=0123456789abcdef build_date=2025-07-15T10:00:00Z go="+
+		runtime.Version(), currentVersion().String())
+
+	v := buildVersion{version: "v0.0.0-dev", goVersion: "go1.24.6"}
+	assert.Equal(t, "go-continuous-fuzz version v0.0.0-dev "+
+		"commit=unknown build_date=unknown go=go1.24.6", v.String())
+}
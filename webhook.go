@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader is the header holding the HMAC-SHA256
+	// signature of the webhook payload, as "sha256=<hex digest>", if a
+	// webhook secret is configured.
+	WebhookSignatureHeader = "X-Signature"
+
+	// WebhookEventCrash is the event of the webhook payloads sent for
+	// reported fuzz crashes.
+	WebhookEventCrash = "crash"
+)
+
+// webhookPayload is the JSON payload posted to the webhook.
+type webhookPayload struct {
+	Event      string      `json:"event"`
+	ReportedAt time.Time   `json:"reported_at"`
+	Crash      crashReport `json:"crash"`
+}
+
+// signWebhookPayload returns the value of the WebhookSignatureHeader for the
+// given payload, signed with the given secret.
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postCrashWebhook posts the report of a fuzz crash as JSON to the configured
+// webhook, with the configured custom headers, signed with the webhook secret
+// if set. The request is bounded by the webhook timeout, and any response
+// status other than 2xx is an error.
+func postCrashWebhook(ctx context.Context, cfg *Config, report crashReport,
+	now time.Time) error {
+
+	payload, err := json.Marshal(webhookPayload{
+		Event:      WebhookEventCrash,
+		ReportedAt: now.UTC(),
+		Crash:      report,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	client := &http.Client{Timeout: cfg.Fuzz.WebhookTimeout}
+	return postJSON(ctx, client, cfg.Fuzz.WebhookURL, payload,
+		cfg.Fuzz.WebhookHTTPHeaders, cfg.Fuzz.WebhookSecret)
+}
+
+// postJSON posts the given JSON payload to the given webhook URL with the given
+// custom headers, signed with the given secret if set. It is shared by all
+// webhooks, so they are signed and fail alike. Since webhook URLs may hold the
+// token of the webhook, they are left out of the returned errors. Any response
+// status other than 2xx is an error.
+func postJSON(ctx context.Context, client *http.Client, webhookURL string,
+	payload []byte, headers map[string]string, secret string) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w",
+			stripURL(err))
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader,
+			signWebhookPayload(secret, payload))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to webhook: %w", stripURL(err))
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	// Drain the body, so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %s",
+			resp.Status)
+	}
+
+	return nil
+}
+
+// stripURL returns the error wrapped by the given *url.Error, if any, leaving
+// out the URL of the request.
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostCrashWebhook verifies that crash reports are posted as JSON with the
+// custom headers and an HMAC-SHA256 signature of the payload, and that error
+// responses and slow webhooks are reported as errors.
+func TestPostCrashWebhook(t *testing.T) {
+	var (
+		body      []byte
+		header    http.Header
+		status    = http.StatusNoContent
+		respDelay time.Duration
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			header = r.Header
+			time.Sleep(respDelay)
+			w.WriteHeader(status)
+		}))
+	defer server.Close()
+
+	cfg := &Config{Fuzz: Fuzz{
+		WebhookURL:    server.URL,
+		WebhookSecret: "secret",
+		WebhookHTTPHeaders: map[string]string{
+			"Authorization": "Bearer token",
+		},
+		WebhookTimeout: time.Second,
+	}}
+	report := crashReport{
+		Package:   "parser",
+		Target:    "FuzzEval",
+		Signature: "abc123",
+		IssueURL:  "https://github.com/OWNER/REPO/issues/1",
+		New:       true,
+	}
+	now := time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+	assert.NoError(t, postCrashWebhook(ctx, cfg, report, now))
+
+	var payload webhookPayload
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, webhookPayload{
+		Event:      WebhookEventCrash,
+		ReportedAt: now,
+		Crash:      report,
+	}, payload)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.Equal(t, signWebhookPayload("secret", body),
+		header.Get(WebhookSignatureHeader))
+
+	// Without a secret, the payload is not signed.
+	cfg.Fuzz.WebhookSecret = ""
+	assert.NoError(t, postCrashWebhook(ctx, cfg, report, now))
+	assert.Empty(t, header.Get(WebhookSignatureHeader))
+
+	status = http.StatusUnauthorized
+	err := postCrashWebhook(ctx, cfg, report, now)
+	assert.ErrorContains(t, err, "401 Unauthorized")
+
+	status = http.StatusOK
+	respDelay = 200 * time.Millisecond
+	cfg.Fuzz.WebhookTimeout = 50 * time.Millisecond
+	err = postCrashWebhook(ctx, cfg, report, now)
+	assert.ErrorContains(t, err, "posting to webhook")
+
+	// Webhook URLs may hold a token, which must not end up in the logged
+	// error.
+	server.Close()
+	cfg.Fuzz.WebhookURL = server.URL + "/hooks/s3cr3t-token"
+	err = postCrashWebhook(ctx, cfg, report, now)
+	assert.ErrorContains(t, err, "posting to webhook")
+	assert.NotContains(t, err.Error(), "s3cr3t-token")
+	assert.NotContains(t, err.Error(), server.URL)
+}
+
+// TestSignWebhookPayload verifies that payloads are signed with HMAC-SHA256, in
+// the form receivers verify.
+func TestSignWebhookPayload(t *testing.T) {
+	// The HMAC-SHA256 test vector of RFC 4231, test case 2.
+	assert.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d"+
+		"2739839dec58b964ec3843", signWebhookPayload("Jefe",
+		[]byte("what do ya want for nothing?")))
+}
+
+// TestPostJSON verifies that the JSON content type cannot be overridden by the
+// custom headers, and that invalid webhook URLs are left out of the errors.
+func TestPostJSON(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+		}))
+	defer server.Close()
+
+	ctx := context.Background()
+	err := postJSON(ctx, server.Client(), server.URL, []byte("{}"),
+		map[string]string{"Content-Type": "text/plain"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	err = postJSON(ctx, server.Client(), "http://host:port/s3cr3t-token",
+		[]byte("{}"), nil, "")
+	assert.ErrorContains(t, err, "creating webhook request")
+	assert.NotContains(t, err.Error(), "s3cr3t-token")
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
@@ -19,25 +25,42 @@ type Task struct {
 	Target      string
 }
 
-// TaskQueue is a simple FIFO queue for scheduling Task items.
+// queuedTask is a Task in the queue, along with its priority.
+type queuedTask struct {
+	task     Task
+	priority int
+}
+
+// TaskQueue is a priority queue for scheduling Task items: tasks of a higher
+// priority are dequeued first, and tasks of the same priority in FIFO order.
 type TaskQueue struct {
 	mu    sync.Mutex
-	tasks []Task
+	tasks []queuedTask
 }
 
 // NewTaskQueue returns an empty, initialized TaskQueue.
 func NewTaskQueue() *TaskQueue {
 	return &TaskQueue{
-		tasks: make([]Task, 0),
+		tasks: make([]queuedTask, 0),
 	}
 }
 
-// Enqueue adds a new Task to the back of the queue.
+// Enqueue adds a new Task of TaskPriorityNormal to the back of the queue.
 func (q *TaskQueue) Enqueue(t Task) {
+	q.EnqueuePriority(t, TaskPriorityNormal)
+}
+
+// EnqueuePriority adds a new Task of the given priority to the queue, after
+// all the tasks of the same or a higher priority.
+func (q *TaskQueue) EnqueuePriority(t Task, priority int) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.tasks = append(q.tasks, t)
+	i := len(q.tasks)
+	for i > 0 && q.tasks[i-1].priority < priority {
+		i--
+	}
+	q.tasks = slices.Insert(q.tasks, i, queuedTask{t, priority})
 }
 
 // Length returns the current number of tasks in the queue.
@@ -48,6 +71,31 @@ func (q *TaskQueue) Length() int {
 	return len(q.tasks)
 }
 
+// Tasks returns a copy of the tasks in the queue, in order.
+func (q *TaskQueue) Tasks() []Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	tasks := make([]Task, 0, len(q.tasks))
+	for _, qt := range q.tasks {
+		tasks = append(tasks, qt.task)
+	}
+	return tasks
+}
+
+// Sort orders the tasks of each priority in the queue by package path, and
+// then by target.
+func (q *TaskQueue) Sort() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	slices.SortFunc(q.tasks, func(a, b queuedTask) int {
+		return cmp.Or(cmp.Compare(b.priority, a.priority),
+			cmp.Compare(a.task.PackagePath, b.task.PackagePath),
+			cmp.Compare(a.task.Target, b.task.Target))
+	})
+}
+
 // Dequeue removes and returns the next Task from the queue. If the queue is
 // empty, it returns false for the second return value.
 func (q *TaskQueue) Dequeue() (Task, bool) {
@@ -59,27 +107,63 @@ func (q *TaskQueue) Dequeue() (Task, bool) {
 	}
 	t := q.tasks[0]
 	q.tasks = q.tasks[1:]
-	return t, true
+	return t.task, true
 }
 
-// WorkerGroup manages a group of fuzzing workers, their context, logger, Docker
-// client, configuration, shared task queue, per-task timeout, and if corpus
-// should be minimized or not.
+// WorkerGroup manages a group of fuzzing workers sharing a task queue.
 type WorkerGroup struct {
-	ctx                  context.Context
-	logger               *slog.Logger
-	goGroup              *errgroup.Group
-	cli                  *client.Client
-	cfg                  *Config
-	taskQueue            *TaskQueue
-	taskTimeout          time.Duration
+	ctx     context.Context
+	logger  *slog.Logger
+	goGroup *errgroup.Group
+	cli     *client.Client
+	cfg     *Config
+
+	// engine is the fuzzing engine building and running the targets.
+	engine fuzzEngine
+
+	taskQueue   *TaskQueue
+	taskTimeout time.Duration
+
+	// taskTimeouts holds the timeouts of the tasks weighted by the
+	// schedule, if any, overriding taskTimeout.
+	taskTimeouts map[Task]time.Duration
+
 	shouldMinimizeCorpus bool
+
+	// bootstrapping is set if the cycle only establishes the baselines of
+	// a new project.
+	bootstrapping bool
+
+	// summary collects the results of the whole run.
+	summary *runSummary
+
+	// status tracks the status of the targets in this cycle.
+	status *cycleStatus
+
+	// disable disables the targets crashing early, if enabled.
+	disable *disablePolicy
+
+	// starve reports the targets starved of executions, if enabled.
+	starve *starvePolicy
+
+	// verifications holds the pending verifications of the targets'
+	// issues, if verified in the background.
+	verifications map[Task]*verification
+
+	// digest collects the fuzz crashes in digest mode.
+	digest *crashDigest
 }
 
-// WorkersStartAndWait starts the specified number of workers and waits for all
-// to finish or for the first error/cancellation. Returns an error if any worker
+// WorkersStartAndWait starts the specified number of workers, along with the
+// configured number of verification workers, if any, and waits for all to
+// finish or for the first error/cancellation. Returns an error if any worker
 // fails.
 func (wg *WorkerGroup) WorkersStartAndWait(numWorkers int) error {
+	if wg.cfg.Fuzz.VerifyWorkers > 0 {
+		wg.verifications = wg.startVerifiers(wg.taskQueue.Tasks(),
+			wg.cfg.Fuzz.VerifyWorkers)
+	}
+
 	for workerID := 1; workerID <= numWorkers; workerID++ {
 		wg.goGroup.Go(func() error {
 			return wg.runWorker(workerID)
@@ -96,7 +180,10 @@ func (wg *WorkerGroup) WorkersStartAndWait(numWorkers int) error {
 
 // runWorker pulls tasks from the taskQueue until it is empty or the worker
 // context is canceled:
-//   - Verifies and close any resolved GitHub issues related to the fuzz target.
+//   - Verifies and close any resolved issues related to the fuzz target, or
+//     waits for their verification if verified in the background.
+//   - Skips the fuzz target if it is disabled for crashing early and its
+//     crashes are not fixed.
 //   - Executes the fuzz target with a timeout.
 func (wg *WorkerGroup) runWorker(workerID int) error {
 	for {
@@ -107,40 +194,48 @@ func (wg *WorkerGroup) runWorker(workerID int) error {
 			return nil
 		}
 
-		wg.logger.Info(
-			"Worker starting issue verification", "workerID",
-			workerID, "package", task.PackagePath, "target",
-			task.Target,
-		)
-
-		// Initialize a GitHub client for issue verification.
-		gh, err := NewGitHubRepo(wg.ctx, wg.logger.With("target",
-			task.Target).With("package", task.PackagePath), wg.cli,
-			wg.cfg)
-		if err != nil {
-			return fmt.Errorf("error initializing GitHub client: "+
-				"%w", err)
-		}
-
-		// The worker will verify and close any open GitHub issues
-		// related to the fuzz target.
-		err = gh.verifyAndCloseResolvedIssues(task.PackagePath,
-			task.Target)
+		tracker, openIssues, err := wg.awaitVerification(workerID,
+			task)
 		if err != nil {
 			if wg.ctx.Err() != nil {
 				return nil
 			}
-			return fmt.Errorf("failed to verify and close open "+
-				"issues: %w", err)
+			return err
+		}
+		if tracker == nil {
+			// The worker context was canceled while waiting.
+			return nil
+		}
+
+		// A target disabled for crashing early is only fuzzed again
+		// once its crashes are fixed.
+		if wg.disable != nil &&
+			wg.disable.disabled(task.PackagePath, task.Target) {
+
+			reenabled, err := wg.reenableTarget(tracker, task,
+				openIssues)
+			if err != nil {
+				if wg.ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("worker %d: fuzz target "+
+					"%q/%q failed: %w", workerID,
+					task.PackagePath, task.Target, err)
+			}
+			if !reenabled {
+				continue
+			}
 		}
 
 		wg.logger.Info(
 			"Worker starting fuzzing", "workerID", workerID,
 			"package", task.PackagePath, "target", task.Target,
-			"timeout", wg.taskTimeout,
+			"timeout", wg.fuzzTimeout(task.PackagePath,
+				task.Target),
 		)
 
-		err = wg.executeFuzzTarget(task.PackagePath, task.Target, gh)
+		err = wg.executeFuzzTarget(workerID, task.PackagePath,
+			task.Target, tracker, openIssues)
 		if err != nil {
 			if wg.ctx.Err() != nil {
 				return nil
@@ -157,17 +252,138 @@ func (wg *WorkerGroup) runWorker(workerID int) error {
 	}
 }
 
+// awaitVerification returns the crash tracker of the task's fuzz target and the
+// number of its issues that remain open after verifying and closing the
+// resolved ones. If the issues are verified in the background, it waits for
+// their verification, returning a nil tracker if the worker context is
+// canceled meanwhile.
+func (wg *WorkerGroup) awaitVerification(workerID int, task Task) (CrashTracker,
+	int, error) {
+
+	v, ok := wg.verifications[task]
+	if !ok {
+		wg.logger.Info(
+			"Worker starting issue verification", "workerID",
+			workerID, "package", task.PackagePath, "target",
+			task.Target,
+		)
+
+		return wg.verifyTask(task)
+	}
+
+	wg.logger.Info("Worker waiting for issue verification", "workerID",
+		workerID, "package", task.PackagePath, "target", task.Target)
+
+	select {
+	case <-wg.ctx.Done():
+		return nil, 0, nil
+
+	case <-v.done:
+		return v.tracker, v.openIssues, v.err
+	}
+}
+
+// reenableTarget re-enables the task's fuzz target disabled for crashing early
+// if none of its issues remain open after verification, closing the issue
+// tracking it. Otherwise, the target is recorded as disabled in this cycle.
+// Returns whether the target is re-enabled.
+func (wg *WorkerGroup) reenableTarget(tracker CrashTracker, task Task,
+	openIssues int) (bool, error) {
+
+	pkg, target := task.PackagePath, task.Target
+	if openIssues > 0 {
+		wg.logger.Info("Skipping fuzz target disabled for crashing "+
+			"early", "package", pkg, "target", target,
+			"openIssues", openIssues)
+		wg.status.record(pkg, target, TargetResultDisabled, "",
+			openIssues)
+		return false, nil
+	}
+
+	closed, err := tracker.closeDisabledIssue(pkg, target)
+	if err != nil {
+		return false, fmt.Errorf("closing disabled target issue: %w",
+			err)
+	}
+
+	wg.logger.Info("Re-enabling fuzz target whose crashes are fixed",
+		"package", pkg, "target", target, "closedIssue", closed)
+
+	return true, nil
+}
+
+// fuzzTimeout returns the time the target is fuzzed for: the per-task timeout,
+// or its own timeout if weighted by the schedule, plus the extra time granted
+// to the target if it was starved in the previous cycle.
+func (wg *WorkerGroup) fuzzTimeout(pkg, target string) time.Duration {
+	timeout := wg.taskTimeout
+	if t, ok := wg.taskTimeouts[Task{pkg, target}]; ok {
+		timeout = t
+	}
+	if wg.starve != nil {
+		timeout += wg.starve.extraTimeFor(pkg, target)
+	}
+
+	return timeout
+}
+
+// dumpStacks logs the goroutine stacks dumped by the container of the target,
+// which is still running at its timeout, e.g. because the target hangs. A
+// failure to dump them is only logged.
+func (wg *WorkerGroup) dumpStacks(c *Container, containerID, pkg,
+	target string) {
+
+	wg.logger.Info("Fuzz target still running at its timeout; dumping "+
+		"goroutine stacks", "package", pkg, "target", target)
+
+	dump, err := c.DumpStacks(containerID)
+	if err != nil {
+		wg.logger.Warn("Failed to dump goroutine stacks of fuzz target",
+			"package", pkg, "target", target, "error", err)
+		return
+	}
+
+	wg.logger.Warn("Goroutine stacks of fuzz target at its timeout",
+		"package", pkg, "target", target, "dump", dump)
+}
+
+// handleEarlyCrash records the early crash of the target reported by the given
+// report, and if the target thereby crashed early in enough consecutive cycles,
+// disables it by reporting it to the crash tracker. Returns the report of the
+// disabled target, or nil if it is not disabled.
+func (wg *WorkerGroup) handleEarlyCrash(tracker CrashTracker, pkg,
+	target string, crash crashReport) (*crashReport, error) {
+
+	wg.status.recordEarlyCrash(pkg, target)
+
+	cycles, disables := wg.disable.disables(pkg, target)
+	if !disables {
+		return nil, nil
+	}
+
+	wg.logger.Warn("Disabling fuzz target crashing early in consecutive "+
+		"cycles", "package", pkg, "target", target, "cycles", cycles)
+
+	return tracker.handleDisabledTarget(pkg, target, cycles, crash)
+}
+
 // executeFuzzTarget runs the specified fuzz target for a package using Docker.
 // It performs the following steps:
+//   - Optionally loads the target's corpus with the corpus loader.
 //   - Starts the fuzzing container and streams its output.
-//   - Reports any fuzz crashes by creating a GitHub issue.
+//   - Reports any fuzz crashes by creating an issue.
 //   - Updates the coverage report.
 //   - Optionally minimizes the corpus if configured.
-func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
-	gh *GitHubRepo) error {
-
+//   - Optionally caps the number of corpus files if configured.
+//   - Optionally saves the target's corpus with the corpus saver.
+//   - Records the target's status, given the number of issues that were open
+//     for it before fuzzing.
+func (wg *WorkerGroup) executeFuzzTarget(workerID int, pkg string,
+	target string, tracker CrashTracker, openIssues int) error {
+
+	timeout := wg.fuzzTimeout(pkg, target)
 	wg.logger.Info("Executing fuzz target in Docker", "package", pkg,
-		"target", target, "duration", wg.taskTimeout)
+		"target", target, "duration", timeout)
 
 	// Construct the absolute path to the package directory within the
 	// temporary project directory on the host machine.
@@ -178,40 +394,80 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 	hostCorpusPath := filepath.Join(wg.cfg.Project.CorpusDir, pkg,
 		"testdata", "fuzz")
 
+	// Ensure that the target's corpus directory on the host machine exists
+	// to avoid permission errors when running the container as a non-root
+	// user, and so a corpus loader can write into it.
+	err := EnsureDirExists(filepath.Join(hostCorpusPath, target))
+	if err != nil {
+		return err
+	}
+
+	// If configured, materialize the target's corpus with the loader
+	// before it is staged or fuzzed.
+	if wg.cfg.Fuzz.CorpusLoader != "" {
+		err := runCorpusHook(wg.ctx, wg.logger, wg.cfg, "corpus loader",
+			wg.cfg.Fuzz.CorpusLoader, pkg, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If a separate fuzz cache is configured, the fuzzer works on a staged
+	// copy of the target's corpus there instead, whose new inputs are
+	// promoted to the corpus once fuzzing ends.
+	fuzzCachePath := hostCorpusPath
+	if wg.cfg.Fuzz.FuzzCacheDir != "" {
+		fuzzCachePath = filepath.Join(wg.cfg.Fuzz.FuzzCacheDir, pkg)
+		err := stageFuzzCache(fuzzCachePath, hostCorpusPath, target)
+		if err != nil {
+			return fmt.Errorf("staging fuzz cache: %w", err)
+		}
+	}
+
 	// Define the path to the fuzz target binary on the host machine that
 	// will be executed inside the container.
 	fuzzBinaryPath := filepath.Join(wg.cfg.Project.BinaryDir, pkg, target)
 
-	// Ensure that the corpus directory on the host machine exists to avoid
-	// permission errors when running the container as a non-root user.
-	if err := EnsureDirExists(hostCorpusPath); err != nil {
+	// Ensure that the directory where failing inputs are saved exists, as
+	// not every fuzzing engine creates it on its own.
+	failingDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz", target)
+	if err := EnsureDirExists(failingDir); err != nil {
 		return err
 	}
 
-	// Prepare the arguments for the 'go test' command to run the specific
-	// fuzz target in container.
-	goTestCmd := []string{
-		fmt.Sprintf("./%s.test", target),
-		fmt.Sprintf("-test.fuzz=^%s$", target),
-		fmt.Sprintf("-test.fuzzcachedir=%s", ContainerCorpusPath),
-		"-test.parallel=1",
+	// Remember the inputs already in the directory (e.g. the seed corpus
+	// copied from the package's testdata), to tell the failing inputs
+	// saved by this run apart.
+	var knownInputs map[string]bool
+	if wg.cfg.Fuzz.ReportAllFailingInputs {
+		knownInputs, err = listFileNames(failingDir)
+		if err != nil {
+			return fmt.Errorf("listing failing inputs: %w", err)
+		}
+	}
+
+	// If enabled, tell the fuzzer how long to fuzz for, so it stops on its
+	// own and flushes its corpus before the timeout below kills it.
+	var budget time.Duration
+	if wg.cfg.Fuzz.FuzzTimeBudget {
+		budget = timeout
 	}
 
 	// Create a subcontext with timeout for this individual fuzz target.
-	fuzzCtx, cancel := context.WithTimeout(wg.ctx, wg.taskTimeout+
+	// When a budget is passed to the fuzzer, the timeout only acts as a
+	// backstop in case the fuzzer does not exit on its own.
+	fuzzCtx, cancel := context.WithTimeout(wg.ctx, timeout+
 		ContainerGracePeriod)
 	defer cancel()
 
-	c := &Container{
-		ctx:            fuzzCtx,
-		logger:         wg.logger,
-		cli:            wg.cli,
-		fuzzBinaryPath: fuzzBinaryPath,
-		hostCorpusPath: hostCorpusPath,
-		cmd:            goTestCmd,
-	}
+	c := newContainer(fuzzCtx, wg.logger, wg.cli, &wg.cfg.Fuzz, wg.engine,
+		pkg, target)
+	c.fuzzBinaryPath = fuzzBinaryPath
+	c.hostCorpusPath = fuzzCachePath
+	c.cmd = wg.engine.fuzzCmd(target, budget)
 
 	// Start the fuzzing container.
+	fuzzStart := time.Now()
 	containerID, err := c.Start()
 	if err != nil {
 		if fuzzCtx.Err() != nil {
@@ -231,6 +487,9 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 		}
 	}()
 
+	// The result of this run, recorded in the target's status.
+	result := TargetResultOK
+
 	// Channels to receive either a fuzz failure or a container error.
 	fuzzCrashChan := make(chan fuzzCrash, 1)
 	errorChan := make(chan error, 1)
@@ -241,21 +500,139 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 
 	select {
 	case <-fuzzCtx.Done():
-		// Context timeout or cancellation occurred.
+		// Context timeout or cancellation occurred. If enabled, record
+		// the state of a target still running at its timeout, unless
+		// the whole cycle is ending.
+		if wg.cfg.Fuzz.DumpOnTimeout && wg.ctx.Err() == nil {
+			wg.dumpStacks(c, containerID, pkg, target)
+		}
 
 	case err := <-errorChan:
-		if err != nil {
-			// Container exited with an error (non-fuzz crash).
+		if err == nil {
+			break
+		}
+
+		// Container exited with an error (non-fuzz crash). Running out
+		// of memory is not a failure of the fuzzing process, so report
+		// it and carry on.
+		var exitErr *containerExitError
+		if errors.As(err, &exitErr) && exitErr.oomKilled {
+			wg.logger.Warn("Fuzz container killed for running "+
+				"out of memory; consider raising the "+
+				"container memory limit", "package", pkg,
+				"target", target, "limit",
+				exitErr.memoryLimit, "error", err)
+
+			result = TargetResultOOM
+			if wg.cfg.Fuzz.SuppressOOMIssues {
+				break
+			}
+
+			report, err := tracker.handleOOM(pkg, target, exitErr)
+			if err != nil {
+				return fmt.Errorf("handling out of memory "+
+					"failure: %w", err)
+			}
+			wg.summary.recordCrash(*report)
+
+			if report.New {
+				openIssues++
+			}
+			break
+		}
+
+		// If enabled, report any other non-zero exit status as an
+		// issue and carry on.
+		if !wg.cfg.Fuzz.ReportUnknownFailures ||
+			!errors.As(err, &exitErr) {
+
 			return fmt.Errorf("fuzz execution failed: %w", err)
 		}
 
+		wg.logger.Warn("Fuzz container failed without a recognized "+
+			"crash; reporting unknown failure", "package", pkg,
+			"target", target, "error", err)
+
+		report, err := tracker.handleUnknownFailure(pkg, target,
+			exitErr)
+		if err != nil {
+			return fmt.Errorf("handling unknown failure: %w", err)
+		}
+		wg.summary.recordCrash(*report)
+
+		result = TargetResultCrash
+		if report.New {
+			openIssues++
+		}
+
 	case fuzzCrash := <-fuzzCrashChan:
+		// An out-of-memory crash is reported like any other, but is
+		// suppressed along with the containers killed by Docker.
+		result = TargetResultCrash
+		if fuzzCrash.failureFileAndLine == OOMFailureLocation {
+			wg.logger.Warn("Fuzzer ran out of memory; consider "+
+				"raising the container memory limit",
+				"package", pkg, "target", target)
+
+			result = TargetResultOOM
+			if wg.cfg.Fuzz.SuppressOOMIssues {
+				break
+			}
+		}
+
 		// Report the fuzz crash.
-		if err := gh.handleCrash(pkg, target, fuzzCrash); err != nil {
+		report, err := wg.handleCrash(tracker, pkg, target, fuzzCrash)
+		if err != nil {
 			return fmt.Errorf("handling fuzz crash: %w", err)
 		}
+		wg.summary.recordCrash(*report)
+
+		if report.New || report.Reopened {
+			openIssues++
+		}
+
+		// If enabled, count the early crashes of the target, which
+		// disable it once it crashed early in enough consecutive
+		// cycles.
+		if wg.disable != nil && result == TargetResultCrash &&
+			wg.disable.earlyCrash(wg.engine, fuzzCrash) {
+
+			disabled, err := wg.handleEarlyCrash(tracker, pkg,
+				target, *report)
+			if err != nil {
+				return fmt.Errorf("handling early crash: %w",
+					err)
+			}
+			if disabled != nil {
+				wg.summary.recordCrash(*disabled)
+				if disabled.New {
+					openIssues++
+				}
+			}
+		}
+
+		// If enabled, also report the crashes of the other failing
+		// inputs saved by this run.
+		if !wg.cfg.Fuzz.ReportAllFailingInputs {
+			break
+		}
+
+		reports, err := wg.reportNewFailingInputs(tracker, pkg, target,
+			fuzzBinaryPath, hostCorpusPath, knownInputs,
+			fuzzCrash)
+		if err != nil {
+			return fmt.Errorf("reporting failing inputs: %w", err)
+		}
+		for _, report := range reports {
+			wg.summary.recordCrash(report)
+			if report.New || report.Reopened {
+				openIssues++
+			}
+		}
 	}
 
+	targetFuzzDuration.Set(time.Since(fuzzStart).Seconds(), pkg, target)
+
 	// Now stop the fuzz container.
 	if err := c.Stop(containerID); err != nil {
 		return fmt.Errorf("failed to stop container %s after fuzzing: "+
@@ -263,9 +640,48 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 	}
 
 	wg.logger.Info("Fuzzing in Docker completed successfully", "package",
-		pkg, "target", target)
+		pkg, "target", target, "execs", c.execs.Load())
+
+	// A target that ran its whole time slot without a crash, but executed
+	// fewer than the minimum inputs, was starved, e.g. by its build and
+	// startup overhead. A cycle ending cuts the slot short, so it does not
+	// count.
+	if wg.starve != nil && result == TargetResultOK &&
+		wg.ctx.Err() == nil && wg.starve.starves(c.execs.Load()) {
+
+		wg.logger.Warn("Fuzz target executed fewer than the minimum "+
+			"inputs in its time slot; build or startup overhead "+
+			"may be starving it", "package", pkg, "target", target,
+			"execs", c.execs.Load(), "minExecs",
+			wg.cfg.Fuzz.MinExecs, "duration", timeout)
+		wg.status.recordStarved(pkg, target)
+	}
 
-	err = updateReport(wg.ctx, pkg, target, wg.cfg, wg.logger)
+	// Persist the new inputs found by the fuzzer in the separate cache.
+	if wg.cfg.Fuzz.FuzzCacheDir != "" {
+		promoted, err := promoteFuzzCache(fuzzCachePath, hostCorpusPath,
+			target)
+		if err != nil {
+			return fmt.Errorf("promoting fuzz cache: %w", err)
+		}
+		wg.logger.Info("Promoted new inputs from fuzz cache", "package",
+			pkg, "target", target, "count", promoted)
+	}
+
+	// Coverage reports, corpus minimization and capping run the corpus
+	// through `go test`, which requires it to be in Go's corpus file
+	// format.
+	if !wg.engine.goCorpus() {
+		wg.logger.Info("Skipping coverage report and corpus "+
+			"minimization for non-Go corpus format", "package", pkg,
+			"target", target, "engine", wg.cfg.Fuzz.Engine)
+		wg.summary.recordTarget(pkg, target, "")
+		wg.status.record(pkg, target, result, "", openIssues)
+		return wg.saveCorpus(pkg, target)
+	}
+
+	coverage, err := updateReport(wg.ctx, pkg, target, workerID, wg.cfg,
+		wg.logger)
 	if err != nil {
 		return fmt.Errorf("failed to add coverage report for package "+
 			"%s, target %s: %w", pkg, target, err)
@@ -274,6 +690,55 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 	wg.logger.Info("Successfully added/updated coverage report", "package",
 		pkg, "target", target)
 
+	if pct, err := strconv.ParseFloat(coverage, 64); err == nil {
+		targetCoverage.Set(pct, pkg, target)
+	}
+
+	// If enabled, assess whether the target is deterministic by measuring
+	// the coverage of its corpus repeatedly.
+	if wg.cfg.Fuzz.FlakinessRuns > 0 {
+		measurements, err := measureCoverageStability(wg.ctx,
+			wg.logger.With("target", target).With("package", pkg),
+			hostPkgPath, hostCorpusPath, target,
+			wg.cfg.Fuzz.FlakinessRuns)
+		if err != nil {
+			return fmt.Errorf("assessing coverage stability for "+
+				"target %q: %w", target, err)
+		}
+
+		unstable := coverageUnstable(measurements)
+		switch {
+		// The coverage of a bootstrap cycle's corpus, grown from
+		// scratch, is only a baseline, so it is not reported.
+		case unstable && wg.bootstrapping:
+			wg.logger.Warn("Nondeterministic fuzz target during "+
+				"bootstrap cycle; not reporting",
+				"package", pkg, "target", target,
+				"measurements", measurements)
+
+		case unstable:
+			wg.logger.Warn("Nondeterministic fuzz target: "+
+				"coverage varies across runs of the same "+
+				"corpus",
+				"package", pkg, "target", target,
+				"measurements", measurements)
+
+			report, err := tracker.handleFlakyCoverage(pkg, target,
+				measurements)
+			if err != nil {
+				return fmt.Errorf("handling flaky coverage: %w",
+					err)
+			}
+			wg.summary.recordCrash(*report)
+
+			if report.New {
+				openIssues++
+			}
+		}
+	}
+	wg.summary.recordTarget(pkg, target, coverage)
+	wg.status.record(pkg, target, result, coverage, openIssues)
+
 	// Minimize the corpus if needed.
 	if wg.shouldMinimizeCorpus {
 		err := MinimizeCorpus(wg.ctx, wg.logger.With("target", target).
@@ -285,5 +750,192 @@ func (wg *WorkerGroup) executeFuzzTarget(pkg string, target string,
 		}
 	}
 
-	return nil
+	// Evict the least valuable inputs beyond the maximum number of corpus
+	// files, which bounds the time of the fuzzer's baseline coverage pass.
+	if wg.cfg.Fuzz.MaxCorpusFiles > 0 {
+		_, err := CapCorpusFiles(wg.ctx, wg.logger.With("target",
+			target).With("package", pkg), hostPkgPath,
+			hostCorpusPath, target, wg.cfg.Fuzz.MaxCorpusFiles)
+		if err != nil {
+			return fmt.Errorf("capping corpus files for target "+
+				"%q: %w", target, err)
+		}
+	}
+
+	return wg.saveCorpus(pkg, target)
+}
+
+// saveCorpus persists the corpus of the target with the corpus saver, if one
+// is configured, once its corpus directory holds the inputs of the fuzz run.
+func (wg *WorkerGroup) saveCorpus(pkg, target string) error {
+	if wg.cfg.Fuzz.CorpusSaver == "" {
+		return nil
+	}
+
+	return runCorpusHook(wg.ctx, wg.logger, wg.cfg, "corpus saver",
+		wg.cfg.Fuzz.CorpusSaver, pkg, target)
+}
+
+// handleCrash reports the fuzz crash of the target to the crash tracker, or
+// adds it to the digest in digest mode, and, if enabled, exports its failing
+// input to the crash export directory and posts its report to the webhook.
+// Returns the report of the crash.
+func (wg *WorkerGroup) handleCrash(tracker CrashTracker, pkg, target string,
+	fc fuzzCrash) (*crashReport, error) {
+
+	var report *crashReport
+	if wg.digest != nil {
+		report = tracker.digestCrash(pkg, target, fc, wg.digest)
+	} else {
+		var err error
+		report, err = tracker.handleCrash(pkg, target, fc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if wg.cfg.Fuzz.CrashExportDir != "" {
+		err := exportCrash(wg.cfg.Fuzz.CrashExportDir, *report,
+			fc.failingInput, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("exporting crash: %w", err)
+		}
+	}
+
+	// A webhook that cannot be reached only misses a notification, so it
+	// does not abort the cycle.
+	if wg.cfg.Fuzz.WebhookURL != "" {
+		err := postCrashWebhook(wg.ctx, wg.cfg, *report, time.Now())
+		if err != nil {
+			wg.logger.Warn("Failed to post crash to webhook",
+				"package", pkg, "target", target, "error", err)
+		}
+	}
+
+	return report, nil
+}
+
+// reportNewFailingInputs reports the crashes of the failing inputs saved by the
+// fuzz run of the target besides the input of the already reported crash, that
+// is those of its failing input directory that are not among knownInputs. Each
+// input is reproduced on its own to get its error logs, and crashes sharing the
+// signature of the reported crash or of another input are only reported once.
+// Returns the reports of the crashes.
+func (wg *WorkerGroup) reportNewFailingInputs(tracker CrashTracker, pkg,
+	target, fuzzBinaryPath, hostCorpusPath string,
+	knownInputs map[string]bool, reported fuzzCrash) ([]crashReport,
+	error) {
+
+	failingDir := filepath.Join(fuzzBinaryPath, "testdata", "fuzz", target)
+	inputs, err := listFileNames(failingDir)
+	if err != nil {
+		return nil, fmt.Errorf("listing failing inputs: %w", err)
+	}
+
+	signature, _ := tracker.crashSignature(reported)
+	seen := map[string]bool{signature: true}
+
+	var reports []crashReport
+	for _, input := range slices.Sorted(maps.Keys(inputs)) {
+		if wg.ctx.Err() != nil {
+			break
+		}
+		if knownInputs[input] || input == reported.failingInputID {
+			continue
+		}
+
+		fc, err := wg.reproduceFailingInput(pkg, target,
+			fuzzBinaryPath, hostCorpusPath, input)
+		if err != nil {
+			return nil, err
+		}
+		if fc == nil {
+			wg.logger.Warn("Failing input did not reproduce a "+
+				"crash; not reporting it", "package", pkg,
+				"target", target, "input", input)
+			continue
+		}
+
+		signature, _ := tracker.crashSignature(*fc)
+		if seen[signature] {
+			wg.logger.Info("Failing input reproduces an already "+
+				"reported crash", "package", pkg, "target",
+				target, "input", input, "signature", signature)
+			continue
+		}
+		seen[signature] = true
+
+		report, err := wg.handleCrash(tracker, pkg, target, *fc)
+		if err != nil {
+			return nil, fmt.Errorf("handling crash of failing "+
+				"input %q: %w", input, err)
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, nil
+}
+
+// reproduceFailingInput runs the target against the failing input saved under
+// the given name in its failing input directory, in a container bounded by the
+// per-target timeout. Returns the crash, with the input attached, or nil if it
+// did not reproduce.
+func (wg *WorkerGroup) reproduceFailingInput(pkg, target, fuzzBinaryPath,
+	hostCorpusPath, input string) (*fuzzCrash, error) {
+
+	inputPath := filepath.Join(fuzzBinaryPath, "testdata", "fuzz", target,
+		input)
+	failingInput, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading failing input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(wg.ctx, wg.taskTimeout+
+		ContainerGracePeriod)
+	defer cancel()
+
+	c := newContainer(ctx, wg.logger, wg.cli, &wg.cfg.Fuzz, wg.engine, pkg,
+		target)
+	c.fuzzBinaryPath = fuzzBinaryPath
+	c.hostCorpusPath = hostCorpusPath
+	c.cmd = wg.engine.reproduceCmd(target, input)
+
+	containerID, err := c.Start()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to start reproduction "+
+			"container: %w", err)
+	}
+	defer func() {
+		if err := c.Stop(containerID); err != nil {
+			wg.logger.Error("Failed to stop container", "error",
+				err, "containerID", containerID)
+		}
+	}()
+
+	fuzzCrashChan := make(chan fuzzCrash, 1)
+	errorChan := make(chan error, 1)
+	go c.WaitAndGetLogs(containerID, pkg, target, fuzzCrashChan, errorChan)
+
+	select {
+	case <-ctx.Done():
+		return nil, nil
+
+	case err := <-errorChan:
+		// An exit without a recognized crash is not reproducing it.
+		var exitErr *containerExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("reproducing failing input: %w",
+				err)
+		}
+		return nil, nil
+
+	case fc := <-fuzzCrashChan:
+		// Reproductions do not report where the input is saved.
+		fc.failingInput = string(failingInput)
+		fc.failingInputID = input
+		return &fc, nil
+	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTaskQueueSort verifies that sorting the task queue orders its tasks by
+// package path, and then by target.
+func TestTaskQueueSort(t *testing.T) {
+	q := NewTaskQueue()
+	for _, task := range []Task{
+		{PackagePath: "parser", Target: "FuzzParse"},
+		{PackagePath: "eval", Target: "FuzzEval"},
+		{PackagePath: "parser", Target: "FuzzLex"},
+		{PackagePath: "eval/expr", Target: "FuzzExpr"},
+	} {
+		q.Enqueue(task)
+	}
+
+	q.Sort()
+	assert.Equal(t, []Task{
+		{PackagePath: "eval", Target: "FuzzEval"},
+		{PackagePath: "eval/expr", Target: "FuzzExpr"},
+		{PackagePath: "parser", Target: "FuzzLex"},
+		{PackagePath: "parser", Target: "FuzzParse"},
+	}, q.Tasks())
+
+	task, ok := q.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, Task{PackagePath: "eval", Target: "FuzzEval"}, task)
+}
+
+// TestFuzzTimeout verifies that a target is fuzzed for its weighted timeout, if
+// any, or the per-task timeout, plus its extra time if it was starved.
+func TestFuzzTimeout(t *testing.T) {
+	wg := &WorkerGroup{
+		taskTimeout: time.Minute,
+		taskTimeouts: map[Task]time.Duration{
+			{PackagePath: "parser", Target: "FuzzEval"}: 90 *
+				time.Second,
+		},
+		starve: &starvePolicy{
+			extraTime: 30 * time.Second,
+			starved: map[TargetState]bool{
+				{PkgPath: "parser", Target: "FuzzEval"}: true,
+				{PkgPath: "tree", Target: "FuzzBuild"}:  true,
+			},
+		},
+	}
+
+	assert.Equal(t, 2*time.Minute, wg.fuzzTimeout("parser", "FuzzEval"))
+	assert.Equal(t, 90*time.Second, wg.fuzzTimeout("tree", "FuzzBuild"))
+	assert.Equal(t, time.Minute, wg.fuzzTimeout("parser", "FuzzLex"))
+}
+
+// TestTaskQueuePriority verifies that tasks of a higher priority are dequeued
+// first, in FIFO order within a priority, and that sorting keeps the tasks
+// within their priority.
+func TestTaskQueuePriority(t *testing.T) {
+	q := NewTaskQueue()
+	q.Enqueue(Task{PackagePath: "parser", Target: "FuzzParse"})
+	q.EnqueuePriority(Task{PackagePath: "tree", Target: "FuzzBuild"},
+		TaskPriorityCrashing)
+	q.Enqueue(Task{PackagePath: "eval", Target: "FuzzEval"})
+	q.EnqueuePriority(Task{PackagePath: "lexer", Target: "FuzzLex"},
+		TaskPriorityCrashing)
+
+	assert.Equal(t, []Task{
+		{PackagePath: "tree", Target: "FuzzBuild"},
+		{PackagePath: "lexer", Target: "FuzzLex"},
+		{PackagePath: "parser", Target: "FuzzParse"},
+		{PackagePath: "eval", Target: "FuzzEval"},
+	}, q.Tasks())
+
+	q.Sort()
+	assert.Equal(t, []Task{
+		{PackagePath: "lexer", Target: "FuzzLex"},
+		{PackagePath: "tree", Target: "FuzzBuild"},
+		{PackagePath: "eval", Target: "FuzzEval"},
+		{PackagePath: "parser", Target: "FuzzParse"},
+	}, q.Tasks())
+
+	task, ok := q.Dequeue()
+	assert.True(t, ok)
+	assert.Equal(t, Task{PackagePath: "lexer", Target: "FuzzLex"}, task)
+	assert.Equal(t, 3, q.Length())
+}